@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	catCheck  bool
+	catOffset int64
+	catLength int64
+)
+
+// catCommand writes the contents of the Git LFS object referenced by the given
+// file, which may be a path in the working tree or a revision of the form
+// "<ref>:<path>" or ":<path>", to standard output, downloading the object if
+// it is not present locally.  With --offset or --length, only that range of
+// the object is written, and only that range is downloaded if the object is
+// not present locally and the server allows it.
+func catCommand(cmd *cobra.Command, args []string) {
+	setupRepository()
+
+	if len(args) != 1 {
+		Exit(tr.Tr.Get("Usage: git lfs cat [--check | --offset=<n> --length=<n>] <path> | <ref>:<path>"))
+	}
+
+	ranged := cmd.Flags().Changed("offset") || cmd.Flags().Changed("length")
+	if ranged && catCheck {
+		Exit(tr.Tr.Get("Cannot combine --check with --offset or --length"))
+	}
+	if catOffset < 0 {
+		Exit(tr.Tr.Get("Invalid offset: %d", catOffset))
+	}
+	if cmd.Flags().Changed("length") && catLength < 0 {
+		Exit(tr.Tr.Get("Invalid length: %d", catLength))
+	}
+
+	ptr, err := catPointer(args[0])
+	if err != nil {
+		if errors.IsNotAPointerError(err) {
+			Exit(tr.Tr.Get("%s is not a Git LFS file", args[0]))
+		}
+		ExitWithError(err)
+	}
+
+	gitfilter := lfs.NewGitFilter(cfg)
+	manifest := getTransferManifestOperationRemote("download", cfg.Remote())
+
+	var r io.ReadCloser
+	if ranged {
+		r, err = gitfilter.ReadObjectRange(ptr, catOffset, catLength, manifest)
+	} else {
+		r, err = gitfilter.Open(ptr, true, manifest)
+	}
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not read object for %s", args[0])))
+	}
+	defer r.Close()
+
+	if catCheck {
+		return
+	}
+
+	if _, err := io.Copy(os.Stdout, r); err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not write object for %s", args[0])))
+	}
+}
+
+// catPointer returns the pointer for the given file.  A path which exists in
+// the working tree is read from there, unless the working tree contains the
+// object's contents rather than its pointer, in which case the pointer in the
+// index is used.  Any other argument is treated as a revision naming a blob.
+func catPointer(arg string) (*lfs.Pointer, error) {
+	if stat, err := os.Stat(arg); err == nil && stat.Mode().IsRegular() {
+		ptr, err := lfs.DecodePointerFromFile(arg)
+		if err == nil || !errors.IsNotAPointerError(err) {
+			return ptr, err
+		}
+		return catPointerFromBlob(catIndexRevision(arg))
+	}
+
+	if !strings.Contains(arg, ":") {
+		return nil, errors.New(tr.Tr.Get("%s: no such file", arg))
+	}
+	return catPointerFromBlob(arg)
+}
+
+// catIndexRevision returns the revision naming the index entry for the given
+// path in the working tree.
+func catIndexRevision(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return ":./" + filepath.ToSlash(path)
+}
+
+func catPointerFromBlob(rev string) (*lfs.Pointer, error) {
+	sha, err := git.ResolveObject(rev)
+	if err != nil {
+		return nil, err
+	}
+	oid, err := hex.DecodeString(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := getObjectDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	blob, err := db.Blob(oid)
+	if err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("Could not read %s", rev))
+	}
+	defer blob.Close()
+
+	return lfs.DecodePointerFromBlob(blob)
+}
+
+func init() {
+	RegisterCommand("cat", catCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&catCheck, "check", "", false, "Only check that the object is available")
+		cmd.Flags().Int64VarP(&catOffset, "offset", "", 0, "Print the object's contents starting at this byte")
+		cmd.Flags().Int64VarP(&catLength, "length", "", -1, "Print at most this many bytes of the object's contents")
+	})
+}
@@ -23,19 +23,20 @@ var (
 
 func checkoutCommand(cmd *cobra.Command, args []string) {
 	setupRepository()
+	requireWorkingCopy()
 
 	stage, err := whichCheckout()
 	if err != nil {
 		Exit(tr.Tr.Get("Error parsing args: %v", err))
 	}
 
-	if checkoutTo != "" && stage != git.IndexStageDefault {
+	if checkoutTo != "" {
 		if len(args) != 1 {
 			Exit(tr.Tr.Get("--to requires exactly one Git LFS object file path"))
 		}
-		checkoutConflict(rootedPaths(args)[0], stage)
+		checkoutToPath(rootedPaths(args)[0], stage)
 		return
-	} else if checkoutTo != "" || stage != git.IndexStageDefault {
+	} else if stage != git.IndexStageDefault {
 		Exit(tr.Tr.Get("--to and exactly one of --theirs, --ours, and --base must be used together"))
 	}
 
@@ -58,6 +59,7 @@ func checkoutCommand(cmd *cobra.Command, args []string) {
 	meter := tq.NewMeter(cfg)
 	meter.Direction = tq.Checkout
 	meter.Logger = meter.LoggerFromEnv(cfg.Os)
+	meter.EventLogger = progressEventLogger(meter)
 	logger.Enqueue(meter)
 	chgitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
@@ -67,7 +69,7 @@ func checkoutCommand(cmd *cobra.Command, args []string) {
 
 		totalBytes += p.Size
 		meter.Add(p.Size)
-		meter.StartTransfer(p.Name)
+		meter.StartTransfer(p.Name, p.Oid)
 		pointers = append(pointers, p)
 	})
 
@@ -91,7 +93,11 @@ func checkoutCommand(cmd *cobra.Command, args []string) {
 	singleCheckout.Close()
 }
 
-func checkoutConflict(file string, stage git.IndexStage) {
+// checkoutToPath writes the content of the Git LFS object file at the given
+// path to the path given by --to, leaving the working tree untouched. The
+// object is read from the given stage of the index, which is the default
+// stage unless one side of a merge conflict was requested.
+func checkoutToPath(file string, stage git.IndexStage) {
 	singleCheckout := newSingleCheckout(cfg.Git, "")
 	if singleCheckout.Skip() {
 		fmt.Println(tr.Tr.Get("Cannot checkout LFS objects, Git LFS is not installed."))
@@ -99,7 +105,9 @@ func checkoutConflict(file string, stage git.IndexStage) {
 	}
 
 	ref, err := git.ResolveRef(fmt.Sprintf(":%d:%s", stage, file))
-	if err != nil {
+	if err != nil && stage == git.IndexStageDefault {
+		Exit(tr.Tr.Get("Could not checkout %q (is it in the index?): %v", file, err))
+	} else if err != nil {
 		Exit(tr.Tr.Get("Could not checkout (are you not in the middle of a merge?): %v", err))
 	}
 
@@ -166,7 +174,7 @@ func rootedPaths(args []string) []string {
 
 func init() {
 	RegisterCommand("checkout", checkoutCommand, func(cmd *cobra.Command) {
-		cmd.Flags().StringVar(&checkoutTo, "to", "", "Checkout a conflicted file to this path")
+		cmd.Flags().StringVar(&checkoutTo, "to", "", "Checkout a file to this path instead of the working tree")
 		cmd.Flags().BoolVar(&checkoutOurs, "ours", false, "Checkout our version of a conflicted file")
 		cmd.Flags().BoolVar(&checkoutTheirs, "theirs", false, "Checkout their version of a conflicted file")
 		cmd.Flags().BoolVar(&checkoutBase, "base", false, "Checkout the base version of a conflicted file")
@@ -7,11 +7,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/git-lfs/v3/subprocess"
 	"github.com/git-lfs/git-lfs/v3/tr"
 
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +21,10 @@ var (
 	cloneFlags git.CloneFlags
 
 	cloneSkipRepoInstall bool
+
+	// cloneIncludePaths are the paths given with --include-path, in
+	// addition to those given with --include.
+	cloneIncludePaths []string
 )
 
 func cloneCommand(cmd *cobra.Command, args []string) {
@@ -79,8 +85,14 @@ func cloneCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if ref, err := git.CurrentRef(); err == nil {
-		includeArg, excludeArg := getIncludeExcludeArgs(cmd)
-		filter := buildFilepathFilter(cfg, includeArg, excludeArg, true)
+		// The objects to fetch are found from the tree of the
+		// checked-out commit alone, never from its history, so a
+		// shallow clone needs no commits beyond its boundary.
+		if shallow, err := git.IsShallow(); err == nil && shallow {
+			tracerx.Printf("clone: shallow clone, fetching Git LFS objects for %s only", ref.Name)
+		}
+
+		filter := buildCloneFilepathFilter(cmd)
 		if cloneFlags.NoCheckout || cloneFlags.Bare {
 			// If --no-checkout or --bare then we shouldn't check out, just fetch instead
 			fetchRef(ref.Name, filter)
@@ -103,6 +115,26 @@ func cloneCommand(cmd *cobra.Command, args []string) {
 	}
 }
 
+// buildCloneFilepathFilter returns the filter for the paths of the objects to
+// fetch after cloning. Any paths given with --include-path are included along
+// with those given with --include, and, like them, replace any paths in the
+// lfs.fetchinclude setting.
+func buildCloneFilepathFilter(cmd *cobra.Command) *filepathfilter.Filter {
+	includeArg, excludeArg := getIncludeExcludeArgs(cmd)
+	include, exclude := determineIncludeExcludePaths(cfg, includeArg, excludeArg, true)
+
+	if len(cloneIncludePaths) > 0 {
+		if includeArg == nil {
+			include = nil
+		}
+		for _, paths := range cloneIncludePaths {
+			include = append(include, tools.CleanPaths(paths, ",")...)
+		}
+	}
+
+	return filepathfilter.New(include, exclude, filepathfilter.GitIgnore)
+}
+
 func postCloneSubmodules(args []string) error {
 	// In git 2.9+ the filter option will have been passed through to submodules
 	// So we need to lfs pull inside each
@@ -167,6 +199,7 @@ func init() {
 
 		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths")
 		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths")
+		cmd.Flags().StringArrayVar(&cloneIncludePaths, "include-path", nil, "Also include the given paths")
 
 		cmd.Flags().BoolVar(&cloneSkipRepoInstall, "skip-repo", false, "Skip LFS repo setup")
 	})
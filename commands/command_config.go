@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/git-lfs/git-lfs/v3/config"
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configLocal  bool
+	configGlobal bool
+	configSystem bool
+	configFile   string
+	configList   bool
+)
+
+// configCommand gets or sets a single Git LFS configuration key, validating
+// and normalizing the value of any key which Git LFS reads, or lists every
+// Git LFS setting which takes effect along with where it comes from.
+func configCommand(cmd *cobra.Command, args []string) {
+	scopes := 0
+	for _, set := range []bool{configLocal, configGlobal, configSystem, len(configFile) > 0} {
+		if set {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		Exit(tr.Tr.Get("Only one of --local, --global, --system, or --file may be given"))
+	}
+
+	if len(configFile) > 0 {
+		// Git is run in the Git directory, so resolve the file
+		// against the current directory first.
+		file, err := filepath.Abs(configFile)
+		if err != nil {
+			ExitWithError(err)
+		}
+		configFile = file
+	}
+
+	if configList {
+		if len(args) > 0 || scopes > 0 {
+			Exit(tr.Tr.Get("Usage: git lfs config --list"))
+		}
+		listConfig()
+		return
+	}
+
+	if len(args) < 1 || len(args) > 2 {
+		Exit(tr.Tr.Get("Usage: git lfs config [--local | --global | --system | --file <file>] <key> [<value>]"))
+	}
+
+	key := args[0]
+	known, ok := config.LookupKey(key)
+	if !ok {
+		Error(tr.Tr.Get("warning: unknown Git LFS configuration key %q", key))
+	}
+
+	if len(args) == 1 {
+		if value, ok := getConfig(key); ok {
+			Print(value)
+			return
+		}
+		os.Exit(1)
+	}
+
+	value := args[1]
+	if known != nil {
+		normalized, err := known.Normalize(value)
+		if err != nil {
+			Exit(tr.Tr.Get("Invalid value %q for %s: %s", value, key, err))
+		}
+		value = normalized
+	}
+
+	if len(configFile) > 0 && filepath.Base(configFile) == ".lfsconfig" && !config.IsSafeKey(key) {
+		Error(tr.Tr.Get("warning: %s is ignored when read from a .lfsconfig file", key))
+	}
+
+	if err := setConfig(key, value); err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not set %s", key)))
+	}
+}
+
+// getConfig returns the value of the given key in the scope given on the
+// command line, or the value which takes effect if no scope was given.
+func getConfig(key string) (string, bool) {
+	var value string
+	switch {
+	case configLocal:
+		requireInRepo()
+		value = cfg.FindGitLocalKey(key)
+	case configGlobal:
+		value = cfg.FindGitGlobalKey(key)
+	case configSystem:
+		value = cfg.FindGitSystemKey(key)
+	case len(configFile) > 0:
+		value = cfg.GitConfig().FindFile(configFile, key)
+	default:
+		return cfg.Git.Get(key)
+	}
+	return value, len(value) > 0
+}
+
+// setConfig sets the given key in the scope given on the command line, which
+// is the local repository's configuration by default.
+func setConfig(key, value string) error {
+	var err error
+	switch {
+	case configGlobal:
+		_, err = cfg.SetGitGlobalKey(key, value)
+	case configSystem:
+		_, err = cfg.SetGitSystemKey(key, value)
+	case len(configFile) > 0:
+		_, err = cfg.GitConfig().SetFile(configFile, key, value)
+	default:
+		requireInRepo()
+		_, err = cfg.SetGitLocalKey(key, value)
+	}
+	return err
+}
+
+// listConfig prints each Git LFS setting which takes effect, preceded by the
+// origin of its value in the same form as "git config --show-origin".
+// Settings which are not set in any Git configuration file come from the
+// repository's .lfsconfig file.
+func listConfig() {
+	origins, err := cfg.GitConfig().Origins()
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not read Git configuration")))
+	}
+
+	all := cfg.Git.All()
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		if config.IsLFSKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		origin, ok := origins[key]
+		if !ok {
+			origin = ".lfsconfig"
+		}
+		values := all[key]
+		Print("%s\t%s=%s", origin, key, values[len(values)-1])
+	}
+}
+
+func init() {
+	RegisterCommand("config", configCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&configLocal, "local", "", false, "Use the local Git repository's configuration.")
+		cmd.Flags().BoolVarP(&configGlobal, "global", "", false, "Use the global Git configuration.")
+		cmd.Flags().BoolVarP(&configSystem, "system", "", false, "Use the system-wide Git configuration.")
+		cmd.Flags().StringVarP(&configFile, "file", "f", "", "Use the given configuration file, such as .lfsconfig.")
+		cmd.Flags().BoolVarP(&configList, "list", "l", false, "List every Git LFS setting and where it is set.")
+	})
+}
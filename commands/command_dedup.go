@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"encoding/hex"
+	"io"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -16,11 +18,13 @@ import (
 
 var (
 	dedupFlags = struct {
-		test bool
+		test   bool
+		dryRun bool
 	}{}
 	dedupStats = &struct {
 		totalProcessedCount int64
 		totalProcessedSize  int64
+		totalSkippedCount   int64
 	}{}
 )
 
@@ -41,11 +45,107 @@ func dedupTestCommand(*cobra.Command, []string) {
 	Print(tr.Tr.Get("OK: This platform and repository support file de-duplication."))
 }
 
+// dedupDryRunCommand reports which files in the working tree could be
+// de-duplicated, and how much space doing so would reclaim, without
+// modifying any of them.
+func dedupDryRunCommand(*cobra.Command, []string) {
+	setupRepository()
+
+	var supported bool
+	if gitDir, err := git.GitDir(); err != nil {
+		ExitWithError(err)
+	} else {
+		supported, _ = tools.CheckCloneFileSupported(gitDir)
+	}
+
+	if len(cfg.Extensions()) > 0 {
+		Exit(tr.Tr.Get("This platform supports file de-duplication, however, Git LFS extensions are configured and therefore de-duplication can not be used."))
+	}
+
+	gitScanner := lfs.NewGitScanner(config.New(), func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			Exit(tr.Tr.Get("Could not scan for Git LFS tree: %s", err))
+			return
+		}
+
+		if err := dedupCheck(p, supported); err != nil {
+			// TRANSLATORS: Leading spaces should be included on
+			// the second line so the format specifier aligns with
+			// with the first format specifier on the first line.
+			Error(tr.Tr.Get("Skipped: %s (Size: %d)\n          %s", p.Name, p.Size, err))
+
+			atomic.AddInt64(&dedupStats.totalSkippedCount, 1)
+		} else {
+			Print(tr.Tr.Get("Would de-duplicate: %s (Size: %d)", p.Name, p.Size))
+
+			atomic.AddInt64(&dedupStats.totalProcessedCount, 1)
+			atomic.AddInt64(&dedupStats.totalProcessedSize, p.Size)
+		}
+	})
+
+	if err := gitScanner.ScanTree("HEAD", nil); err != nil {
+		ExitWithError(err)
+	}
+
+	// TRANSLATORS: The second, third, and fourth strings should have the
+	// colons aligned in a column.
+	Print("\n\n%s\n  %s\n  %s\n  %s", tr.Tr.Get("Dry run finished; no files were changed."),
+		tr.Tr.GetN(
+			"Reclaimable size: %d byte",
+			"Reclaimable size: %d bytes",
+			int(dedupStats.totalProcessedSize),
+			dedupStats.totalProcessedSize),
+		tr.Tr.Get("           count: %d", dedupStats.totalProcessedCount),
+		tr.Tr.Get("         skipped: %d", dedupStats.totalSkippedCount))
+}
+
+// dedupCheck returns an error describing why the given file can not be
+// de-duplicated, or nil if it can be.
+func dedupCheck(p *lfs.WrappedPointer, supported bool) error {
+	if !cfg.LFSObjectExists(p.Oid, p.Size) {
+		return errors.New(tr.Tr.Get("Git LFS object file does not exist"))
+	}
+
+	if matches, err := dedupContentMatches(p); err != nil {
+		return err
+	} else if !matches {
+		return errors.New(tr.Tr.Get("Working tree file does not match Git LFS object"))
+	}
+
+	if !supported {
+		return errors.New(tr.Tr.Get("This system does not support de-duplication."))
+	}
+	return nil
+}
+
+// dedupContentMatches returns whether the working tree file for p has the
+// contents of the Git LFS object it refers to.
+func dedupContentMatches(p *lfs.WrappedPointer) (bool, error) {
+	f, err := os.Open(filepath.Join(cfg.LocalWorkingDir(), p.Name))
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	hasher, err := tools.NewLfsContentHashFor(p.OidType)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(hasher, f); err != nil {
+		return false, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)) == p.Oid, nil
+}
+
 func dedupCommand(cmd *cobra.Command, args []string) {
 	if dedupFlags.test {
 		dedupTestCommand(cmd, args)
 		return
 	}
+	if dedupFlags.dryRun {
+		dedupDryRunCommand(cmd, args)
+		return
+	}
 
 	setupRepository()
 	if gitDir, err := git.GitDir(); err != nil {
@@ -143,5 +243,6 @@ func dedup(p *lfs.WrappedPointer) (success bool, err error) {
 func init() {
 	RegisterCommand("dedup", dedupCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&dedupFlags.test, "test", "t", false, "test")
+		cmd.Flags().BoolVarP(&dedupFlags.dryRun, "dry-run", "d", false, "Report what would be de-duplicated without changing anything")
 	})
 }
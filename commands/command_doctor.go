@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/git/gitattr"
+	"github.com/git-lfs/git-lfs/v3/tq"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorJSON bool
+)
+
+const (
+	doctorPass = "pass"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorMaxClockSkew is the largest difference between the local time and
+// that of the Git LFS server which the "clock" check allows.
+const doctorMaxClockSkew = 5 * time.Minute
+
+// doctorCheck is the result of a single diagnostic check.
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+func doctorCommand(cmd *cobra.Command, args []string) {
+	setupRepository()
+
+	if len(args) > 0 {
+		if err := cfg.SetValidRemote(args[0]); err != nil {
+			Exit(tr.Tr.Get("Invalid remote name %q: %s", args[0], err))
+		}
+	}
+
+	checks := []*doctorCheck{
+		doctorCheckFilters(),
+		doctorCheckAttributes(),
+		doctorCheckStorage(),
+	}
+	checks = append(checks, doctorCheckRemote(cfg.Remote())...)
+
+	failed := false
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed = true
+		}
+	}
+
+	if doctorJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", " ")
+		if err := encoder.Encode(struct {
+			Checks []*doctorCheck `json:"checks"`
+		}{checks}); err != nil {
+			ExitWithError(err)
+		}
+	} else {
+		for _, c := range checks {
+			var status string
+			switch c.Status {
+			case doctorPass:
+				status = tr.Tr.Get("PASS")
+			case doctorFail:
+				status = tr.Tr.Get("FAIL")
+			default:
+				status = tr.Tr.Get("SKIP")
+			}
+			Print("%s %s: %s", status, c.Name, c.Message)
+			if len(c.Hint) > 0 {
+				Print("     %s", c.Hint)
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// doctorCheckFilters checks that the Git LFS filters are configured, as
+// they are by "git lfs install".
+func doctorCheckFilters() *doctorCheck {
+	c := &doctorCheck{Name: "filters"}
+
+	var missing []string
+	for _, key := range envFilterKeys {
+		if v, _ := cfg.Git.Get(key); len(v) == 0 {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		c.Status = doctorFail
+		c.Message = tr.Tr.Get("Git LFS filters are not configured: %s", strings.Join(missing, ", "))
+		c.Hint = tr.Tr.Get("Run `git lfs install` to configure them.")
+		return c
+	}
+	c.Status = doctorPass
+	c.Message = tr.Tr.Get("Git LFS filters are configured")
+	return c
+}
+
+// doctorCheckAttributes checks that some files are tracked by Git LFS in a
+// .gitattributes file.
+func doctorCheckAttributes() *doctorCheck {
+	c := &doctorCheck{Name: "attributes"}
+
+	tracked := 0
+	paths := git.GetAttributePaths(gitattr.NewMacroProcessor(), cfg.LocalWorkingDir(), cfg.LocalGitDir())
+	for _, p := range paths {
+		if p.Tracked {
+			tracked++
+		}
+	}
+
+	if tracked == 0 {
+		c.Status = doctorFail
+		c.Message = tr.Tr.Get("No files are tracked by Git LFS")
+		c.Hint = tr.Tr.Get("Run `git lfs track` to track files, and commit the `.gitattributes` file.")
+		return c
+	}
+	c.Status = doctorPass
+	c.Message = tr.Tr.GetN("%d pattern is tracked by Git LFS", "%d patterns are tracked by Git LFS", tracked, tracked)
+	return c
+}
+
+// doctorCheckStorage checks that objects can be written to the local object
+// directory.
+func doctorCheckStorage() *doctorCheck {
+	c := &doctorCheck{Name: "storage"}
+
+	dir := cfg.LFSObjectDir()
+	f, err := os.CreateTemp(dir, "doctor")
+	if err != nil {
+		c.Status = doctorFail
+		c.Message = tr.Tr.Get("Unable to write to %s: %s", dir, err)
+		c.Hint = tr.Tr.Get("Check the permissions of the directory, or set `lfs.storage` to a writable directory.")
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	c.Status = doctorPass
+	c.Message = tr.Tr.Get("%s is writable", dir)
+	return c
+}
+
+// doctorCheckRemote checks the Git LFS endpoint of the given remote by making
+// a download batch request for a single, nonexistent, object, and returns the
+// results of the "endpoint", "credentials", "batch", and "clock" checks. A
+// check is skipped if an earlier one fails.
+func doctorCheckRemote(remote string) []*doctorCheck {
+	endpointCheck := &doctorCheck{Name: "endpoint"}
+	credsCheck := &doctorCheck{Name: "credentials", Status: doctorSkip}
+	batchCheck := &doctorCheck{Name: "batch", Status: doctorSkip}
+	clockCheck := &doctorCheck{
+		Name:    "clock",
+		Status:  doctorSkip,
+		Message: tr.Tr.Get("No response from the endpoint to compare the time with"),
+	}
+	checks := []*doctorCheck{endpointCheck, credsCheck, batchCheck, clockCheck}
+
+	endpoint := getAPIClient().Endpoints.Endpoint("download", remote)
+	if len(endpoint.Url) == 0 {
+		endpointCheck.Status = doctorFail
+		endpointCheck.Message = tr.Tr.Get("No Git LFS endpoint is configured for remote %q", remote)
+		endpointCheck.Hint = tr.Tr.Get("Add a Git remote, or set `lfs.url` to the URL of the Git LFS API.")
+		credsCheck.Message = tr.Tr.Get("No endpoint to authenticate with")
+		batchCheck.Message = tr.Tr.Get("No endpoint to send a request to")
+		return checks
+	}
+
+	// An object which does not exist on any server; the server's response
+	// need only be well-formed.
+	obj := &tq.Transfer{Oid: strings.Repeat("0", 64), Size: 1}
+	manifest := getTransferManifestOperationRemote("download", remote)
+	bRes, err := tq.Batch(manifest, tq.Download, remote, nil, []*tq.Transfer{obj})
+
+	var netErr net.Error
+	if err != nil && goerrors.As(errors.Cause(err), &netErr) {
+		endpointCheck.Status = doctorFail
+		endpointCheck.Message = tr.Tr.Get("Unable to reach %s: %s", endpoint.Url, err)
+		endpointCheck.Hint = tr.Tr.Get("Check the URL of the remote or of `lfs.url`, and any proxy settings.")
+		credsCheck.Message = tr.Tr.Get("Endpoint is not reachable")
+		batchCheck.Message = tr.Tr.Get("Endpoint is not reachable")
+		return checks
+	}
+	endpointCheck.Status = doctorPass
+	endpointCheck.Message = tr.Tr.Get("%s is reachable", endpoint.Url)
+
+	access := getAPIClient().Endpoints.AccessFor(endpoint.Url)
+	if err != nil && errors.IsAuthError(err) {
+		credsCheck.Status = doctorFail
+		credsCheck.Message = tr.Tr.Get("Unable to authenticate to %s: %s", endpoint.Url, err)
+		credsCheck.Hint = tr.Tr.Get("Check that a Git credential helper is configured and has valid credentials for the remote.")
+		batchCheck.Message = tr.Tr.Get("Unable to authenticate")
+		return checks
+	}
+	credsCheck.Status = doctorPass
+	credsCheck.Message = tr.Tr.Get("Authenticated (auth=%s)", access.Mode())
+
+	if err != nil {
+		batchCheck.Status = doctorFail
+		batchCheck.Message = tr.Tr.Get("Batch request failed: %s", err)
+		batchCheck.Hint = tr.Tr.Get("Check that the endpoint is a Git LFS API server; run with `GIT_TRACE=1` for details.")
+		return checks
+	}
+	batchCheck.Status = doctorPass
+	batchCheck.Message = tr.Tr.Get("Batch API request succeeded")
+
+	doctorCheckClock(clockCheck, bRes.Date(), time.Now())
+	return checks
+}
+
+// doctorCheckClock fills in the "clock" check c by comparing the local time,
+// now, with the time given by the server's response, which is the zero time if
+// it gave none. A large difference makes Git LFS treat the actions in batch
+// responses as expired too early or too late, and may cause TLS certificates
+// to be rejected.
+func doctorCheckClock(c *doctorCheck, server, now time.Time) {
+	if server.IsZero() {
+		c.Message = tr.Tr.Get("The endpoint did not send the time of its response")
+		return
+	}
+
+	// The server's time is given only to the second.
+	skew := now.Truncate(time.Second).Sub(server)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > doctorMaxClockSkew {
+		c.Status = doctorFail
+		c.Message = tr.Tr.Get("The local clock differs from the endpoint's by %s", skew)
+		c.Hint = tr.Tr.Get("Set the system clock to the correct time, for example by enabling time synchronization.")
+		return
+	}
+	c.Status = doctorPass
+	c.Message = tr.Tr.Get("The local clock agrees with the endpoint's")
+}
+
+func init() {
+	RegisterCommand("doctor", doctorCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&doctorJSON, "json", "", false, "print output in JSON")
+	})
+}
@@ -1,6 +1,12 @@
 package commands
 
 import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
 	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfs"
@@ -8,28 +14,45 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	envJSONArg bool
+)
+
+// envFilterKeys are the Git configuration keys of the LFS filter which are
+// included in the environment.
+var envFilterKeys = []string{"filter.lfs.process", "filter.lfs.smudge", "filter.lfs.clean"}
+
+type envEndpoint struct {
+	Remote string `json:"remote"`
+	Url    string `json:"url"`
+	Auth   string `json:"auth"`
+	SSH    string `json:"ssh,omitempty"`
+}
+
+type envJSON struct {
+	Version           string            `json:"version"`
+	GitVersion        string            `json:"git_version"`
+	OS                string            `json:"os"`
+	Arch              string            `json:"arch"`
+	Endpoints         []*envEndpoint    `json:"endpoints"`
+	Environment       map[string]string `json:"environment"`
+	DownloadTransfers []string          `json:"download_transfers"`
+	UploadTransfers   []string          `json:"upload_transfers"`
+	GitConfig         map[string]string `json:"git_config"`
+}
+
 func envCommand(cmd *cobra.Command, args []string) {
 	config.ShowConfigWarnings = true
 
-	gitV, err := git.Version()
-	if err != nil {
-		gitV = tr.Tr.Get("Error getting Git version: %s", err.Error())
-	}
-
-	Print(config.VersionDesc)
-	Print(gitV)
-	Print("")
+	gitV, gitErr := git.Version()
 
+	// The endpoint of the default remote, if any, is listed first.
+	var endpoints []*envEndpoint
 	defaultRemote := ""
 	if cfg.IsDefaultRemote() {
 		defaultRemote = cfg.Remote()
-		endpoint := getAPIClient().Endpoints.Endpoint("download", defaultRemote)
-		if len(endpoint.Url) > 0 {
-			access := getAPIClient().Endpoints.AccessFor(endpoint.Url)
-			Print("Endpoint=%s (auth=%s)", endpoint.Url, access.Mode())
-			if len(endpoint.SSHMetadata.UserAndHost) > 0 {
-				Print("  SSH=%s:%s", endpoint.SSHMetadata.UserAndHost, endpoint.SSHMetadata.Path)
-			}
+		if e := getEnvEndpoint(defaultRemote); len(e.Url) > 0 {
+			endpoints = append(endpoints, e)
 		}
 	}
 
@@ -37,24 +60,94 @@ func envCommand(cmd *cobra.Command, args []string) {
 		if remote == defaultRemote {
 			continue
 		}
-		remoteEndpoint := getAPIClient().Endpoints.Endpoint("download", remote)
-		remoteAccess := getAPIClient().Endpoints.AccessFor(remoteEndpoint.Url)
-		Print("Endpoint (%s)=%s (auth=%s)", remote, remoteEndpoint.Url, remoteAccess.Mode())
-		if len(remoteEndpoint.SSHMetadata.UserAndHost) > 0 {
-			Print("  SSH=%s:%s", remoteEndpoint.SSHMetadata.UserAndHost, remoteEndpoint.SSHMetadata.Path)
+		endpoints = append(endpoints, getEnvEndpoint(remote))
+	}
+
+	manifest := getTransferManifest()
+	environ := lfs.Environ(cfg, manifest, oldEnv)
+
+	if envJSONArg {
+		data := &envJSON{
+			Version:           config.Version,
+			GitVersion:        strings.TrimSpace(strings.TrimPrefix(gitV, "git version")),
+			OS:                runtime.GOOS,
+			Arch:              runtime.GOARCH,
+			Endpoints:         endpoints,
+			Environment:       make(map[string]string, len(environ)),
+			DownloadTransfers: manifest.GetDownloadAdapterNames(),
+			UploadTransfers:   manifest.GetUploadAdapterNames(),
+			GitConfig:         make(map[string]string, len(envFilterKeys)),
+		}
+		if data.Endpoints == nil {
+			data.Endpoints = make([]*envEndpoint, 0)
+		}
+		sort.Strings(data.DownloadTransfers)
+		sort.Strings(data.UploadTransfers)
+
+		for _, env := range environ {
+			key, value, _ := strings.Cut(env, "=")
+			data.Environment[key] = value
+		}
+		for _, key := range envFilterKeys {
+			data.GitConfig[key], _ = cfg.Git.Get(key)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", " ")
+		if err := encoder.Encode(data); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
+	if gitErr != nil {
+		gitV = tr.Tr.Get("Error getting Git version: %s", gitErr.Error())
+	}
+
+	Print(config.VersionDesc)
+	Print(gitV)
+	Print("")
+
+	for _, e := range endpoints {
+		if e.Remote == defaultRemote {
+			Print("Endpoint=%s (auth=%s)", e.Url, e.Auth)
+		} else {
+			Print("Endpoint (%s)=%s (auth=%s)", e.Remote, e.Url, e.Auth)
+		}
+		if len(e.SSH) > 0 {
+			Print("  SSH=%s", e.SSH)
 		}
 	}
 
-	for _, env := range lfs.Environ(cfg, getTransferManifest(), oldEnv) {
+	for _, env := range environ {
 		Print(env)
 	}
 
-	for _, key := range []string{"filter.lfs.process", "filter.lfs.smudge", "filter.lfs.clean"} {
+	for _, key := range envFilterKeys {
 		value, _ := cfg.Git.Get(key)
 		Print("git config %s = %q", key, value)
 	}
 }
 
+// getEnvEndpoint returns the download endpoint of the given remote, along with
+// its access mode.
+func getEnvEndpoint(remote string) *envEndpoint {
+	endpoint := getAPIClient().Endpoints.Endpoint("download", remote)
+	access := getAPIClient().Endpoints.AccessFor(endpoint.Url)
+
+	e := &envEndpoint{
+		Remote: remote,
+		Url:    endpoint.Url,
+		Auth:   string(access.Mode()),
+	}
+	if len(endpoint.SSHMetadata.UserAndHost) > 0 {
+		e.SSH = endpoint.SSHMetadata.UserAndHost + ":" + endpoint.SSHMetadata.Path
+	}
+	return e
+}
+
 func init() {
-	RegisterCommand("env", envCommand, nil)
+	RegisterCommand("env", envCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&envJSONArg, "json", "", false, "print output in JSON")
+	})
 }
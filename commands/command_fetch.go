@@ -3,14 +3,20 @@ package commands
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfs"
 	"github.com/git-lfs/git-lfs/v3/tasklog"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/git-lfs/git-lfs/v3/tq"
 	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/git-lfs/wildmatch/v2"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
@@ -19,8 +25,51 @@ var (
 	fetchRecentArg bool
 	fetchAllArg    bool
 	fetchPruneArg  bool
+	fetchDryRunArg bool
+
+	// fetchIncludeDeletedArg fetches the objects of every file in the
+	// tree of each commit in the given ranges, including those of files
+	// which were later deleted.
+	fetchIncludeDeletedArg bool
+
+	// fetchSortBySizeArg is the order, "asc" or "desc", in which objects
+	// are queued for download by size, or empty to queue them in the
+	// order in which they are found.
+	fetchSortBySizeArg string
+
+	// fetchRetriesPerObjectArg is the maximum number of times the
+	// download of each object is retried, or zero to use the value of
+	// "lfs.transfer.maxretriesperobject".
+	fetchRetriesPerObjectArg int
+
+	// fetchIncludeRefs and fetchExcludeRefs are sets of glob patterns
+	// matched against the full names of recent refs to determine which
+	// are fetched by --recent.
+	fetchIncludeRefs []string
+	fetchExcludeRefs []string
+
+	// fetchRemotesArg are the remotes given with --remote, from which each
+	// object is fetched from the first which has it.
+	fetchRemotesArg []string
+	fetchRemotes    []string
+
+	// fetchDryRun records the objects reported by --dry-run, so that each
+	// is listed only once and the totals can be printed afterwards.
+	fetchDryRun = &fetchDryRunStats{seen: make(map[string]bool)}
+
+	// fetchErrors records the transfer errors reported while fetching, so
+	// that the exit code reflects the kind of failure.
+	fetchErrors []error
 )
 
+type fetchDryRunStats struct {
+	seen        map[string]bool
+	missing     int
+	missingSize int64
+	present     int
+	presentSize int64
+}
+
 func getIncludeExcludeArgs(cmd *cobra.Command) (include, exclude *string) {
 	includeFlag := cmd.Flag("include")
 	excludeFlag := cmd.Flag("exclude")
@@ -38,20 +87,38 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 	setupRepository()
 
 	var refs []*git.Ref
+	var ranges []*fetchRefRange
 
-	if len(args) > 0 {
+	refArgs := args
+	if len(fetchRemotesArg) > 0 {
+		// All args are refs
+		fetchRemotes = setValidRemotes(fetchRemotesArg)
+	} else if len(args) > 0 {
 		// Remote is first arg
 		if err := cfg.SetValidRemote(args[0]); err != nil {
 			Exit(tr.Tr.Get("Invalid remote name %q: %s", args[0], err))
 		}
+		refArgs = args[1:]
 	}
 
-	if len(args) > 1 {
-		resolvedrefs, err := git.ResolveRefs(args[1:])
+	if len(refArgs) > 0 && fetchIncludeDeletedArg {
+		resolvedranges, err := resolveFetchRefRanges(refArgs)
+		if err != nil {
+			Panic(err, tr.Tr.Get("Invalid ref argument: %v", refArgs))
+		}
+		ranges = resolvedranges
+	} else if len(refArgs) > 0 {
+		resolvedrefs, err := git.ResolveRefs(refArgs)
 		if err != nil {
-			Panic(err, tr.Tr.Get("Invalid ref argument: %v", args[1:]))
+			Panic(err, tr.Tr.Get("Invalid ref argument: %v", refArgs))
 		}
 		refs = resolvedrefs
+	} else if fetchIncludeDeletedArg {
+		ref, err := git.CurrentRef()
+		if err != nil {
+			Panic(err, tr.Tr.Get("Could not fetch"))
+		}
+		ranges = []*fetchRefRange{{name: ref.Refspec(), include: ref.Sha}}
 	} else if !fetchAllArg {
 		ref, err := git.CurrentRef()
 		if err != nil {
@@ -64,6 +131,30 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 	include, exclude := getIncludeExcludeArgs(cmd)
 	fetchPruneCfg := lfs.NewFetchPruneConfig(cfg.Git)
 
+	if len(fetchIncludeRefs) > 0 || len(fetchExcludeRefs) > 0 {
+		if !fetchRecentArg && !fetchPruneCfg.FetchRecentAlways {
+			Exit(tr.Tr.Get("Cannot use --include-ref or --exclude-ref without --recent"))
+		}
+	}
+
+	if fetchIncludeDeletedArg && (fetchAllArg || fetchRecentArg) {
+		Exit(tr.Tr.Get("Cannot combine --include-deleted with --all or --recent"))
+	}
+
+	if fetchDryRunArg && fetchPruneArg {
+		Exit(tr.Tr.Get("Cannot combine --dry-run with --prune"))
+	}
+
+	switch fetchSortBySizeArg {
+	case "", "asc", "desc":
+	default:
+		Exit(tr.Tr.Get("Invalid value for --sort-by-size: %q (expected \"asc\" or \"desc\")", fetchSortBySizeArg))
+	}
+
+	if fetchRetriesPerObjectArg < 0 {
+		Exit(tr.Tr.Get("Invalid value for --retries-per-object: %d (expected a number which is not negative)", fetchRetriesPerObjectArg))
+	}
+
 	if fetchAllArg {
 		if fetchRecentArg {
 			Exit(tr.Tr.Get("Cannot combine --all with --recent"))
@@ -75,7 +166,7 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 			Print(tr.Tr.Get("Ignoring global include / exclude paths to fulfil --all"))
 		}
 
-		if len(args) > 1 {
+		if len(refArgs) > 0 {
 			refShas := make([]string, len(refs))
 			for _, ref := range refs {
 				refShas = append(refShas, ref.Sha)
@@ -88,6 +179,12 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 	} else { // !all
 		filter := buildFilepathFilter(cfg, include, exclude, true)
 
+		for _, r := range ranges {
+			Print("fetch: %s", tr.Tr.Get("Fetching reference range %s, including deleted files", r.name))
+			s := fetchRefRangeWithDeleted(r, filter)
+			success = success && s
+		}
+
 		// Fetch refs sequentially per arg order; duplicates in later refs will be ignored
 		for _, ref := range refs {
 			Print("fetch: %s", tr.Tr.Get("Fetching reference %s", ref.Refspec()))
@@ -101,6 +198,10 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if fetchDryRunArg {
+		printFetchDryRunSummary()
+	}
+
 	if fetchPruneArg {
 		verify := fetchPruneCfg.PruneVerifyRemoteAlways
 		verifyUnreachable := fetchPruneCfg.PruneVerifyUnreachableAlways
@@ -110,9 +211,7 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if !success {
-		c := getAPIClient()
-		e := c.Endpoints.Endpoint("download", cfg.Remote())
-		Exit(tr.Tr.Get("error: failed to fetch some objects from '%s'", e.Url))
+		ExitWithErrors(fetchErrors, tr.Tr.Get("error: failed to fetch some objects from '%s'", describeRemotes(cfg.Remote(), fetchRemotes)))
 	}
 }
 
@@ -192,6 +291,86 @@ func fetchRefs(refs []string) bool {
 	return fetchAndReportToChan(pointers, nil, nil)
 }
 
+// fetchRefRange is a range of commits given to fetch --include-deleted, those
+// reachable from include but not from exclude, if set.
+type fetchRefRange struct {
+	name    string
+	include string
+	exclude string
+}
+
+// resolveFetchRefRanges resolves each of the given arguments, either a range
+// "<a>..<b>", in which a missing end is "HEAD", or a ref, which stands for every
+// commit reachable from it.
+func resolveFetchRefRanges(args []string) ([]*fetchRefRange, error) {
+	ranges := make([]*fetchRefRange, 0, len(args))
+	for _, arg := range args {
+		include, exclude := arg, ""
+		if i := strings.Index(arg, ".."); i >= 0 {
+			exclude, include = arg[:i], arg[i+2:]
+			if strings.HasPrefix(include, ".") {
+				return nil, errors.New(tr.Tr.Get("symmetric difference ranges are not supported: %q", arg))
+			}
+			if len(exclude) == 0 {
+				exclude = "HEAD"
+			}
+			if len(include) == 0 {
+				include = "HEAD"
+			}
+		}
+
+		r := &fetchRefRange{name: arg}
+		ref, err := git.ResolveRef(include)
+		if err != nil {
+			return nil, err
+		}
+		r.include = ref.Sha
+		if len(exclude) > 0 {
+			ref, err := git.ResolveRef(exclude)
+			if err != nil {
+				return nil, err
+			}
+			r.exclude = ref.Sha
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// fetchRefRangeWithDeleted fetches the objects of every file in the tree of each
+// commit in the given range, so that the objects of files which were deleted by
+// a later commit are fetched too.
+func fetchRefRangeWithDeleted(r *fetchRefRange, filter *filepathfilter.Filter) bool {
+	var pointers []*lfs.WrappedPointer
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+
+	tempgitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			// Files which should be pointers but are not have
+			// nothing to fetch.
+			if errors.IsPointerScanError(err) {
+				return
+			}
+			Panic(err, tr.Tr.Get("Could not scan for Git LFS files"))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[p.Oid] || (filter != nil && !filter.Allows(p.Name)) {
+			return
+		}
+		seen[p.Oid] = true
+		pointers = append(pointers, p)
+	})
+
+	if err := tempgitscanner.ScanRefRangeByTree(r.include, r.exclude, nil); err != nil {
+		ExitWithError(err)
+	}
+
+	return fetchAndReportToChan(pointers, filter, nil)
+}
+
 // Fetch all previous versions of objects from since to ref (not including final state at ref)
 // So this will fetch all the '-' sides of the diff from since to ref
 func fetchPreviousVersions(ref string, since time.Time, filter *filepathfilter.Filter) bool {
@@ -241,6 +420,11 @@ func fetchRecent(fetchconf lfs.FetchPruneConfig, alreadyFetchedRefs []*git.Ref,
 			Panic(err, tr.Tr.Get("Could not scan for recent refs"))
 		}
 		for _, ref := range refs {
+			if !recentRefAllowed(ref) {
+				tracerx.Printf("Skipping fetch for %v, excluded by ref filter", ref.Name)
+				continue
+			}
+
 			// Don't fetch for the same SHA twice
 			if prevRefName, ok := uniqueRefShas[ref.Sha]; ok {
 				if ref.Name != prevRefName {
@@ -279,6 +463,29 @@ func fetchRecent(fetchconf lfs.FetchPruneConfig, alreadyFetchedRefs []*git.Ref,
 	return ok
 }
 
+// recentRefAllowed returns whether the given recent ref should be fetched
+// according to the --include-ref and --exclude-ref patterns, which are matched
+// against the full name of the ref.  If no --include-ref patterns were given,
+// all refs not excluded are allowed.
+func recentRefAllowed(ref *git.Ref) bool {
+	name := ref.Refspec()
+	for _, pattern := range fetchExcludeRefs {
+		if wildmatch.NewWildmatch(pattern).Match(name) {
+			return false
+		}
+	}
+
+	if len(fetchIncludeRefs) == 0 {
+		return true
+	}
+	for _, pattern := range fetchIncludeRefs {
+		if wildmatch.NewWildmatch(pattern).Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func fetchAll() bool {
 	pointers := scanAll()
 	Print("fetch: %s", tr.Tr.Get("Fetching all references..."))
@@ -326,42 +533,55 @@ func scanAll() []*lfs.WrappedPointer {
 // Fetch and report completion of each OID to a channel (optional, pass nil to skip)
 // Returns true if all completed with no errors, false if errors were written to stderr/log
 func fetchAndReportToChan(allpointers []*lfs.WrappedPointer, filter *filepathfilter.Filter, out chan<- *lfs.WrappedPointer) bool {
+	if fetchDryRunArg {
+		reportFetchDryRun(allpointers)
+		return true
+	}
+
 	ready, pointers, meter := readyAndMissingPointers(allpointers, filter)
+	sortPointersBySize(pointers, fetchSortBySizeArg)
+	options := []tq.Option{
+		tq.WithSortAscending(fetchSortBySizeArg == "asc"),
+		tq.WithMaxRetriesPerObject(fetchRetriesPerObjectArg),
+	}
 	q := newDownloadQueue(
 		getTransferManifestOperationRemote("download", cfg.Remote()),
-		cfg.Remote(), tq.WithProgress(meter),
+		cfg.Remote(), append([]tq.Option{tq.WithProgress(meter)}, options...)...,
 	)
 
+	// fetch only reports single OID, but OID *might* be referenced by multiple
+	// WrappedPointers if same content is at multiple paths, so map oid->slice
+	pending := newPointerMap()
+	for _, p := range pointers {
+		pending.Add(p)
+	}
+	fetched := func(plist []*lfs.WrappedPointer) {
+		if out == nil {
+			return
+		}
+		for _, p := range plist {
+			out <- p
+		}
+	}
+
 	if out != nil {
 		// If we already have it, or it won't be fetched
 		// report it to chan immediately to support pull/checkout
 		for _, p := range ready {
 			out <- p
 		}
+	}
 
-		dlwatch := q.Watch()
-
-		go func() {
-			// fetch only reports single OID, but OID *might* be referenced by multiple
-			// WrappedPointers if same content is at multiple paths, so map oid->slice
-			oidToPointers := make(map[string][]*lfs.WrappedPointer, len(pointers))
-			for _, pointer := range pointers {
-				plist := oidToPointers[pointer.Oid]
-				oidToPointers[pointer.Oid] = append(plist, pointer)
-			}
+	dlwatch := q.Watch()
+	var wg sync.WaitGroup
+	wg.Add(1)
 
-			for t := range dlwatch {
-				plist, ok := oidToPointers[t.Oid]
-				if !ok {
-					continue
-				}
-				for _, p := range plist {
-					out <- p
-				}
-			}
-			close(out)
-		}()
-	}
+	go func() {
+		for t := range dlwatch {
+			fetched(pending.All(t.Oid))
+		}
+		wg.Done()
+	}()
 
 	for _, p := range pointers {
 		tracerx.Printf("fetch %v [%v]", p.Name, p.Oid)
@@ -371,16 +591,66 @@ func fetchAndReportToChan(allpointers []*lfs.WrappedPointer, filter *filepathfil
 
 	processQueue := time.Now()
 	q.Wait()
+	wg.Wait()
+	if len(fetchRemotes) > 1 {
+		q = fetchFromFallbackRemotes(q, fetchRemotes[1:], pending, meter, fetched, options...)
+	}
 	tracerx.PerformanceSince("process queue", processQueue)
+	if out != nil {
+		close(out)
+	}
 
 	ok := true
 	for _, err := range q.Errors() {
 		ok = false
+		fetchErrors = append(fetchErrors, err)
 		FullError(err)
 	}
+	reportExhaustedRetries(q.ExhaustedRetries(), pointers)
 	return ok
 }
 
+// reportExhaustedRetries lists the objects with the given OIDs, which were not
+// fetched because they ran out of retries, along with the names of the files
+// for them among the given pointers.
+func reportExhaustedRetries(oids []string, pointers []*lfs.WrappedPointer) {
+	if len(oids) == 0 {
+		return
+	}
+
+	names := make(map[string]string, len(oids))
+	for _, p := range pointers {
+		if _, ok := names[p.Oid]; !ok {
+			names[p.Oid] = p.Name
+		}
+	}
+
+	Error(tr.Tr.GetN(
+		"%d object could not be fetched after exhausting its retries:",
+		"%d objects could not be fetched after exhausting their retries:",
+		len(oids),
+		len(oids)))
+	for _, oid := range oids {
+		Error("  %s (%s)", names[oid], oid)
+	}
+}
+
+// sortPointersBySize sorts the given pointers by the size of their objects in
+// the given order, either "asc" or "desc", keeping pointers to objects of the
+// same size in their original order.  Any other order leaves them unsorted.
+func sortPointersBySize(pointers []*lfs.WrappedPointer, order string) {
+	switch order {
+	case "asc":
+		sort.SliceStable(pointers, func(i, j int) bool {
+			return pointers[i].Size < pointers[j].Size
+		})
+	case "desc":
+		sort.SliceStable(pointers, func(i, j int) bool {
+			return pointers[i].Size > pointers[j].Size
+		})
+	}
+}
+
 func readyAndMissingPointers(allpointers []*lfs.WrappedPointer, filter *filepathfilter.Filter) ([]*lfs.WrappedPointer, []*lfs.WrappedPointer, *tq.Meter) {
 	logger := tasklog.NewLogger(os.Stdout,
 		tasklog.ForceProgress(cfg.ForceProgress()),
@@ -414,12 +684,62 @@ func readyAndMissingPointers(allpointers []*lfs.WrappedPointer, filter *filepath
 	return ready, missing, meter
 }
 
+// reportFetchDryRun prints each of the given objects which has not already
+// been reported, along with whether it is present locally or would be
+// downloaded. Nothing is downloaded, and neither the Batch API nor the storage
+// backend is contacted.
+func reportFetchDryRun(allpointers []*lfs.WrappedPointer) {
+	for _, p := range allpointers {
+		if fetchDryRun.seen[p.Oid] {
+			continue
+		}
+		fetchDryRun.seen[p.Oid] = true
+
+		size := humanize.FormatBytes(uint64(p.Size))
+		if cfg.LFSObjectExists(p.Oid, p.Size) {
+			fetchDryRun.present++
+			fetchDryRun.presentSize += p.Size
+			Print("%s %s => %s (%s)", tr.Tr.Get("present"), p.Oid, p.Name, size)
+		} else {
+			fetchDryRun.missing++
+			fetchDryRun.missingSize += p.Size
+			Print("%s %s => %s (%s)", tr.Tr.Get("missing"), p.Oid, p.Name, size)
+		}
+	}
+}
+
+func printFetchDryRunSummary() {
+	Print(tr.Tr.GetN(
+		"Would download %d object (%s)",
+		"Would download %d objects (%s)",
+		fetchDryRun.missing,
+		fetchDryRun.missing,
+		humanize.FormatBytes(uint64(fetchDryRun.missingSize)),
+	))
+	Print(tr.Tr.GetN(
+		"%d object already present locally (%s)",
+		"%d objects already present locally (%s)",
+		fetchDryRun.present,
+		fetchDryRun.present,
+		humanize.FormatBytes(uint64(fetchDryRun.presentSize)),
+	))
+}
+
 func init() {
 	RegisterCommand("fetch", fetchCommand, func(cmd *cobra.Command) {
 		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths")
 		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths")
 		cmd.Flags().BoolVarP(&fetchRecentArg, "recent", "r", false, "Fetch recent refs & commits")
+		cmd.Flags().StringSliceVar(&fetchIncludeRefs, "include-ref", nil, "With --recent, only fetch recent refs matching these patterns")
+		cmd.Flags().StringSliceVar(&fetchExcludeRefs, "exclude-ref", nil, "With --recent, don't fetch recent refs matching these patterns")
+		cmd.Flags().StringSliceVar(&fetchRemotesArg, "remote", nil, "Fetch each object from the first of these remotes which has it")
 		cmd.Flags().BoolVarP(&fetchAllArg, "all", "a", false, "Fetch all LFS files ever referenced")
+		cmd.Flags().BoolVar(&fetchIncludeDeletedArg, "include-deleted", false, "Fetch the objects of every file in each commit of the given ranges, including deleted files")
 		cmd.Flags().BoolVarP(&fetchPruneArg, "prune", "p", false, "After fetching, prune old data")
+		cmd.Flags().IntVar(&progressFdArg, "progress-fd", -1, "Write JSON progress events to this file descriptor")
+		cmd.Flags().BoolVar(&noCacheArg, "no-cache", false, "Don't use the cached transfer adapter chosen by the server")
+		cmd.Flags().BoolVarP(&fetchDryRunArg, "dry-run", "d", false, "List the objects that would be fetched, without downloading them")
+		cmd.Flags().IntVar(&fetchRetriesPerObjectArg, "retries-per-object", 0, "Retry the download of each object at most this many times")
+		cmd.Flags().StringVar(&fetchSortBySizeArg, "sort-by-size", "", "Download objects in order of size, either \"asc\" or \"desc\"")
 	})
 }
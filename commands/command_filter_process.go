@@ -9,7 +9,6 @@ import (
 	"sync"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
-	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfs"
 	"github.com/git-lfs/git-lfs/v3/tq"
@@ -61,7 +60,7 @@ func filterCommand(cmd *cobra.Command, args []string) {
 	}
 
 	skip := filterSmudgeSkip || cfg.Os.Bool("GIT_LFS_SKIP_SMUDGE", false)
-	filter := filepathfilter.New(cfg.FetchIncludePaths(), cfg.FetchExcludePaths(), filepathfilter.GitIgnore)
+	filter := smudgeFilter()
 
 	ptrs := make(map[string]*lfs.Pointer)
 
@@ -186,6 +185,8 @@ func filterCommand(cmd *cobra.Command, args []string) {
 		if errors.IsNotAPointerError(err) {
 			malformed = append(malformed, req.Header["pathname"])
 			err = nil
+		} else if errors.IsUnsupportedHashAlgorithmError(err) {
+			Error(err.Error())
 		} else if possiblyMalformedObjectSize(n) {
 			malformedOnWindows = append(malformedOnWindows, req.Header["pathname"])
 		}
@@ -225,7 +226,7 @@ func filterCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	if len(malformedOnWindows) > 0 && cfg.Git.Bool("lfs.largefilewarning", !git.IsGitVersionAtLeast("2.34.0")) {
+	if len(malformedOnWindows) > 0 && cfg.LargeFileWarning() {
 		fmt.Fprintln(os.Stderr, tr.Tr.GetN(
 			"Encountered %d file that may not have been copied correctly on Windows:",
 			"Encountered %d files that may not have been copied correctly on Windows:",
@@ -1,18 +1,19 @@
 package commands
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tasklog"
 	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/spf13/cobra"
@@ -37,10 +38,6 @@ func (p corruptPointer) String() string {
 	return fmt.Sprintf("%s: %s", p.kind, p.message)
 }
 
-// TODO(zeroshirts): 'git fsck' reports status (percentage, current#/total) as
-// it checks... we should do the same, as we are rehashing potentially gigs and
-// gigs of content.
-//
 // NOTE(zeroshirts): Ideally git would have hooks for fsck such that we could
 // chain a lfs-fsck, but I don't think it does.
 func fsckCommand(cmd *cobra.Command, args []string) {
@@ -123,20 +120,15 @@ func fsckCommand(cmd *cobra.Command, args []string) {
 }
 
 // doFsckObjects checks that the objects in the given ref are correct and exist.
+// The objects are hashed concurrently, but the problems found are reported in
+// the order in which the objects were found.
 func doFsckObjects(include, exclude string, useIndex bool) []string {
-	var corruptOids []string
+	var pointers []*lfs.WrappedPointer
 	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
-		if err == nil {
-			var pointerOk bool
-			pointerOk, err = fsckPointer(p.Name, p.Oid, p.Size)
-			if !pointerOk {
-				corruptOids = append(corruptOids, p.Oid)
-			}
-		}
-
 		if err != nil {
 			Panic(err, tr.Tr.Get("Error checking Git LFS files"))
 		}
+		pointers = append(pointers, p)
 	})
 
 	// If 'lfs.fetchexclude' is set and 'git lfs fsck' is run after the
@@ -162,16 +154,105 @@ func doFsckObjects(include, exclude string, useIndex bool) []string {
 		}
 	}
 
+	results := fsckCheckObjects(pointers)
+
+	var corruptOids []string
+	for i, r := range results {
+		if r.err != nil {
+			Panic(r.err, tr.Tr.Get("Error checking Git LFS files"))
+		}
+		if len(r.message) > 0 {
+			Print(r.message)
+		}
+		if !r.ok {
+			corruptOids = append(corruptOids, pointers[i].Oid)
+		}
+	}
 	return corruptOids
 }
 
+// fsckObjectResult is the result of checking the object of a single pointer,
+// with the message to report, if any.
+type fsckObjectResult struct {
+	ok      bool
+	message string
+	err     error
+}
+
+// fsckCheckObjects checks the objects of the given pointers with a pool of
+// lfs.fsck.concurrency workers, or lfs.concurrenttransfers if that is not set,
+// and returns their results in the same order as the pointers.
+func fsckCheckObjects(pointers []*lfs.WrappedPointer) []fsckObjectResult {
+	results := make([]fsckObjectResult, len(pointers))
+	if len(pointers) == 0 {
+		return results
+	}
+
+	workers := cfg.Git.Int("lfs.fsck.concurrency", 0)
+	if workers < 1 {
+		workers = cfg.Git.Int("lfs.concurrenttransfers", 8)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	logger := tasklog.NewLogger(os.Stderr,
+		tasklog.ForceProgress(cfg.ForceProgress()),
+	)
+	defer logger.Close()
+	task := logger.Percentage(fmt.Sprintf("fsck: %s", tr.Tr.Get("Checking objects")), uint64(len(pointers)))
+	defer task.Complete()
+
+	indexes := make(chan int)
+	done := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				p := pointers[i]
+				ok, message, err := fsckPointer(p.Name, p.Oid, p.OidType, p.Size)
+				results[i] = fsckObjectResult{ok: ok, message: message, err: err}
+				done <- i
+			}
+		}()
+	}
+
+	go func() {
+		for i := range pointers {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+		close(done)
+	}()
+
+	// Count progress from this goroutine alone, since the task may not be
+	// counted concurrently once it nears completion.
+	for range done {
+		task.Count(1)
+	}
+	return results
+}
+
 // doFsckPointers checks that the pointers in the given ref are correct and canonical.
 func doFsckPointers(include, exclude string) []corruptPointer {
 	var corruptPointers []corruptPointer
 	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if p != nil {
 			Debug(tr.Tr.Get("Examining %v (%v)", p.Oid, p.Name))
-			if !p.Canonical {
+			if size, ok := fsckPointerSize(p); !ok {
+				cp := corruptPointer{
+					blobOid: p.Sha1,
+					lfsOid:  p.Oid,
+					path:    p.Name,
+					message: tr.Tr.Get("Pointer for %s (blob %s) has size %d, but the object is %d bytes", p.Oid, p.Sha1, p.Size, size),
+					kind:    "brokenPointer",
+				}
+				Print("pointer: %s", cp.String())
+				corruptPointers = append(corruptPointers, cp)
+			} else if !p.Canonical {
 				cp := corruptPointer{
 					blobOid: p.Sha1,
 					lfsOid:  p.Oid,
@@ -183,7 +264,16 @@ func doFsckPointers(include, exclude string) []corruptPointer {
 			}
 		} else if errors.IsPointerScanError(err) {
 			psErr, ok := err.(errors.PointerScanError)
-			if ok {
+			if ok && errors.IsInvalidPointerError(err) {
+				cp := corruptPointer{
+					treeOid: psErr.OID(),
+					path:    psErr.Path(),
+					message: tr.Tr.Get("%q (treeish %s) looks like a pointer but could not be parsed: %s", psErr.Path(), psErr.OID(), errors.Cause(err)),
+					kind:    "brokenPointer",
+				}
+				Print("pointer: %s", cp.String())
+				corruptPointers = append(corruptPointers, cp)
+			} else if ok {
 				cp := corruptPointer{
 					treeOid: psErr.OID(),
 					path:    psErr.Path(),
@@ -211,7 +301,39 @@ func doFsckPointers(include, exclude string) []corruptPointer {
 	return corruptPointers
 }
 
-func fsckPointer(name, oid string, size int64) (bool, error) {
+// fsckPointerSize checks the size given by the pointer p against its object in
+// the local cache, if present, returning the actual size of the object and
+// false if they differ. An object whose contents do not match its OID is
+// reported as a corrupt object instead, and not as a problem with the pointer.
+func fsckPointerSize(p *lfs.WrappedPointer) (int64, bool) {
+	path := cfg.Filesystem().ObjectPathname(p.Oid)
+	if path == os.DevNull {
+		return 0, true
+	}
+	stat, err := os.Stat(path)
+	if err != nil || stat.Size() == p.Size {
+		return 0, true
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, true
+	}
+	defer f.Close()
+
+	oidHash, err := tools.NewLfsContentHashFor(p.OidType)
+	if err != nil {
+		return 0, true
+	}
+	if _, err := io.Copy(oidHash, f); err != nil || hex.EncodeToString(oidHash.Sum(nil)) != p.Oid {
+		return 0, true
+	}
+	return stat.Size(), false
+}
+
+// fsckPointer checks that the object with the given OID exists and is not
+// corrupt, returning false and a message describing the problem if it is not.
+func fsckPointer(name, oid, oidType string, size int64) (bool, string, error) {
 	path := cfg.Filesystem().ObjectPathname(oid)
 
 	Debug(tr.Tr.Get("Examining %v (%v)", name, path))
@@ -220,30 +342,32 @@ func fsckPointer(name, oid string, size int64) (bool, error) {
 	if pErr, pOk := err.(*os.PathError); pOk {
 		// This is an empty file.  No problem here.
 		if size == 0 {
-			return true, nil
+			return true, "", nil
 		}
-		Print("objects: openError: %s", tr.Tr.Get("%s (%s) could not be checked: %s", name, oid, pErr.Err))
-		return false, nil
+		return false, fmt.Sprintf("objects: openError: %s", tr.Tr.Get("%s (%s) could not be checked: %s", name, oid, pErr.Err)), nil
 	}
 
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
-	oidHash := sha256.New()
+	oidHash, err := tools.NewLfsContentHashFor(oidType)
+	if err != nil {
+		f.Close()
+		return false, "", err
+	}
 	_, err = io.Copy(oidHash, f)
 	f.Close()
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
 	recalculatedOid := hex.EncodeToString(oidHash.Sum(nil))
 	if recalculatedOid == oid {
-		return true, nil
+		return true, "", nil
 	}
 
-	Print(fmt.Sprintf("objects: corruptObject: %s", tr.Tr.Get("%s (%s) is corrupt", name, oid)))
-	return false, nil
+	return false, fmt.Sprintf("objects: corruptObject: %s", tr.Tr.Get("%s (%s) is corrupt", name, oid)), nil
 }
 
 func init() {
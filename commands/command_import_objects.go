@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importObjectsStats = &struct {
+		importedCount int64
+		importedSize  int64
+		presentCount  int64
+		skippedCount  int64
+	}{}
+)
+
+// importObjectsCommand imports the files in a directory which are named by the
+// OIDs of their contents into the local Git LFS object store.
+func importObjectsCommand(cmd *cobra.Command, args []string) {
+	setupRepository()
+
+	if len(args) != 1 {
+		Exit(tr.Tr.Get("Usage: git lfs import-objects <directory>"))
+	}
+
+	dir := args[0]
+	if !tools.DirExists(dir) {
+		Exit(tr.Tr.Get("Not a directory: %s", dir))
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		var (
+			present bool
+			size    int64
+		)
+		if lfs.IsValidOid(d.Name()) {
+			present, size, err = importObject(path, d.Name())
+		} else {
+			err = errors.New(tr.Tr.Get("file name is not a Git LFS object ID"))
+		}
+
+		if err != nil {
+			// TRANSLATORS: Leading spaces should be included on
+			// the second line so the format specifier aligns with
+			// with the first format specifier on the first line.
+			Error(tr.Tr.Get("Skipped: %s\n          %s", path, err))
+			importObjectsStats.skippedCount++
+		} else if present {
+			importObjectsStats.presentCount++
+		} else {
+			importObjectsStats.importedCount++
+			importObjectsStats.importedSize += size
+		}
+		return nil
+	})
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Error reading directory %s", dir)))
+	}
+
+	// TRANSLATORS: The second, third, and fourth strings should have the
+	// colons aligned in a column.
+	Print("%s\n  %s\n  %s\n  %s", tr.Tr.Get("Import finished."),
+		tr.Tr.Get("       imported: %d (%s)", importObjectsStats.importedCount, humanize.FormatBytes(uint64(importObjectsStats.importedSize))),
+		tr.Tr.Get("already present: %d", importObjectsStats.presentCount),
+		tr.Tr.Get("        skipped: %d", importObjectsStats.skippedCount))
+
+	if importObjectsStats.skippedCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// importObject copies, or if possible clones, the file at path into the local
+// object store as the object with the given OID, after verifying that the OID
+// is that of its contents. It returns whether the object was already present,
+// and the size of the object.
+func importObject(path, oid string) (bool, int64, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, 0, err
+	}
+	if cfg.LFSObjectExists(oid, stat.Size()) {
+		return true, stat.Size(), nil
+	}
+
+	tmp, err := lfs.TempFile(cfg, oid)
+	if err != nil {
+		return false, 0, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hasher, err := tools.NewLfsContentHashFor(cfg.HashAlgorithm())
+	if err != nil {
+		return false, 0, err
+	}
+
+	// If the file can be cloned, hash the clone, which is what will be
+	// stored; otherwise, hash the contents as they are copied.
+	var size int64
+	if cloned, _ := tools.CloneFileByPath(tmp.Name(), path); cloned {
+		tracerx.Printf("import-objects: cloned %s", path)
+		f, err := os.Open(tmp.Name())
+		if err != nil {
+			return false, 0, err
+		}
+		size, err = io.Copy(hasher, f)
+		f.Close()
+		if err != nil {
+			return false, 0, err
+		}
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return false, 0, err
+		}
+		size, err = io.Copy(io.MultiWriter(tmp, hasher), f)
+		f.Close()
+		if err != nil {
+			return false, 0, err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return false, 0, err
+	}
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != oid {
+		return false, 0, errors.New(tr.Tr.Get("file contents have Git LFS object ID %s", actual))
+	}
+
+	objectPath, err := cfg.Filesystem().ObjectPath(oid)
+	if err != nil {
+		return false, 0, err
+	}
+	if err := tools.RenameFileCopyPermissions(tmp.Name(), objectPath); err != nil {
+		return false, 0, err
+	}
+	return false, size, nil
+}
+
+func init() {
+	RegisterCommand("import-objects", importObjectsCommand, nil)
+}
@@ -18,10 +18,16 @@ var (
 	systemInstall     = false
 	skipSmudgeInstall = false
 	skipRepoInstall   = false
+	preCommitInstall  = false
 )
 
 func installCommand(cmd *cobra.Command, args []string) {
-	if err := cmdInstallOptions().Install(); err != nil {
+	opt := cmdInstallOptions()
+	if worktreeInstall {
+		requireWorktreeConfig()
+	}
+
+	if err := opt.Install(); err != nil {
 		Print(tr.Tr.Get("warning: %s", err.Error()))
 		Print(tr.Tr.Get("Run `git lfs install --force` to reset Git configuration."))
 		os.Exit(2)
@@ -77,6 +83,25 @@ func cmdInstallOptions() *lfs.FilterOptions {
 	}
 }
 
+// requireWorktreeConfig exits without changing any configuration if the
+// --worktree option was given in a repository with multiple working trees, but
+// the "worktreeConfig" extension, without which Git cannot write to the
+// configuration of a single working tree, is not enabled.
+func requireWorktreeConfig() {
+	// Versions before 2.20.0 don't support the extension, and Git
+	// reports an error for the --worktree option itself.
+	if !git.IsGitVersionAtLeast("2.20.0") || cfg.Git.Bool("extensions.worktreeconfig", false) {
+		return
+	}
+
+	worktrees, err := git.GetAllWorkTrees(cfg.LocalGitStorageDir())
+	if err != nil || len(worktrees) <= 1 {
+		return
+	}
+
+	Exit(tr.Tr.Get("The --worktree option requires the `worktreeConfig` extension when there are\nmultiple working trees, so no configuration was changed. Enable it with\n`git config extensions.worktreeConfig true` and try again."))
+}
+
 func installHooksCommand(cmd *cobra.Command, args []string) {
 	updateForce = forceInstall
 
@@ -88,6 +113,7 @@ func installHooksCommand(cmd *cobra.Command, args []string) {
 	// At a later date, extract `git-lfs-update(1)`-related logic into its
 	// own function, and translate this flag as a boolean argument to it.
 	updateManual = manualInstall
+	updatePreCommit = preCommitInstall
 
 	updateCommand(cmd, args)
 }
@@ -104,6 +130,7 @@ func init() {
 		cmd.Flags().BoolVarP(&skipSmudgeInstall, "skip-smudge", "s", false, "Skip automatic downloading of objects on clone or pull.")
 		cmd.Flags().BoolVarP(&skipRepoInstall, "skip-repo", "", false, "Skip repo setup, just install global filters.")
 		cmd.Flags().BoolVarP(&manualInstall, "manual", "m", false, "Print instructions for manual install.")
+		cmd.Flags().BoolVarP(&preCommitInstall, "pre-commit", "", false, "Also install the pre-commit hook.")
 		cmd.AddCommand(NewCommand("hooks", installHooksCommand))
 	})
 }
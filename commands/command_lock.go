@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/git"
@@ -15,10 +16,27 @@ import (
 )
 
 var (
-	lockRemote string
+	lockRemote        string
+	lockLeaseDuration time.Duration
 )
 
+// lockResponse is the status of an attempt to lock a single path, as reported
+// by `git lfs lock --json`.
+type lockResponse struct {
+	Id        string        `json:"id,omitempty"`
+	Path      string        `json:"path,omitempty"`
+	Owner     *locking.User `json:"owner,omitempty"`
+	LockedAt  *time.Time    `json:"locked_at,omitempty"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	Locked    bool          `json:"locked"`
+	Reason    string        `json:"reason,omitempty"`
+}
+
 func lockCommand(cmd *cobra.Command, args []string) {
+	if lockLeaseDuration < 0 || (lockLeaseDuration > 0 && lockLeaseDuration < time.Second) {
+		Exit(tr.Tr.Get("Invalid lease duration: %s (must be at least 1s)", lockLeaseDuration))
+	}
+
 	if len(lockRemote) > 0 {
 		cfg.SetRemote(lockRemote)
 	}
@@ -33,24 +51,48 @@ func lockCommand(cmd *cobra.Command, args []string) {
 	lockClient.RemoteRef = refUpdate.RemoteRef()
 	defer lockClient.Close()
 
+	lockClient.LeaseDuration = lockLeaseDuration
+
 	success := true
-	locks := make([]locking.Lock, 0, len(args))
-	for _, path := range args {
-		path, err := lockPath(lockData, path)
+	responses := make([]lockResponse, len(args))
+	paths := make([]string, 0, len(args))
+	indexes := make([]int, 0, len(args))
+	for i, arg := range args {
+		path, err := lockPath(lockData, arg)
 		if err != nil {
 			Error(err.Error())
+			responses[i] = lockResponse{Path: arg, Reason: err.Error()}
 			success = false
 			continue
 		}
 
-		lock, err := lockClient.LockFile(path)
-		if err != nil {
+		paths = append(paths, path)
+		indexes = append(indexes, i)
+	}
+
+	locks, errs := lockClient.LockFiles(paths)
+	for j, path := range paths {
+		i := indexes[j]
+		if err := errs[j]; err != nil {
 			Error(tr.Tr.Get("Locking %s failed: %v", path, errors.Cause(err)))
+			responses[i] = lockResponse{Path: path, Reason: errors.Cause(err).Error()}
 			success = false
 			continue
 		}
 
-		locks = append(locks, lock)
+		lock := locks[j]
+		responses[i] = lockResponse{
+			Id:        lock.Id,
+			Path:      lock.Path,
+			Owner:     lock.Owner,
+			LockedAt:  &lock.LockedAt,
+			ExpiresAt: lock.ExpiresAt,
+			Locked:    true,
+		}
+
+		if lockLeaseDuration > 0 && lock.ExpiresAt == nil {
+			Error(tr.Tr.Get("warning: server does not support lock leases; lock on %s will not expire", path))
+		}
 
 		if locksCmdFlags.JSON {
 			continue
@@ -60,7 +102,7 @@ func lockCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if locksCmdFlags.JSON {
-		if err := json.NewEncoder(os.Stdout).Encode(locks); err != nil {
+		if err := json.NewEncoder(os.Stdout).Encode(responses); err != nil {
 			Error(err.Error())
 			success = false
 		}
@@ -143,5 +185,6 @@ func init() {
 	RegisterCommand("lock", lockCommand, func(cmd *cobra.Command) {
 		cmd.Flags().StringVarP(&lockRemote, "remote", "r", "", "specify which remote to use when interacting with locks")
 		cmd.Flags().BoolVarP(&locksCmdFlags.JSON, "json", "", false, "print output in json")
+		cmd.Flags().DurationVarP(&lockLeaseDuration, "lease-duration", "", 0, "request that the lock expire after the given duration")
 	})
 }
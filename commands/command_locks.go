@@ -5,6 +5,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/git"
@@ -28,6 +29,14 @@ func locksCommand(cmd *cobra.Command, args []string) {
 		Exit(tr.Tr.Get("Error building filters: %v", err))
 	}
 
+	var staleAfter time.Duration
+	if len(locksCmdFlags.Stale) > 0 {
+		staleAfter, err = tools.ParseDuration(locksCmdFlags.Stale)
+		if err != nil || staleAfter <= 0 {
+			Exit(tr.Tr.Get("Invalid value for --stale: %q", locksCmdFlags.Stale))
+		}
+	}
+
 	if len(lockRemote) > 0 {
 		cfg.SetRemote(lockRemote)
 	}
@@ -47,6 +56,24 @@ func locksCommand(cmd *cobra.Command, args []string) {
 		if locksCmdFlags.Local {
 			Exit(tr.Tr.Get("--cached option can't be combined with --local"))
 		}
+		if locksCmdFlags.JSON {
+			Exit(tr.Tr.Get("--cached option can't be combined with --json"))
+		}
+	}
+
+	if locksCmdFlags.Refresh {
+		if locksCmdFlags.Cached {
+			Exit(tr.Tr.Get("--refresh option can't be combined with --cached"))
+		}
+		if locksCmdFlags.Limit > 0 {
+			Exit(tr.Tr.Get("--refresh option can't be combined with --limit"))
+		}
+		if len(filters) > 0 {
+			Exit(tr.Tr.Get("--refresh option can't be combined with filters"))
+		}
+		if locksCmdFlags.Local {
+			Exit(tr.Tr.Get("--refresh option can't be combined with --local"))
+		}
 	}
 
 	if locksCmdFlags.Verify {
@@ -58,14 +85,22 @@ func locksCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// With --refresh, updating the cache is the point of the command, so
+	// don't ignore a failure to write it.
+	lockClient.RequireLocksCache = locksCmdFlags.Refresh
+
 	var locks []locking.Lock
 	var locksOwned map[locking.Lock]bool
 	var jsonWriteFunc func(io.Writer) error
 	if locksCmdFlags.Verify {
 		var ourLocks, theirLocks []locking.Lock
 		ourLocks, theirLocks, err = lockClient.SearchLocksVerifiable(locksCmdFlags.Limit, locksCmdFlags.Cached)
+		if staleAfter > 0 {
+			ourLocks = staleLocks(ourLocks, staleAfter, time.Now())
+			theirLocks = staleLocks(theirLocks, staleAfter, time.Now())
+		}
 		jsonWriteFunc = func(writer io.Writer) error {
-			return lockClient.EncodeLocksVerifiable(ourLocks, theirLocks, writer)
+			return lockClient.EncodeLocksVerifiable(nonNilLocks(ourLocks), nonNilLocks(theirLocks), writer)
 		}
 
 		locks = append(ourLocks, theirLocks...)
@@ -75,8 +110,17 @@ func locksCommand(cmd *cobra.Command, args []string) {
 		}
 	} else {
 		locks, err = lockClient.SearchLocks(filters, locksCmdFlags.Limit, locksCmdFlags.Local, locksCmdFlags.Cached)
+		if staleAfter > 0 {
+			locks = staleLocks(locks, staleAfter, time.Now())
+		}
 		jsonWriteFunc = func(writer io.Writer) error {
-			return lockClient.EncodeLocks(locks, writer)
+			return lockClient.EncodeLocks(nonNilLocks(locks), writer)
+		}
+	}
+
+	if locksCmdFlags.Cached && err == nil {
+		if cachedAt, cerr := lockClient.LocksCacheTime(locksCmdFlags.Verify); cerr == nil {
+			Error(tr.Tr.Get("Showing locks cached %s ago; use --refresh to update them.", time.Since(cachedAt).Round(time.Second)))
 		}
 	}
 
@@ -102,7 +146,15 @@ func locksCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	sort.Strings(lockPaths)
+	if staleAfter > 0 {
+		// List the oldest locks first, as they are the most likely
+		// to have been abandoned.
+		sort.SliceStable(lockPaths, func(i, j int) bool {
+			return locksByPath[lockPaths[i]].LockedAt.Before(locksByPath[lockPaths[j]].LockedAt)
+		})
+	} else {
+		sort.Strings(lockPaths)
+	}
 	for _, lockPath := range lockPaths {
 		var ownerName string
 		lock := locksByPath[lockPath]
@@ -121,9 +173,18 @@ func locksCommand(cmd *cobra.Command, args []string) {
 			}
 		}
 
-		Print("%s%s%s\t%s%s\tID:%s", kind, lock.Path, strings.Repeat(" ", pathPadding),
+		var lease string
+		if lock.ExpiresAt != nil {
+			if remaining := time.Until(*lock.ExpiresAt); remaining > 0 {
+				lease = "\t" + tr.Tr.Get("expires in %s", remaining.Round(time.Second))
+			} else {
+				lease = "\t" + tr.Tr.Get("expired")
+			}
+		}
+
+		Print("%s%s%s\t%s%s\tID:%s%s", kind, lock.Path, strings.Repeat(" ", pathPadding),
 			ownerName, strings.Repeat(" ", namePadding),
-			lock.Id,
+			lock.Id, lease,
 		)
 	}
 
@@ -132,6 +193,37 @@ func locksCommand(cmd *cobra.Command, args []string) {
 	}
 }
 
+// staleLocks returns those of the given locks which were acquired more than
+// staleAfter before now, or whose lease had expired by then, ordered from the
+// oldest to the newest, and by path for locks acquired at the same time.
+func staleLocks(locks []locking.Lock, staleAfter time.Duration, now time.Time) []locking.Lock {
+	var stale []locking.Lock
+	for _, lock := range locks {
+		expired := lock.ExpiresAt != nil && !lock.ExpiresAt.After(now)
+		if expired || now.Sub(lock.LockedAt) > staleAfter {
+			stale = append(stale, lock)
+		}
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		if !stale[i].LockedAt.Equal(stale[j].LockedAt) {
+			return stale[i].LockedAt.Before(stale[j].LockedAt)
+		}
+		return stale[i].Path < stale[j].Path
+	})
+	return stale
+}
+
+// nonNilLocks returns the given set of locks, or an empty set if it is nil,
+// so that an empty result is encoded as "[]" rather than "null" in JSON
+// output.
+func nonNilLocks(locks []locking.Lock) []locking.Lock {
+	if locks == nil {
+		return []locking.Lock{}
+	}
+	return locks
+}
+
 // locksFlags wraps up and holds all of the flags that can be given to the
 // `git lfs locks` command.
 type locksFlags struct {
@@ -152,9 +244,15 @@ type locksFlags struct {
 	// for non-local queries, report cached query results from the last query
 	// instead of actually querying the server again
 	Cached bool
+	// Refresh queries the server and replaces the cached query results
+	// used by Cached, failing if they can't be written.
+	Refresh bool
 	// for non-local queries, verify lock owner on server and
 	// denote our locks in output
 	Verify bool
+	// Stale is an optional duration after which locks are considered
+	// stale, in which case only stale locks are reported, oldest first.
+	Stale string
 }
 
 // Filters produces a filter based on locksFlags instance.
@@ -184,7 +282,9 @@ func init() {
 		cmd.Flags().IntVarP(&locksCmdFlags.Limit, "limit", "l", 0, "optional limit for number of results to return")
 		cmd.Flags().BoolVarP(&locksCmdFlags.Local, "local", "", false, "only list cached local record of own locks")
 		cmd.Flags().BoolVarP(&locksCmdFlags.Cached, "cached", "", false, "list cached lock information from the last remote query, instead of actually querying the server")
+		cmd.Flags().BoolVarP(&locksCmdFlags.Refresh, "refresh", "", false, "query the server and update the cached lock information used by --cached")
 		cmd.Flags().BoolVarP(&locksCmdFlags.Verify, "verify", "", false, "verify lock owner on server and mark own locks by 'O'")
 		cmd.Flags().BoolVarP(&locksCmdFlags.JSON, "json", "", false, "print output in json")
+		cmd.Flags().StringVarP(&locksCmdFlags.Stale, "stale", "", "", "only list locks held for longer than the given duration, or whose lease has expired, oldest first")
 	})
 }
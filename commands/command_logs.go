@@ -1,14 +1,24 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfshttp"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/spf13/cobra"
 )
 
+var (
+	logsParseJSON bool
+)
+
 func logsCommand(cmd *cobra.Command, args []string) {
 	for _, path := range sortedLogs() {
 		Print(path)
@@ -41,6 +51,64 @@ func logsShowCommand(cmd *cobra.Command, args []string) {
 	os.Stdout.Write(by)
 }
 
+func logsParseCommand(cmd *cobra.Command, args []string) {
+	dir := filepath.Join(cfg.LocalLogDir(), "http")
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		logs := sortedHTTPLogs(dir)
+		if len(logs) < 1 {
+			Exit(tr.Tr.Get("No HTTP stats logs to parse; set GIT_LOG_STATS=1 to record them"))
+		}
+		name = logs[len(logs)-1]
+	}
+
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		Exit(tr.Tr.Get("Error reading log: %s", name))
+	}
+	defer f.Close()
+
+	summary, err := lfshttp.SummarizeHTTPStats(f)
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Error parsing log: %s", name)))
+	}
+
+	if logsParseJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(summary); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
+	Print(tr.Tr.Get("Log: %s", name))
+	Print(tr.Tr.GetN("%d request", "%d requests", summary.Requests, summary.Requests) +
+		", " + tr.Tr.GetN("%d retry", "%d retries", summary.Retries, summary.Retries))
+	Print(tr.Tr.Get("Sent %s, received %s",
+		humanize.FormatBytes(uint64(summary.BytesSent)),
+		humanize.FormatBytes(uint64(summary.BytesReceived))))
+	Print(tr.Tr.Get("Latency: p50 %s, p95 %s",
+		formatLatency(summary.LatencyP50), formatLatency(summary.LatencyP95)))
+
+	for _, e := range summary.Endpoints {
+		Print("")
+		Print("%s %s %s", e.Key, e.Method, e.Host)
+		Print("  " + tr.Tr.GetN("%d request", "%d requests", e.Requests, e.Requests) +
+			", " + tr.Tr.GetN("%d retry", "%d retries", e.Retries, e.Retries))
+		Print("  " + tr.Tr.Get("Sent %s, received %s",
+			humanize.FormatBytes(uint64(e.BytesSent)),
+			humanize.FormatBytes(uint64(e.BytesReceived))))
+		Print("  " + tr.Tr.Get("Latency: p50 %s, p95 %s",
+			formatLatency(e.LatencyP50), formatLatency(e.LatencyP95)))
+	}
+}
+
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
 func logsClearCommand(cmd *cobra.Command, args []string) {
 	err := os.RemoveAll(cfg.LocalLogDir())
 	if err != nil {
@@ -73,11 +141,42 @@ func sortedLogs() []string {
 	return names
 }
 
+// sortedHTTPLogs returns the names of the HTTP stats logs in dir, oldest
+// first.
+func sortedHTTPLogs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{}
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	// Log names contain the time at which they were created, such as
+	// "http-1700000000.log", so sort shorter names first.
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
 func init() {
 	RegisterCommand("logs", logsCommand, func(cmd *cobra.Command) {
+		parseCmd := NewCommand("parse", logsParseCommand)
+		parseCmd.Flags().BoolVarP(&logsParseJSON, "json", "", false, "print output in JSON")
+
 		cmd.AddCommand(
 			NewCommand("last", logsLastCommand),
 			NewCommand("show", logsShowCommand),
+			parseCmd,
 			NewCommand("clear", logsClearCommand),
 			NewCommand("boomtown", logsBoomtownCommand),
 		)
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,10 +24,16 @@ var (
 	lsFilesShowNameOnly = false
 	lsFilesJSON         = false
 	debug               = false
+
+	// lsFilesMissing and lsFilesNotDownloaded restrict the files shown to
+	// those absent from the working tree or the local object cache.
+	lsFilesMissing       = false
+	lsFilesNotDownloaded = false
 )
 
 type lsFilesObject struct {
 	Name       string `json:"name"`
+	Path       string `json:"path"`
 	Size       int64  `json:"size"`
 	Checkout   bool   `json:"checkout"`
 	Downloaded bool   `json:"downloaded"`
@@ -60,6 +68,16 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 			}
 			includeRef = args[1]
 			scanRange = true
+		} else if strings.Contains(ref, "...") {
+			Exit(tr.Tr.Get("Cannot use symmetric difference %q; use a reference range such as %q instead", ref, "A..B"))
+		} else if left, right, ok := strings.Cut(ref, ".."); ok {
+			if lsFilesScanDeleted {
+				Exit(tr.Tr.Get("Cannot use --deleted with reference range"))
+			}
+			// As with Git, either side of the range defaults to
+			// HEAD if omitted.
+			ref, includeRef = defaultRangeRef(left), defaultRangeRef(right)
+			scanRange = true
 		}
 	} else {
 		fullref, err := git.CurrentRef()
@@ -80,7 +98,7 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 	}
 
 	seen := make(map[string]struct{})
-	var items []lsFilesObject
+	jsonWriter := &lsFilesJSONWriter{w: os.Stdout}
 
 	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
@@ -98,6 +116,11 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 			}
 		}
 
+		if !lsFilesShow(p) {
+			seen[p.Name] = struct{}{}
+			return
+		}
+
 		if debug {
 			// TRANSLATORS: these strings should have the colons
 			// aligned in a column.
@@ -111,8 +134,9 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 					p.Oid,
 					p.Version))
 		} else if lsFilesJSON {
-			items = append(items, lsFilesObject{
+			err := jsonWriter.Write(&lsFilesObject{
 				Name:       p.Name,
+				Path:       p.Name,
 				Size:       p.Size,
 				Checkout:   fileExistsOfSize(p),
 				Downloaded: cfg.LFSObjectExists(p.Oid, p.Size),
@@ -120,6 +144,9 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 				Oid:        p.Oid,
 				Version:    p.Version,
 			})
+			if err != nil {
+				ExitWithError(err)
+			}
 		} else {
 			msg := []string{p.Oid[:showOidLen], lsFilesMarker(p), p.Name}
 			if lsFilesShowNameOnly {
@@ -168,17 +195,75 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 	if lsFilesJSON {
-		data := struct {
-			Files []lsFilesObject `json:"files"`
-		}{Files: items}
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", " ")
-		if err := encoder.Encode(data); err != nil {
+		if err := jsonWriter.Close(); err != nil {
 			ExitWithError(err)
 		}
 	}
 }
 
+// lsFilesJSONWriter writes the "files" array of "git lfs ls-files --json" one
+// object at a time, so that large repositories need not be held in memory.
+type lsFilesJSONWriter struct {
+	w     io.Writer
+	count int
+}
+
+// Write writes a single object to the "files" array.
+func (j *lsFilesJSONWriter) Write(obj *lsFilesObject) error {
+	data, err := json.MarshalIndent(obj, "  ", " ")
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "{\n \"files\": [\n  "
+	}
+	j.count++
+
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, data)
+	return err
+}
+
+// Close ends the "files" array and the enclosing object.
+func (j *lsFilesJSONWriter) Close() error {
+	var err error
+	if j.count == 0 {
+		_, err = io.WriteString(j.w, "{\n \"files\": []\n}\n")
+	} else {
+		_, err = io.WriteString(j.w, "\n ]\n}\n")
+	}
+	return err
+}
+
+// defaultRangeRef returns the given side of a reference range, or HEAD if it
+// was omitted.
+func defaultRangeRef(ref string) string {
+	if len(ref) == 0 {
+		return "HEAD"
+	}
+	return ref
+}
+
+// lsFilesShow returns whether the given file passes the --missing and
+// --not-downloaded filters.
+func lsFilesShow(p *lfs.WrappedPointer) bool {
+	if lsFilesMissing && fileExists(p) {
+		return false
+	}
+	if lsFilesNotDownloaded && cfg.LFSObjectExists(p.Oid, p.Size) {
+		return false
+	}
+	return true
+}
+
+// Returns true if anything exists at the pointer's path in the working tree
+func fileExists(p *lfs.WrappedPointer) bool {
+	path := cfg.Filesystem().DecodePathname(p.Name)
+	_, err := os.Lstat(filepath.Join(cfg.LocalWorkingDir(), path))
+	return !os.IsNotExist(err)
+}
+
 // Returns true if a pointer appears to be properly smudge on checkout
 func fileExistsOfSize(p *lfs.WrappedPointer) bool {
 	path := cfg.Filesystem().DecodePathname(p.Name)
@@ -201,6 +286,8 @@ func init() {
 		cmd.Flags().BoolVarP(&debug, "debug", "d", false, "")
 		cmd.Flags().BoolVarP(&lsFilesScanAll, "all", "a", false, "")
 		cmd.Flags().BoolVar(&lsFilesScanDeleted, "deleted", false, "")
+		cmd.Flags().BoolVar(&lsFilesMissing, "missing", false, "")
+		cmd.Flags().BoolVar(&lsFilesNotDownloaded, "not-downloaded", false, "")
 		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths")
 		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths")
 		cmd.Flags().BoolVarP(&lsFilesJSON, "json", "", false, "print output in JSON")
@@ -38,6 +38,12 @@ var (
 	// above the provided size.
 	migrateImportAboveFmt string
 
+	// migrateImportRespectAttrs indicates the presence of the
+	// --respect-attributes flag, and instructs 'git lfs migrate import
+	// --above' to also import files tracked by the '.gitattributes' file
+	// at each rewritten commit.
+	migrateImportRespectAttrs bool
+
 	// migrateEverything indicates the presence of the --everything flag,
 	// and instructs 'git lfs migrate' to migrate all local references.
 	migrateEverything bool
@@ -59,6 +65,9 @@ var (
 	// exportRemote is the remote from which to download objects when
 	// performing an export
 	exportRemote string
+	// exportOrphans is the path of the file to which to write the objects
+	// which are no longer referenced after performing an export
+	exportOrphans string
 
 	// migrateFixup is the flag indicating whether or not to infer the
 	// included and excluded filepath patterns.
@@ -122,6 +131,7 @@ func rewriteOptions(args []string, opts *githistory.RewriteOptions, l *tasklog.L
 		BlobFn:            opts.BlobFn,
 		TreePreCallbackFn: opts.TreePreCallbackFn,
 		TreeCallbackFn:    opts.TreeCallbackFn,
+		CommitCallbackFn:  opts.CommitCallbackFn,
 	}, nil
 }
 
@@ -345,44 +355,42 @@ func ensureWorkingCopyClean(in io.Reader, out io.Writer) {
 		return
 	}
 
-	var proceed bool
-	if migrateYes {
-		proceed = true
+	proceed := migrateYes || promptYesNo(in, out, fmt.Sprintf("migrate: %s", tr.Tr.Get("override changes in your working copy?  All uncommitted changes will be lost! [y/N] ")))
+	if proceed {
+		fmt.Fprintf(out, "migrate: %s\n", tr.Tr.Get("changes in your working copy will be overridden ..."))
 	} else {
-		answer := bufio.NewReader(in)
-	L:
-		for {
-			fmt.Fprintf(out, "migrate: %s", tr.Tr.Get("override changes in your working copy?  All uncommitted changes will be lost! [y/N] "))
-			s, err := answer.ReadString('\n')
-			if err != nil {
-				if err == io.EOF {
-					break L
-				}
-				ExitWithError(errors.Wrap(err,
-					tr.Tr.Get("Could not read answer")))
-			}
+		Exit("migrate: %s", tr.Tr.Get("working copy must not be dirty"))
+	}
+}
 
-			switch strings.TrimSpace(s) {
-			// TRANSLATORS: these are negative (no) responses.
-			case tr.Tr.Get("n"), tr.Tr.Get("N"), "":
-				proceed = false
-				break L
-			// TRANSLATORS: these are positive (yes) responses.
-			case tr.Tr.Get("y"), tr.Tr.Get("Y"):
-				proceed = true
-				break L
+// promptYesNo writes the given prompt to "out" and reads an answer from "in",
+// repeating the prompt until a yes or no answer is given.  An empty answer or
+// the end of the input is treated as no.
+func promptYesNo(in io.Reader, out io.Writer, prompt string) bool {
+	answer := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, prompt)
+		s, err := answer.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return false
 			}
+			ExitWithError(errors.Wrap(err,
+				tr.Tr.Get("Could not read answer")))
+		}
 
-			if !strings.HasSuffix(s, "\n") {
-				fmt.Fprintf(out, "\n")
-			}
+		switch strings.TrimSpace(s) {
+		// TRANSLATORS: these are negative (no) responses.
+		case tr.Tr.Get("n"), tr.Tr.Get("N"), "":
+			return false
+		// TRANSLATORS: these are positive (yes) responses.
+		case tr.Tr.Get("y"), tr.Tr.Get("Y"):
+			return true
 		}
-	}
 
-	if proceed {
-		fmt.Fprintf(out, "migrate: %s\n", tr.Tr.Get("changes in your working copy will be overridden ..."))
-	} else {
-		Exit("migrate: %s", tr.Tr.Get("working copy must not be dirty"))
+		if !strings.HasSuffix(s, "\n") {
+			fmt.Fprintf(out, "\n")
+		}
 	}
 }
 
@@ -393,9 +401,12 @@ func init() {
 	info.Flags().StringVar(&migrateInfoUnitFmt, "unit", "", "--unit=<unit>")
 	info.Flags().StringVar(&migrateInfoPointers, "pointers", "", "Ignore, dereference, or include LFS pointer files")
 	info.Flags().BoolVar(&migrateFixup, "fixup", false, "Infer filepaths based on .gitattributes")
+	info.Flags().BoolVar(&migrateInfoJSON, "json", false, "print output in JSON")
+	info.Flags().BoolVar(&migrateInfoHistogram, "histogram", false, "Show a histogram of file sizes")
 
 	importCmd := NewCommand("import", migrateImportCommand)
 	importCmd.Flags().StringVar(&migrateImportAboveFmt, "above", "", "--above=<n>")
+	importCmd.Flags().BoolVar(&migrateImportRespectAttrs, "respect-attributes", false, "With --above, also import files tracked by each commit's .gitattributes")
 	importCmd.Flags().BoolVar(&migrateVerbose, "verbose", false, "Verbose logging")
 	importCmd.Flags().StringVar(&objectMapFilePath, "object-map", "", "Object map file")
 	importCmd.Flags().BoolVar(&migrateNoRewrite, "no-rewrite", false, "Add new history without rewriting previous")
@@ -406,6 +417,7 @@ func init() {
 	exportCmd.Flags().BoolVar(&migrateVerbose, "verbose", false, "Verbose logging")
 	exportCmd.Flags().StringVar(&objectMapFilePath, "object-map", "", "Object map file")
 	exportCmd.Flags().StringVar(&exportRemote, "remote", "", "Remote from which to download objects")
+	exportCmd.Flags().StringVar(&exportOrphans, "orphans", "", "Write objects no longer referenced after the export to this file")
 
 	RegisterCommand("migrate", nil, func(cmd *cobra.Command) {
 		cmd.PersistentFlags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths")
@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/filepathfilter"
@@ -38,6 +40,107 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 		ExitWithError(errors.Errorf(tr.Tr.Get("One or more files must be specified with --include")))
 	}
 
+	remote := cfg.Remote()
+	if cmd.Flag("remote").Changed {
+		remote = exportRemote
+	}
+
+	_, objects := exportHistory(db, rewriter, l, args, remote, cmd.Flag("remote").Changed)
+
+	if len(exportOrphans) > 0 {
+		n, err := writeExportOrphans(l, exportOrphans, objects)
+		if err != nil {
+			ExitWithError(errors.Wrap(err, tr.Tr.Get("Unable to write orphaned objects to %q", exportOrphans)))
+		}
+		Print(tr.Tr.GetN(
+			"%d object is no longer referenced by any local branch or tag; see %s",
+			"%d objects are no longer referenced by any local branch or tag; see %s",
+			n,
+			n,
+			exportOrphans,
+		))
+	}
+}
+
+// writeExportOrphans writes to the file at path the OID and size of each of the
+// given objects, whose pointers were replaced by an export, which is no longer
+// referenced by any local branch or tag, one per line and sorted by OID.  Once
+// the rewritten history has been pushed, the remote no longer needs these
+// objects, unless other refs on the remote still refer to them.  Nothing is
+// deleted.
+//
+// It returns the number of objects written.
+func writeExportOrphans(l *tasklog.Logger, path string, objects map[string]int64) (int, error) {
+	t := l.Waiter(fmt.Sprintf("migrate: %s", tr.Tr.Get("Finding orphaned objects")))
+	defer t.Complete()
+
+	orphans := make(map[string]int64, len(objects))
+	for oid, size := range objects {
+		orphans[oid] = size
+	}
+
+	refs, err := git.LocalRefs()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(refs) > 0 && len(orphans) > 0 {
+		include := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			include = append(include, ref.Sha)
+		}
+
+		var scanErr error
+		gs := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+			if err != nil {
+				scanErr = err
+				return
+			}
+			delete(orphans, p.Oid)
+		})
+		if err := gs.ScanRefs(include, nil, nil); err != nil {
+			return 0, err
+		}
+		if scanErr != nil {
+			return 0, scanErr
+		}
+	}
+
+	oids := make([]string, 0, len(orphans))
+	for oid := range orphans {
+		oids = append(oids, oid)
+	}
+	sort.Strings(oids)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, oid := range oids {
+		fmt.Fprintf(w, "%s %d\n", oid, orphans[oid])
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(oids), f.Close()
+}
+
+// exportHistory rewrites the history selected by the given arguments, as
+// interpreted by rewriteOptions, replacing Git LFS pointers to files matched by
+// the rewriter's filter with the contents of their objects.  Any objects
+// which are not present locally are first downloaded from the given remote.
+// If requireRemote is true, the remote must be valid.
+//
+// It returns the set of paths whose pointers were replaced, and the sizes of
+// the objects to which those pointers referred, keyed by OID.
+func exportHistory(db *gitobj.ObjectDatabase, rewriter *githistory.Rewriter, l *tasklog.Logger, args []string, remote string, requireRemote bool) (*tools.OrderedSet, map[string]int64) {
+	filter := rewriter.Filter()
+	exported := tools.NewOrderedSet()
+	objects := make(map[string]int64)
+
 	tracked := trackedFromExportFilter(filter)
 	gitfilter := lfs.NewGitFilter(cfg)
 
@@ -62,6 +165,8 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 				return nil, err
 			}
 
+			exported.Add(path)
+			objects[ptr.Oid] = ptr.Size
 			return gitobj.NewBlobFromFile(downloadPath)
 		},
 
@@ -105,17 +210,13 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 
 	setupRepository()
 
-	opts, err = rewriteOptions(args, opts, l)
+	opts, err := rewriteOptions(args, opts, l)
 	if err != nil {
 		ExitWithError(err)
 	}
 
-	remote := cfg.Remote()
-	if cmd.Flag("remote").Changed {
-		remote = exportRemote
-	}
 	remoteURL := getAPIClient().Endpoints.RemoteEndpoint("download", remote).Url
-	if remoteURL == "" && cmd.Flag("remote").Changed {
+	if remoteURL == "" && requireRemote {
 		ExitWithError(errors.Errorf(tr.Tr.Get("Invalid remote %s provided", remote)))
 	}
 
@@ -173,6 +274,8 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 
 	// Prune our cache
 	prune(fetchPruneCfg, false, false, false, false, true)
+
+	return exported, objects
 }
 
 func performForceCheckout(l *tasklog.Logger) error {
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -47,7 +48,23 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 			ExitWithError(errors.Errorf(tr.Tr.Get("--no-rewrite and --fixup cannot be combined")))
 		}
 
-		if len(args) == 0 {
+		// Files may be selected from the current commit with
+		// --include, --exclude, or --above, rather than given as
+		// arguments.
+		include, exclude := getIncludeExcludeArgs(cmd)
+		above, err := humanize.ParseBytes(migrateImportAboveFmt)
+		if err != nil {
+			ExitWithError(errors.Wrap(err, tr.Tr.Get("Cannot parse --above=<n>")))
+		}
+		if above > 0 && (include != nil || exclude != nil) {
+			ExitWithError(errors.Errorf(tr.Tr.Get("Cannot use --above with --include, --exclude, --fixup")))
+		}
+		selecting := include != nil || exclude != nil || above > 0
+		if selecting && len(args) > 0 {
+			ExitWithError(errors.Errorf(tr.Tr.Get("Cannot use --include, --exclude, or --above with files given with --no-rewrite")))
+		}
+
+		if len(args) == 0 && !selecting {
 			ExitWithError(errors.Errorf(tr.Tr.Get("Expected one or more files with --no-rewrite")))
 		}
 
@@ -64,20 +81,52 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 
 		root := commit.TreeID
 
-		filter := git.GetAttributeFilter(cfg.LocalWorkingDir(), cfg.LocalGitDir())
-		if len(filter.Include()) == 0 {
-			ExitWithError(errors.Errorf(tr.Tr.Get("No Git LFS filters found in '.gitattributes'")))
-		}
+		files := args
+		patterns := strings.Join(args, ",")
+		if selecting {
+			filter := buildFilepathFilterWithPatternType(cfg, include, exclude, false, filepathfilter.GitAttributes)
 
-		gf := lfs.NewGitFilter(cfg)
+			files, err = selectNoRewriteFiles(db, root, "", filter, above)
+			if err != nil {
+				ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not select files to import")))
+			}
+			if len(files) == 0 {
+				ExitWithError(errors.Errorf(tr.Tr.Get("No files to import with --no-rewrite")))
+			}
+
+			// Track the selected files in the new commit, by the
+			// --include patterns if given or by their paths
+			// otherwise.
+			tracked := trackedFromFilter(filter)
+			if len(filter.Include()) > 0 {
+				patterns = strings.Join(filter.Include(), ",")
+			} else {
+				for _, file := range files {
+					tracked.Add(fmt.Sprintf("/%s filter=lfs diff=lfs merge=lfs -text", escapeGlobCharacters(file)))
+				}
+				patterns = strings.Join(files, ",")
+			}
 
-		for _, file := range args {
-			if !filter.Allows(file) {
-				ExitWithError(errors.Errorf(tr.Tr.Get("File %s did not match any Git LFS filters in '.gitattributes'", file)))
+			root, err = rewriteRootAttributes(db, root, tracked)
+			if err != nil {
+				ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not update '.gitattributes'")))
+			}
+		} else {
+			filter := git.GetAttributeFilter(cfg.LocalWorkingDir(), cfg.LocalGitDir())
+			if len(filter.Include()) == 0 {
+				ExitWithError(errors.Errorf(tr.Tr.Get("No Git LFS filters found in '.gitattributes'")))
+			}
+
+			for _, file := range args {
+				if !filter.Allows(file) {
+					ExitWithError(errors.Errorf(tr.Tr.Get("File %s did not match any Git LFS filters in '.gitattributes'", file)))
+				}
 			}
 		}
 
-		for _, file := range args {
+		gf := lfs.NewGitFilter(cfg)
+
+		for _, file := range files {
 			root, err = rewriteTree(gf, db, root, file)
 			if err != nil {
 				ExitWithError(errors.Wrapf(err, tr.Tr.Get("Could not rewrite %q", file)))
@@ -102,7 +151,7 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 			Author:    author.String(),
 			Committer: committer.String(),
 			ParentIDs: [][]byte{sha},
-			Message:   generateMigrateCommitMessage(cmd, strings.Join(args, ",")),
+			Message:   generateMigrateCommitMessage(cmd, patterns),
 			TreeID:    root,
 		})
 
@@ -144,8 +193,16 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 		if include != nil || exclude != nil || migrateFixup {
 			ExitWithError(errors.Errorf(tr.Tr.Get("Cannot use --above with --include, --exclude, --fixup")))
 		}
+	} else if migrateImportRespectAttrs {
+		ExitWithError(errors.Errorf(tr.Tr.Get("Cannot use --respect-attributes without --above")))
 	}
 
+	// stats holds the per-commit counts reported with
+	// --respect-attributes or --fixup, in the order in which commits were
+	// rewritten.
+	var stats []string
+	var converted, pointers int
+
 	migrate(args, rewriter, l, &githistory.RewriteOptions{
 		Verbose:           migrateVerbose,
 		ObjectMapFilePath: objectMapFilePath,
@@ -153,20 +210,33 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 			if filepath.Base(path) == ".gitattributes" {
 				return b, nil
 			}
-			if (above > 0) && (uint64(b.Size) < above) {
+
+			var matched bool
+			if migrateImportRespectAttrs {
+				var isPointer bool
+
+				b, isPointer = decodeBlobPointer(b)
+				if isPointer {
+					pointers++
+					return b, nil
+				}
+				matched = trackedByAttrs(fixups, path)
+			}
+
+			if (above > 0) && (uint64(b.Size) < above) && !matched {
 				return b, nil
 			}
 
 			if migrateFixup {
-				var ok bool
-				attrs := fixups.Applied(path)
-				for _, attr := range attrs {
-					if attr.K == "filter" {
-						ok = attr.V == "lfs"
-					}
+				if !trackedByAttrs(fixups, path) {
+					return b, nil
 				}
 
-				if !ok {
+				var isPointer bool
+
+				b, isPointer = decodeBlobPointer(b)
+				if isPointer {
+					pointers++
 					return b, nil
 				}
 			}
@@ -177,10 +247,16 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 				return nil, err
 			}
 
-			if ext := filepath.Ext(path); len(ext) > 0 && above == 0 {
-				exts.Add(fmt.Sprintf("*%s filter=lfs diff=lfs merge=lfs -text", ext))
-			} else {
-				exts.Add(fmt.Sprintf("/%s filter=lfs diff=lfs merge=lfs -text", escapeGlobCharacters(path)))
+			converted++
+
+			// Paths matched by the commit's .gitattributes are
+			// already tracked there.
+			if !matched {
+				if ext := filepath.Ext(path); len(ext) > 0 && above == 0 {
+					exts.Add(fmt.Sprintf("*%s filter=lfs diff=lfs merge=lfs -text", ext))
+				} else {
+					exts.Add(fmt.Sprintf("/%s filter=lfs diff=lfs merge=lfs -text", escapeGlobCharacters(path)))
+				}
 			}
 
 			return &gitobj.Blob{
@@ -189,7 +265,7 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 		},
 
 		TreePreCallbackFn: func(path string, t *gitobj.Tree) error {
-			if migrateFixup && path == "/" {
+			if (migrateFixup || migrateImportRespectAttrs) && path == "/" {
 				var err error
 
 				fixups, err = gitattr.New(db, t)
@@ -256,9 +332,27 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 			}), nil
 		},
 
+		CommitCallbackFn: func(original, rewritten []byte) error {
+			if migrateImportRespectAttrs {
+				stats = append(stats, tr.Tr.Get("%s -> %s: %d converted, %d already in Git LFS",
+					hex.EncodeToString(original)[:7], hex.EncodeToString(rewritten)[:7],
+					converted, pointers))
+			} else if migrateFixup && converted+pointers > 0 {
+				stats = append(stats, tr.Tr.Get("%s -> %s: %d fixed, %d already in Git LFS",
+					hex.EncodeToString(original)[:7], hex.EncodeToString(rewritten)[:7],
+					converted, pointers))
+			}
+			converted, pointers = 0, 0
+			return nil
+		},
+
 		UpdateRefs: true,
 	})
 
+	for _, line := range stats {
+		Print(line)
+	}
+
 	if err := checkoutNonBare(l); err != nil {
 		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not checkout")))
 	}
@@ -459,6 +553,75 @@ func rewriteTree(gf *lfs.GitFilter, db *gitobj.ObjectDatabase, root []byte, path
 	}
 }
 
+// selectNoRewriteFiles returns the paths of the files in the tree "root",
+// whose own path is "prefix", which are allowed by the given filter and are at
+// least "above" bytes in size, and which are not already Git LFS pointers.
+func selectNoRewriteFiles(db *gitobj.ObjectDatabase, root []byte, prefix string, filter *filepathfilter.Filter, above uint64) ([]string, error) {
+	tree, err := db.Tree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range tree.Entries {
+		path := e.Name
+		if len(prefix) > 0 {
+			path = prefix + "/" + e.Name
+		}
+
+		switch e.Type() {
+		case gitobj.TreeObjectType:
+			subfiles, err := selectNoRewriteFiles(db, e.Oid, path, filter, above)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, subfiles...)
+		case gitobj.BlobObjectType:
+			if e.IsLink() || e.Name == ".gitattributes" || !filter.Allows(path) {
+				continue
+			}
+
+			b, err := db.Blob(e.Oid)
+			if err != nil {
+				return nil, err
+			}
+			_, isPointer := decodeBlobPointer(b)
+			b.Close()
+
+			if isPointer || uint64(b.Size) < above {
+				continue
+			}
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// rewriteRootAttributes returns the tree "root" with the given patterns added
+// to its '.gitattributes' file, if they aren't already present.
+func rewriteRootAttributes(db *gitobj.ObjectDatabase, root []byte, patterns *tools.OrderedSet) ([]byte, error) {
+	tree, err := db.Tree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := trackedFromAttrs(db, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := trackedToBlob(db, theirs.Clone().Union(patterns))
+	if err != nil {
+		return nil, err
+	}
+
+	return db.WriteTree(tree.Merge(&gitobj.TreeEntry{
+		Name:     ".gitattributes",
+		Filemode: 0100644,
+		Oid:      blob,
+	}))
+}
+
 // findEntry searches a tree for the desired entry, and returns the index of that
 // entry within the tree's Entries array
 func findEntry(t *gitobj.Tree, name string) int {
@@ -470,3 +633,30 @@ func findEntry(t *gitobj.Tree, name string) int {
 
 	return -1
 }
+
+// trackedByAttrs returns whether the given path is tracked by Git LFS
+// according to the attributes in "t".
+func trackedByAttrs(t *gitattr.Tree, path string) bool {
+	var ok bool
+	for _, attr := range t.Applied(path) {
+		if attr.K == "filter" {
+			ok = attr.V == "lfs"
+		}
+	}
+	return ok
+}
+
+// decodeBlobPointer returns whether the given blob is a Git LFS pointer, along
+// with a blob equivalent to "b" which may still be read in full, since
+// determining whether "b" is a pointer consumes some of its contents.
+func decodeBlobPointer(b *gitobj.Blob) (*gitobj.Blob, bool) {
+	var consumed bytes.Buffer
+
+	p, err := lfs.DecodePointerFromBlob(&gitobj.Blob{
+		Contents: io.TeeReader(b.Contents, &consumed), Size: b.Size,
+	})
+
+	return &gitobj.Blob{
+		Contents: io.MultiReader(&consumed, b.Contents), Size: b.Size,
+	}, p != nil && err == nil
+}
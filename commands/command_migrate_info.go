@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"math/bits"
 	"os"
 	"path/filepath"
 	"sort"
@@ -55,6 +57,15 @@ var (
 	// migrateInfoPointersMode is the Git LFS pointer treatment mode
 	// parsed from migrateInfoPointers.
 	migrateInfoPointersMode migrateInfoPointersType
+
+	// migrateInfoJSON is a flag given to the git-lfs-migrate(1) subcommand
+	// 'info' specifying that the entries should be output as JSON.
+	migrateInfoJSON bool
+
+	// migrateInfoHistogram is a flag given to the git-lfs-migrate(1)
+	// subcommand 'info' specifying that a histogram of file sizes should
+	// be output after the entries.
+	migrateInfoHistogram bool
 )
 
 func migrateInfoCommand(cmd *cobra.Command, args []string) {
@@ -113,6 +124,7 @@ func migrateInfoCommand(cmd *cobra.Command, args []string) {
 
 	migrateInfoAbove = above
 	pointersInfoEntry := &MigrateInfoEntry{Qualifier: "LFS Objects", Separate: true}
+	histogram := make(MigrateInfoHistogram, 0)
 	var fixups *gitattr.Tree
 
 	migrate(args, rewriter, l, &githistory.RewriteOptions{
@@ -154,6 +166,8 @@ func migrateInfoCommand(cmd *cobra.Command, args []string) {
 			}
 
 			entry.Total++
+			entry.Bytes += size
+			histogram = histogram.Add(size)
 
 			if size > int64(migrateInfoAbove) {
 				entry.TotalAbove++
@@ -198,27 +212,66 @@ func migrateInfoCommand(cmd *cobra.Command, args []string) {
 	migrateInfoTopN = tools.ClampInt(migrateInfoTopN, 0, len(entries))
 
 	entries = entries[:migrateInfoTopN]
+	if !migrateInfoHistogram {
+		histogram = nil
+	}
+
+	if migrateInfoJSON {
+		data := &migrateInfoJSONOutput{
+			Above:     migrateInfoAbove,
+			Entries:   entries,
+			Histogram: histogram.Trim(),
+		}
+		if pointersInfoEntry.Total > 0 {
+			data.LFSObjects = pointersInfoEntry
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", " ")
+		if err := encoder.Encode(data); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
 	if pointersInfoEntry.Total > 0 {
 		entries = append(entries, pointersInfoEntry)
 	}
 
 	entries.Print(os.Stdout)
+	if migrateInfoHistogram {
+		if len(entries) > 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		histogram.Trim().Print(os.Stdout)
+	}
+}
+
+// migrateInfoJSONOutput is the structure of the output of the
+// git-lfs-migrate(1) subcommand 'info' when given the --json flag.
+type migrateInfoJSONOutput struct {
+	Above      uint64               `json:"above"`
+	Entries    []*MigrateInfoEntry  `json:"entries"`
+	LFSObjects *MigrateInfoEntry    `json:"lfs_objects,omitempty"`
+	Histogram  MigrateInfoHistogram `json:"histogram,omitempty"`
 }
 
 // MigrateInfoEntry represents a tuple of filetype to bytes and entry count
 // above and below a threshold.
 type MigrateInfoEntry struct {
 	// Qualifier is the filepath's extension.
-	Qualifier string
+	Qualifier string `json:"qualifier"`
 	// Separate indicates if the entry should be printed separately.
-	Separate bool
+	Separate bool `json:"-"`
 
 	// BytesAbove is total size of all files above a given threshold.
-	BytesAbove int64
+	BytesAbove int64 `json:"bytes_above"`
 	// TotalAbove is the count of all files above a given size threshold.
-	TotalAbove int64
+	TotalAbove int64 `json:"total_above"`
+	// Bytes is the total size of all files.
+	Bytes int64 `json:"bytes"`
 	// Total is the count of all files.
-	Total int64
+	Total int64 `json:"total"`
 }
 
 // findEntryByExtension finds or creates an entry from the given map that
@@ -358,3 +411,105 @@ func (e EntriesBySize) Print(to io.Writer) (int, error) {
 
 	return fmt.Fprintln(to, strings.Join(output, "\n"))
 }
+
+// MigrateInfoBucket counts the files whose sizes are at least Min bytes and
+// less than Max bytes.
+type MigrateInfoBucket struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+
+	// Bytes is the total size of all files in the bucket.
+	Bytes int64 `json:"bytes"`
+	// Total is the count of all files in the bucket.
+	Total int64 `json:"total"`
+}
+
+// MigrateInfoHistogram is a set of `*MigrateInfoBucket`'s whose sizes are
+// successive powers of two. The bucket at index 0 counts empty files, and the
+// bucket at index n > 0 counts files of at least 2^(n-1) and less than 2^n
+// bytes.
+type MigrateInfoHistogram []*MigrateInfoBucket
+
+// Add counts a file of the given size in the appropriate bucket, adding
+// buckets as necessary, and returns the resulting histogram.
+func (h MigrateInfoHistogram) Add(size int64) MigrateInfoHistogram {
+	n := bits.Len64(uint64(size))
+	for i := len(h); i <= n; i++ {
+		bucket := &MigrateInfoBucket{Max: 1 << i}
+		if i > 0 {
+			bucket.Min = 1 << (i - 1)
+		}
+		h = append(h, bucket)
+	}
+
+	h[n].Total++
+	h[n].Bytes += size
+	return h
+}
+
+// Trim returns the histogram without any leading buckets which contain no
+// files.
+func (h MigrateInfoHistogram) Trim() MigrateInfoHistogram {
+	for len(h) > 0 && h[0].Total == 0 {
+		h = h[1:]
+	}
+	return h
+}
+
+// Print formats the buckets of the histogram and prints them to the given
+// io.Writer, "to", returning "n" the number of bytes written, and any error, if
+// one occurred.
+func (h MigrateInfoHistogram) Print(to io.Writer) (int, error) {
+	if len(h) == 0 {
+		return 0, nil
+	}
+
+	ranges := make([]string, 0, len(h))
+	stats := make([]string, 0, len(h))
+	sizes := make([]string, 0, len(h))
+
+	for _, bucket := range h {
+		var size string
+		if migrateInfoUnit > 0 {
+			size = humanize.FormatBytesUnit(uint64(bucket.Bytes), migrateInfoUnit)
+		} else {
+			size = humanize.FormatBytes(uint64(bucket.Bytes))
+		}
+
+		ranges = append(ranges, fmt.Sprintf("%s - %s",
+			formatHistogramBound(bucket.Min), formatHistogramBound(bucket.Max)))
+		// TRANSLATORS: The strings here are intended to have the same
+		// display width including spaces, so please insert trailing
+		// spaces as necessary for your language.
+		stats = append(stats, tr.Tr.GetN(
+			"%d file ",
+			"%d files",
+			int(bucket.Total),
+			bucket.Total,
+		))
+		sizes = append(sizes, size)
+	}
+
+	ranges = tools.Ljust(ranges)
+	stats = tools.Rjust(stats)
+
+	output := make([]string, 0, len(h))
+	for i := 0; i < len(h); i++ {
+		output = append(output, strings.Join([]string{ranges[i], stats[i], sizes[i]}, "\t"))
+	}
+
+	return fmt.Fprintln(to, strings.Join(output, "\n"))
+}
+
+// formatHistogramBound formats a bucket bound, which is zero or a power of
+// two, exactly as a quantity of IEC storage units.
+func formatHistogramBound(n int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+	i := 0
+	for n >= 1024 && n%1024 == 0 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	return fmt.Sprintf("%d %s", n, units[i])
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/fs"
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tasklog"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	objectsGCDryRunArg  bool
+	objectsGCVerboseArg bool
+)
+
+// objectsGCCommand deletes every object in the local cache which is not
+// referenced by any commit reachable from a ref, by the index of any work
+// tree, or by a stash.  Unlike prune, it has no notion of recent refs or
+// commits; it removes exactly what is unreachable.
+func objectsGCCommand(cmd *cobra.Command, args []string) {
+	setupRepository()
+
+	// Objects in unpushed commits are always reachable from a local ref,
+	// but retain them explicitly, as prune does, so that nothing which is
+	// not on the remote can be deleted.
+	fetchPruneConfig := lfs.NewFetchPruneConfig(cfg.Git)
+	fetchPruneConfig.PruneForce = false
+
+	localObjects := make([]fs.Object, 0, 100)
+	retainedObjects := tools.NewStringSetWithCapacity(100)
+
+	logger := tasklog.NewLogger(OutputWriter,
+		tasklog.ForceProgress(cfg.ForceProgress()),
+	)
+	defer logger.Close()
+
+	var taskwait sync.WaitGroup
+	taskwait.Add(5) // 1..5: localObjects, reachable, unpushed, worktree, stashes
+
+	progressChan := make(PruneProgressChan, 100)
+
+	errorChan := make(chan error, 10)
+	var errorwait sync.WaitGroup
+	errorwait.Add(1)
+	var taskErrors []error
+	go pruneTaskCollectErrors(&taskErrors, errorChan, &errorwait)
+
+	go pruneTaskGetLocalObjects(&localObjects, progressChan, &taskwait)
+
+	retainChan := make(chan string, 100)
+
+	gitscanner := lfs.NewGitScanner(cfg, nil)
+	sem := semaphore.NewWeighted(int64(runtime.NumCPU() * 2))
+
+	go objectsGCTaskGetReachable(gitscanner, retainChan, errorChan, &taskwait)
+	go pruneTaskGetRetainedUnpushed(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
+	go pruneTaskGetRetainedWorktree(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
+	go pruneTaskGetRetainedStashed(gitscanner, retainChan, errorChan, &taskwait, sem)
+
+	var retainwait sync.WaitGroup
+	retainwait.Add(1)
+	go pruneTaskCollectRetained(&retainedObjects, retainChan, progressChan, &retainwait)
+
+	var progresswait sync.WaitGroup
+	progresswait.Add(1)
+	go pruneTaskDisplayProgress("gc", progressChan, &progresswait, logger)
+
+	taskwait.Wait()
+	close(retainChan)
+	retainwait.Wait()
+
+	close(errorChan)
+	errorwait.Wait()
+	pruneCheckErrors(taskErrors)
+
+	close(progressChan)
+	progresswait.Wait()
+
+	var unreachableObjects []string
+	var totalSize int64
+	var verboseOutput []string
+	for _, file := range localObjects {
+		if retainedObjects.Contains(file.Oid) {
+			continue
+		}
+
+		unreachableObjects = append(unreachableObjects, file.Oid)
+		totalSize += file.Size
+		if objectsGCVerboseArg || objectsGCDryRunArg {
+			verboseOutput = append(verboseOutput,
+				fmt.Sprintf("%s (%s)",
+					file.Oid,
+					humanize.FormatBytes(uint64(file.Size))))
+		}
+	}
+
+	if len(unreachableObjects) == 0 {
+		return
+	}
+
+	logVerboseOutput("gc", logger, verboseOutput, len(unreachableObjects), totalSize, objectsGCDryRunArg)
+
+	if !objectsGCDryRunArg {
+		pruneDeleteFiles("gc", unreachableObjects, logger)
+	}
+}
+
+// Background task, must call waitg.Done() once at end
+func objectsGCTaskGetReachable(gitscanner *lfs.GitScanner, retainChan chan string, errorChan chan error, waitg *sync.WaitGroup) {
+	defer waitg.Done()
+
+	err := gitscanner.ScanAll(func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		retainChan <- p.Oid
+		tracerx.Printf("RETAIN: %v reachable", p.Oid)
+	})
+
+	if err != nil {
+		errorChan <- err
+	}
+}
+
+// objectsRelayoutCommand moves the objects in the local cache to the
+// directories given by lfs.objectsharddepth.
+func objectsRelayoutCommand(cmd *cobra.Command, args []string) {
+	setupRepository()
+
+	moved, err := cfg.Filesystem().RelayoutObjects()
+	if moved > 0 {
+		Print(tr.Tr.GetN("Moved %d object", "Moved %d objects", moved, moved))
+	}
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Error moving objects")))
+	}
+}
+
+func init() {
+	RegisterCommand("objects", nil, func(cmd *cobra.Command) {
+		gc := NewCommand("gc", objectsGCCommand)
+		gc.Flags().BoolVarP(&objectsGCDryRunArg, "dry-run", "d", false, "Don't delete anything, just report")
+		gc.Flags().BoolVarP(&objectsGCVerboseArg, "verbose", "v", false, "Print full details of what is/would be deleted")
+
+		cmd.AddCommand(gc)
+		cmd.AddCommand(NewCommand("relayout", objectsRelayoutCommand))
+	})
+}
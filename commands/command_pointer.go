@@ -1,16 +1,18 @@
 package commands
 
 import (
+	"bufio"
 	"bytes"
-	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +24,8 @@ var (
 	pointerCheck    bool
 	pointerStrict   bool
 	pointerNoStrict bool
+	pointerOid      string
+	pointerSize     int64
 )
 
 func pointerCommand(cmd *cobra.Command, args []string) {
@@ -30,6 +34,25 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 	buildOid := ""
 	compareOid := ""
 
+	fromOid := len(pointerOid) > 0 || cmd.Flags().Changed("size")
+	if fromOid {
+		if pointerCheck {
+			ExitWithError(errors.New(tr.Tr.Get("Cannot combine --check with --oid or --size")))
+		}
+		if len(pointerFile) > 0 {
+			ExitWithError(errors.New(tr.Tr.Get("Cannot combine --file with --oid or --size")))
+		}
+		if len(pointerOid) == 0 || !cmd.Flags().Changed("size") {
+			ExitWithError(errors.New(tr.Tr.Get("--oid and --size must be given together")))
+		}
+		if !lfs.IsValidOid(pointerOid) {
+			ExitWithError(errors.New(tr.Tr.Get("Invalid OID: %s", pointerOid)))
+		}
+		if pointerSize < 0 {
+			ExitWithError(errors.New(tr.Tr.Get("Invalid size: %d", pointerSize)))
+		}
+	}
+
 	if pointerCheck {
 		var r io.ReadCloser
 		var err error
@@ -42,6 +65,16 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 			ExitWithError(errors.New(tr.Tr.Get("Cannot combine --check with --compare")))
 		}
 
+		if len(args) > 0 {
+			if len(pointerFile) > 0 || pointerStdin {
+				ExitWithError(errors.New(tr.Tr.Get("With --check, paths cannot be combined with --file or --stdin")))
+			}
+			if !pointerCheckPaths(pointerPaths(args)) {
+				os.Exit(1)
+			}
+			return
+		}
+
 		if len(pointerFile) > 0 {
 			if pointerStdin {
 				ExitWithError(errors.New(tr.Tr.Get("With --check, --file cannot be combined with --stdin")))
@@ -71,15 +104,23 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 		comparing = true
 	}
 
-	if len(pointerFile) > 0 {
-		something = true
+	var ptr *lfs.Pointer
+	var ptrName string
+	if fromOid {
+		ptr = lfs.NewPointer(pointerOid, pointerSize, nil)
+		ptrName = pointerOid
+	} else if len(pointerFile) > 0 {
 		buildFile, err := os.Open(pointerFile)
 		if err != nil {
 			Error(err.Error())
 			os.Exit(1)
 		}
 
-		oidHash := sha256.New()
+		oidHash, err := tools.NewLfsContentHashFor(cfg.HashAlgorithm())
+		if err != nil {
+			Error(err.Error())
+			os.Exit(1)
+		}
 		size, err := io.Copy(oidHash, buildFile)
 		buildFile.Close()
 
@@ -88,12 +129,19 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 
-		ptr := lfs.NewPointer(hex.EncodeToString(oidHash.Sum(nil)), size, nil)
-		fmt.Fprint(os.Stderr, tr.Tr.Get("Git LFS pointer for %s", pointerFile), "\n\n")
+		ptr = lfs.NewPointer(hex.EncodeToString(oidHash.Sum(nil)), size, nil)
+		ptrName = pointerFile
+	}
+
+	if ptr != nil {
+		something = true
+		ptr.OidType = cfg.HashAlgorithm()
+		fmt.Fprint(os.Stderr, tr.Tr.Get("Git LFS pointer for %s", ptrName), "\n\n")
 		buf := &bytes.Buffer{}
 		lfs.EncodePointer(io.MultiWriter(os.Stdout, buf), ptr)
 
 		if comparing {
+			var err error
 			buildOid, err = git.HashObject(bytes.NewReader(buf.Bytes()))
 			if err != nil {
 				Error(err.Error())
@@ -151,6 +199,84 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 	}
 }
 
+// pointerPaths returns the paths to check given as arguments, or, if the only
+// argument is "-", the paths read from standard input, one per line.
+func pointerPaths(args []string) []string {
+	if len(args) != 1 || args[0] != "-" {
+		return args
+	}
+
+	requireStdin(tr.Tr.Get("The - argument expects a list of paths from STDIN."))
+
+	var paths []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if path := strings.TrimSuffix(scanner.Text(), "\r"); len(path) > 0 {
+			paths = append(paths, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		ExitWithError(errors.New(tr.Tr.Get("Error reading paths from STDIN: %s", err)))
+	}
+	return paths
+}
+
+// pointerCheckPaths reports whether each of the given files is a valid Git LFS
+// pointer, the contents of a Git LFS object in the local object store, or
+// neither, and returns false if any of them is inconsistent: that is, if it is
+// neither, if it is a pointer whose size does not match that of the object it
+// references in the local object store, or, with --strict, if it is a
+// non-canonical pointer.
+func pointerCheckPaths(paths []string) bool {
+	ok := true
+	for _, path := range paths {
+		status, consistent := pointerCheckPath(path)
+		Print("%s: %s", path, status)
+		ok = ok && consistent
+	}
+	return ok
+}
+
+func pointerCheckPath(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err.Error(), false
+	}
+	defer f.Close()
+
+	p, contents, err := lfs.DecodeFrom(f)
+	if err == nil {
+		if pointerStrict && !p.Canonical {
+			return tr.Tr.Get("non-canonical pointer"), false
+		}
+		if !cfg.InRepo() || p.Size == 0 {
+			return tr.Tr.Get("pointer"), true
+		}
+
+		stat, err := os.Stat(cfg.Filesystem().ObjectPathname(p.Oid))
+		if err != nil {
+			return tr.Tr.Get("pointer, object not present locally"), true
+		}
+		if stat.Size() != p.Size {
+			return tr.Tr.Get("pointer, size mismatch: pointer has size %d but object has size %d", p.Size, stat.Size()), false
+		}
+		return tr.Tr.Get("pointer, object present locally"), true
+	}
+	oidHash, err := tools.NewLfsContentHashFor(cfg.HashAlgorithm())
+	if err != nil {
+		return err.Error(), false
+	}
+	size, err := io.Copy(oidHash, contents)
+	if err != nil {
+		return err.Error(), false
+	}
+
+	if cfg.InRepo() && cfg.LFSObjectExists(hex.EncodeToString(oidHash.Sum(nil)), size) {
+		return tr.Tr.Get("Git LFS object"), true
+	}
+	return tr.Tr.Get("neither a pointer nor a Git LFS object"), false
+}
+
 func pointerReader() (io.ReadCloser, error) {
 	if len(pointerCompare) > 0 {
 		if pointerStdin {
@@ -173,5 +299,7 @@ func init() {
 		cmd.Flags().BoolVarP(&pointerCheck, "check", "", false, "Check whether the given file is a Git LFS pointer.")
 		cmd.Flags().BoolVarP(&pointerStrict, "strict", "", false, "Check whether the given Git LFS pointer is canonical.")
 		cmd.Flags().BoolVarP(&pointerNoStrict, "no-strict", "", false, "Don't check whether the given Git LFS pointer is canonical.")
+		cmd.Flags().StringVarP(&pointerOid, "oid", "", "", "OID of an object to generate the pointer from, with --size.")
+		cmd.Flags().Int64VarP(&pointerSize, "size", "", 0, "Size of an object to generate the pointer from, with --oid.")
 	})
 }
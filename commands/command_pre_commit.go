@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"encoding/hex"
+	"os"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+	"github.com/spf13/cobra"
+)
+
+// largeFile is a file staged for commit which is not a Git LFS pointer and is
+// larger than the size given by lfs.largefilewarning.
+type largeFile struct {
+	path string
+	size int64
+}
+
+// preCommitCommand is run through Git's pre-commit hook, which passes no
+// arguments.
+//
+// It warns about files staged for commit which are not Git LFS pointers and
+// are larger than the size given by lfs.largefilewarning, which are usually
+// files that were meant to be tracked by Git LFS, and refuses the commit if
+// lfs.largefilerefuse is set.
+func preCommitCommand(cmd *cobra.Command, args []string) {
+	threshold, err := cfg.LargeFileWarningSize()
+	if err != nil {
+		Exit(err.Error())
+	}
+	if threshold == 0 {
+		return
+	}
+
+	requireGitVersion()
+	setupWorkingCopy()
+
+	tracerx.Printf("pre-commit: checking for files larger than %d bytes", threshold)
+	files, err := preCommitLargeFiles(int64(threshold))
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not check staged files")))
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	refuse := cfg.Git.Bool("lfs.largefilerefuse", false)
+	if refuse {
+		Error(tr.Tr.GetN(
+			"Refusing to commit %d file larger than %s which is not tracked by Git LFS:",
+			"Refusing to commit %d files larger than %s which are not tracked by Git LFS:",
+			len(files), len(files), humanize.FormatBytes(threshold)))
+	} else {
+		Error(tr.Tr.GetN(
+			"Warning: committing %d file larger than %s which is not tracked by Git LFS:",
+			"Warning: committing %d files larger than %s which are not tracked by Git LFS:",
+			len(files), len(files), humanize.FormatBytes(threshold)))
+	}
+	for _, f := range files {
+		Error("\t%s (%s)", f.path, humanize.FormatBytes(uint64(f.size)))
+	}
+	Error("\n" + tr.Tr.Get("Use `git lfs track` to store such files in Git LFS, and `git rm --cached` to unstage them."))
+
+	if refuse {
+		os.Exit(1)
+	}
+}
+
+// preCommitLargeFiles returns the files which differ between HEAD and the
+// index whose contents in the index are larger than the given threshold and
+// are not Git LFS pointers.
+func preCommitLargeFiles(threshold int64) ([]largeFile, error) {
+	// tolerate errors getting ref so this works before first commit
+	ref, _ := git.CurrentRef()
+
+	scanAt := "HEAD"
+	if ref == nil {
+		var err error
+		scanAt, err = git.EmptyTree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scanner, err := lfs.NewDiffIndexScanner(scanAt, true, false, cfg.LocalWorkingDir())
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := getObjectDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var files []largeFile
+	for scanner.Scan() {
+		entry := scanner.Entry()
+		if entry.Status == lfs.StatusDeletion || entry.Status == lfs.StatusUnmerged {
+			continue
+		}
+		// Skip submodules, whose entries name commits, not blobs.
+		if entry.DstMode == "160000" {
+			continue
+		}
+
+		// The destination name is only set for copies and renames.
+		path := entry.DstName
+		if len(path) == 0 {
+			path = entry.SrcName
+		}
+
+		oid, err := hex.DecodeString(entry.DstSha)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := db.Blob(oid)
+		if err != nil {
+			return nil, errors.Wrap(err, tr.Tr.Get("Could not read %s", path))
+		}
+		large := blob.Size > threshold
+		if large {
+			if _, err := lfs.DecodePointerFromBlob(blob); err == nil {
+				large = false
+			}
+		}
+		blob.Close()
+
+		if large {
+			files = append(files, largeFile{path: path, size: blob.Size})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func init() {
+	RegisterCommand("pre-commit", preCommitCommand, nil)
+}
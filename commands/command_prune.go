@@ -19,6 +19,7 @@ import (
 	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/git-lfs/git-lfs/v3/tq"
 	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/git-lfs/wildmatch/v2"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/semaphore"
@@ -28,12 +29,15 @@ var (
 	pruneDryRunArg                 bool
 	pruneVerboseArg                bool
 	pruneVerifyArg                 bool
+	pruneVerifyRemoteOnlyArg       bool
 	pruneRecentArg                 bool
 	pruneForceArg                  bool
 	pruneDoNotVerifyArg            bool
 	pruneVerifyUnreachableArg      bool
 	pruneDoNotVerifyUnreachableArg bool
 	pruneWhenUnverifiedArg         string
+	pruneOlderThanArg              string
+	pruneExcludeRefsArg            []string
 )
 
 func pruneCommand(cmd *cobra.Command, args []string) {
@@ -42,9 +46,13 @@ func pruneCommand(cmd *cobra.Command, args []string) {
 		Exit(tr.Tr.Get("Cannot specify both --verify-remote and --no-verify-remote"))
 	}
 
+	if pruneVerifyRemoteOnlyArg && pruneDoNotVerifyArg {
+		Exit(tr.Tr.Get("Cannot specify both --verify-remote-only and --no-verify-remote"))
+	}
+
 	fetchPruneConfig := lfs.NewFetchPruneConfig(cfg.Git)
-	verify := !pruneDoNotVerifyArg &&
-		(fetchPruneConfig.PruneVerifyRemoteAlways || pruneVerifyArg)
+	verify := pruneVerifyRemoteOnlyArg || (!pruneDoNotVerifyArg &&
+		(fetchPruneConfig.PruneVerifyRemoteAlways || pruneVerifyArg))
 	verifyUnreachable := !pruneDoNotVerifyUnreachableArg && (pruneVerifyUnreachableArg || fetchPruneConfig.PruneVerifyUnreachableAlways)
 
 	continueWhenUnverified := false
@@ -57,9 +65,33 @@ func pruneCommand(cmd *cobra.Command, args []string) {
 		Exit(tr.Tr.Get("Invalid value for --when-unverified: %s", pruneWhenUnverifiedArg))
 	}
 
+	// When only verifying, report any objects missing on the remote and
+	// never delete anything.
+	dryRun := pruneDryRunArg || pruneVerifyRemoteOnlyArg
+	if pruneVerifyRemoteOnlyArg {
+		continueWhenUnverified = false
+	}
+
+	verbose := pruneVerboseArg
+	if len(pruneOlderThanArg) > 0 {
+		if pruneRecentArg {
+			Exit(tr.Tr.Get("Cannot specify both --older-than and --recent"))
+		}
+
+		d, err := tools.ParseDuration(pruneOlderThanArg)
+		if err != nil || d <= 0 {
+			Exit(tr.Tr.Get("Invalid value for --older-than: %s", pruneOlderThanArg))
+		}
+		fetchPruneConfig.PruneOlderThan = time.Now().Add(-d)
+
+		// List exactly which objects would be deleted.
+		verbose = verbose || dryRun
+	}
+
 	fetchPruneConfig.PruneRecent = pruneRecentArg || pruneForceArg
 	fetchPruneConfig.PruneForce = pruneForceArg
-	prune(fetchPruneConfig, verify, verifyUnreachable, continueWhenUnverified, pruneDryRunArg, pruneVerboseArg)
+	fetchPruneConfig.PruneExcludeRefs = pruneExcludeRefsArg
+	prune(fetchPruneConfig, verify, verifyUnreachable, continueWhenUnverified, dryRun, verbose)
 }
 
 type PruneProgressType int
@@ -93,9 +125,9 @@ func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, verifyUnreachabl
 	// Add all the base funcs to the waitgroup before starting them, in case
 	// one completes really fast & hits 0 unexpectedly
 	// each main process can Add() to the wg itself if it subdivides the task
-	taskwait.Add(5) // 1..5: localObjects, current & recent refs, unpushed, worktree, stashes
+	taskwait.Add(6) // 1..6: localObjects, current & recent refs, excluded refs, unpushed, worktree, stashes
 	if verifyRemote && !verifyUnreachable {
-		taskwait.Add(1) // 6
+		taskwait.Add(1) // 7
 	}
 
 	progressChan := make(PruneProgressChan, 100)
@@ -119,6 +151,7 @@ func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, verifyUnreachabl
 	sem := semaphore.NewWeighted(int64(runtime.NumCPU() * 2))
 
 	go pruneTaskGetRetainedCurrentAndRecentRefs(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
+	go pruneTaskGetRetainedExcludedRefs(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
 	go pruneTaskGetRetainedUnpushed(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
 	go pruneTaskGetRetainedWorktree(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
 	go pruneTaskGetRetainedStashed(gitscanner, retainChan, errorChan, &taskwait, sem)
@@ -135,7 +168,7 @@ func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, verifyUnreachabl
 	// Report progress
 	var progresswait sync.WaitGroup
 	progresswait.Add(1)
-	go pruneTaskDisplayProgress(progressChan, &progresswait, logger)
+	go pruneTaskDisplayProgress("prune", progressChan, &progresswait, logger)
 
 	taskwait.Wait()   // wait for subtasks
 	close(retainChan) // triggers retain collector to end now all tasks have
@@ -221,19 +254,19 @@ func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, verifyUnreachabl
 		return
 	}
 
-	logVerboseOutput(logger, verboseOutput, len(prunableObjects), totalSize, dryRun)
+	logVerboseOutput("prune", logger, verboseOutput, len(prunableObjects), totalSize, dryRun)
 
 	if !dryRun {
-		pruneDeleteFiles(prunableObjects, logger)
+		pruneDeleteFiles("prune", prunableObjects, logger)
 	}
 }
 
-func logVerboseOutput(logger *tasklog.Logger, verboseOutput []string, numPrunableObjects int, totalSize int64, dryRun bool) {
+func logVerboseOutput(name string, logger *tasklog.Logger, verboseOutput []string, numPrunableObjects int, totalSize int64, dryRun bool) {
 	info := logger.Simple()
 	defer info.Complete()
 
 	if dryRun {
-		info.Logf("prune: %s", tr.Tr.GetN(
+		info.Logf("%s: %s", name, tr.Tr.GetN(
 			"%d file would be pruned (%s)",
 			"%d files would be pruned (%s)",
 			numPrunableObjects,
@@ -285,7 +318,7 @@ func pruneCheckErrors(taskErrors []error) {
 	}
 }
 
-func pruneTaskDisplayProgress(progressChan PruneProgressChan, waitg *sync.WaitGroup, logger *tasklog.Logger) {
+func pruneTaskDisplayProgress(name string, progressChan PruneProgressChan, waitg *sync.WaitGroup, logger *tasklog.Logger) {
 	defer waitg.Done()
 
 	task := logger.Simple()
@@ -307,7 +340,7 @@ func pruneTaskDisplayProgress(progressChan PruneProgressChan, waitg *sync.WaitGr
 		case PruneProgressTypeUnverified:
 			notRemoteCount += p.Count
 		}
-		msg = fmt.Sprintf("prune: %s, %s",
+		msg = fmt.Sprintf("%s: %s, %s", name,
 			tr.Tr.GetN("%d local object", "%d local objects", localCount, localCount),
 			tr.Tr.GetN("%d retained", "%d retained", retainCount, retainCount))
 		if verifyCount > 0 {
@@ -341,8 +374,8 @@ func pruneTaskCollectErrors(outtaskErrors *[]error, errorChan chan error, errorw
 	}
 }
 
-func pruneDeleteFiles(prunableObjects []string, logger *tasklog.Logger) {
-	task := logger.Percentage(fmt.Sprintf("prune: %s", tr.Tr.Get("Deleting objects")), uint64(len(prunableObjects)))
+func pruneDeleteFiles(name string, prunableObjects []string, logger *tasklog.Logger) {
+	task := logger.Percentage(fmt.Sprintf("%s: %s", name, tr.Tr.Get("Deleting objects")), uint64(len(prunableObjects)))
 	defer task.Complete()
 
 	var problems bytes.Buffer
@@ -446,6 +479,11 @@ func pruneTaskGetRetainedCurrentAndRecentRefs(gitscanner *lfs.GitScanner, fetchc
 		go pruneTaskGetRetainedAtRef(gitscanner, ref.Sha, retainChan, errorChan, waitg, sem)
 	}
 
+	if since := fetchconf.PruneOlderThan; !since.IsZero() {
+		pruneTaskGetRetainedSince(gitscanner, fetchconf, since, commits, retainChan, errorChan, waitg, sem)
+		return
+	}
+
 	// Now recent
 	if !fetchconf.PruneRecent && fetchconf.FetchRecentRefsDays > 0 {
 		pruneRefDays := fetchconf.FetchRecentRefsDays + fetchconf.PruneOffsetDays
@@ -483,10 +521,114 @@ func pruneTaskGetRetainedCurrentAndRecentRefs(gitscanner *lfs.GitScanner, fetchc
 	}
 }
 
+// pruneTaskGetRetainedExcludedRefs retains all objects reachable from any ref
+// whose full name matches one of the --exclude-ref patterns, regardless of the
+// recent and --older-than options.
+//
+// Background task, must call waitg.Done() once at end
+func pruneTaskGetRetainedExcludedRefs(gitscanner *lfs.GitScanner, fetchconf lfs.FetchPruneConfig, retainChan chan string, errorChan chan error, waitg *sync.WaitGroup, sem *semaphore.Weighted) {
+	defer waitg.Done()
+
+	if len(fetchconf.PruneExcludeRefs) == 0 {
+		return
+	}
+
+	refs, err := git.AllRefs()
+	if err != nil {
+		errorChan <- err
+		return
+	}
+
+	commits := tools.NewStringSet()
+	for _, ref := range refs {
+		name := ref.Refspec()
+		for _, pattern := range fetchconf.PruneExcludeRefs {
+			if !wildmatch.NewWildmatch(pattern).Match(name) {
+				continue
+			}
+
+			tracerx.Printf("PRUNE: Retaining objects reachable from %s", name)
+			if commits.Add(ref.Sha) {
+				waitg.Add(1)
+				go pruneTaskGetRetainedReachableFromRef(gitscanner, ref.Sha, retainChan, errorChan, waitg, sem)
+			}
+			break
+		}
+	}
+}
+
+// Background task, must call waitg.Done() once at end
+func pruneTaskGetRetainedReachableFromRef(gitscanner *lfs.GitScanner, ref string, retainChan chan string, errorChan chan error, waitg *sync.WaitGroup, sem *semaphore.Weighted) {
+	sem.Acquire(context.Background(), 1)
+	defer sem.Release(1)
+	defer waitg.Done()
+
+	err := gitscanner.ScanRefWithDeleted(ref, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			errorChan <- err
+			return
+		}
+
+		retainChan <- p.Oid
+		tracerx.Printf("RETAIN: %v reachable from ref %v", p.Oid, ref)
+	})
+
+	if err != nil {
+		errorChan <- err
+	}
+}
+
+// pruneTaskGetRetainedSince retains objects referenced by any commit made at
+// or after "since" on the current ref (whose SHA is already in "commits") or on
+// any branch updated since then, including objects that such a commit replaced.
+// It increments waitg for each sub-goroutine it starts.
+func pruneTaskGetRetainedSince(gitscanner *lfs.GitScanner, fetchconf lfs.FetchPruneConfig, since time.Time, commits tools.StringSet, retainChan chan string, errorChan chan error, waitg *sync.WaitGroup, sem *semaphore.Weighted) {
+	tracerx.Printf("PRUNE: Retaining objects referenced by commits since %v", since)
+
+	if fetchconf.PruneForce {
+		// The current checkout is not otherwise retained with
+		// --force, but is still referenced by a recent commit.
+		for head := range commits.Iter() {
+			summ, err := git.GetCommitSummary(head)
+			if err != nil {
+				errorChan <- errors.New(tr.Tr.Get("couldn't scan commits at %v: %v", head, err))
+				return
+			}
+			if !summ.CommitDate.Before(since) {
+				waitg.Add(1)
+				go pruneTaskGetRetainedAtRef(gitscanner, head, retainChan, errorChan, waitg, sem)
+			}
+		}
+	}
+
+	refs, err := git.RecentBranches(since, fetchconf.FetchRecentRefsIncludeRemotes, "")
+	if err != nil {
+		Panic(err, tr.Tr.Get("Could not scan for recent refs"))
+	}
+	for _, ref := range refs {
+		if commits.Add(ref.Sha) {
+			waitg.Add(1)
+			go pruneTaskGetRetainedAtRef(gitscanner, ref.Sha, retainChan, errorChan, waitg, sem)
+		}
+	}
+
+	for commit := range commits.Iter() {
+		waitg.Add(1)
+		go pruneTaskGetPreviousVersionsOfRef(gitscanner, commit, since, retainChan, errorChan, waitg, sem)
+	}
+}
+
 // Background task, must call waitg.Done() once at end
 func pruneTaskGetRetainedUnpushed(gitscanner *lfs.GitScanner, fetchconf lfs.FetchPruneConfig, retainChan chan string, errorChan chan error, waitg *sync.WaitGroup, sem *semaphore.Weighted) {
 	defer waitg.Done()
 
+	if fetchconf.PruneForce && !fetchconf.PruneOlderThan.IsZero() {
+		// Objects in unpushed commits made since the cutoff are
+		// retained anyway, so only older ones will be pruned.
+		tracerx.Printf("PRUNE: Not retaining unpushed objects older than %v", fetchconf.PruneOlderThan)
+		return
+	}
+
 	err := gitscanner.ScanUnpushed(fetchconf.PruneRemoteName, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
 			errorChan <- err
@@ -607,9 +749,12 @@ func init() {
 		cmd.Flags().BoolVarP(&pruneRecentArg, "recent", "", false, "Prune even recent objects")
 		cmd.Flags().BoolVarP(&pruneForceArg, "force", "f", false, "Prune everything that has been pushed")
 		cmd.Flags().BoolVarP(&pruneVerifyArg, "verify-remote", "c", false, "Verify that remote has reachable LFS files before deleting")
+		cmd.Flags().BoolVar(&pruneVerifyRemoteOnlyArg, "verify-remote-only", false, "Verify that remote has LFS files which would be pruned, without deleting anything")
 		cmd.Flags().BoolVar(&pruneDoNotVerifyArg, "no-verify-remote", false, "Override lfs.pruneverifyremotealways and don't verify")
 		cmd.Flags().BoolVar(&pruneVerifyUnreachableArg, "verify-unreachable", false, "When using --verify-remote, additionally verify unreachable LFS files before deleting.")
 		cmd.Flags().BoolVar(&pruneDoNotVerifyUnreachableArg, "no-verify-unreachable", false, "Override lfs.pruneverifyunreachablealways and don't verify unreachable objects")
 		cmd.Flags().StringVar(&pruneWhenUnverifiedArg, "when-unverified", "halt", "halt|continue the execution when objects are not found on the remote")
+		cmd.Flags().StringVar(&pruneOlderThanArg, "older-than", "", "Prune objects not referenced by any commit within the given duration")
+		cmd.Flags().StringSliceVar(&pruneExcludeRefsArg, "exclude-ref", nil, "Retain all objects reachable from refs matching this pattern")
 	})
 }
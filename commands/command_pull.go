@@ -1,11 +1,16 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfs"
@@ -16,18 +21,45 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	pullStdinArg  bool
+	pullStrictArg bool
+
+	// pullRemotesArg are the remotes given with --remote, from which each
+	// object is fetched from the first which has it.
+	pullRemotesArg []string
+	pullRemotes    []string
+)
+
 func pullCommand(cmd *cobra.Command, args []string) {
 	requireGitVersion()
 	setupRepository()
 
-	if len(args) > 0 {
+	if len(pullRemotesArg) > 0 {
+		if len(args) > 0 {
+			Exit(tr.Tr.Get("Cannot combine --remote with a remote argument"))
+		}
+		pullRemotes = setValidRemotes(pullRemotesArg)
+	} else if len(args) > 0 {
 		// Remote is first arg
 		if err := cfg.SetValidRemote(args[0]); err != nil {
 			Exit(tr.Tr.Get("Invalid remote name %q: %s", args[0], err))
 		}
 	}
 
+	if pullStrictArg && !pullStdinArg {
+		Exit(tr.Tr.Get("Cannot use --strict without --stdin"))
+	}
+
 	includeArg, excludeArg := getIncludeExcludeArgs(cmd)
+	if pullStdinArg {
+		if includeArg != nil || excludeArg != nil {
+			Exit(tr.Tr.Get("Cannot combine --stdin with --include or --exclude"))
+		}
+		pullObjectIDs(readPullObjects(os.Stdin))
+		return
+	}
+
 	filter := buildFilepathFilter(cfg, includeArg, excludeArg, true)
 	pull(filter)
 }
@@ -44,6 +76,7 @@ func pull(filter *filepathfilter.Filter) {
 	)
 	meter := tq.NewMeter(cfg)
 	meter.Logger = meter.LoggerFromEnv(cfg.Os)
+	meter.EventLogger = progressEventLogger(meter)
 	logger.Enqueue(meter)
 	remote := cfg.Remote()
 	singleCheckout := newSingleCheckout(cfg.Git, remote)
@@ -95,20 +128,210 @@ func pull(filter *filepathfilter.Filter) {
 	meter.Start()
 	q.Wait()
 	wg.Wait()
+	if len(pullRemotes) > 1 {
+		q = fetchFromFallbackRemotes(q, pullRemotes[1:], pointers, meter, func(plist []*lfs.WrappedPointer) {
+			for _, p := range plist {
+				singleCheckout.Run(p)
+			}
+		})
+	}
 	tracerx.PerformanceSince("process queue", processQueue)
 
 	singleCheckout.Close()
 
-	success := true
+	var errs []error
 	for _, err := range q.Errors() {
-		success = false
+		errs = append(errs, err)
 		FullError(err)
 	}
 
-	if !success {
-		c := getAPIClient()
-		e := c.Endpoints.Endpoint("download", remote)
-		Exit(tr.Tr.Get("Failed to fetch some objects from '%s'", e.Url))
+	if len(errs) > 0 {
+		ExitWithErrors(errs, tr.Tr.Get("Failed to fetch some objects from '%s'", describeRemotes(remote, pullRemotes)))
+	}
+
+	if singleCheckout.Skip() {
+		fmt.Println(tr.Tr.Get("Skipping object checkout, Git LFS is not installed for this repository.\nConsider installing it with 'git lfs install'."))
+	}
+}
+
+// readPullObjects reads the objects given to "git lfs pull --stdin", one per
+// line, each of which is an OID optionally followed by the size of the object
+// and then by the path of a file in the working tree, relative to the root of
+// the repository, into which the object should be checked out.
+//
+// An object given without a size takes its size from the pointer file at its
+// path, if it is one for the same object, or else from the local copy of the
+// object.  Objects whose size can not be determined are reported and skipped,
+// or with --strict, are an error.
+func readPullObjects(r *os.File) []*lfs.WrappedPointer {
+	var pointers []*lfs.WrappedPointer
+	var unknown []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		oid, rest, _ := strings.Cut(line, " ")
+		oid = strings.ToLower(oid)
+		if !lfs.IsValidOid(oid) {
+			Exit(tr.Tr.Get("Invalid object ID: %q", oid))
+		}
+
+		size := int64(-1)
+		rest = strings.TrimSpace(rest)
+		field, name, _ := strings.Cut(rest, " ")
+		if n, err := strconv.ParseInt(field, 10, 64); err == nil && n >= 0 {
+			size = n
+			rest = strings.TrimSpace(name)
+		}
+
+		p, ok := pullObjectPointer(oid, size, rest)
+		if !ok {
+			unknown = append(unknown, oid)
+			continue
+		}
+		pointers = append(pointers, &lfs.WrappedPointer{
+			Name:    rest,
+			Pointer: p,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Error reading from stdin:")))
+	}
+
+	for _, oid := range unknown {
+		Error(tr.Tr.Get("Unable to determine the size of object %s, skipping", oid))
+	}
+	if len(unknown) > 0 && pullStrictArg {
+		Exit(tr.Tr.GetN(
+			"%d object has an unknown size",
+			"%d objects have an unknown size",
+			len(unknown),
+			len(unknown),
+		))
+	}
+
+	return pointers
+}
+
+// pullObjectPointer returns a pointer for the object with the given OID and
+// size.  If the size is negative, it is taken from the pointer file at the
+// given path, if it is one for the same object, or else from the local copy
+// of the object, and false is returned if neither exists.
+func pullObjectPointer(oid string, size int64, name string) (*lfs.Pointer, bool) {
+	if size >= 0 {
+		return lfs.NewPointer(oid, size, nil), true
+	}
+	if len(name) > 0 {
+		path := filepath.Join(cfg.LocalWorkingDir(), name)
+		if p, err := lfs.DecodePointerFromFile(path); err == nil && p.Oid == oid {
+			return p, true
+		}
+	}
+	if path, err := cfg.Filesystem().ObjectPath(oid); err == nil {
+		if stat, err := os.Stat(path); err == nil {
+			return lfs.NewPointer(oid, stat.Size(), nil), true
+		}
+	}
+	return nil, false
+}
+
+// pullObjectIDs downloads exactly the given objects into the local cache, and
+// checks out each one with a path into the working tree. Objects which do not
+// exist on the server are reported, but are only an error with --strict.
+func pullObjectIDs(pointers []*lfs.WrappedPointer) {
+	logger := tasklog.NewLogger(os.Stdout,
+		tasklog.ForceProgress(cfg.ForceProgress()),
+	)
+	meter := buildProgressMeter(false, tq.Download)
+	logger.Enqueue(meter)
+	remote := cfg.Remote()
+	singleCheckout := newSingleCheckout(cfg.Git, remote)
+	q := newDownloadQueue(singleCheckout.Manifest(), remote, tq.WithProgress(meter))
+
+	pending := newPointerMap()
+	checkout := func(p *lfs.WrappedPointer) {
+		if len(p.Name) == 0 {
+			return
+		}
+		singleCheckout.Run(p)
+	}
+
+	dlwatch := q.Watch()
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		for t := range dlwatch {
+			for _, p := range pending.All(t.Oid) {
+				checkout(p)
+			}
+		}
+		wg.Done()
+	}()
+
+	processQueue := time.Now()
+	for _, p := range pointers {
+		if pending.Seen(p) {
+			continue
+		}
+
+		if path, err := cfg.Filesystem().ObjectPath(p.Oid); err == nil {
+			if stat, err := os.Stat(path); err == nil && stat.Size() == p.Size {
+				checkout(p)
+				continue
+			}
+		}
+
+		meter.Add(p.Size)
+		tracerx.Printf("fetch %v [%v]", p.Name, p.Oid)
+		pending.Add(p)
+		q.Add(downloadTransfer(p))
+	}
+
+	meter.Start()
+	q.Wait()
+	wg.Wait()
+	if len(pullRemotes) > 1 {
+		q = fetchFromFallbackRemotes(q, pullRemotes[1:], pending, meter, func(plist []*lfs.WrappedPointer) {
+			for _, p := range plist {
+				checkout(p)
+			}
+		})
+	}
+	tracerx.PerformanceSince("process queue", processQueue)
+
+	singleCheckout.Close()
+
+	var errs []error
+	var unknown int
+	for _, err := range q.Errors() {
+		if objErr, ok := errors.Cause(err).(*tq.ObjectError); ok && objErr.Code == 404 {
+			unknown++
+			if !pullStrictArg {
+				Error(err.Error())
+				continue
+			}
+		}
+
+		errs = append(errs, err)
+		FullError(err)
+	}
+
+	if len(errs) > 0 {
+		ExitWithErrors(errs, tr.Tr.Get("Failed to fetch some objects from '%s'", describeRemotes(remote, pullRemotes)))
+	}
+
+	if unknown > 0 {
+		Error(tr.Tr.GetN(
+			"%d object was not found on the server",
+			"%d objects were not found on the server",
+			unknown,
+			unknown,
+		))
 	}
 
 	if singleCheckout.Skip() {
@@ -119,7 +342,9 @@ func pull(filter *filepathfilter.Filter) {
 // tracks LFS objects being downloaded, according to their unique OIDs.
 type pointerMap struct {
 	pointers map[string][]*lfs.WrappedPointer
-	mu       sync.Mutex
+	// oids are the OIDs of the objects in the order they were added.
+	oids []string
+	mu   sync.Mutex
 }
 
 func newPointerMap() *pointerMap {
@@ -139,6 +364,9 @@ func (m *pointerMap) Seen(p *lfs.WrappedPointer) bool {
 func (m *pointerMap) Add(p *lfs.WrappedPointer) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if _, ok := m.pointers[p.Oid]; !ok {
+		m.oids = append(m.oids, p.Oid)
+	}
 	m.pointers[p.Oid] = append(m.pointers[p.Oid], p)
 }
 
@@ -150,9 +378,30 @@ func (m *pointerMap) All(oid string) []*lfs.WrappedPointer {
 	return pointers
 }
 
+// Remaining returns the first pointer for each object which has not yet been
+// removed by All, in the order in which the objects were added.
+func (m *pointerMap) Remaining() []*lfs.WrappedPointer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var pointers []*lfs.WrappedPointer
+	seen := make(map[string]bool, len(m.pointers))
+	for _, oid := range m.oids {
+		if plist, ok := m.pointers[oid]; ok && !seen[oid] {
+			pointers = append(pointers, plist[0])
+			seen[oid] = true
+		}
+	}
+	return pointers
+}
+
 func init() {
 	RegisterCommand("pull", pullCommand, func(cmd *cobra.Command) {
 		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths")
 		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths")
+		cmd.Flags().IntVar(&progressFdArg, "progress-fd", -1, "Write JSON progress events to this file descriptor")
+		cmd.Flags().BoolVar(&noCacheArg, "no-cache", false, "Don't use the cached transfer adapter chosen by the server")
+		cmd.Flags().BoolVar(&pullStdinArg, "stdin", false, "Read the object IDs to pull from stdin")
+		cmd.Flags().BoolVar(&pullStrictArg, "strict", false, "With --stdin, fail if any object is not found on the server")
+		cmd.Flags().StringSliceVar(&pullRemotesArg, "remote", nil, "Fetch each object from the first of these remotes which has it")
 	})
 }
@@ -101,26 +101,45 @@ func uploadsBetweenRefAndRemote(ctx *uploadContext, refnames []string) {
 	}
 }
 
+// uploadsWithObjectIDs uploads the given objects from the local cache.  Every
+// object is looked up before any are uploaded, so that all of the missing ones
+// can be reported at once and nothing is pushed if any are missing.
 func uploadsWithObjectIDs(ctx *uploadContext, oids []string) {
-	pointers := make([]*lfs.WrappedPointer, len(oids))
-	for i, oid := range oids {
+	pointers := make([]*lfs.WrappedPointer, 0, len(oids))
+	var missing []string
+	for _, oid := range oids {
 		mp, err := ctx.gitfilter.ObjectPath(oid)
 		if err != nil {
 			ExitWithError(errors.Wrap(err, tr.Tr.Get("Unable to find local media path:")))
 		}
 
 		stat, err := os.Stat(mp)
-		if err != nil {
+		if os.IsNotExist(err) {
+			missing = append(missing, oid)
+			continue
+		} else if err != nil {
 			ExitWithError(errors.Wrap(err, tr.Tr.Get("Unable to stat local media path")))
 		}
 
-		pointers[i] = &lfs.WrappedPointer{
+		pointers = append(pointers, &lfs.WrappedPointer{
 			Name: mp,
 			Pointer: &lfs.Pointer{
 				Oid:  oid,
 				Size: stat.Size(),
 			},
+		})
+	}
+
+	if len(missing) > 0 {
+		for _, oid := range missing {
+			Error(tr.Tr.Get("Object %s not found in local storage", oid))
 		}
+		Exit(tr.Tr.GetN(
+			"Unable to push %d missing object",
+			"Unable to push %d missing objects",
+			len(missing),
+			len(missing),
+		))
 	}
 
 	q := ctx.NewQueue(tq.RemoteRef(currentRemoteRef()))
@@ -173,5 +192,7 @@ func init() {
 		cmd.Flags().BoolVarP(&pushObjectIDs, "object-id", "o", false, "Push LFS object ID(s)")
 		cmd.Flags().BoolVarP(&useStdin, "stdin", "", false, "Read object IDs or refs from stdin")
 		cmd.Flags().BoolVarP(&pushAll, "all", "a", false, "Push all objects for the current ref to the remote.")
+		cmd.Flags().IntVar(&progressFdArg, "progress-fd", -1, "Write JSON progress events to this file descriptor")
+		cmd.Flags().BoolVar(&noCacheArg, "no-cache", false, "Don't use the cached transfer adapter chosen by the server")
 	})
 }
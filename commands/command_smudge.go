@@ -20,6 +20,11 @@ var (
 	// smudgeSkip is a command-line flag belonging to the "git-lfs smudge"
 	// command specifying whether to skip the smudge process.
 	smudgeSkip = false
+
+	// smudgeSkipDownload is a command-line flag belonging to the
+	// "git-lfs smudge" command specifying whether to write the pointer
+	// through unchanged, after checking that it is valid.
+	smudgeSkipDownload = false
 )
 
 // delayedSmudge performs a 'delayed' smudge, adding the LFS pointer to the
@@ -31,6 +36,9 @@ var (
 // delayed, the *lfs.Pointer that was smudged, and an error, if one occurred.
 func delayedSmudge(gf *lfs.GitFilter, s *git.FilterProcessScanner, to io.Writer, from io.Reader, q *tq.TransferQueue, filename string, skip bool, filter *filepathfilter.Filter) (int64, bool, *lfs.Pointer, error) {
 	ptr, pbuf, perr := lfs.DecodeFrom(from)
+	if errors.IsUnsupportedHashAlgorithmError(perr) {
+		return 0, false, nil, errors.Wrapf(perr, tr.Tr.Get("Unable to smudge %q", filename))
+	}
 	if perr != nil {
 		// Write 'statusFromErr(nil)', even though 'perr != nil', since
 		// we are about to write non-delayed smudged contents to "to".
@@ -89,7 +97,8 @@ func delayedSmudge(gf *lfs.GitFilter, s *git.FilterProcessScanner, to io.Writer,
 // If the encoded LFS pointer is not parse-able as a pointer, the contents of
 // that file will instead be spooled to a temporary location on disk and then
 // copied out back to Git. If the pointer file is empty, an empty file will be
-// written with no error.
+// written with no error. A pointer whose OID uses an unsupported hash
+// algorithm is an error, rather than being treated as an ordinary file.
 //
 // If the smudged object did not "pass" the include and exclude filterset, it
 // will not be downloaded, and the object will remain a pointer on disk, as if
@@ -100,6 +109,9 @@ func delayedSmudge(gf *lfs.GitFilter, s *git.FilterProcessScanner, to io.Writer,
 // terminated by using the `commands.Panic()` func.
 func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, skip bool, filter *filepathfilter.Filter) (int64, error) {
 	ptr, pbuf, perr := lfs.DecodeFrom(from)
+	if errors.IsUnsupportedHashAlgorithmError(perr) {
+		return 0, errors.Wrapf(perr, tr.Tr.Get("Unable to smudge %q", filename))
+	}
 	if perr != nil {
 		n, err := tools.Spool(to, pbuf, cfg.TempDir())
 		if err != nil {
@@ -139,6 +151,7 @@ func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, sk
 
 		LoggedError(err, tr.Tr.Get("Error downloading object: %s (%s): %s", filename, oid, err))
 		if !cfg.SkipDownloadErrors() {
+			writeMetrics()
 			os.Exit(2)
 		}
 	}
@@ -146,20 +159,52 @@ func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, sk
 	return n, nil
 }
 
+// smudgePassthrough writes the Git LFS pointer read from "from" to "to"
+// unchanged, without downloading its object or replacing it with the object's
+// contents even if they are present locally.
+//
+// Unlike smudge, input which is not a valid pointer is an error rather than
+// being copied to "to", except that an empty file is written as is.
+func smudgePassthrough(to io.Writer, from io.Reader, filename string) (int64, error) {
+	_, pbuf, perr := lfs.DecodeFrom(from)
+	if errors.IsUnsupportedHashAlgorithmError(perr) {
+		return 0, errors.Wrapf(perr, tr.Tr.Get("Unable to smudge %q", filename))
+	}
+	if perr != nil {
+		if n, _ := io.Copy(io.Discard, io.LimitReader(pbuf, 1)); n == 0 {
+			return 0, nil
+		}
+		return 0, errors.NewNotAPointerError(errors.Errorf(
+			tr.Tr.Get("Unable to parse pointer at: %q", filename),
+		))
+	}
+
+	return io.Copy(to, pbuf)
+}
+
 func smudgeCommand(cmd *cobra.Command, args []string) {
 	requireStdin(tr.Tr.Get("This command should be run by the Git 'smudge' filter"))
 	setupRepository()
 	installHooks(false)
 
+	if smudgeSkipDownload {
+		if _, err := smudgePassthrough(os.Stdout, os.Stdin, smudgeFilename(args)); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
 	if !smudgeSkip && cfg.Os.Bool("GIT_LFS_SKIP_SMUDGE", false) {
 		smudgeSkip = true
 	}
-	filter := filepathfilter.New(cfg.FetchIncludePaths(), cfg.FetchExcludePaths(), filepathfilter.GitIgnore)
+	filter := smudgeFilter()
 	gitfilter := lfs.NewGitFilter(cfg)
 
 	if n, err := smudge(gitfilter, os.Stdout, os.Stdin, smudgeFilename(args), smudgeSkip, filter); err != nil {
 		if errors.IsNotAPointerError(err) {
 			fmt.Fprintln(os.Stderr, err.Error())
+		} else if errors.IsUnsupportedHashAlgorithmError(err) {
+			ExitWithError(err)
 		} else {
 			Error(err.Error())
 		}
@@ -168,6 +213,14 @@ func smudgeCommand(cmd *cobra.Command, args []string) {
 	}
 }
 
+// smudgeFilter returns the filter of the paths which the smudge filter
+// replaces with their objects' contents, which excludes those which are not
+// fetched and those of lfs.skipsmudgepaths.
+func smudgeFilter() *filepathfilter.Filter {
+	exclude := append(cfg.FetchExcludePaths(), cfg.SkipSmudgePaths()...)
+	return filepathfilter.New(cfg.FetchIncludePaths(), exclude, filepathfilter.GitIgnore)
+}
+
 func smudgeFilename(args []string) string {
 	if len(args) > 0 {
 		return args[0]
@@ -182,5 +235,6 @@ func possiblyMalformedObjectSize(n int64) bool {
 func init() {
 	RegisterCommand("smudge", smudgeCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&smudgeSkip, "skip", "s", false, "")
+		cmd.Flags().BoolVarP(&smudgeSkipDownload, "skip-download", "", false, "Write the pointer through unchanged, failing if it is invalid")
 	})
 }
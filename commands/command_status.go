@@ -1,12 +1,12 @@
 package commands
 
 import (
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/git-lfs/git-lfs/v3/git"
@@ -17,8 +17,9 @@ import (
 )
 
 var (
-	porcelain  = false
+	porcelain  = ""
 	statusJson = false
+	statusZ    = false
 )
 
 func statusCommand(cmd *cobra.Command, args []string) {
@@ -41,10 +42,26 @@ func statusCommand(cmd *cobra.Command, args []string) {
 		ExitWithError(err)
 	}
 
-	if porcelain {
+	if statusZ && len(porcelain) == 0 {
+		porcelain = "v2"
+	}
+
+	switch porcelain {
+	case "":
+	case "v1":
+		if statusZ {
+			Exit(tr.Tr.Get("-z requires --porcelain=v2"))
+		}
 		porcelainStagedPointers(scanIndexAt)
 		return
-	} else if statusJson {
+	case "v2":
+		porcelainV2Pointers(scanner, scanIndexAt)
+		return
+	default:
+		Exit(tr.Tr.Get("Unknown porcelain format: %q", porcelain))
+	}
+
+	if statusJson {
 		jsonStagedPointers(scanner, scanIndexAt)
 		return
 	}
@@ -129,13 +146,32 @@ func blobInfoTo(s *lfs.PointerScanner, entry *lfs.DiffIndexEntry) (sha, from str
 }
 
 func blobInfo(s *lfs.PointerScanner, blobSha, name string) (sha, from string, err error) {
+	oid, from, _, err := blobDetails(s, blobSha, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case len(oid) > 0:
+		return oid[:7], from, nil
+	case from == "?":
+		return fmt.Sprintf("<%s>", tr.Tr.Get("missing")), from, nil
+	default:
+		return tr.Tr.Get("deleted"), from, nil
+	}
+}
+
+// blobDetails returns the full OID, source, and size of the contents of the
+// given blob, or of the named file in the working tree if blobSha is the zero
+// object ID. If the blob is missing, the returned OID is empty.
+func blobDetails(s *lfs.PointerScanner, blobSha, name string) (oid, from string, size int64, err error) {
 	if !git.IsZeroObjectID(blobSha) {
 		s.Scan(blobSha)
 		if err := s.Err(); err != nil {
 			if git.IsMissingObject(err) {
-				return fmt.Sprintf("<%s>", tr.Tr.Get("missing")), "?", nil
+				return "", "?", 0, nil
 			}
-			return "", "", err
+			return "", "", 0, err
 		}
 
 		var from string
@@ -145,29 +181,40 @@ func blobInfo(s *lfs.PointerScanner, blobSha, name string) (sha, from string, er
 			from = "Git"
 		}
 
-		return s.ContentsSha()[:7], from, nil
+		return s.ContentsSha(), from, s.ContentsSize(), nil
 	}
 
+	return fileDetails(name)
+}
+
+// fileDetails returns the OID, computed with the hash algorithm set by
+// lfs.hashalgo, and size of the named file in the working tree, or an empty OID
+// if the file has been deleted.
+func fileDetails(name string) (oid, from string, size int64, err error) {
 	f, err := os.Open(filepath.Join(cfg.LocalWorkingDir(), name))
 	if os.IsNotExist(err) {
-		return tr.Tr.Get("deleted"), tr.Tr.Get("File"), nil
+		return "", tr.Tr.Get("File"), 0, nil
 	}
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 	defer f.Close()
 
 	// We've replaced a file with a directory.
 	if fi, err := f.Stat(); err == nil && fi.Mode().IsDir() {
-		return tr.Tr.Get("deleted"), tr.Tr.Get("File"), nil
+		return "", tr.Tr.Get("File"), 0, nil
 	}
 
-	shasum := sha256.New()
-	if _, err = io.Copy(shasum, f); err != nil {
-		return "", "", err
+	shasum, err := tools.NewLfsContentHashFor(cfg.HashAlgorithm())
+	if err != nil {
+		return "", "", 0, err
+	}
+	size, err = io.Copy(shasum, f)
+	if err != nil {
+		return "", "", 0, err
 	}
 
-	return fmt.Sprintf("%x", shasum.Sum(nil))[:7], tr.Tr.Get("File"), nil
+	return fmt.Sprintf("%x", shasum.Sum(nil)), tr.Tr.Get("File"), size, nil
 }
 
 func scanIndex(ref string) (staged, unstaged []*lfs.DiffIndexEntry, err error) {
@@ -258,8 +305,23 @@ type JSONStatusEntry struct {
 	From   string `json:"from,omitempty"`
 }
 
+// JSONStatusFile describes a single Git LFS file in the staged, not_staged,
+// and untracked lists. For renames and copies, Name is the new path and From
+// the old one. Size is the size of the new contents.
+type JSONStatusFile struct {
+	Name    string `json:"name"`
+	From    string `json:"from,omitempty"`
+	Status  string `json:"status"`
+	FromOid string `json:"from_oid"`
+	ToOid   string `json:"to_oid"`
+	Size    int64  `json:"size"`
+}
+
 type JSONStatus struct {
-	Files map[string]JSONStatusEntry `json:"files"`
+	Files     map[string]JSONStatusEntry `json:"files"`
+	Staged    []JSONStatusFile           `json:"staged"`
+	NotStaged []JSONStatusFile           `json:"not_staged"`
+	Untracked []JSONStatusFile           `json:"untracked"`
 }
 
 func jsonStagedPointers(scanner *lfs.PointerScanner, ref string) {
@@ -268,7 +330,12 @@ func jsonStagedPointers(scanner *lfs.PointerScanner, ref string) {
 		ExitWithError(err)
 	}
 
-	status := JSONStatus{Files: make(map[string]JSONStatusEntry)}
+	status := JSONStatus{
+		Files:     make(map[string]JSONStatusEntry),
+		Staged:    make([]JSONStatusFile, 0),
+		NotStaged: make([]JSONStatusFile, 0),
+		Untracked: make([]JSONStatusFile, 0),
+	}
 
 	for _, entry := range append(unstaged, staged...) {
 		_, fromSrc, err := blobInfoFrom(scanner, entry)
@@ -292,6 +359,23 @@ func jsonStagedPointers(scanner *lfs.PointerScanner, ref string) {
 		}
 	}
 
+	for _, entry := range staged {
+		if file := jsonStatusFile(scanner, entry); file != nil {
+			status.Staged = append(status.Staged, *file)
+		}
+	}
+	for _, entry := range unstaged {
+		if file := jsonStatusFile(scanner, entry); file != nil {
+			status.NotStaged = append(status.NotStaged, *file)
+		}
+	}
+
+	untracked, err := jsonUntrackedFiles()
+	if err != nil {
+		ExitWithError(err)
+	}
+	status.Untracked = append(status.Untracked, untracked...)
+
 	ret, err := json.Marshal(status)
 	if err != nil {
 		ExitWithError(err)
@@ -299,6 +383,77 @@ func jsonStagedPointers(scanner *lfs.PointerScanner, ref string) {
 	Print(string(ret))
 }
 
+// jsonStatusFile returns the JSON description of the given entry, or nil if
+// neither side of it is stored in Git LFS.
+func jsonStatusFile(s *lfs.PointerScanner, entry *lfs.DiffIndexEntry) *JSONStatusFile {
+	file := &JSONStatusFile{
+		Name:   entry.SrcName,
+		Status: string(entry.Status),
+	}
+
+	switch entry.Status {
+	case lfs.StatusRename, lfs.StatusCopy:
+		file.Name, file.From = entry.DstName, entry.SrcName
+	}
+
+	var fromSrc, toSrc string
+	if !git.IsZeroObjectID(entry.SrcSha) {
+		oid, src, _, err := blobDetails(s, entry.SrcSha, entry.SrcName)
+		if err != nil {
+			ExitWithError(err)
+		}
+		file.FromOid, fromSrc = oid, src
+	}
+
+	if entry.Status != lfs.StatusDeletion {
+		oid, src, size, err := blobDetails(s, entry.DstSha, file.Name)
+		if err != nil {
+			ExitWithError(err)
+		}
+		file.ToOid, file.Size, toSrc = oid, size, src
+	}
+
+	if fromSrc != "LFS" && toSrc != "LFS" {
+		return nil
+	}
+	return file
+}
+
+// jsonUntrackedFiles returns the JSON descriptions of the untracked files in
+// the working tree which match a Git LFS pattern, and so would be stored in
+// Git LFS once added.
+func jsonUntrackedFiles() ([]JSONStatusFile, error) {
+	filter := git.GetAttributeFilter(cfg.LocalWorkingDir(), cfg.LocalGitDir())
+	if len(filter.Include()) == 0 {
+		return nil, nil
+	}
+
+	paths, err := git.UntrackedFiles(cfg.LocalWorkingDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var files []JSONStatusFile
+	for _, path := range paths {
+		if !filter.Allows(path) {
+			continue
+		}
+
+		oid, _, size, err := fileDetails(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, JSONStatusFile{
+			Name:   path,
+			Status: "?",
+			ToOid:  oid,
+			Size:   size,
+		})
+	}
+	return files, nil
+}
+
 func porcelainStagedPointers(ref string) {
 	staged, unstaged, err := scanIndex(ref)
 	if err != nil {
@@ -332,6 +487,97 @@ func porcelainStatusLine(entry *lfs.DiffIndexEntry) string {
 	return fmt.Sprintf("%s  %s", entry.Status, entry.SrcName)
 }
 
+// porcelainV2File is the state of a single Git LFS file in the output of
+// "git lfs status --porcelain=v2".
+type porcelainV2File struct {
+	name     string
+	from     string
+	staged   string
+	unstaged string
+	oid      string
+}
+
+// porcelainV2Pointers prints a line for each Git LFS file which is staged or
+// modified in the working tree, of the form "XY <oid> <path>", where X is its
+// status in the index relative to the given ref, Y its status in the working
+// tree relative to the index, either of which is "." if the file is
+// unchanged, and <oid> is the short OID of its newest contents.  A renamed or
+// copied file is followed by its original path, separated by a tab, or a NUL
+// with -z, in which case each line is also terminated by a NUL.
+func porcelainV2Pointers(scanner *lfs.PointerScanner, ref string) {
+	cached, err := lfs.NewDiffIndexScanner(ref, true, true, "")
+	if err != nil {
+		ExitWithError(err)
+	}
+	staged, err := drainScanner(make(map[string]struct{}), cached)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	uncached, err := lfs.NewDiffFilesScanner(false, "")
+	if err != nil {
+		ExitWithError(err)
+	}
+	unstaged, err := drainScanner(make(map[string]struct{}), uncached)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	files := make(map[string]*porcelainV2File)
+	var names []string
+	fileFor := func(name string) *porcelainV2File {
+		f, ok := files[name]
+		if !ok {
+			f = &porcelainV2File{name: name, staged: ".", unstaged: "."}
+			files[name] = f
+			names = append(names, name)
+		}
+		return f
+	}
+
+	for _, entry := range staged {
+		if file := jsonStatusFile(scanner, entry); file != nil {
+			f := fileFor(file.Name)
+			f.staged, f.from, f.oid = file.Status, file.From, porcelainV2Oid(file)
+		}
+	}
+	for _, entry := range unstaged {
+		if file := jsonStatusFile(scanner, entry); file != nil {
+			f := fileFor(file.Name)
+			f.unstaged, f.oid = file.Status, porcelainV2Oid(file)
+		}
+	}
+
+	sep, term := "\t", "\n"
+	if statusZ {
+		sep, term = "\x00", "\x00"
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		f := files[name]
+		fmt.Fprintf(OutputWriter, "%s%s %s %s", f.staged, f.unstaged, f.oid, f.name)
+		if len(f.from) > 0 {
+			fmt.Fprintf(OutputWriter, "%s%s", sep, f.from)
+		}
+		fmt.Fprint(OutputWriter, term)
+	}
+}
+
+// porcelainV2Oid returns the short OID of the newest contents of the given
+// file, which for a deleted file are its old contents, or "-" if they are not
+// known.
+func porcelainV2Oid(file *JSONStatusFile) string {
+	oid := file.ToOid
+	if file.Status == string(lfs.StatusDeletion) {
+		oid = file.FromOid
+	}
+	if len(oid) < 7 {
+		return "-"
+	}
+	return oid[:7]
+}
+
 // relativize relatives a path from "from" to "to". For instance, note that, for
 // any paths "from" and "to", that:
 //
@@ -367,7 +613,9 @@ func relativize(from, to string) string {
 
 func init() {
 	RegisterCommand("status", statusCommand, func(cmd *cobra.Command) {
-		cmd.Flags().BoolVarP(&porcelain, "porcelain", "p", false, "Give the output in an easy-to-parse format for scripts.")
+		cmd.Flags().StringVarP(&porcelain, "porcelain", "p", "", "Give the output in an easy-to-parse format for scripts.")
+		cmd.Flags().Lookup("porcelain").NoOptDefVal = "v1"
+		cmd.Flags().BoolVarP(&statusZ, "null", "z", false, "Terminate lines of --porcelain=v2 output with NUL.")
 		cmd.Flags().BoolVarP(&statusJson, "json", "j", false, "Give the output in a stable json format for scripts.")
 	})
 }
@@ -9,13 +9,21 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/git/gitattr"
+	"github.com/git-lfs/git-lfs/v3/git/githistory"
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tasklog"
 	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/git-lfs/gitobj/v2"
 	"github.com/spf13/cobra"
 )
 
@@ -26,18 +34,29 @@ var (
 
 	trackLockableFlag       bool
 	trackNotLockableFlag    bool
+	trackListLockableFlag   bool
 	trackVerboseLoggingFlag bool
 	trackDryRunFlag         bool
 	trackNoModifyAttrsFlag  bool
 	trackNoExcludedFlag     bool
 	trackFilenameFlag       bool
 	trackJSONFlag           bool
+	trackMigrateFlag        bool
+	trackYesFlag            bool
 )
 
 func trackCommand(cmd *cobra.Command, args []string) {
 	requireGitVersion()
 	setupWorkingCopy()
 
+	if trackMigrateFlag && trackNoModifyAttrsFlag {
+		Exit(tr.Tr.Get("--migrate option can't be combined with --no-modify-attrs"))
+	}
+
+	if trackLockableFlag && trackNotLockableFlag {
+		Exit(tr.Tr.Get("--lockable option can't be combined with --not-lockable"))
+	}
+
 	if trackDryRunFlag {
 		trackNoModifyAttrsFlag = true
 	}
@@ -55,6 +74,10 @@ func trackCommand(cmd *cobra.Command, args []string) {
 		Exit(tr.Tr.Get("--json option can't be combined with arguments"))
 	}
 
+	if trackListLockableFlag {
+		Exit(tr.Tr.Get("--list-lockable option can't be combined with arguments"))
+	}
+
 	mp := gitattr.NewMacroProcessor()
 
 	// Intentionally do _not_ consider global- and system-level
@@ -74,7 +97,24 @@ func trackCommand(cmd *cobra.Command, args []string) {
 		Exit(tr.Tr.Get("Current directory %q outside of Git working directory %q.", wd, cfg.LocalWorkingDir()))
 	}
 
+	if trackMigrateFlag {
+		if relpath != "." {
+			Exit(tr.Tr.Get("--migrate option must be used from the root of the working tree"))
+		}
+
+		dirty, err := git.IsWorkingCopyDirty()
+		if err != nil {
+			ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not determine if working copy is dirty")))
+		}
+		if dirty {
+			Exit(tr.Tr.Get("--migrate option requires a clean working copy"))
+		}
+	}
+
 	changedAttribLines := make(map[string]string)
+	// attribPaths are the new patterns relative to the root of the
+	// repository, as they would be read from the attributes file.
+	attribPaths := make(map[string]string)
 	var readOnlyPatterns []string
 	var writeablePatterns []string
 ArgsLoop:
@@ -84,15 +124,26 @@ ArgsLoop:
 		// Generate the new / changed attrib line for merging
 		var encodedArg string
 		if trackFilenameFlag {
-			encodedArg = escapeGlobCharacters(pattern)
 			pattern = escapeGlobCharacters(pattern)
+			// A leading "!" would negate the pattern and a leading
+			// quote would start a C-style quoted one, so anchor such
+			// filenames to the root of the repository instead.
+			if strings.HasPrefix(pattern, "!") || strings.HasPrefix(pattern, `"`) {
+				pattern = "/" + pattern
+			}
+			encodedArg = pattern
 		} else {
 			encodedArg = escapeAttrPattern(pattern)
 		}
 
 		if !trackNoModifyAttrsFlag {
+			unescaped := unescapeAttrPattern(encodedArg)
+			knownPath := path.Join(relpath, unescaped)
+			if strings.HasPrefix(unescaped, "/") {
+				knownPath = "/" + knownPath
+			}
 			for _, known := range knownPatterns {
-				if unescapeAttrPattern(known.Path) == path.Join(relpath, pattern) &&
+				if unescapeAttrPattern(known.Path) == knownPath &&
 					((trackLockableFlag && known.Lockable) || // enabling lockable & already lockable (no change)
 						(trackNotLockableFlag && !known.Lockable) || // disabling lockable & not lockable (no change)
 						(!trackLockableFlag && !trackNotLockableFlag)) { // leave lockable as-is in all cases
@@ -108,6 +159,10 @@ ArgsLoop:
 		}
 
 		changedAttribLines[pattern] = fmt.Sprintf("%s filter=lfs diff=lfs merge=lfs -text%v%s", encodedArg, lockableArg, lineEnd)
+		attribPaths[pattern] = unescapeAttrPattern(encodedArg)
+		if relpath != "." {
+			attribPaths[pattern] = path.Join(filepath.ToSlash(relpath), attribPaths[pattern])
+		}
 
 		if trackLockableFlag {
 			readOnlyPatterns = append(readOnlyPatterns, pattern)
@@ -152,6 +207,12 @@ ArgsLoop:
 
 				pattern := unescapeAttrPattern(fields[0])
 				if newline, ok := changedAttribLines[pattern]; ok {
+					// Only the lockable attribute is being
+					// changed, so keep any others the line
+					// already sets.
+					if (trackLockableFlag || trackNotLockableFlag) && isLFSTrackLine(fields) {
+						newline = setLockableAttrib(fields, trackLockableFlag) + lineEnd
+					}
 					// Replace this line (newline already embedded)
 					attributesFile.WriteString(newline)
 					// Remove from map so we know we don't have to add it to the end
@@ -168,6 +229,8 @@ ArgsLoop:
 
 	modified := false
 	sawError := false
+	migratePatterns := make(map[string]string)
+	var addedPaths []string
 	// Any items left in the map, write new lines at the end of the file
 	// Note this is only new patterns, not ones which changed locking flags
 	for pattern, newline := range changedAttribLines {
@@ -210,6 +273,8 @@ ArgsLoop:
 			attributesFile.WriteString(newline)
 		}
 		modified = true
+		migratePatterns[pattern] = strings.TrimSuffix(newline, lineEnd)
+		addedPaths = append(addedPaths, attribPaths[pattern])
 
 		for _, f := range gittracked {
 			if trackVerboseLoggingFlag || trackDryRunFlag {
@@ -228,17 +293,33 @@ ArgsLoop:
 		}
 	}
 
+	if trackDryRunFlag && len(addedPaths) > 0 {
+		if err := printNewlyTrackedFiles(knownPatterns, relpath, addedPaths); err != nil {
+			ExitWithError(err)
+		}
+	}
+
 	// now flip read-only mode based on lockable / not lockable changes
-	lockClient := newLockClient()
-	err = lockClient.FixFileWriteFlagsInDir(relpath, readOnlyPatterns, writeablePatterns)
-	if err != nil {
-		LoggedError(err, tr.Tr.Get("Error changing lockable file permissions: %s", err))
-		sawError = true
+	if !trackDryRunFlag {
+		lockClient := newLockClient()
+		err = lockClient.FixFileWriteFlagsInDir(relpath, readOnlyPatterns, writeablePatterns)
+		if err != nil {
+			LoggedError(err, tr.Tr.Get("Error changing lockable file permissions: %s", err))
+			sawError = true
+		}
 	}
 
 	if sawError {
 		os.Exit(2)
 	}
+
+	if trackMigrateFlag && len(migratePatterns) > 0 {
+		if attributesFile != nil {
+			attributesFile.Close()
+		}
+		trackMigrate(knownPatterns, addedPaths, migratePatterns)
+	}
+
 	// If we didn't modify things, but that's because the patterns
 	// were already supported, don't return an error, since what the
 	// user wanted has already been done.
@@ -249,6 +330,234 @@ ArgsLoop:
 	}
 }
 
+// printNewlyTrackedFiles prints the files in the index, and the untracked files
+// in the working tree which are not ignored, which would be tracked by Git LFS
+// once the given patterns, relative to the root of the repository, were added
+// to the .gitattributes file in the directory "relpath", but are not already.
+func printNewlyTrackedFiles(known []git.AttributePath, relpath string, patterns []string) error {
+	files, isUntracked, err := newlyTrackedFiles(known, relpath, patterns)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		name := f
+		if relpath != "." {
+			name = strings.TrimPrefix(f, filepath.ToSlash(relpath)+"/")
+		}
+		if isUntracked[f] {
+			Print(tr.Tr.Get("Would track %q (untracked)", name))
+		} else {
+			Print(tr.Tr.Get("Would track %q", name))
+		}
+	}
+
+	Print(tr.Tr.GetN(
+		"%d file would be newly tracked by Git LFS",
+		"%d files would be newly tracked by Git LFS",
+		len(files),
+		len(files)))
+	return nil
+}
+
+// newlyTrackedFiles returns, in sorted order, the files in the index, and the
+// untracked files in the working tree which are not ignored, which would be
+// tracked by Git LFS once the given patterns were added to the .gitattributes
+// file in the directory "relpath", but are not already, along with the set of
+// those which are untracked.  The files are matched against the patterns in
+// the same way as any others from the repository's attributes files,
+// including those which take precedence over the new ones.
+func newlyTrackedFiles(known []git.AttributePath, relpath string, patterns []string) ([]string, map[string]bool, error) {
+	before := git.AttributeFilter(known)
+	after := git.AttributeFilter(withTrackedPatterns(known, filepath.Join(relpath, ".gitattributes"), patterns))
+
+	lsFiles, err := git.NewLsFiles(cfg.LocalWorkingDir(), true, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	untracked, err := git.UntrackedFiles(cfg.LocalWorkingDir())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make([]string, 0, len(lsFiles.Files)+len(untracked))
+	for f := range lsFiles.Files {
+		files = append(files, f)
+	}
+	files = append(files, untracked...)
+	sort.Strings(files)
+
+	isUntracked := make(map[string]bool, len(untracked))
+	for _, f := range untracked {
+		isUntracked[f] = true
+	}
+
+	matched := make([]string, 0, len(files))
+	for _, f := range files {
+		if after.Allows(f) && !before.Allows(f) {
+			matched = append(matched, f)
+		}
+	}
+	return matched, isUntracked, nil
+}
+
+// withTrackedPatterns returns the given entries from the repository's
+// attributes files with entries which track the given patterns added to the
+// end of those from the file "file", relative to the root of the repository,
+// as if they had been added to that file.  If the file has no entries, the
+// new ones are placed among those of the other files in the same order of
+// precedence in which git.GetAttributePaths finds them.
+func withTrackedPatterns(known []git.AttributePath, file string, patterns []string) []git.AttributePath {
+	source := &git.AttributeSource{Path: file}
+	at := -1
+	for i, p := range known {
+		if p.Source != nil && p.Source.Path == file {
+			source = p.Source
+			at = i + 1
+		}
+	}
+	if at < 0 {
+		at = len(known)
+		for i, p := range known {
+			if p.Source != nil && filepath.Base(p.Source.Path) == ".gitattributes" && len(p.Source.Path) < len(file) {
+				at = i
+				break
+			}
+		}
+	}
+
+	paths := make([]git.AttributePath, 0, len(known)+len(patterns))
+	paths = append(paths, known[:at]...)
+	for _, pattern := range patterns {
+		paths = append(paths, git.AttributePath{Path: pattern, Source: source, Tracked: true})
+	}
+	return append(paths, known[at:]...)
+}
+
+// trackMigrate rewrites the history of the current branch so that any
+// existing files matching the given newly tracked patterns are converted to
+// Git LFS pointers, in the same manner as `git lfs migrate import`.  The
+// patterns are mapped to the .gitattributes lines which track them, and
+// "added" holds the same patterns relative to the root of the repository.
+//
+// The number of files and bytes in the current tree to be converted are
+// reported first, and the user is asked to confirm the rewrite unless --yes
+// was given.
+func trackMigrate(known []git.AttributePath, added []string, patterns map[string]string) {
+	matched, isUntracked, err := newlyTrackedFiles(known, ".", added)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	var files, size int64
+	for _, f := range matched {
+		if isUntracked[f] {
+			continue
+		}
+
+		path := filepath.Join(cfg.LocalWorkingDir(), f)
+		if p, err := lfs.DecodePointerFromFile(path); p != nil && err == nil {
+			continue
+		}
+
+		files++
+		if fi, err := os.Stat(path); err == nil {
+			size += fi.Size()
+		}
+	}
+
+	if files == 0 {
+		Print(tr.Tr.Get("No existing files to convert to Git LFS objects"))
+		return
+	}
+
+	Print(tr.Tr.GetN(
+		"%d file (%s) would be converted to Git LFS objects",
+		"%d files (%s) would be converted to Git LFS objects",
+		int(files),
+		files,
+		humanize.FormatBytes(uint64(size))))
+
+	if trackDryRunFlag {
+		return
+	}
+
+	if !trackYesFlag && !promptYesNo(os.Stdin, os.Stderr, tr.Tr.Get("Rewrite the history of the current branch to convert them? [y/N] ")) {
+		Print(tr.Tr.Get("Not converting existing files; run `git lfs migrate import` to convert them later"))
+		return
+	}
+
+	db, err := getObjectDatabase()
+	if err != nil {
+		ExitWithError(err)
+	}
+	defer db.Close()
+
+	include := make([]string, 0, len(patterns))
+	for pattern := range patterns {
+		include = append(include, pattern)
+	}
+	sort.Strings(include)
+
+	lines := tools.NewOrderedSet()
+	for _, pattern := range include {
+		lines.Add(patterns[pattern])
+	}
+	filter := filepathfilter.New(include, nil, filepathfilter.GitAttributes)
+
+	l := tasklog.NewLogger(os.Stderr,
+		tasklog.ForceProgress(cfg.ForceProgress()),
+	)
+	defer l.Close()
+
+	gitfilter := lfs.NewGitFilter(cfg)
+	migrate(nil, githistory.NewRewriter(db,
+		githistory.WithFilter(filter), githistory.WithLogger(l)), l, &githistory.RewriteOptions{
+		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
+			if filepath.Base(path) == ".gitattributes" {
+				return b, nil
+			}
+
+			var buf bytes.Buffer
+			if _, err := clean(gitfilter, &buf, b.Contents, path, b.Size); err != nil {
+				return nil, err
+			}
+
+			return &gitobj.Blob{
+				Contents: &buf, Size: int64(buf.Len()),
+			}, nil
+		},
+
+		TreeCallbackFn: func(path string, t *gitobj.Tree) (*gitobj.Tree, error) {
+			if path != "/" {
+				return t, nil
+			}
+
+			theirs, err := trackedFromAttrs(db, t)
+			if err != nil {
+				return nil, err
+			}
+
+			blob, err := trackedToBlob(db, theirs.Clone().Union(lines))
+			if err != nil {
+				return nil, err
+			}
+
+			return t.Merge(&gitobj.TreeEntry{
+				Name:     ".gitattributes",
+				Filemode: 0100644,
+				Oid:      blob,
+			}), nil
+		},
+
+		UpdateRefs: true,
+	})
+
+	if err := checkoutNonBare(l); err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not checkout")))
+	}
+}
+
 type PatternData struct {
 	Pattern  string `json:"pattern"`
 	Source   string `json:"source"`
@@ -266,6 +575,9 @@ func listPatterns() {
 			Patterns []PatternData `json:"patterns"`
 		}{Patterns: make([]PatternData, 0, len(knownPatterns))}
 		for _, p := range knownPatterns {
+			if trackListLockableFlag && !p.Lockable {
+				continue
+			}
 			patterns.Patterns = append(patterns.Patterns, PatternData{
 				Pattern:  p.Path,
 				Source:   p.Source.String(),
@@ -286,6 +598,16 @@ func listPatterns() {
 		return
 	}
 
+	if trackListLockableFlag {
+		Print(tr.Tr.Get("Listing lockable patterns"))
+		for _, t := range knownPatterns {
+			if t.Lockable {
+				Print("    %s (%s)", t.Path, t.Source)
+			}
+		}
+		return
+	}
+
 	Print(tr.Tr.Get("Listing tracked patterns"))
 	for _, t := range knownPatterns {
 		if t.Lockable {
@@ -335,6 +657,34 @@ func getAttributeLineEnding(attribs []git.AttributePath) string {
 	return ""
 }
 
+// isLFSTrackLine returns whether the given fields of a .gitattributes line
+// configure Git LFS to track the pattern with which the line begins.
+func isLFSTrackLine(fields []string) bool {
+	for _, f := range fields[1:] {
+		if f == "filter=lfs" {
+			return true
+		}
+	}
+	return false
+}
+
+// setLockableAttrib returns the .gitattributes line made up of the given
+// fields, with any existing setting of the lockable attribute replaced by
+// one which sets it, if lockable is true, or by none at all otherwise.
+func setLockableAttrib(fields []string, lockable bool) string {
+	attrs := []string{fields[0]}
+	for _, f := range fields[1:] {
+		name := strings.TrimLeft(strings.SplitN(f, "=", 2)[0], "-!")
+		if name != git.LockableAttrib {
+			attrs = append(attrs, f)
+		}
+	}
+	if lockable {
+		attrs = append(attrs, git.LockableAttrib)
+	}
+	return strings.Join(attrs, " ")
+}
+
 // blocklistItem returns the name of the blocklist item preventing the given
 // file-name from being tracked, or an empty string, if there is none.
 func blocklistItem(name string) string {
@@ -372,6 +722,9 @@ func escapeGlobCharacters(s string) string {
 	for from, to := range trackEscapePatterns {
 		escaped = strings.Replace(escaped, from, to, -1)
 	}
+
+	// Like a space, a tab would otherwise end the pattern.
+	escaped = strings.Replace(escaped, "\t", "[[:space:]]", -1)
 	return escaped
 }
 
@@ -408,11 +761,14 @@ func init() {
 	RegisterCommand("track", trackCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&trackLockableFlag, "lockable", "l", false, "make pattern lockable, i.e. read-only unless locked")
 		cmd.Flags().BoolVarP(&trackNotLockableFlag, "not-lockable", "", false, "remove lockable attribute from pattern")
+		cmd.Flags().BoolVarP(&trackListLockableFlag, "list-lockable", "", false, "list only lockable patterns")
 		cmd.Flags().BoolVarP(&trackVerboseLoggingFlag, "verbose", "v", false, "log which files are being tracked and modified")
 		cmd.Flags().BoolVarP(&trackDryRunFlag, "dry-run", "d", false, "preview results of running `git lfs track`")
 		cmd.Flags().BoolVarP(&trackNoModifyAttrsFlag, "no-modify-attrs", "", false, "skip modifying .gitattributes file")
 		cmd.Flags().BoolVarP(&trackNoExcludedFlag, "no-excluded", "", false, "skip listing excluded paths")
 		cmd.Flags().BoolVarP(&trackFilenameFlag, "filename", "", false, "treat this pattern as a literal filename")
 		cmd.Flags().BoolVarP(&trackJSONFlag, "json", "", false, "print output in JSON")
+		cmd.Flags().BoolVarP(&trackMigrateFlag, "migrate", "", false, "convert existing files matching new patterns in the current branch's history")
+		cmd.Flags().BoolVarP(&trackYesFlag, "yes", "y", false, "with --migrate, don't prompt before rewriting history")
 	})
 }
@@ -30,6 +30,9 @@ type unlockResponse struct {
 	Path     string `json:"path,omitempty"`
 	Unlocked bool   `json:"unlocked"`
 	Reason   string `json:"reason,omitempty"`
+	// Owner is the owner of the lock, which is given when the server
+	// refused to remove the lock, or when it was removed with --force.
+	Owner *locking.User `json:"owner,omitempty"`
 }
 
 func handleUnlockError(locks []unlockResponse, id string, path string, err error) []unlockResponse {
@@ -45,6 +48,37 @@ func handleUnlockError(locks []unlockResponse, id string, path string, err error
 	return locks
 }
 
+// handleUnlockRefused reports that the server failed to remove a lock, along
+// with the owner and ID of the lock which remains, if the lock can be found
+// given the filter.
+func handleUnlockRefused(locks []unlockResponse, lockClient *locking.Client, filter map[string]string, id string, path string, err error) []unlockResponse {
+	locks = handleUnlockError(locks, id, path, err)
+
+	remaining, _ := lockClient.SearchLocks(filter, 1, false, false)
+	if len(remaining) == 0 || remaining[0].Owner == nil {
+		return locks
+	}
+
+	lock := remaining[0]
+	if locksCmdFlags.JSON {
+		locks[len(locks)-1].Id = lock.Id
+		locks[len(locks)-1].Path = lock.Path
+		locks[len(locks)-1].Owner = lock.Owner
+	} else {
+		Error(tr.Tr.Get("%s is locked by %s (ID: %s)", lock.Path, lock.Owner.Name, lock.Id))
+	}
+	return locks
+}
+
+// unlockedLockOwner returns the owner of the given lock, which was removed, if
+// it was removed with --force and the server reported its owner.
+func unlockedLockOwner(lock *locking.Lock) *locking.User {
+	if !unlockCmdFlags.Force || lock == nil {
+		return nil
+	}
+	return lock.Owner
+}
+
 func unlockCommand(cmd *cobra.Command, args []string) {
 	hasPath := len(args) > 0
 	hasId := len(unlockCmdFlags.Id) > 0
@@ -88,36 +122,52 @@ func unlockCommand(cmd *cobra.Command, args []string) {
 				continue
 			}
 
-			err = lockClient.UnlockFile(path, unlockCmdFlags.Force)
+			lock, err := lockClient.UnlockFile(path, unlockCmdFlags.Force)
 			if err != nil {
-				locks = handleUnlockError(locks, "", path, errors.Cause(err))
+				locks = handleUnlockRefused(locks, lockClient, map[string]string{"path": path}, "", path, errors.Cause(err))
 				success = false
 				continue
 			}
 
+			owner := unlockedLockOwner(lock)
 			if !locksCmdFlags.JSON {
-				Print(tr.Tr.Get("Unlocked %s", path))
+				if owner != nil {
+					Print(tr.Tr.Get("Unlocked %s (locked by %s)", path, owner.Name))
+				} else {
+					Print(tr.Tr.Get("Unlocked %s", path))
+				}
 				continue
 			}
-			locks = append(locks, unlockResponse{
+
+			resp := unlockResponse{
 				Path:     path,
 				Unlocked: true,
-			})
+				Owner:    owner,
+			}
+			if owner != nil {
+				resp.Id = lock.Id
+			}
+			locks = append(locks, resp)
 		}
 	} else if unlockCmdFlags.Id != "" {
 		// This call can early-out
 		unlockAbortIfFileModifiedById(unlockCmdFlags.Id, lockClient)
 
-		err := lockClient.UnlockFileById(unlockCmdFlags.Id, unlockCmdFlags.Force)
+		lock, err := lockClient.UnlockFileById(unlockCmdFlags.Id, unlockCmdFlags.Force)
 		if err != nil {
-			locks = handleUnlockError(locks, unlockCmdFlags.Id, "", errors.New(tr.Tr.Get("Unable to unlock %v: %v", unlockCmdFlags.Id, errors.Cause(err))))
+			locks = handleUnlockRefused(locks, lockClient, map[string]string{"id": unlockCmdFlags.Id}, unlockCmdFlags.Id, "", errors.New(tr.Tr.Get("Unable to unlock %v: %v", unlockCmdFlags.Id, errors.Cause(err))))
 			success = false
-		} else if !locksCmdFlags.JSON {
-			Print(tr.Tr.Get("Unlocked Lock %s", unlockCmdFlags.Id))
+		} else if owner := unlockedLockOwner(lock); !locksCmdFlags.JSON {
+			if owner != nil {
+				Print(tr.Tr.Get("Unlocked Lock %s (locked by %s)", unlockCmdFlags.Id, owner.Name))
+			} else {
+				Print(tr.Tr.Get("Unlocked Lock %s", unlockCmdFlags.Id))
+			}
 		} else {
 			locks = append(locks, unlockResponse{
 				Id:       unlockCmdFlags.Id,
 				Unlocked: true,
+				Owner:    owner,
 			})
 		}
 	} else {
@@ -3,13 +3,24 @@ package commands
 import (
 	"bufio"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/filepathfilter"
+	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/git/githistory"
+	"github.com/git-lfs/git-lfs/v3/tasklog"
 	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/spf13/cobra"
 )
 
+var (
+	untrackPruneFlag bool
+	untrackForceFlag bool
+)
+
 // untrackCommand takes a list of paths as an argument, and removes each path from the
 // default attributes file (.gitattributes), if it exists.
 func untrackCommand(cmd *cobra.Command, args []string) {
@@ -22,6 +33,22 @@ func untrackCommand(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if untrackPruneFlag {
+		wd, _ := tools.Getwd()
+		wd = tools.ResolveSymlinks(wd)
+		if relpath, err := filepath.Rel(cfg.LocalWorkingDir(), wd); err != nil || relpath != "." {
+			Exit(tr.Tr.Get("--prune option must be used from the root of the working tree"))
+		}
+
+		dirty, err := git.IsWorkingCopyDirty()
+		if err != nil {
+			ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not determine if working copy is dirty")))
+		}
+		if dirty && !untrackForceFlag {
+			Exit(tr.Tr.Get("--prune option requires a clean working copy; use --force to discard uncommitted changes"))
+		}
+	}
+
 	data, err := os.ReadFile(".gitattributes")
 	if err != nil {
 		return
@@ -40,6 +67,7 @@ func untrackCommand(cmd *cobra.Command, args []string) {
 
 	// Iterate through each line of the attributes file and rewrite it,
 	// if the path was meant to be untracked, omit it, and print a message instead.
+	var untracked []string
 	for scanner.Scan() {
 		line := scanner.Text()
 		if !strings.Contains(line, "filter=lfs") {
@@ -50,10 +78,48 @@ func untrackCommand(cmd *cobra.Command, args []string) {
 		path := strings.Fields(line)[0]
 		if removePath(path, args) {
 			Print(tr.Tr.Get("Untracking %q", unescapeAttrPattern(path)))
+			untracked = append(untracked, unescapeAttrPattern(path))
 		} else {
 			attributesFile.WriteString(line + "\n")
 		}
 	}
+
+	if untrackPruneFlag && len(untracked) > 0 {
+		attributesFile.Close()
+		untrackPrune(untracked)
+	}
+}
+
+// untrackPrune rewrites the history of the current branch so that files
+// matching the given untracked patterns are replaced with the contents of
+// their Git LFS objects, in the same manner as `git lfs migrate export`.
+func untrackPrune(patterns []string) {
+	l := tasklog.NewLogger(os.Stderr,
+		tasklog.ForceProgress(cfg.ForceProgress()),
+	)
+	defer l.Close()
+
+	db, err := getObjectDatabase()
+	if err != nil {
+		ExitWithError(err)
+	}
+	defer db.Close()
+
+	filter := filepathfilter.New(patterns, nil, filepathfilter.GitAttributes)
+	rewriter := githistory.NewRewriter(db,
+		githistory.WithFilter(filter), githistory.WithLogger(l))
+
+	exported, _ := exportHistory(db, rewriter, l, nil, cfg.Remote(), false)
+	l.Close()
+
+	Print(tr.Tr.GetN(
+		"Converted %d file from a Git LFS object",
+		"Converted %d files from Git LFS objects",
+		exported.Cardinality(),
+		exported.Cardinality()))
+	for path := range exported.Iter() {
+		Print("    %s", path)
+	}
 }
 
 func removePath(path string, args []string) bool {
@@ -68,5 +134,8 @@ func removePath(path string, args []string) bool {
 }
 
 func init() {
-	RegisterCommand("untrack", untrackCommand, nil)
+	RegisterCommand("untrack", untrackCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&untrackPruneFlag, "prune", "", false, "convert files matching the patterns in the current branch's history back from Git LFS objects")
+		cmd.Flags().BoolVarP(&untrackForceFlag, "force", "f", false, "with --prune, discard uncommitted changes in the working copy")
+	})
 }
@@ -8,8 +8,9 @@ import (
 )
 
 var (
-	updateForce  = false
-	updateManual = false
+	updateForce     = false
+	updateManual    = false
+	updatePreCommit = false
 )
 
 // updateCommand is used for updating parts of Git LFS that reside under
@@ -43,9 +44,13 @@ func updateCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if updateManual {
-		Print(getHookInstallSteps())
+		Print(getHookInstallSteps(updatePreCommit))
 	} else {
-		if err := installHooks(updateForce); err != nil {
+		err := installHooks(updateForce)
+		if err == nil && updatePreCommit {
+			err = installPreCommitHook(updateForce)
+		}
+		if err != nil {
 			Error(err.Error())
 			Exit("%s\n  1: %s\n  2: %s",
 				tr.Tr.Get("To resolve this, either:"),
@@ -62,5 +67,6 @@ func init() {
 	RegisterCommand("update", updateCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&updateForce, "force", "f", false, "Overwrite existing hooks.")
 		cmd.Flags().BoolVarP(&updateManual, "manual", "m", false, "Print instructions for manual install.")
+		cmd.Flags().BoolVarP(&updatePreCommit, "pre-commit", "", false, "Also install the pre-commit hook.")
 	})
 }
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"os"
+	"sort"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/locking"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/spf13/cobra"
+)
+
+// verifyLocksCommand checks the files modified in the working tree against
+// the locks held on the server, and reports lockable files which were modified
+// without being locked by the current user, and files which were modified
+// while locked by another user.
+func verifyLocksCommand(cmd *cobra.Command, args []string) {
+	setupWorkingCopy()
+
+	if len(lockRemote) > 0 {
+		cfg.SetRemote(lockRemote)
+	}
+
+	modified, err := verifyLocksModifiedPaths()
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not list modified files")))
+	}
+
+	refUpdate := git.NewRefUpdate(cfg.Git, cfg.PushRemote(), cfg.CurrentRef(), nil)
+	lockClient := newLockClient()
+	lockClient.RemoteRef = refUpdate.RemoteRef()
+	defer lockClient.Close()
+
+	ourLocks, theirLocks, err := lockClient.SearchLocksVerifiable(0, false)
+	if err != nil {
+		ExitWithError(errors.Wrap(err, tr.Tr.Get("Could not verify locks")))
+	}
+
+	ours := make(map[string]bool, len(ourLocks))
+	for _, lock := range ourLocks {
+		ours[lock.Path] = true
+	}
+	theirs := make(map[string]locking.Lock, len(theirLocks))
+	for _, lock := range theirLocks {
+		theirs[lock.Path] = lock
+	}
+
+	var unlocked, lockedByOthers []string
+	for _, path := range modified {
+		if _, ok := theirs[path]; ok {
+			lockedByOthers = append(lockedByOthers, path)
+		} else if !ours[path] && lockClient.IsFileLockable(path) {
+			unlocked = append(unlocked, path)
+		}
+	}
+
+	if len(unlocked) == 0 && len(lockedByOthers) == 0 {
+		Print(tr.Tr.Get("All modified files are locked by you or are not lockable"))
+		return
+	}
+
+	if len(unlocked) > 0 {
+		Print(tr.Tr.Get("Modified files not locked by you:"))
+		for _, path := range unlocked {
+			Print("* %s", path)
+		}
+	}
+	if len(lockedByOthers) > 0 {
+		Print(tr.Tr.Get("Modified files locked by others:"))
+		for _, path := range lockedByOthers {
+			lock := theirs[path]
+			var owner string
+			if lock.Owner != nil {
+				owner = lock.Owner.Name
+			}
+			Print("* %s - %s (ID: %s)", path, owner, lock.Id)
+		}
+	}
+	os.Exit(1)
+}
+
+// verifyLocksModifiedPaths returns the sorted paths, relative to the root of
+// the working tree, of the files which differ between HEAD and the working
+// tree or index, including any which were deleted.
+func verifyLocksModifiedPaths() ([]string, error) {
+	// tolerate errors getting ref so this works before first commit
+	ref, _ := git.CurrentRef()
+
+	scanAt := "HEAD"
+	if ref == nil {
+		var err error
+		scanAt, err = git.EmptyTree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scanner, err := lfs.NewDiffIndexScanner(scanAt, false, true, cfg.LocalWorkingDir())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for scanner.Scan() {
+		entry := scanner.Entry()
+		for _, name := range []string{entry.SrcName, entry.DstName} {
+			if len(name) > 0 {
+				seen[name] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func init() {
+	RegisterCommand("verify-locks", verifyLocksCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVarP(&lockRemote, "remote", "r", "", "specify which remote to use when interacting with locks")
+	})
+}
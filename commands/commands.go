@@ -44,6 +44,14 @@ var (
 
 	includeArg string
 	excludeArg string
+
+	// progressFdArg is the file descriptor given by --progress-fd, or -1
+	// if none was given.
+	progressFdArg = -1
+
+	// noCacheArg is whether --no-cache was given, to ignore the cache of
+	// the transfer adapters chosen by the server.
+	noCacheArg bool
 )
 
 // getTransferManifest builds a tq.Manifest from the global os and git
@@ -122,6 +130,7 @@ func newDownloadCheckQueue(manifest tq.Manifest, remote string, options ...tq.Op
 func newDownloadQueue(manifest tq.Manifest, remote string, options ...tq.Option) *tq.TransferQueue {
 	return tq.NewTransferQueue(tq.Download, manifest, remote, append(options,
 		tq.RemoteRef(currentRemoteRef()),
+		tq.WithAdapterCache(!noCacheArg),
 	)...)
 }
 
@@ -143,13 +152,17 @@ func downloadTransfer(p *lfs.WrappedPointer) (name, path, oid string, size int64
 	return p.Name, path, p.Oid, p.Size, false, err
 }
 
-// Get user-readable manual install steps for hooks
-func getHookInstallSteps() string {
+// Get user-readable manual install steps for hooks, including the pre-commit
+// hook if preCommit is true
+func getHookInstallSteps(preCommit bool) string {
 	hookDir, err := cfg.HookDir()
 	if err != nil {
 		ExitWithError(err)
 	}
 	hooks := lfs.LoadHooks(hookDir, cfg)
+	if preCommit {
+		hooks = append(hooks, lfs.LoadPreCommitHook(hookDir, cfg))
+	}
 	hookDir = filepath.ToSlash(hookDir)
 	workingDir := filepath.ToSlash(fmt.Sprintf("%s%c", cfg.LocalWorkingDir(), os.PathSeparator))
 	steps := make([]string, 0, len(hooks))
@@ -177,6 +190,16 @@ func installHooks(force bool) error {
 	return nil
 }
 
+// installPreCommitHook installs the pre-commit hook, which is not installed
+// by installHooks.
+func installPreCommitHook(force bool) error {
+	hookDir, err := cfg.HookDir()
+	if err != nil {
+		return err
+	}
+	return lfs.LoadPreCommitHook(hookDir, cfg).Install(force)
+}
+
 // uninstallHooks removes all hooks in range of the `hooks` var.
 func uninstallHooks() error {
 	if !cfg.InRepo() {
@@ -194,6 +217,12 @@ func uninstallHooks() error {
 		}
 	}
 
+	// The pre-commit hook is only installed on request, so leave any
+	// other pre-commit hook alone.
+	if h := lfs.LoadPreCommitHook(hookDir, cfg); h.Exists() && h.Installed() {
+		return h.Uninstall()
+	}
+
 	return nil
 }
 
@@ -220,13 +249,25 @@ func Print(format string, args ...interface{}) {
 // Exit prints a formatted message and exits.
 func Exit(format string, args ...interface{}) {
 	Error(format, args...)
+	writeMetrics()
 	os.Exit(2)
 }
 
 // ExitWithError either panics with a full stack trace for fatal errors, or
-// simply prints the error message and exits immediately.
+// simply prints the error message and exits immediately.  The exit code is
+// chosen by exitCodeFor.
 func ExitWithError(err error) {
-	errorWith(err, Panic, Exit)
+	FullError(err)
+	writeMetrics()
+	os.Exit(exitCodeFor(err))
+}
+
+// ExitWithErrors prints a formatted message and exits with the exit code for
+// the given errors, which have already been reported.
+func ExitWithErrors(errs []error, format string, args ...interface{}) {
+	Error(format, args...)
+	writeMetrics()
+	os.Exit(exitCodeFor(errs...))
 }
 
 // FullError prints either a full stack trace for fatal errors, or just the
@@ -274,6 +315,7 @@ func LoggedError(err error, format string, args ...interface{}) {
 // a log file before exiting.
 func Panic(err error, format string, args ...interface{}) {
 	LoggedError(err, format, args...)
+	writeMetrics()
 	os.Exit(2)
 }
 
@@ -536,11 +578,21 @@ func determineIncludeExcludePaths(config *config.Configuration, includeArg, excl
 func buildProgressMeter(dryRun bool, d tq.Direction) *tq.Meter {
 	m := tq.NewMeter(cfg)
 	m.Logger = m.LoggerFromEnv(cfg.Os)
+	m.EventLogger = progressEventLogger(m)
 	m.DryRun = dryRun
 	m.Direction = d
 	return m
 }
 
+// progressEventLogger returns the writer to which JSON progress events are
+// written for m, which is given by --progress-fd or GIT_LFS_PROGRESS_FD.
+func progressEventLogger(m *tq.Meter) *tools.SyncWriter {
+	if progressFdArg >= 0 {
+		return m.EventLoggerToFd(progressFdArg)
+	}
+	return m.EventLoggerFromEnv(cfg.Os)
+}
+
 func requireGitVersion() {
 	minimumGit := "1.8.2"
 
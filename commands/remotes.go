@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/v3/lfs"
+	"github.com/git-lfs/git-lfs/v3/tq"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+)
+
+// setValidRemotes validates each of the remotes given with --remote, exiting
+// if any is not valid, and returns their names, with any local paths rewritten
+// as URLs.  The first of them becomes the current remote.
+func setValidRemotes(names []string) []string {
+	remotes := make([]string, 0, len(names))
+	for _, name := range names {
+		if err := cfg.SetValidRemote(name); err != nil {
+			Exit(tr.Tr.Get("Invalid remote name %q: %s", name, err))
+		}
+		remotes = append(remotes, cfg.Remote())
+	}
+	cfg.SetRemote(remotes[0])
+	return remotes
+}
+
+// describeRemotes returns the download endpoint URL of the given remote for use
+// in an error message, or a list of the given remotes if there are several.
+func describeRemotes(remote string, remotes []string) string {
+	if len(remotes) > 1 {
+		return strings.Join(remotes, "', '")
+	}
+	return getAPIClient().Endpoints.Endpoint("download", remote).Url
+}
+
+// fetchFromFallbackRemotes tries each of the given fallback remotes in turn for
+// the objects left in pending once q, the queue for the remote before them, has
+// finished, until none are left.  Objects which are downloaded are removed from
+// pending and their pointers passed to fetched, and are listed along with the
+// remote they came from once all of the remotes have been tried.
+//
+// It returns the queue for the last remote tried, whose errors are those of the
+// objects which could not be downloaded from any of the remotes, or q itself if
+// no fallback remote was needed.
+func fetchFromFallbackRemotes(q *tq.TransferQueue, remotes []string, pending *pointerMap, meter *tq.Meter, fetched func(pointers []*lfs.WrappedPointer), options ...tq.Option) *tq.TransferQueue {
+	var served []*lfs.WrappedPointer
+	servedBy := make(map[string]string)
+
+	for _, remote := range remotes {
+		pointers := pending.Remaining()
+		if len(pointers) == 0 {
+			break
+		}
+
+		for _, err := range q.Errors() {
+			tracerx.Printf("fetch: trying remote %q after error: %s", remote, err)
+		}
+
+		q = newDownloadQueue(
+			getTransferManifestOperationRemote("download", remote),
+			remote, append([]tq.Option{tq.WithProgress(meter)}, options...)...,
+		)
+
+		dlwatch := q.Watch()
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		go func(remote string) {
+			for t := range dlwatch {
+				plist := pending.All(t.Oid)
+				if len(plist) == 0 {
+					continue
+				}
+				served = append(served, plist[0])
+				servedBy[t.Oid] = remote
+				fetched(plist)
+			}
+			wg.Done()
+		}(remote)
+
+		for _, p := range pointers {
+			tracerx.Printf("fetch %v [%v] from %v", p.Name, p.Oid, remote)
+			meter.Add(p.Size)
+			q.Add(downloadTransfer(p))
+		}
+
+		meter.Start()
+		q.Wait()
+		wg.Wait()
+	}
+
+	if len(served) > 0 {
+		Print(tr.Tr.GetN(
+			"%d object was fetched from a fallback remote:",
+			"%d objects were fetched from a fallback remote:",
+			len(served),
+			len(served)))
+		for _, p := range served {
+			Print("  %s (%s) <= %s", p.Name, p.Oid, servedBy[p.Oid])
+		}
+	}
+	return q
+}
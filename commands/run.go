@@ -2,8 +2,10 @@ package commands
 
 import (
 	"bytes"
+	goerrors "errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,16 +13,40 @@ import (
 	"time"
 
 	"github.com/git-lfs/git-lfs/v3/config"
+	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tq"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/spf13/cobra"
 )
 
+// Exit codes returned by Git LFS commands, as documented in git-lfs(1).
+const (
+	// exitCodeError indicates a usage error, or a failure which does not
+	// belong to one of the classes below.
+	exitCodeError = 2
+	// exitCodeAuth indicates that the remote rejected the credentials
+	// offered, or that none could be found.
+	exitCodeAuth = 3
+	// exitCodeNetwork indicates that the remote could not be reached, or
+	// that the connection to it failed or timed out.
+	exitCodeNetwork = 4
+	// exitCodeIntegrity indicates that the contents of an object do not
+	// match its OID.
+	exitCodeIntegrity = 5
+)
+
 var (
 	commandFuncs []func() *cobra.Command
 	commandMu    sync.Mutex
 
 	rootVersion bool
+
+	// metricsCommand is the name of the command being run, and
+	// metricsStart the time at which it started, for writeMetrics.
+	metricsCommand = "git-lfs"
+	metricsStart   time.Time
+	metricsOnce    sync.Once
 )
 
 // NewCommand creates a new 'git-lfs' sub command, given a command name and
@@ -57,11 +83,15 @@ func RegisterCommand(name string, runFn func(cmd *cobra.Command, args []string),
 //
 // It returns an exit code.
 func Run() int {
+	metricsStart = time.Now()
 	log.SetOutput(ErrorWriter)
 	tr.InitializeLocale()
 
 	root := NewCommand("git-lfs", gitlfsCommand)
 	root.PreRun = nil
+	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		metricsCommand = cmd.Name()
+	}
 
 	completionCmd := &cobra.Command{
 		Use:                   "completion [bash|fish|zsh]",
@@ -153,13 +183,42 @@ Simply type ` + root.Name() + ` help [path to command] for full details.`,
 
 	err := root.Execute()
 	closeAPIClient()
+	writeMetrics()
 
 	if err != nil {
-		return 127
+		return exitCodeError
 	}
 	return 0
 }
 
+// exitCodeFor returns the exit code for a command which failed with the given
+// errors.  If the errors do not all belong to the same class, exitCodeError is
+// returned.
+func exitCodeFor(errs ...error) int {
+	code := exitCodeError
+	for i, err := range errs {
+		c := exitCodeForError(err)
+		if i > 0 && c != code {
+			return exitCodeError
+		}
+		code = c
+	}
+	return code
+}
+
+func exitCodeForError(err error) int {
+	var netErr net.Error
+	switch {
+	case errors.IsAuthError(err):
+		return exitCodeAuth
+	case errors.IsIntegrityError(err):
+		return exitCodeIntegrity
+	case goerrors.As(errors.Cause(err), &netErr):
+		return exitCodeNetwork
+	}
+	return exitCodeError
+}
+
 func gitlfsCommand(cmd *cobra.Command, args []string) {
 	versionCommand(cmd, args)
 	if !rootVersion {
@@ -191,6 +250,45 @@ func printHelp(commandName string) {
 	}
 }
 
+// writeMetrics writes the transfer metrics of the command to the file named by
+// GIT_LFS_METRICS_FILE, if set, for a Prometheus textfile collector.  The
+// file is replaced atomically, and is only written by commands which made
+// transfers.
+func writeMetrics() {
+	metricsOnce.Do(func() {
+		if cfg == nil || !tq.HasMetrics() {
+			return
+		}
+		path, ok := cfg.Os.Get("GIT_LFS_METRICS_FILE")
+		if !ok || len(path) == 0 {
+			return
+		}
+		if err := writeMetricsFile(path); err != nil {
+			fmt.Fprintln(os.Stderr, tr.Tr.Get("Error writing metrics: %s", err))
+		}
+	})
+}
+
+func writeMetricsFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = tq.WriteMetrics(tmp, metricsCommand, time.Since(metricsStart))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 func setupHTTPLogger(cmd *cobra.Command, args []string) {
 	if len(os.Getenv("GIT_LOG_STATS")) < 1 {
 		return
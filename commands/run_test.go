@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"net"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	plain := errors.New("plain")
+	auth := errors.NewAuthError(errors.New("no credentials"))
+	network := errors.Wrap(&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "batch request")
+	integrity := errors.Wrap(errors.NewIntegrityError(errors.New("expected OID a, got b")), "download")
+
+	assert.Equal(t, exitCodeError, exitCodeFor())
+	assert.Equal(t, exitCodeError, exitCodeFor(plain))
+	assert.Equal(t, exitCodeAuth, exitCodeFor(auth))
+	assert.Equal(t, exitCodeNetwork, exitCodeFor(network))
+	assert.Equal(t, exitCodeNetwork, exitCodeFor(errors.NewRetriableError(network)))
+	assert.Equal(t, exitCodeError, exitCodeFor(errors.NewRetriableError(plain)))
+	assert.Equal(t, exitCodeIntegrity, exitCodeFor(integrity))
+	assert.Equal(t, exitCodeAuth, exitCodeFor(auth, auth))
+	assert.Equal(t, exitCodeError, exitCodeFor(auth, integrity))
+	assert.Equal(t, exitCodeError, exitCodeFor(network, plain))
+}
@@ -124,6 +124,7 @@ func (c *uploadContext) NewQueue(options ...tq.Option) *tq.TransferQueue {
 	return tq.NewTransferQueue(tq.Upload, c.Manifest, c.Remote, append(options,
 		tq.DryRun(c.DryRun),
 		tq.WithProgress(c.meter),
+		tq.WithAdapterCache(!noCacheArg),
 	)...)
 }
 
@@ -295,12 +296,17 @@ func (c *uploadContext) ReportErrors() {
 				tr.Tr.Get("hint: You can disable this check with: `git config lfs.allowincompletepush true`"),
 			}
 			Print(strings.Join(pushMissingHint, "\n"))
-			os.Exit(2)
+			writeMetrics()
+			if len(c.missing) == 0 {
+				os.Exit(exitCodeIntegrity)
+			}
+			os.Exit(exitCodeError)
 		}
 	}
 
 	if len(c.otherErrs) > 0 {
-		os.Exit(2)
+		writeMetrics()
+		os.Exit(exitCodeFor(c.otherErrs...))
 	}
 
 	if c.lockVerifier.HasUnownedLocks() {
@@ -17,6 +17,7 @@ import (
 	"github.com/git-lfs/git-lfs/v3/fs"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/rubyist/tracerx"
 )
@@ -201,6 +202,17 @@ func (c *Configuration) FetchExcludePaths() []string {
 	return tools.CleanPaths(patterns, ",")
 }
 
+// SkipSmudgePaths returns the patterns of the paths which the smudge filter
+// leaves as pointers, from GIT_LFS_SKIP_SMUDGE_PATHS if it is set, or else from
+// lfs.skipsmudgepaths.
+func (c *Configuration) SkipSmudgePaths() []string {
+	patterns, ok := c.Os.Get("GIT_LFS_SKIP_SMUDGE_PATHS")
+	if !ok {
+		patterns, _ = c.Git.Get("lfs.skipsmudgepaths")
+	}
+	return tools.CleanPaths(patterns, ",")
+}
+
 func (c *Configuration) CurrentRef() *git.Ref {
 	c.loading.Lock()
 	defer c.loading.Unlock()
@@ -327,10 +339,60 @@ func (c *Configuration) SortedExtensions() ([]Extension, error) {
 	return SortExtensions(c.Extensions())
 }
 
+// ContentTransform returns the command, set by lfs.contenttransform.clean or
+// lfs.contenttransform.smudge, through which the contents of each object are
+// piped by the given filter action, or an empty string if there is none.
+func (c *Configuration) ContentTransform(action string) string {
+	v, _ := c.Git.Get("lfs.contenttransform." + action)
+	return v
+}
+
+// AutoRepair returns whether a corrupt object found in the local cache when
+// smudging should be downloaded again, as set by lfs.autorepair.
+func (c *Configuration) AutoRepair() bool {
+	return c.Git.Bool("lfs.autorepair", false)
+}
+
 func (c *Configuration) SkipDownloadErrors() bool {
 	return c.Os.Bool("GIT_LFS_SKIP_DOWNLOAD_ERRORS", false) || c.Git.Bool("lfs.skipdownloaderrors", false)
 }
 
+// HashAlgorithm returns the name of the hash algorithm, as set by
+// lfs.hashalgo, with which the OIDs of new Git LFS objects are computed.
+func (c *Configuration) HashAlgorithm() string {
+	if v, ok := c.Git.Get("lfs.hashalgo"); ok && len(v) > 0 {
+		return v
+	}
+	return tools.HashAlgorithmSHA256
+}
+
+// LargeFileWarning returns whether to warn about files of 4 GiB or more, which
+// Git for Windows before 2.34.0 corrupts, as set by lfs.largefilewarning when
+// it is a boolean.  Otherwise, the warning is given for versions of Git before
+// 2.34.0.
+func (c *Configuration) LargeFileWarning() bool {
+	if v, ok := c.Git.Get("lfs.largefilewarning"); ok && isBool(v) {
+		return Bool(v, false)
+	}
+	return !git.IsGitVersionAtLeast("2.34.0")
+}
+
+// LargeFileWarningSize returns the size, set by lfs.largefilewarning, above
+// which git-lfs-pre-commit(1) reports files staged for commit which are not
+// tracked by Git LFS, or zero if the key is unset or is a boolean.
+func (c *Configuration) LargeFileWarningSize() (uint64, error) {
+	v, ok := c.Git.Get("lfs.largefilewarning")
+	if !ok || len(v) == 0 || isBool(v) {
+		return 0, nil
+	}
+
+	n, err := humanize.ParseBytes(v)
+	if err != nil {
+		return 0, errors.New(tr.Tr.Get("Invalid value for `lfs.largefilewarning`: %q", v))
+	}
+	return n, nil
+}
+
 func (c *Configuration) SetLockableFilesReadOnly() bool {
 	return c.Os.Bool("GIT_LFS_SET_LOCKABLE_READONLY", true) && c.Git.Bool("lfs.setlockablereadonly", true)
 }
@@ -442,6 +504,14 @@ func (c *Configuration) Filesystem() *fs.Filesystem {
 			lfsdir,
 			c.RepositoryPermissions(false),
 		)
+		if depth := c.Git.Int("lfs.objectsharddepth", 0); depth != 0 {
+			c.fs.SetObjectShardDepth(depth)
+		}
+		for _, dir := range c.Git.GetAll("lfs.readonlycachedir") {
+			if expanded, err := tools.ExpandPath(dir, false); err == nil {
+				c.fs.AddReadOnlyCacheDir(expanded)
+			}
+		}
 	}
 
 	return c.fs
@@ -125,3 +125,14 @@ func Bool(value string, def bool) bool {
 		return false
 	}
 }
+
+// isBool returns whether the given value is one which Bool recognizes as
+// either true or false, rather than treating as false because it is not.
+func isBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "1", "on", "yes", "t", "false", "0", "off", "no", "f":
+		return true
+	default:
+		return false
+	}
+}
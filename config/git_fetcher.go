@@ -154,6 +154,12 @@ func (g *GitFetcher) All() map[string][]string {
 }
 
 func (g *GitFetcher) caseFoldKey(key string) string {
+	return foldKey(key)
+}
+
+// foldKey returns the given configuration key in the canonical form used by
+// Git, in which the section and the name of the key are in lowercase.
+func foldKey(key string) string {
 	parts := strings.Split(key, ".")
 	last := len(parts) - 1
 
@@ -190,6 +196,7 @@ var safeKeys = []string{
 	"lfs.gitprotocol",
 	"lfs.locksverify",
 	"lfs.pushurl",
+	"lfs.readurl",
 	"lfs.skipdownloaderrors",
 	"lfs.url",
 }
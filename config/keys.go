@@ -0,0 +1,249 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+// KeyKind is the kind of value which a configuration key takes.
+type KeyKind int
+
+const (
+	// StringKey is a key which takes any value.
+	StringKey KeyKind = iota
+	// BoolKey is a key which takes a boolean value.
+	BoolKey
+	// IntKey is a key which takes an integer value.
+	IntKey
+	// SizeKey is a key which takes a number of bytes, optionally with a
+	// unit, such as "10MB" or "1 GiB".
+	SizeKey
+	// BoolOrSizeKey is a key which takes either a boolean value or, like
+	// a SizeKey, a number of bytes.
+	BoolOrSizeKey
+)
+
+// KnownKey describes a configuration key which Git LFS reads.
+type KnownKey struct {
+	// Name is the name of the key.  A "*" in the name stands for a part
+	// of the key which varies, such as a URL, a remote, or the name of a
+	// custom transfer agent.
+	Name string
+	Kind KeyKind
+	// Values, if not empty, is the set of values which a StringKey may
+	// be given.
+	Values []string
+}
+
+// knownKeys are the configuration keys read by Git LFS, as documented in
+// git-lfs-config(5).  Keys with a "*" in their name are matched in order after
+// all other keys, so they are listed from the most to the least specific.
+var knownKeys = []*KnownKey{
+	{Name: "lfs.access", Values: accessValues},
+	{Name: "lfs.activitytimeout", Kind: IntKey},
+	{Name: "lfs.allowedoids"},
+	{Name: "lfs.allowincompletepush", Kind: BoolKey},
+	{Name: "lfs.autorepair", Kind: BoolKey},
+	{Name: "lfs.basictransfersonly", Kind: BoolKey},
+	{Name: "lfs.batchcompression", Kind: BoolKey},
+	{Name: "lfs.blockedoids"},
+	{Name: "lfs.cachecredentials", Kind: BoolKey},
+	{Name: "lfs.concurrenttransfers", Kind: IntKey},
+	{Name: "lfs.contenttransform.clean"},
+	{Name: "lfs.contenttransform.smudge"},
+	{Name: "lfs.contenttype", Kind: BoolKey},
+	{Name: "lfs.credentialhelper"},
+	{Name: "lfs.credentialsbackend"},
+	{Name: "lfs.customtransfer.dir"},
+	{Name: "lfs.defaulttokenttl", Kind: IntKey},
+	{Name: "lfs.deltatransfers", Kind: BoolKey},
+	{Name: "lfs.dialtimeout", Kind: IntKey},
+	{Name: "lfs.fetchexclude"},
+	{Name: "lfs.fetchinclude"},
+	{Name: "lfs.fetchrecentalways", Kind: BoolKey},
+	{Name: "lfs.fetchrecentcommitsdays", Kind: IntKey},
+	{Name: "lfs.fetchrecentrefsdays", Kind: IntKey},
+	{Name: "lfs.fetchrecentremoterefs", Kind: BoolKey},
+	{Name: "lfs.forceprogress", Kind: BoolKey},
+	{Name: "lfs.fsck.concurrency", Kind: IntKey},
+	{Name: "lfs.gitprotocol"},
+	{Name: "lfs.hashalgo", Values: []string{"sha256", "blake3"}},
+	{Name: "lfs.keepalive", Kind: IntKey},
+	{Name: "lfs.largefilerefuse", Kind: BoolKey},
+	{Name: "lfs.largefilewarning", Kind: BoolOrSizeKey},
+	{Name: "lfs.lockignoredfiles", Kind: BoolKey},
+	{Name: "lfs.locksverify", Kind: BoolKey},
+	{Name: "lfs.objectsharddepth", Kind: IntKey},
+	{Name: "lfs.pruneoffsetdays", Kind: IntKey},
+	{Name: "lfs.pruneremotetocheck"},
+	{Name: "lfs.pruneverifyremotealways", Kind: BoolKey},
+	{Name: "lfs.pruneverifyunreachablealways", Kind: BoolKey},
+	{Name: "lfs.pushurl"},
+	{Name: "lfs.readonlycachedir"},
+	{Name: "lfs.readurl"},
+	{Name: "lfs.remote.autodetect", Kind: BoolKey},
+	{Name: "lfs.remote.searchall", Kind: BoolKey},
+	{Name: "lfs.setlockablereadonly", Kind: BoolKey},
+	{Name: "lfs.skipdownloaderrors", Kind: BoolKey},
+	{Name: "lfs.skipsmudgepaths"},
+	{Name: "lfs.ssh.automultiplex", Kind: BoolKey},
+	{Name: "lfs.ssh.retries", Kind: IntKey},
+	{Name: "lfs.sshtransfer", Values: sshTransferValues},
+	{Name: "lfs.standalonetransferagent"},
+	{Name: "lfs.storage"},
+	{Name: "lfs.tlstimeout", Kind: IntKey},
+	{Name: "lfs.transfer.acceptencoding"},
+	{Name: "lfs.transfer.adaptercache", Kind: BoolKey},
+	{Name: "lfs.transfer.batchcache", Kind: BoolKey},
+	{Name: "lfs.transfer.batchtimeout", Kind: IntKey},
+	{Name: "lfs.transfer.chunksize", Kind: SizeKey},
+	{Name: "lfs.transfer.compression", Values: []string{"zstd", "none"}},
+	{Name: "lfs.transfer.enablehrefrewrite", Kind: BoolKey},
+	{Name: "lfs.transfer.http2", Kind: BoolKey},
+	{Name: "lfs.transfer.maxbytespersecond", Kind: SizeKey},
+	{Name: "lfs.transfer.maxidleconnsperhost", Kind: IntKey},
+	{Name: "lfs.transfer.maxretries", Kind: IntKey},
+	{Name: "lfs.transfer.maxretriesperobject", Kind: IntKey},
+	{Name: "lfs.transfer.maxretrydelay", Kind: IntKey},
+	{Name: "lfs.transfer.maxverifies", Kind: IntKey},
+	{Name: "lfs.transfer.presignedurls", Values: []string{"auto", "always", "never"}},
+	{Name: "lfs.transfer.retrybackoff", Kind: IntKey},
+	{Name: "lfs.transfer.retrydeadline", Kind: IntKey},
+	{Name: "lfs.transfer.sendref", Kind: BoolKey},
+	{Name: "lfs.transfer.stalltimeout", Kind: IntKey},
+	{Name: "lfs.tustransfers", Kind: BoolKey},
+	{Name: "lfs.url"},
+	{Name: "remote.lfsdefault"},
+	{Name: "remote.lfspushdefault"},
+
+	{Name: "lfs.customtransfer.*.args"},
+	{Name: "lfs.customtransfer.*.concurrent", Kind: BoolKey},
+	{Name: "lfs.customtransfer.*.direction", Values: []string{"download", "upload", "both"}},
+	{Name: "lfs.customtransfer.*.env"},
+	{Name: "lfs.customtransfer.*.path"},
+	{Name: "lfs.extension.*.clean"},
+	{Name: "lfs.extension.*.priority", Kind: IntKey},
+	{Name: "lfs.extension.*.smudge"},
+	{Name: "lfs.contenttype.*"},
+	{Name: "lfs.proxy.*"},
+	{Name: "lfs.*.access", Values: accessValues},
+	{Name: "lfs.*.activitytimeout", Kind: IntKey},
+	{Name: "lfs.*.batchcompression", Kind: BoolKey},
+	{Name: "lfs.*.concurrenttransfers", Kind: IntKey},
+	{Name: "lfs.*.contenttype", Kind: BoolKey},
+	{Name: "lfs.*.credentialhelper"},
+	{Name: "lfs.*.locksverify", Kind: BoolKey},
+	{Name: "lfs.*.sshtransfer", Values: sshTransferValues},
+	{Name: "remote.*.lfspushurl"},
+	{Name: "remote.*.lfsreadurl"},
+	{Name: "remote.*.lfsurl"},
+}
+
+var (
+	accessValues      = []string{"basic", "negotiate", "none", "private"}
+	sshTransferValues = []string{"always", "negotiate", "never"}
+)
+
+// LookupKey returns the description of the given configuration key, and true,
+// if it is one which Git LFS reads.  Otherwise, it returns nil and false.
+func LookupKey(key string) (*KnownKey, bool) {
+	key = foldKey(key)
+	for _, k := range knownKeys {
+		if !strings.Contains(k.Name, "*") && k.Name == key {
+			return k, true
+		}
+	}
+	for _, k := range knownKeys {
+		if prefix, suffix, ok := strings.Cut(k.Name, "*"); ok {
+			if len(key) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(key, prefix) &&
+				strings.HasSuffix(key, suffix) {
+				return k, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Normalize returns the given value for the key in its canonical form, or an
+// error if it is not a valid value for the key.  Boolean values are given as
+// "true" or "false", and sizes as a number of bytes.
+func (k *KnownKey) Normalize(value string) (string, error) {
+	switch k.Kind {
+	case BoolKey:
+		switch strings.ToLower(value) {
+		case "true", "1", "on", "yes", "t":
+			return "true", nil
+		case "false", "0", "off", "no", "f":
+			return "false", nil
+		}
+		return "", errors.New(tr.Tr.Get("expected a boolean value"))
+	case IntKey:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", errors.New(tr.Tr.Get("expected an integer value"))
+		}
+		return strconv.Itoa(n), nil
+	case SizeKey, BoolOrSizeKey:
+		if k.Kind == BoolOrSizeKey && isBool(value) {
+			return strconv.FormatBool(Bool(value, false)), nil
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			return "", errors.New(tr.Tr.Get("expected a size"))
+		}
+		n, err := humanize.ParseBytes(value)
+		if err != nil {
+			return "", errors.Wrap(err, tr.Tr.Get("expected a size"))
+		}
+		return strconv.FormatUint(n, 10), nil
+	}
+
+	if len(k.Values) == 0 {
+		return value, nil
+	}
+	for _, v := range k.Values {
+		if strings.EqualFold(v, value) {
+			return v, nil
+		}
+	}
+	return "", errors.New(tr.Tr.Get("expected one of: %s", strings.Join(k.Values, ", ")))
+}
+
+// IsLFSKey returns whether the given configuration key is used by Git LFS,
+// either because it is one Git LFS reads or because it is in the "lfs" section
+// or the configuration of the "lfs" filter.
+func IsLFSKey(key string) bool {
+	key = foldKey(key)
+	if strings.HasPrefix(key, "lfs.") || strings.HasPrefix(key, "filter.lfs.") {
+		return true
+	}
+	_, ok := LookupKey(key)
+	return ok
+}
+
+// IsSafeKey returns whether the given configuration key is read from a
+// .lfsconfig file, rather than being ignored.
+func IsSafeKey(key string) bool {
+	key = foldKey(key)
+	if !keyIsUnsafe(key) {
+		return true
+	}
+
+	parts := strings.Split(key, ".")
+	last := parts[len(parts)-1]
+	switch {
+	case len(parts) > 1 && parts[0] == "remote":
+		return len(parts) != 3 || last == "lfsurl"
+	case len(parts) > 2 && last == "access":
+		return true
+	case len(parts) == 4 && parts[0] == "lfs" && parts[1] == "extension":
+		return last == "priority"
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupKey(t *testing.T) {
+	for key, name := range map[string]string{
+		"lfs.url":                                 "lfs.url",
+		"LFS.ConcurrentTransfers":                 "lfs.concurrenttransfers",
+		"lfs.proxy.git.example.com":               "lfs.proxy.*",
+		"lfs.contenttype.psd":                     "lfs.contenttype.*",
+		"lfs.https://git.example.com.access":      "lfs.*.access",
+		"lfs.https://Git.Example.com.LocksVerify": "lfs.*.locksverify",
+		"lfs.customtransfer.agent.path":           "lfs.customtransfer.*.path",
+		"lfs.extension.foo.priority":              "lfs.extension.*.priority",
+		"remote.origin.lfsurl":                    "remote.*.lfsurl",
+	} {
+		k, ok := LookupKey(key)
+		require.True(t, ok, key)
+		assert.Equal(t, name, k.Name, key)
+	}
+
+	for _, key := range []string{"lfs.bogus", "lfs.access.extra.bogus", "lfs..access", "user.name", "remote.origin.url"} {
+		_, ok := LookupKey(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestKnownKeyNormalize(t *testing.T) {
+	for _, c := range []struct {
+		Key      string
+		Given    string
+		Expected string
+	}{
+		{"lfs.locksverify", "YES", "true"},
+		{"lfs.locksverify", "off", "false"},
+		{"lfs.concurrenttransfers", " 08", "8"},
+		{"lfs.transfer.maxbytespersecond", "10 MB", "10000000"},
+		{"lfs.transfer.chunksize", "1MiB", "1048576"},
+		{"lfs.hashalgo", "BLAKE3", "blake3"},
+		{"lfs.largefilewarning", "On", "true"},
+		{"lfs.largefilewarning", "10MB", "10000000"},
+		{"lfs.https://git.example.com.access", "Basic", "basic"},
+		{"lfs.url", "https://Example.com/LFS", "https://Example.com/LFS"},
+	} {
+		k, ok := LookupKey(c.Key)
+		require.True(t, ok, c.Key)
+		value, err := k.Normalize(c.Given)
+		require.Nil(t, err, c.Key)
+		assert.Equal(t, c.Expected, value, c.Key)
+	}
+
+	for key, value := range map[string]string{
+		"lfs.locksverify":                "maybe",
+		"lfs.concurrenttransfers":        "many",
+		"lfs.transfer.maxbytespersecond": "",
+		"lfs.transfer.chunksize":         "10 parsecs",
+		"lfs.transfer.compression":       "gzip",
+		"lfs.largefilewarning":           "huge",
+	} {
+		k, ok := LookupKey(key)
+		require.True(t, ok, key)
+		_, err := k.Normalize(value)
+		assert.NotNil(t, err, key)
+	}
+}
+
+func TestIsSafeKey(t *testing.T) {
+	for _, key := range []string{"lfs.url", "lfs.FetchInclude", "remote.origin.lfsurl", "lfs.https://git.example.com.access", "lfs.extension.foo.priority"} {
+		assert.True(t, IsSafeKey(key), key)
+	}
+	for _, key := range []string{"lfs.storage", "core.askpass", "remote.origin.url", "lfs.extension.foo.clean"} {
+		assert.False(t, IsSafeKey(key), key)
+	}
+}
+
+func TestIsLFSKey(t *testing.T) {
+	for _, key := range []string{"lfs.bogus", "filter.lfs.process", "remote.origin.lfspushurl", "remote.lfsdefault"} {
+		assert.True(t, IsLFSKey(key), key)
+	}
+	for _, key := range []string{"user.name", "remote.origin.url", "filter.other.clean"} {
+		assert.False(t, IsLFSKey(key), key)
+	}
+}
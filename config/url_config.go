@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"regexp"
 	"strings"
@@ -38,6 +39,21 @@ func (c *URLConfig) Get(prefix, rawurl, key string) (string, bool) {
 	return c.git.Get(strings.Join([]string{prefix, key}, "."))
 }
 
+// GetSpecific retrieves a `http.{url}.{key}` for the given key and urls like
+// Get, but without falling back to the value for `http.{key}`.
+func (c *URLConfig) GetSpecific(prefix, rawurl, key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	key = strings.ToLower(key)
+	prefix = strings.ToLower(prefix)
+	if v := c.getAll(prefix, rawurl, key); len(v) > 0 {
+		return v[len(v)-1], true
+	}
+	return "", false
+}
+
 func (c *URLConfig) GetAll(prefix, rawurl, key string) []string {
 	if c == nil {
 		return nil
@@ -186,8 +202,8 @@ func portForURL(u *url.URL) string {
 // a match. It returns an integer indicating the strength of the match, or 0 if
 // the two hostnames did not match.
 func compareHosts(searchHostname, configHostname string) int {
-	searchHost := strings.Split(searchHostname, ".")
-	configHost := strings.Split(configHostname, ".")
+	searchHost := strings.Split(normalizeHost(searchHostname), ".")
+	configHost := strings.Split(normalizeHost(configHostname), ".")
 
 	if len(searchHost) != len(configHost) {
 		return 0
@@ -209,6 +225,22 @@ func compareHosts(searchHostname, configHostname string) int {
 	return score
 }
 
+// normalizeHost returns the canonical form of a hostname which is an IP
+// address, so that the different ways of writing an IPv6 address match, or
+// otherwise returns the hostname unchanged. A zone identifier, as in
+// "fe80::1%eth0", is preserved.
+func normalizeHost(hostname string) string {
+	addr, zone, hasZone := strings.Cut(hostname, "%")
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return hostname
+	}
+	if hasZone {
+		return ip.String() + "%" + zone
+	}
+	return ip.String()
+}
+
 // comparePaths compares a path with a configuration path to determine a match.
 // It returns an integer indicating the strength of the match, or 0 if the two
 // paths did not match.
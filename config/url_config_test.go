@@ -72,3 +72,52 @@ func TestURLConfig(t *testing.T) {
 		assert.Equal(t, expected, values, "get all: "+rawurl)
 	}
 }
+
+func TestURLConfigIPv6Hosts(t *testing.T) {
+	u := NewURLConfig(EnvironmentOf(MapFetcher(map[string][]string{
+		"http.https://[2001:db8::1].key":                []string{"host"},
+		"http.https://[2001:db8::1]:8080.key":           []string{"port"},
+		"http.https://[fe80::1%25eth0]:8080/repo.key":   []string{"zone"},
+		"http.https://[fe80::1%25eth1]:8080/repo.key":   []string{"other zone"},
+		"http.https://user@[2001:db8::1]/user-repo.key": []string{"user"},
+	})))
+
+	getOne := map[string]string{
+		"https://[2001:db8::1]/repo":                      "host",
+		"https://[2001:db8:0:0:0:0:0:1]/repo":             "host",
+		"https://[2001:DB8::1]:443/repo":                  "host",
+		"https://[2001:db8::1]:8080/repo":                 "port",
+		"https://[fe80::1%25eth0]:8080/repo.git/info/lfs": "zone",
+		"https://[fe80:0::1%25eth0]:8080/repo":            "zone",
+		"https://[fe80::1%25eth1]:8080/repo":              "other zone",
+		"https://[fe80::1%25eth2]:8080/repo":              "",
+		"https://[fe80::1]:8080/repo":                     "",
+		"https://user:pass@[2001:db8::1]/user-repo":       "user",
+		"https://[2001:db8::2]/repo":                      "",
+		"https://[2001:db8::1%25eth0]:8080/anything":      "",
+	}
+
+	for rawurl, expected := range getOne {
+		value, _ := u.Get("http", rawurl, "key")
+		assert.Equal(t, expected, value, "get one: "+rawurl)
+	}
+}
+
+func TestURLConfigGetSpecific(t *testing.T) {
+	u := NewURLConfig(EnvironmentOf(MapFetcher(map[string][]string{
+		"http.key":                  []string{"root"},
+		"http.https://host.com.key": []string{"host", "host-2"},
+	})))
+
+	value, ok := u.GetSpecific("http", "https://host.com/a/b/c", "key")
+	assert.True(t, ok)
+	assert.Equal(t, "host-2", value)
+
+	value, ok = u.GetSpecific("http", "https://root.com/a/b/c", "key")
+	assert.False(t, ok)
+	assert.Equal(t, "", value)
+
+	value, ok = u.Get("http", "https://root.com/a/b/c", "key")
+	assert.True(t, ok)
+	assert.Equal(t, "root", value)
+}
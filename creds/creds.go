@@ -80,6 +80,7 @@ type CredentialHelperContext struct {
 	commandCredHelper *commandCredentialHelper
 	askpassCredHelper *AskPassCredentialHelper
 	cachingCredHelper *credentialCacher
+	nativeCredHelper  *nativeCredentialHelper
 
 	urlConfig      *config.URLConfig
 	wwwAuthHeaders []string
@@ -115,6 +116,10 @@ func NewCredentialHelperContext(gitEnv config.Environment, osEnv config.Environm
 		c.cachingCredHelper = NewCredentialCacher()
 	}
 
+	if backend, _ := gitEnv.Get("lfs.credentialsbackend"); backend == "native" {
+		c.nativeCredHelper = newNativeCredentialHelper()
+	}
+
 	c.commandCredHelper = &commandCredentialHelper{
 		SkipPrompt: osEnv.Bool("GIT_TERMINAL_PROMPT", false),
 	}
@@ -136,7 +141,9 @@ func (ctxt *CredentialHelperContext) SetStateFields(fields []string) {
 // It returns an error if any configuration was invalid, or otherwise
 // un-useable.
 func (ctxt *CredentialHelperContext) GetCredentialHelper(helper CredentialHelper, u *url.URL) CredentialHelperWrapper {
-	rawurl := fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, u.Path)
+	// Build the URL with net/url, which percent-encodes the zone
+	// identifier of an IPv6 literal host so the URL can be parsed again.
+	rawurl := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
 	input := Creds{"protocol": []string{u.Scheme}, "host": []string{u.Host}}
 	if u.User != nil && u.User.Username() != "" {
 		input["username"] = []string{u.User.Username()}
@@ -155,13 +162,19 @@ func (ctxt *CredentialHelperContext) GetCredentialHelper(helper CredentialHelper
 		return CredentialHelperWrapper{CredentialHelper: helper, Input: input, Url: u}
 	}
 
-	helpers := make([]CredentialHelper, 0, 4)
+	helpers := make([]CredentialHelper, 0, 6)
 	if ctxt.netrcCredHelper != nil {
 		helpers = append(helpers, ctxt.netrcCredHelper)
 	}
 	if ctxt.cachingCredHelper != nil {
 		helpers = append(helpers, ctxt.cachingCredHelper)
 	}
+	if helper, _ := ctxt.urlConfig.Get("lfs", rawurl, "credentialhelper"); len(helper) > 0 {
+		helpers = append(helpers, newLfsCredentialHelper(helper))
+	}
+	if ctxt.nativeCredHelper != nil {
+		helpers = append(helpers, ctxt.nativeCredHelper)
+	}
 	if ctxt.askpassCredHelper != nil {
 		helper, _ := ctxt.urlConfig.Get("credential", rawurl, "helper")
 		if len(helper) == 0 {
@@ -374,8 +387,14 @@ func (h *commandCredentialHelper) exec(subcommand string, input Creds) (Creds, e
 		return nil, errors.New(tr.Tr.Get("`git credential %s` error: %s", subcommand, err.Error()))
 	}
 
+	return parseCreds(output.String()), nil
+}
+
+// parseCreds parses the output of a credential helper, which consists of lines
+// of "key=value" pairs.
+func parseCreds(output string) Creds {
 	creds := make(Creds)
-	for _, line := range strings.Split(output.String(), "\n") {
+	for _, line := range strings.Split(output, "\n") {
 		pieces := strings.SplitN(line, "=", 2)
 		if len(pieces) < 2 || len(pieces[1]) < 1 {
 			continue
@@ -387,7 +406,7 @@ func (h *commandCredentialHelper) exec(subcommand string, input Creds) (Creds, e
 		}
 	}
 
-	return creds, nil
+	return creds
 }
 
 type credentialCacher struct {
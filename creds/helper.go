@@ -0,0 +1,137 @@
+package creds
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/subprocess"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+)
+
+// helperCredsSource is the value of the "source" key of credentials filled by
+// an lfsCredentialHelper, so that only those credentials are approved or
+// rejected with the same helper.
+const helperCredsSource = "lfs.credentialhelper"
+
+// lfsCredentialHelper implements the CredentialHelper type for the program
+// given by the "lfs.credentialhelper" configuration value, which is consulted
+// for credentials before Git's own credential helpers. The program speaks the
+// same protocol as a Git credential helper, and is specified in the same way:
+// see https://git-scm.com/docs/gitcredentials#_custom_helpers.
+type lfsCredentialHelper struct {
+	Helper string
+}
+
+func newLfsCredentialHelper(helper string) *lfsCredentialHelper {
+	return &lfsCredentialHelper{Helper: helper}
+}
+
+// Fill implements CredentialHelper.Fill by running the helper with the "get"
+// action. If the helper does not provide a password or other credential, the
+// next CredentialHelper is consulted.
+func (h *lfsCredentialHelper) Fill(what Creds) (Creds, error) {
+	tracerx.Printf("creds: lfs.credentialhelper get (%q, %q, %q)",
+		FirstEntryForKey(what, "protocol"),
+		FirstEntryForKey(what, "host"),
+		FirstEntryForKey(what, "path"))
+
+	creds, err := h.exec("get", what)
+	if err != nil {
+		return nil, err
+	}
+	if len(FirstEntryForKey(creds, "password")) == 0 && len(FirstEntryForKey(creds, "credential")) == 0 {
+		return nil, credHelperNoOp
+	}
+
+	// As with Git, any attributes which the helper does not return are
+	// taken from the request.
+	for k, v := range what {
+		if _, ok := creds[k]; !ok && !strings.HasSuffix(k, "[]") {
+			creds[k] = v
+		}
+	}
+	creds["source"] = []string{helperCredsSource}
+	return creds, nil
+}
+
+// Approve implements CredentialHelper.Approve by running the helper with the
+// "store" action, if the credentials were filled by the helper.
+func (h *lfsCredentialHelper) Approve(what Creds) error {
+	if FirstEntryForKey(what, "source") != helperCredsSource {
+		return credHelperNoOp
+	}
+
+	tracerx.Printf("creds: lfs.credentialhelper store (%q, %q, %q)",
+		FirstEntryForKey(what, "protocol"),
+		FirstEntryForKey(what, "host"),
+		FirstEntryForKey(what, "path"))
+	_, err := h.exec("store", what)
+	return err
+}
+
+// Reject implements CredentialHelper.Reject by running the helper with the
+// "erase" action, if the credentials were filled by the helper.
+func (h *lfsCredentialHelper) Reject(what Creds) error {
+	if FirstEntryForKey(what, "source") != helperCredsSource {
+		return credHelperNoOp
+	}
+
+	tracerx.Printf("creds: lfs.credentialhelper erase (%q, %q, %q)",
+		FirstEntryForKey(what, "protocol"),
+		FirstEntryForKey(what, "host"),
+		FirstEntryForKey(what, "path"))
+	_, err := h.exec("erase", what)
+	return err
+}
+
+// command returns the shell command which runs the helper, which, as with
+// Git, may be a shell snippet prefixed with "!", an absolute path, or the name
+// of a "git credential-<name>" program, any of which may be followed by
+// arguments.
+func (h *lfsCredentialHelper) command() string {
+	switch {
+	case strings.HasPrefix(h.Helper, "!"):
+		return h.Helper[1:]
+	case isAbsHelperPath(h.Helper):
+		return h.Helper
+	default:
+		return "git credential-" + h.Helper
+	}
+}
+
+func (h *lfsCredentialHelper) exec(action string, input Creds) (Creds, error) {
+	in := make(Creds, len(input))
+	for k, v := range input {
+		if k != "source" {
+			in[k] = v
+		}
+	}
+
+	output := new(bytes.Buffer)
+	name, args := subprocess.FormatForShell(h.command(), action)
+	cmd, err := subprocess.ExecCommand(name, args...)
+	if err != nil {
+		return nil, errors.New(tr.Tr.Get("failed to find credential helper %q: %v", h.Helper, err))
+	}
+	cmd.Stdin = bufferCreds(in)
+	cmd.Stdout = output
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(tr.Tr.Get("credential helper %q %s error: %s", h.Helper, action, err.Error()))
+	}
+	return parseCreds(output.String()), nil
+}
+
+// isAbsHelperPath returns whether the given helper is specified by an absolute
+// path, which Git recognizes by a leading slash or backslash, or a drive
+// letter.
+func isAbsHelperPath(helper string) bool {
+	if strings.HasPrefix(helper, "/") || strings.HasPrefix(helper, `\`) {
+		return true
+	}
+	return len(helper) > 2 && helper[1] == ':' && (helper[2] == '/' || helper[2] == '\\')
+}
@@ -0,0 +1,64 @@
+package creds
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLfsCredentialHelperCommand(t *testing.T) {
+	for helper, expected := range map[string]string{
+		"store --file=creds": "git credential-store --file=creds",
+		"!f() { echo; }; f":  "f() { echo; }; f",
+		"/usr/bin/helper -v": "/usr/bin/helper -v",
+		`C:\bin\helper.exe`:  `C:\bin\helper.exe`,
+	} {
+		assert.Equal(t, expected, newLfsCredentialHelper(helper).command(), helper)
+	}
+}
+
+func TestLfsCredentialHelperFillAndApprove(t *testing.T) {
+	log := filepath.ToSlash(filepath.Join(t.TempDir(), "helper.log"))
+	helper := newLfsCredentialHelper("!f() { echo \"$1\" >>" + log + "; " +
+		"test \"$1\" = get && echo username=user && echo password=pass; true; }; f")
+	other := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{helper, other})
+
+	input := Creds{"protocol": []string{"https"}, "host": []string{"example.com"}}
+	creds, err := helpers.Fill(input)
+	require.Nil(t, err)
+	assert.Equal(t, "user", FirstEntryForKey(creds, "username"))
+	assert.Equal(t, "pass", FirstEntryForKey(creds, "password"))
+	assert.Equal(t, "example.com", FirstEntryForKey(creds, "host"))
+	assert.Equal(t, helperCredsSource, FirstEntryForKey(creds, "source"))
+	assert.Equal(t, 0, len(other.fill))
+
+	require.Nil(t, helpers.Approve(creds))
+	assert.Equal(t, 0, len(other.approve))
+	require.Nil(t, helpers.Reject(creds))
+	assert.Equal(t, 0, len(other.reject))
+
+	by, err := os.ReadFile(log)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"get", "store", "erase"}, strings.Fields(string(by)))
+}
+
+func TestLfsCredentialHelperFallsBack(t *testing.T) {
+	helper := newLfsCredentialHelper("!true")
+	other := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{helper, other})
+
+	input := Creds{"protocol": []string{"https"}, "host": []string{"example.com"}}
+	creds, err := helpers.Fill(input)
+	require.Nil(t, err)
+	assert.Equal(t, input, creds)
+	assert.Equal(t, 1, len(other.fill))
+
+	// Credentials filled by another helper are not stored by this one.
+	require.Nil(t, helpers.Approve(creds))
+	assert.Equal(t, 1, len(other.approve))
+}
@@ -0,0 +1,126 @@
+package creds
+
+import (
+	"github.com/rubyist/tracerx"
+)
+
+// nativeCredential is a credential as it is kept in the platform's credential
+// store, identified by its protocol, host, path, and, optionally, username.
+type nativeCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// nativeStore is the platform's credential store, such as the macOS Keychain
+// or the Windows Credential Manager.
+type nativeStore interface {
+	// Get returns the stored credential matching c, ignoring its
+	// password, or nil if there is none.
+	Get(c nativeCredential) (*nativeCredential, error)
+	// Set stores c, replacing any stored credential matching it.
+	Set(c nativeCredential) error
+	// Delete removes any stored credential matching c.
+	Delete(c nativeCredential) error
+}
+
+// nativeCredentialHelper implements the CredentialHelper type by reading and
+// storing credentials in the platform's credential store directly, rather than
+// by running a Git credential helper, when "lfs.credentialsbackend" is set to
+// "native". If no credential is stored for a URL, or the store cannot be used,
+// the next CredentialHelper is consulted.
+type nativeCredentialHelper struct {
+	store nativeStore
+}
+
+// newNativeCredentialHelper returns a nativeCredentialHelper for the
+// platform's credential store, or nil if the platform has none which Git LFS
+// supports.
+func newNativeCredentialHelper() *nativeCredentialHelper {
+	store, err := newNativeStore()
+	if err != nil {
+		tracerx.Printf("creds: native credential store unavailable, using Git credential helpers: %v", err)
+		return nil
+	}
+	return &nativeCredentialHelper{store: store}
+}
+
+func nativeCredentialFor(what Creds) (nativeCredential, bool) {
+	c := nativeCredential{
+		Protocol: FirstEntryForKey(what, "protocol"),
+		Host:     FirstEntryForKey(what, "host"),
+		Path:     FirstEntryForKey(what, "path"),
+		Username: FirstEntryForKey(what, "username"),
+		Password: FirstEntryForKey(what, "password"),
+	}
+	return c, len(c.Protocol) > 0 && len(c.Host) > 0
+}
+
+// Fill implements CredentialHelper.Fill by looking up the credential in the
+// platform's credential store.
+func (h *nativeCredentialHelper) Fill(what Creds) (Creds, error) {
+	c, ok := nativeCredentialFor(what)
+	if !ok {
+		return nil, credHelperNoOp
+	}
+
+	found, err := h.store.Get(c)
+	if err != nil {
+		tracerx.Printf("creds: native credential store error: %v", err)
+		return nil, credHelperNoOp
+	}
+	if found == nil || len(found.Password) == 0 {
+		return nil, credHelperNoOp
+	}
+
+	tracerx.Printf("creds: native credential store (%q, %q, %q)", c.Protocol, c.Host, c.Path)
+
+	creds := Creds{
+		"protocol": []string{c.Protocol},
+		"host":     []string{c.Host},
+		"username": []string{found.Username},
+		"password": []string{found.Password},
+	}
+	if len(c.Path) > 0 {
+		creds["path"] = []string{c.Path}
+	}
+	return creds, nil
+}
+
+// Approve implements CredentialHelper.Approve by storing the credential in the
+// platform's credential store, so that later commands need not run a Git
+// credential helper to find it. The next CredentialHelper is always asked to
+// approve it as well, so that Git's configured credential helper also stores
+// it.
+func (h *nativeCredentialHelper) Approve(what Creds) error {
+	c, ok := nativeCredentialFor(what)
+	if !ok || len(c.Password) == 0 {
+		return credHelperNoOp
+	}
+
+	// Avoid rewriting an unchanged credential, since some stores ask
+	// the user to confirm each change.
+	if found, err := h.store.Get(c); err == nil && found != nil &&
+		found.Username == c.Username && found.Password == c.Password {
+		return credHelperNoOp
+	}
+
+	if err := h.store.Set(c); err != nil {
+		tracerx.Printf("creds: unable to store credential in native credential store: %v", err)
+	}
+	return credHelperNoOp
+}
+
+// Reject implements CredentialHelper.Reject by removing the credential from
+// the platform's credential store. The next CredentialHelper is always asked
+// to reject it as well, in case it was filled by a Git credential helper.
+func (h *nativeCredentialHelper) Reject(what Creds) error {
+	if c, ok := nativeCredentialFor(what); ok {
+		if err := h.store.Delete(c); err != nil {
+			tracerx.Printf("creds: unable to remove credential from native credential store: %v", err)
+		}
+	}
+	return credHelperNoOp
+}
@@ -0,0 +1,217 @@
+//go:build darwin
+// +build darwin
+
+package creds
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/subprocess"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+const (
+	// securityPath is the location of the security(1) tool, which
+	// manages the Keychain.
+	securityPath = "/usr/bin/security"
+
+	// securityItemNotFound is the exit status of security(1) when no
+	// matching Keychain item exists.
+	securityItemNotFound = 44
+
+	// securityMaxCommandLen is the longest command which security(1)
+	// reads in interactive mode.
+	securityMaxCommandLen = 4096
+)
+
+// keychainStore is a nativeStore backed by the macOS Keychain. Credentials
+// are stored as Internet password items with the same attributes as those
+// stored by git-credential-osxkeychain, so that it may be used to manage
+// them. The items are read and written by running security(1), which, unlike
+// the Security framework itself, may be used without cgo.
+type keychainStore struct{}
+
+func newNativeStore() (nativeStore, error) {
+	if _, err := os.Stat(securityPath); err != nil {
+		return nil, err
+	}
+	return &keychainStore{}, nil
+}
+
+// keychainProtocol returns the Keychain protocol code for the given URL
+// scheme, or an empty string if the Keychain has none.
+func keychainProtocol(protocol string) string {
+	switch protocol {
+	case "https":
+		return "htps"
+	case "http":
+		return "http"
+	default:
+		return ""
+	}
+}
+
+// keychainArgs returns the security(1) options which select the Keychain
+// item for c, and whether c may be kept in the Keychain at all.
+func keychainArgs(c nativeCredential) ([]string, bool) {
+	protocol := keychainProtocol(c.Protocol)
+	if len(protocol) == 0 {
+		return nil, false
+	}
+
+	host := c.Host
+	args := []string{"-r", protocol}
+	if h, port, err := net.SplitHostPort(c.Host); err == nil {
+		host = h
+		if _, err := strconv.Atoi(port); err == nil {
+			args = append(args, "-P", port)
+		}
+	}
+	args = append(args, "-s", host)
+	if len(c.Path) > 0 {
+		args = append(args, "-p", c.Path)
+	}
+	if len(c.Username) > 0 {
+		args = append(args, "-a", c.Username)
+	}
+	return args, true
+}
+
+func (s *keychainStore) Get(c nativeCredential) (*nativeCredential, error) {
+	args, ok := keychainArgs(c)
+	if !ok {
+		return nil, nil
+	}
+
+	// With -g, the item's attributes are written to stdout, and its
+	// password to stderr.
+	cmd, err := subprocess.ExecCommand(securityPath, append([]string{"find-internet-password", "-g"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == securityItemNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, tr.Tr.Get("security find-internet-password: %s", strings.TrimSpace(stderr.String())))
+	}
+
+	found := &nativeCredential{
+		Protocol: c.Protocol,
+		Host:     c.Host,
+		Path:     c.Path,
+		Username: c.Username,
+	}
+	if len(found.Username) == 0 {
+		found.Username = parseKeychainAttribute(stdout.String(), `"acct"<blob>=`)
+	}
+	found.Password = parseKeychainAttribute(stderr.String(), "password: ")
+	return found, nil
+}
+
+func (s *keychainStore) Set(c nativeCredential) error {
+	args, ok := keychainArgs(c)
+	if !ok {
+		return nil
+	}
+	if strings.ContainsAny(c.Password, "\n\r") {
+		return errors.New(tr.Tr.Get("credential password contains a newline"))
+	}
+
+	// Give the command to security(1) on its standard input, rather than
+	// on its command line, so that the password is never visible to
+	// other processes.
+	args = append(args, "-w", c.Password)
+	for i, arg := range args {
+		args[i] = quoteKeychainArg(arg)
+	}
+	command := "add-internet-password -U " + strings.Join(args, " ") + "\n"
+	if len(command) > securityMaxCommandLen {
+		return errors.New(tr.Tr.Get("credential is too long to store in the Keychain"))
+	}
+
+	cmd, err := subprocess.ExecCommand(securityPath, "-i")
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(command)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, tr.Tr.Get("security add-internet-password: %s", strings.TrimSpace(stderr.String())))
+	}
+	if stderr.Len() > 0 {
+		return errors.New(tr.Tr.Get("security add-internet-password: %s", strings.TrimSpace(stderr.String())))
+	}
+	return nil
+}
+
+func (s *keychainStore) Delete(c nativeCredential) error {
+	args, ok := keychainArgs(c)
+	if !ok {
+		return nil
+	}
+
+	cmd, err := subprocess.ExecCommand(securityPath, append([]string{"delete-internet-password"}, args...)...)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = &bytes.Buffer{}
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == securityItemNotFound {
+			return nil
+		}
+		return errors.Wrap(err, tr.Tr.Get("security delete-internet-password: %s", strings.TrimSpace(stderr.String())))
+	}
+	return nil
+}
+
+// quoteKeychainArg quotes s for the command parser of security(1)'s
+// interactive mode, which splits words in the same way as sh.
+func quoteKeychainArg(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+// parseKeychainAttribute returns the value of the first line of the given
+// security(1) output which begins, after any indentation, with prefix.
+// Values which security(1) prints as text are quoted, and all others are
+// printed in hexadecimal, prefixed with "0x" and followed by their quoted and
+// escaped text; an attribute without a value is printed as "<NULL>".
+func parseKeychainAttribute(output, prefix string) string {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		value := line[len(prefix):]
+		if strings.HasPrefix(value, "0x") {
+			digits := value[2:]
+			if i := strings.IndexByte(digits, ' '); i >= 0 {
+				digits = digits[:i]
+			}
+			if by, err := hex.DecodeString(digits); err == nil {
+				return string(by)
+			}
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			return value[1 : len(value)-1]
+		}
+		return ""
+	}
+	return ""
+}
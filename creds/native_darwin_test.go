@@ -0,0 +1,49 @@
+//go:build darwin
+// +build darwin
+
+package creds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeychainArgs(t *testing.T) {
+	args, ok := keychainArgs(nativeCredential{
+		Protocol: "https",
+		Host:     "git-server.com:8443",
+		Path:     "repo.git",
+		Username: "monalisa",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"-r", "htps", "-P", "8443", "-s", "git-server.com", "-p", "repo.git", "-a", "monalisa"}, args)
+
+	args, ok = keychainArgs(nativeCredential{Protocol: "http", Host: "git-server.com"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"-r", "http", "-s", "git-server.com"}, args)
+
+	_, ok = keychainArgs(nativeCredential{Protocol: "ssh", Host: "git-server.com"})
+	assert.False(t, ok)
+}
+
+func TestQuoteKeychainArg(t *testing.T) {
+	assert.Equal(t, `'secret'`, quoteKeychainArg("secret"))
+	assert.Equal(t, `'it'"'"'s'`, quoteKeychainArg("it's"))
+}
+
+func TestParseKeychainAttribute(t *testing.T) {
+	attrs := `keychain: "/Users/monalisa/Library/Keychains/login.keychain-db"
+class: "inet"
+attributes:
+    "acct"<blob>="monalisa"
+    "ptcl"<uint32>="htps"
+    "srvr"<blob>="git-server.com"
+`
+	assert.Equal(t, "monalisa", parseKeychainAttribute(attrs, `"acct"<blob>=`))
+	assert.Equal(t, "", parseKeychainAttribute(attrs, `"path"<blob>=`))
+	assert.Equal(t, "", parseKeychainAttribute(`    "acct"<blob>=<NULL>`, `"acct"<blob>=`))
+
+	assert.Equal(t, "secret", parseKeychainAttribute(`password: "secret"`, "password: "))
+	assert.Equal(t, "sécret", parseKeychainAttribute(`password: 0x73C3A963726574  "s\303\251cret"`, "password: "))
+}
@@ -0,0 +1,13 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package creds
+
+import (
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+func newNativeStore() (nativeStore, error) {
+	return nil, errors.New(tr.Tr.Get("no native credential store is supported on this platform"))
+}
@@ -0,0 +1,160 @@
+package creds
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNativeStore struct {
+	creds map[string]nativeCredential
+	err   error
+	sets  int
+}
+
+func newTestNativeStore() *testNativeStore {
+	return &testNativeStore{creds: make(map[string]nativeCredential)}
+}
+
+func (s *testNativeStore) key(c nativeCredential) string {
+	return c.Protocol + "://" + c.Host + "/" + c.Path
+}
+
+func (s *testNativeStore) Get(c nativeCredential) (*nativeCredential, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	found, ok := s.creds[s.key(c)]
+	if !ok || (len(c.Username) > 0 && c.Username != found.Username) {
+		return nil, nil
+	}
+	return &found, nil
+}
+
+func (s *testNativeStore) Set(c nativeCredential) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sets++
+	s.creds[s.key(c)] = c
+	return nil
+}
+
+func (s *testNativeStore) Delete(c nativeCredential) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.creds, s.key(c))
+	return nil
+}
+
+func TestNativeCredHelperFill(t *testing.T) {
+	store := newTestNativeStore()
+	store.creds["https://git-server.com/repo.git"] = nativeCredential{
+		Protocol: "https",
+		Host:     "git-server.com",
+		Path:     "repo.git",
+		Username: "monalisa",
+		Password: "secret",
+	}
+	native := &nativeCredentialHelper{store: store}
+	next := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{native, next})
+
+	creds, err := helpers.Fill(Creds{
+		"protocol": []string{"https"},
+		"host":     []string{"git-server.com"},
+		"path":     []string{"repo.git"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "monalisa", FirstEntryForKey(creds, "username"))
+	assert.Equal(t, "secret", FirstEntryForKey(creds, "password"))
+	assert.Equal(t, "repo.git", FirstEntryForKey(creds, "path"))
+	assert.Equal(t, 0, len(next.fill))
+
+	// A credential for another path, or another user, falls through to
+	// the next helper.
+	_, err = helpers.Fill(Creds{
+		"protocol": []string{"https"},
+		"host":     []string{"git-server.com"},
+		"path":     []string{"other.git"},
+	})
+	require.Nil(t, err)
+	_, err = helpers.Fill(Creds{
+		"protocol": []string{"https"},
+		"host":     []string{"git-server.com"},
+		"path":     []string{"repo.git"},
+		"username": []string{"hubot"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(next.fill))
+}
+
+func TestNativeCredHelperFillStoreError(t *testing.T) {
+	store := newTestNativeStore()
+	store.err = errors.New("locked")
+	native := &nativeCredentialHelper{store: store}
+	next := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{native, next})
+
+	input := Creds{"protocol": []string{"https"}, "host": []string{"git-server.com"}}
+	creds, err := helpers.Fill(input)
+	require.Nil(t, err)
+	assert.Equal(t, input, creds)
+	assert.Equal(t, 1, len(next.fill))
+
+	// A failure to store the credential lets the next helper approve it.
+	input["username"] = []string{"monalisa"}
+	input["password"] = []string{"secret"}
+	require.Nil(t, helpers.Approve(input))
+	assert.Equal(t, 1, len(next.approve))
+}
+
+func TestNativeCredHelperApprove(t *testing.T) {
+	store := newTestNativeStore()
+	native := &nativeCredentialHelper{store: store}
+	next := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{native, next})
+
+	creds := Creds{
+		"protocol": []string{"https"},
+		"host":     []string{"git-server.com:8443"},
+		"username": []string{"monalisa"},
+		"password": []string{"secret"},
+	}
+	require.Nil(t, helpers.Approve(creds))
+	require.Nil(t, helpers.Approve(creds))
+	assert.Equal(t, 1, store.sets)
+
+	// Git's credential helper is asked to store the credential too.
+	assert.Equal(t, 2, len(next.approve))
+
+	stored, ok := store.creds["https://git-server.com:8443/"]
+	require.True(t, ok)
+	assert.Equal(t, "monalisa", stored.Username)
+	assert.Equal(t, "secret", stored.Password)
+}
+
+func TestNativeCredHelperReject(t *testing.T) {
+	store := newTestNativeStore()
+	store.creds["https://git-server.com/"] = nativeCredential{
+		Protocol: "https",
+		Host:     "git-server.com",
+		Username: "monalisa",
+		Password: "secret",
+	}
+	native := &nativeCredentialHelper{store: store}
+	next := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{native, next})
+
+	require.Nil(t, helpers.Reject(Creds{
+		"protocol": []string{"https"},
+		"host":     []string{"git-server.com"},
+		"username": []string{"monalisa"},
+		"password": []string{"secret"},
+	}))
+	assert.Equal(t, 0, len(store.creds))
+	assert.Equal(t, 1, len(next.reject))
+}
@@ -0,0 +1,157 @@
+//go:build windows
+// +build windows
+
+package creds
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modadvapi32     = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW   = modadvapi32.NewProc("CredReadW")
+	procCredWriteW  = modadvapi32.NewProc("CredWriteW")
+	procCredDeleteW = modadvapi32.NewProc("CredDeleteW")
+	procCredFree    = modadvapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+// winCredential mirrors the CREDENTIALW structure from wincred.h.
+type winCredential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// winCredStore is a nativeStore backed by the Windows Credential Manager.
+// Credentials are stored as generic credentials named, and with their
+// passwords encoded as UTF-8, in the same way as those stored by Git
+// Credential Manager, so that it may be used to manage them.  Passwords
+// encoded as UTF-16, as git-credential-wincred stores them, may also be read.
+type winCredStore struct{}
+
+func newNativeStore() (nativeStore, error) {
+	if err := procCredReadW.Find(); err != nil {
+		return nil, err
+	}
+	return &winCredStore{}, nil
+}
+
+func winCredTarget(c nativeCredential) (*uint16, error) {
+	target := "git:" + c.Protocol + "://" + c.Host
+	if len(c.Path) > 0 {
+		target += "/" + c.Path
+	}
+	return windows.UTF16PtrFromString(target)
+}
+
+func (s *winCredStore) Get(c nativeCredential) (*nativeCredential, error) {
+	target, err := winCredTarget(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *winCredential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "CredReadW")
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	found := &nativeCredential{
+		Protocol: c.Protocol,
+		Host:     c.Host,
+		Path:     c.Path,
+		Username: windows.UTF16PtrToString(cred.UserName),
+	}
+	if len(c.Username) > 0 && c.Username != found.Username {
+		return nil, nil
+	}
+
+	if cred.CredentialBlobSize > 0 {
+		blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+		found.Password = decodeCredentialBlob(blob)
+	}
+	return found, nil
+}
+
+// decodeCredentialBlob returns the password stored in the given credential
+// blob, which is encoded as UTF-8, or, if it contains NUL bytes, which no
+// UTF-8 password does, as UTF-16.
+func decodeCredentialBlob(blob []byte) string {
+	if len(blob)%2 != 0 || bytes.IndexByte(blob, 0) < 0 {
+		return string(blob)
+	}
+	u := make([]uint16, len(blob)/2)
+	for i := range u {
+		u[i] = uint16(blob[2*i]) | uint16(blob[2*i+1])<<8
+	}
+	return string(utf16.Decode(u))
+}
+
+func (s *winCredStore) Set(c nativeCredential) error {
+	target, err := winCredTarget(c)
+	if err != nil {
+		return err
+	}
+	username, err := windows.UTF16PtrFromString(c.Username)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(c.Password)
+	cred := &winCredential{
+		Type:       credTypeGeneric,
+		TargetName: target,
+		Persist:    credPersistLocalMachine,
+		UserName:   username,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlobSize = uint32(len(blob))
+		cred.CredentialBlob = &blob[0]
+	}
+
+	r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(cred)), 0)
+	if r == 0 {
+		return errors.Wrap(err, "CredWriteW")
+	}
+	return nil
+}
+
+func (s *winCredStore) Delete(c nativeCredential) error {
+	found, err := s.Get(c)
+	if err != nil || found == nil {
+		return err
+	}
+
+	target, err := winCredTarget(c)
+	if err != nil {
+		return err
+	}
+	r, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if r == 0 && err != windows.ERROR_NOT_FOUND {
+		return errors.Wrap(err, "CredDeleteW")
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package creds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCredentialBlob(t *testing.T) {
+	// UTF-8, as stored by Git Credential Manager and Git LFS.
+	assert.Equal(t, "sécret", decodeCredentialBlob([]byte("sécret")))
+	// UTF-16, as stored by git-credential-wincred.
+	assert.Equal(t, "sécret", decodeCredentialBlob([]byte{'s', 0, 0xe9, 0, 'c', 0, 'r', 0, 'e', 0, 't', 0}))
+}
@@ -107,6 +107,20 @@ func IsPointerScanError(err error) bool {
 	return false
 }
 
+// IsInvalidPointerError indicates that the parsed data appeared to be an LFS
+// pointer, but could not be parsed as one.
+func IsInvalidPointerError(err error) bool {
+	if e, ok := err.(interface {
+		InvalidPointerError() bool
+	}); ok {
+		return e.InvalidPointerError()
+	}
+	if parent := parentOf(err); parent != nil {
+		return IsInvalidPointerError(parent)
+	}
+	return false
+}
+
 // IsBadPointerKeyError indicates that the parsed data has an invalid key.
 func IsBadPointerKeyError(err error) bool {
 	if e, ok := err.(interface {
@@ -121,6 +135,21 @@ func IsBadPointerKeyError(err error) bool {
 	return false
 }
 
+// IsUnsupportedHashAlgorithmError indicates that an OID was, or was to be,
+// computed with a hash algorithm which Git LFS does not support.
+func IsUnsupportedHashAlgorithmError(err error) bool {
+	if e, ok := err.(interface {
+		UnsupportedHashAlgorithmError() bool
+	}); ok {
+		return e.UnsupportedHashAlgorithmError()
+	}
+
+	if parent := parentOf(err); parent != nil {
+		return IsUnsupportedHashAlgorithmError(parent)
+	}
+	return false
+}
+
 // IsProtocolError indicates that the SSH pkt-line protocol data is invalid.
 func IsProtocolError(err error) bool {
 	if e, ok := err.(interface {
@@ -191,6 +220,20 @@ func IsRetriableError(err error) bool {
 	return false
 }
 
+// IsIntegrityError indicates that the contents of an object do not match its
+// OID.
+func IsIntegrityError(err error) bool {
+	if e, ok := err.(interface {
+		IntegrityError() bool
+	}); ok {
+		return e.IntegrityError()
+	}
+	if parent := parentOf(err); parent != nil {
+		return IsIntegrityError(parent)
+	}
+	return false
+}
+
 func IsRetriableLaterError(err error) (time.Time, bool) {
 	if e, ok := err.(interface {
 		RetriableLaterError() (time.Time, bool)
@@ -350,6 +393,20 @@ func NewNotAPointerError(err error) error {
 	return notAPointerError{newWrappedError(err, tr.Tr.Get("Pointer file error"))}
 }
 
+// Definitions for IsInvalidPointerError()
+
+type invalidPointerError struct {
+	*wrappedError
+}
+
+func (e invalidPointerError) InvalidPointerError() bool {
+	return true
+}
+
+func NewInvalidPointerError(err error) error {
+	return invalidPointerError{newWrappedError(err, tr.Tr.Get("Invalid pointer"))}
+}
+
 // Definitions for IsPointerScanError()
 
 type PointerScanError struct {
@@ -390,6 +447,21 @@ func NewBadPointerKeyError(expected, actual string) error {
 	return badPointerKeyError{expected, actual, newWrappedError(err, tr.Tr.Get("pointer parsing"))}
 }
 
+type unsupportedHashAlgorithmError struct {
+	Algorithm string
+
+	*wrappedError
+}
+
+func (e unsupportedHashAlgorithmError) UnsupportedHashAlgorithmError() bool {
+	return true
+}
+
+func NewUnsupportedHashAlgorithmError(algorithm string) error {
+	err := Errorf(tr.Tr.Get("Unsupported hash algorithm: %q", algorithm))
+	return unsupportedHashAlgorithmError{algorithm, newWrappedError(err, tr.Tr.Get("Pointer file error"))}
+}
+
 // Definitions for IsDownloadDeclinedError()
 
 type downloadDeclinedError struct {
@@ -468,6 +540,20 @@ func NewRetriableError(err error) error {
 	return retriableError{newWrappedError(err, "")}
 }
 
+// Definitions for IsIntegrityError()
+
+type integrityError struct {
+	*wrappedError
+}
+
+func (e integrityError) IntegrityError() bool {
+	return true
+}
+
+func NewIntegrityError(err error) error {
+	return integrityError{newWrappedError(errors.WithStack(err), "")}
+}
+
 // Definitions for IsProtocolError()
 
 type protocolError struct {
@@ -44,3 +44,11 @@ func TestCannotRetryOnGenericUrlError(t *testing.T) {
 	err := &url.Error{Err: errors.New("")}
 	assert.False(t, errors.IsRetriableError(err))
 }
+
+func TestIntegrityErrorKeepsMessage(t *testing.T) {
+	err := errors.NewIntegrityError(errors.New("expected OID a, got b"))
+	assert.True(t, errors.IsIntegrityError(err))
+	assert.True(t, errors.IsIntegrityError(errors.Wrap(err, "download")))
+	assert.Equal(t, "expected OID a, got b", err.Error())
+	assert.False(t, errors.IsIntegrityError(errors.New("expected OID a, got b")))
+}
@@ -18,9 +18,18 @@ type Pattern interface {
 type Filter struct {
 	include      []Pattern
 	exclude      []Pattern
+	rules        []Rule
 	defaultValue bool
 }
 
+// Rule is one of an ordered list of patterns, as in a .gitattributes file, of
+// which the last to match a filename decides whether it is allowed. A Rule
+// which is Negated disallows the filenames which it matches.
+type Rule struct {
+	Pattern
+	Negated bool
+}
+
 type PatternType bool
 
 const (
@@ -57,6 +66,16 @@ func NewFromPatterns(include, exclude []Pattern, setters ...option) *Filter {
 	return &Filter{include: include, exclude: exclude, defaultValue: args.defaultValue}
 }
 
+// NewFromRules returns a filter which allows a filename if the last of the
+// given rules to match it is not negated, and disallows it if that rule is
+// negated. A filename which matches none of the rules is given the default
+// value.
+func NewFromRules(rules []Rule, setters ...option) *Filter {
+	f := NewFromPatterns(nil, nil, setters...)
+	f.rules = rules
+	return f
+}
+
 func New(include, exclude []string, ptype PatternType, setters ...option) *Filter {
 	return NewFromPatterns(
 		convertToWildmatch(include, ptype),
@@ -64,12 +83,27 @@ func New(include, exclude []string, ptype PatternType, setters ...option) *Filte
 }
 
 // Include returns the result of calling String() on each Pattern in the
-// include set of this *Filter.
-func (f *Filter) Include() []string { return wildmatchToString(f.include...) }
+// include set of this *Filter, followed by each of its rules which is not
+// negated.
+func (f *Filter) Include() []string {
+	return append(wildmatchToString(f.include...), f.ruleStrings(false)...)
+}
 
 // Exclude returns the result of calling String() on each Pattern in the
-// exclude set of this *Filter.
-func (f *Filter) Exclude() []string { return wildmatchToString(f.exclude...) }
+// exclude set of this *Filter, followed by each of its rules which is negated.
+func (f *Filter) Exclude() []string {
+	return append(wildmatchToString(f.exclude...), f.ruleStrings(true)...)
+}
+
+func (f *Filter) ruleStrings(negated bool) []string {
+	var s []string
+	for _, r := range f.rules {
+		if r.Negated == negated {
+			s = append(s, r.String())
+		}
+	}
+	return s
+}
 
 // wildmatchToString maps the given set of Pattern's to a string slice by
 // calling String() on each pattern.
@@ -87,6 +121,10 @@ func (f *Filter) Allows(filename string) bool {
 		return true
 	}
 
+	if len(f.rules) > 0 {
+		return f.allowsByRules(filename)
+	}
+
 	var included bool
 	for _, inc := range f.include {
 		if included = inc.Match(filename); included {
@@ -120,6 +158,30 @@ func (f *Filter) Allows(filename string) bool {
 	return true
 }
 
+// allowsByRules returns whether the filename is allowed by the last of the
+// filter's rules which matches it, or the default value if none do.
+func (f *Filter) allowsByRules(filename string) bool {
+	for i := len(f.rules) - 1; i >= 0; i-- {
+		r := f.rules[i]
+		if !r.Match(filename) {
+			continue
+		}
+		if r.Negated {
+			tracerx.Printf("filepathfilter: rejecting %q via %q", filename, r.String())
+			return false
+		}
+		tracerx.Printf("filepathfilter: accepting %q via %q", filename, r.String())
+		return true
+	}
+
+	if f.defaultValue {
+		tracerx.Printf("filepathfilter: accepting %q", filename)
+	} else {
+		tracerx.Printf("filepathfilter: rejecting %q", filename)
+	}
+	return f.defaultValue
+}
+
 type wm struct {
 	w *wildmatch.Wildmatch
 	p string
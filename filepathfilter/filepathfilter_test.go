@@ -152,3 +152,48 @@ func TestFilterReportsExcludePatterns(t *testing.T) {
 
 	assert.Equal(t, []string{"*.baz", "*.quux"}, filter.Exclude())
 }
+
+func TestFilterRulesLastMatchWins(t *testing.T) {
+	filter := NewFromRules([]Rule{
+		{Pattern: NewPattern("*.png", GitAttributes)},
+		{Pattern: NewPattern("generated/**", GitAttributes), Negated: true},
+		{Pattern: NewPattern("generated/keep.png", GitAttributes)},
+	}, DefaultValue(false))
+
+	assert.True(t, filter.Allows("a.png"))
+	assert.True(t, filter.Allows("sub/a.png"))
+	assert.False(t, filter.Allows("generated/a.png"))
+	assert.False(t, filter.Allows("generated/sub/a.png"))
+	assert.True(t, filter.Allows("generated/keep.png"))
+	assert.False(t, filter.Allows("a.txt"))
+}
+
+func TestFilterRulesOrderMatters(t *testing.T) {
+	filter := NewFromRules([]Rule{
+		{Pattern: NewPattern("generated/**", GitAttributes), Negated: true},
+		{Pattern: NewPattern("*.png", GitAttributes)},
+	}, DefaultValue(false))
+
+	// The later inclusion overrides the earlier exclusion.
+	assert.True(t, filter.Allows("generated/a.png"))
+	assert.False(t, filter.Allows("generated/a.txt"))
+}
+
+func TestFilterRulesDefaultValue(t *testing.T) {
+	rules := []Rule{{Pattern: NewPattern("*.bin", GitAttributes), Negated: true}}
+
+	assert.True(t, NewFromRules(rules).Allows("a.txt"))
+	assert.False(t, NewFromRules(rules).Allows("a.bin"))
+	assert.False(t, NewFromRules(rules, DefaultValue(false)).Allows("a.txt"))
+}
+
+func TestFilterReportsRulePatterns(t *testing.T) {
+	filter := NewFromRules([]Rule{
+		{Pattern: NewPattern("*.foo", GitAttributes)},
+		{Pattern: NewPattern("*.bar", GitAttributes), Negated: true},
+		{Pattern: NewPattern("*.baz", GitAttributes)},
+	})
+
+	assert.Equal(t, []string{"*.foo", "*.baz"}, filter.Include())
+	assert.Equal(t, []string{"*.bar"}, filter.Exclude())
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -18,6 +19,16 @@ import (
 	"github.com/rubyist/tracerx"
 )
 
+const (
+	// DefaultObjectShardDepth is the number of levels of directories, each
+	// named by the next pair of hexadecimal digits of an object's OID, in
+	// which objects are stored by default, as in "ab/cd/abcdef...".
+	DefaultObjectShardDepth = 2
+	// MaxObjectShardDepth is the largest number of levels of directories
+	// which may be configured with SetObjectShardDepth.
+	MaxObjectShardDepth = 4
+)
+
 var (
 	oidRE             = regexp.MustCompile(`\A[[:alnum:]]{64}`)
 	EmptyObjectSHA256 = hex.EncodeToString(sha256.New().Sum(nil))
@@ -45,6 +56,7 @@ type Filesystem struct {
 	tmpdir        string
 	logdir        string
 	repoPerms     os.FileMode
+	shardDepth    int
 	mu            sync.Mutex
 }
 
@@ -72,8 +84,46 @@ func (f *Filesystem) ObjectExists(oid string, size int64) bool {
 	return tools.FileExistsOfSize(f.ObjectPathname(oid), size)
 }
 
+// ObjectNotFoundError is returned by OpenObject when the requested object
+// is not present in the local object store.
+type ObjectNotFoundError struct {
+	Oid string
+}
+
+func (e *ObjectNotFoundError) Error() string {
+	return tr.Tr.Get("object %s not found in local storage", e.Oid)
+}
+
+// OpenObject opens the locally stored object with the given OID for reading.
+// It returns an *ObjectNotFoundError if the object is not present, and an
+// error if the stored object is not of the expected size.
+func (f *Filesystem) OpenObject(oid string, size int64) (io.ReadCloser, error) {
+	if size == 0 || oid == EmptyObjectSHA256 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	file, err := os.Open(f.ObjectPathname(oid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, &ObjectNotFoundError{Oid: oid}
+		}
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if stat.Size() != size {
+		file.Close()
+		return nil, errors.New(tr.Tr.Get("object %s has size %d, expected %d", oid, stat.Size(), size))
+	}
+	return file, nil
+}
+
 func (f *Filesystem) ObjectPath(oid string) (string, error) {
-	if len(oid) < 4 {
+	if len(oid) < 2*tools.MaxInt(f.ObjectShardDepth(), DefaultObjectShardDepth) {
 		return "", errors.New(tr.Tr.Get("too short object ID: %q", oid))
 	}
 	if oid == EmptyObjectSHA256 {
@@ -136,8 +186,47 @@ func DecodePathBytes(path []byte) []byte {
 	return buffer.Bytes()
 }
 
+// SetObjectShardDepth sets the number of levels of directories in which new
+// objects are stored, from 1 to MaxObjectShardDepth.  Objects stored at the
+// default depth are still found.  Any other depth is ignored.
+func (f *Filesystem) SetObjectShardDepth(depth int) {
+	if depth < 1 || depth > MaxObjectShardDepth {
+		tracerx.Printf("ignoring invalid object shard depth %d", depth)
+		return
+	}
+	f.shardDepth = depth
+}
+
+// ObjectShardDepth returns the number of levels of directories in which new
+// objects are stored.
+func (f *Filesystem) ObjectShardDepth() int {
+	if f.shardDepth == 0 {
+		return DefaultObjectShardDepth
+	}
+	return f.shardDepth
+}
+
+// localObjectDir returns the directory of the object with the given OID at the
+// configured depth, unless the object is only present at the default depth, in
+// which case that directory is returned.
 func (f *Filesystem) localObjectDir(oid string) string {
-	return filepath.Join(f.LFSObjectDir(), oid[0:2], oid[2:4])
+	dir := shardedObjectDir(f.LFSObjectDir(), oid, f.ObjectShardDepth())
+	if f.ObjectShardDepth() != DefaultObjectShardDepth && !tools.FileExists(filepath.Join(dir, oid)) {
+		legacy := shardedObjectDir(f.LFSObjectDir(), oid, DefaultObjectShardDepth)
+		if tools.FileExists(filepath.Join(legacy, oid)) {
+			return legacy
+		}
+	}
+	return dir
+}
+
+func shardedObjectDir(root, oid string, depth int) string {
+	parts := make([]string, 0, depth+1)
+	parts = append(parts, root)
+	for i := 0; i < depth; i++ {
+		parts = append(parts, oid[2*i:2*i+2])
+	}
+	return filepath.Join(parts...)
 }
 
 func (f *Filesystem) ObjectReferencePaths(oid string) []string {
@@ -147,11 +236,92 @@ func (f *Filesystem) ObjectReferencePaths(oid string) []string {
 
 	var paths []string
 	for _, ref := range f.ReferenceDirs {
-		paths = append(paths, filepath.Join(ref, oid[0:2], oid[2:4], oid))
+		paths = append(paths, filepath.Join(shardedObjectDir(ref, oid, f.ObjectShardDepth()), oid))
+		if f.ObjectShardDepth() != DefaultObjectShardDepth {
+			paths = append(paths, filepath.Join(shardedObjectDir(ref, oid, DefaultObjectShardDepth), oid))
+		}
 	}
 	return paths
 }
 
+// RelayoutObjects moves each object in the local object directory which is not
+// stored at the configured depth to the directory in which it would be stored
+// now, and removes the directories which are left empty.  An object which is
+// already present at the configured depth is not moved, and its other copy is
+// removed instead.  It returns the number of objects which were moved.
+//
+// No other Git LFS process should access the local object directory while the
+// objects are moved.
+func (f *Filesystem) RelayoutObjects() (int, error) {
+	type storedObject struct {
+		dir string
+		oid string
+	}
+
+	root := f.LFSObjectDir()
+	var objects []storedObject
+	var walkErr error
+	tools.FastWalkDir(root, func(parentDir string, info os.FileInfo, err error) {
+		if err != nil {
+			walkErr = err
+			return
+		}
+		if info.IsDir() || !oidRE.MatchString(info.Name()) {
+			return
+		}
+		objects = append(objects, storedObject{dir: parentDir, oid: info.Name()})
+	})
+	if walkErr != nil {
+		return 0, walkErr
+	}
+
+	moved := 0
+	for _, obj := range objects {
+		dir := shardedObjectDir(root, obj.oid, f.ObjectShardDepth())
+		if filepath.Clean(obj.dir) == dir {
+			continue
+		}
+
+		src := filepath.Join(obj.dir, obj.oid)
+		dst := filepath.Join(dir, obj.oid)
+		if tools.FileExists(dst) {
+			if err := os.Remove(src); err != nil {
+				return moved, err
+			}
+		} else {
+			if err := tools.MkdirAll(dir, f); err != nil {
+				return moved, errors.New(tr.Tr.Get("error trying to create local storage directory in %q: %s", dir, err))
+			}
+			if err := os.Rename(src, dst); err != nil {
+				return moved, err
+			}
+			moved++
+		}
+
+		// Remove the directories which are now empty; os.Remove fails
+		// for the first which is not.
+		for d := filepath.Clean(obj.dir); d != root && strings.HasPrefix(d, root); d = filepath.Dir(d) {
+			if os.Remove(d) != nil {
+				break
+			}
+		}
+	}
+	return moved, nil
+}
+
+// AddReadOnlyCacheDir adds dir, a directory of Git LFS objects laid out in the
+// same way as the local object directory, to the directories searched for an
+// object before it is downloaded. Objects found there are linked or copied
+// into the local object directory, so dir itself is never written to. A dir
+// which does not exist is ignored.
+func (f *Filesystem) AddReadOnlyCacheDir(dir string) {
+	if !tools.DirExists(dir) {
+		tracerx.Printf("skipping missing read-only cache directory %s", dir)
+		return
+	}
+	f.ReferenceDirs = append(f.ReferenceDirs, dir)
+}
+
 func (f *Filesystem) LFSObjectDir() string {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -201,7 +371,7 @@ func (f *Filesystem) Cleanup() error {
 // repoPerms is the permissions for directories in the repository.
 func New(env Environment, gitdir, workdir, lfsdir string, repoPerms os.FileMode) *Filesystem {
 	fs := &Filesystem{
-		GitStorageDir: resolveGitStorageDir(gitdir),
+		GitStorageDir: resolveGitStorageDir(env, gitdir),
 	}
 
 	fs.ReferenceDirs = resolveReferenceDirs(env, fs.GitStorageDir)
@@ -292,12 +462,21 @@ func existsAlternate(objs string) (string, bool) {
 	return "", false
 }
 
-// From a git dir, get the location that objects are to be stored (we will store lfs alongside)
-// Sometimes there is an additional level of redirect on the .git folder by way of a commondir file
-// before you find object storage, e.g. 'git worktree' uses this. It redirects to gitdir either by GIT_DIR
-// (during setup) or .git/git-dir: (during use), but this only contains the index etc, the objects
-// are found in another git dir via 'commondir'.
-func resolveGitStorageDir(gitDir string) string {
+// resolveGitStorageDir returns the directory shared by every worktree of the
+// repository whose Git directory is gitDir, in which objects are stored (we
+// will store lfs alongside).  A linked worktree, as made by 'git worktree',
+// has its own Git directory which only contains the index etc.  Its objects
+// are found in the directory given by GIT_COMMON_DIR, if set, which is
+// relative to the current directory like Git's, or else by the "commondir"
+// file in its Git directory.
+func resolveGitStorageDir(env Environment, gitDir string) string {
+	if dir, ok := env.Get("GIT_COMMON_DIR"); ok && len(dir) > 0 {
+		if abs, err := filepath.Abs(dir); err == nil {
+			return abs
+		}
+		return dir
+	}
+
 	commondirpath := filepath.Join(gitDir, "commondir")
 	if tools.FileExists(commondirpath) && !tools.DirExists(filepath.Join(gitDir, "objects")) {
 		// no git-dir: prefix in commondir
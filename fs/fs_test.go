@@ -1,10 +1,13 @@
 package fs
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDecodeNone(t *testing.T) {
@@ -42,3 +45,130 @@ func TestRepositoryPermissions(t *testing.T) {
 		assert.Equal(t, v, fs.RepositoryPermissions(false))
 	}
 }
+
+func TestOpenObject(t *testing.T) {
+	dir := t.TempDir()
+	fs := New(testEnv{}, dir, dir, filepath.Join(dir, "lfs"), 0666)
+
+	oid := "4ae5a2cff5e1d1bd6a4c5b5d1a8c2f126dbb5d1d0c3f217e2dbb0d7fbcd5f29b"
+	path, err := fs.ObjectPath(oid)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	r, err := fs.OpenObject(oid, 7)
+	require.NoError(t, err)
+	by, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "content", string(by))
+
+	_, err = fs.OpenObject(oid, 8)
+	assert.EqualError(t, err, "object "+oid+" has size 7, expected 8")
+}
+
+func TestOpenObjectNotFound(t *testing.T) {
+	dir := t.TempDir()
+	fs := New(testEnv{}, dir, dir, filepath.Join(dir, "lfs"), 0666)
+
+	oid := "4ae5a2cff5e1d1bd6a4c5b5d1a8c2f126dbb5d1d0c3f217e2dbb0d7fbcd5f29b"
+	_, err := fs.OpenObject(oid, 7)
+	require.Error(t, err)
+
+	notFound, ok := err.(*ObjectNotFoundError)
+	require.True(t, ok, "expected *ObjectNotFoundError, got %T", err)
+	assert.Equal(t, oid, notFound.Oid)
+}
+
+func TestOpenEmptyObject(t *testing.T) {
+	fs := New(testEnv{}, "", "", "", 0666)
+
+	r, err := fs.OpenObject(EmptyObjectSHA256, 0)
+	require.NoError(t, err)
+	by, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Empty(t, by)
+}
+
+func TestAddReadOnlyCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	require.NoError(t, os.Mkdir(cache, 0755))
+
+	fs := New(testEnv{}, dir, dir, filepath.Join(dir, "lfs"), 0666)
+	fs.AddReadOnlyCacheDir(cache)
+	fs.AddReadOnlyCacheDir(filepath.Join(dir, "missing"))
+
+	oid := "4ae5a2cff5e1d1bd6a4c5b5d1a8c2f126dbb5d1d0c3f217e2dbb0d7fbcd5f29b"
+	assert.Equal(t, []string{cache}, fs.ReferenceDirs)
+	assert.Equal(t, []string{filepath.Join(cache, "4a", "e5", oid)}, fs.ObjectReferencePaths(oid))
+}
+
+func TestObjectShardDepth(t *testing.T) {
+	dir := t.TempDir()
+	fs := New(testEnv{}, dir, dir, filepath.Join(dir, "lfs"), 0666)
+	objs := filepath.Join(dir, "lfs", "objects")
+
+	legacyOid := "4ae5a2cff5e1d1bd6a4c5b5d1a8c2f126dbb5d1d0c3f217e2dbb0d7fbcd5f29b"
+	path, err := fs.ObjectPath(legacyOid)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(objs, "4a", "e5", legacyOid), path)
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0644))
+
+	fs.SetObjectShardDepth(0)
+	fs.SetObjectShardDepth(MaxObjectShardDepth + 1)
+	assert.Equal(t, DefaultObjectShardDepth, fs.ObjectShardDepth())
+	fs.SetObjectShardDepth(3)
+	assert.Equal(t, 3, fs.ObjectShardDepth())
+
+	// Objects at the default depth are still found.
+	assert.True(t, fs.ObjectExists(legacyOid, 7))
+	path, err = fs.ObjectPath(legacyOid)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(objs, "4a", "e5", legacyOid), path)
+
+	// New objects are stored at the configured depth.
+	newOid := "b5d1a8c2f126dbb5d1d0c3f217e2dbb0d7fbcd5f29b4ae5a2cff5e1d1bd6a4c5"
+	path, err = fs.ObjectPath(newOid)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(objs, "b5", "d1", "a8", newOid), path)
+	require.NoError(t, os.WriteFile(path, []byte("new"), 0644))
+
+	moved, err := fs.RelayoutObjects()
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	assert.FileExists(t, filepath.Join(objs, "4a", "e5", "a2", legacyOid))
+	assert.NoFileExists(t, filepath.Join(objs, "4a", "e5", legacyOid))
+	assert.FileExists(t, filepath.Join(objs, "b5", "d1", "a8", newOid))
+
+	fs.SetObjectShardDepth(DefaultObjectShardDepth)
+	moved, err = fs.RelayoutObjects()
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	assert.FileExists(t, filepath.Join(objs, "4a", "e5", legacyOid))
+	assert.FileExists(t, filepath.Join(objs, "b5", "d1", newOid))
+	assert.NoDirExists(t, filepath.Join(objs, "4a", "e5", "a2"))
+	assert.NoDirExists(t, filepath.Join(objs, "b5", "d1", "a8"))
+}
+
+func TestGitStorageDirOfLinkedWorktree(t *testing.T) {
+	dir := t.TempDir()
+	common := filepath.Join(dir, "repo", ".git")
+	gitdir := filepath.Join(common, "worktrees", "worktree")
+	require.NoError(t, os.MkdirAll(gitdir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitdir, "commondir"), []byte("../..\n"), 0644))
+
+	fs := New(testEnv{}, gitdir, filepath.Join(dir, "worktree"), "", 0666)
+	assert.Equal(t, common, fs.GitStorageDir)
+	assert.Equal(t, filepath.Join(common, "lfs"), fs.LFSStorageDir)
+
+	other := filepath.Join(dir, "other")
+	fs = New(testEnv{"GIT_COMMON_DIR": other}, gitdir, filepath.Join(dir, "worktree"), "", 0666)
+	assert.Equal(t, other, fs.GitStorageDir)
+}
+
+type testEnv map[string]string
+
+func (e testEnv) Get(key string) (string, bool) {
+	v, ok := e[key]
+	return v, ok
+}
@@ -31,6 +31,10 @@ type AttributePath struct {
 	Lockable bool
 	// Path is handled by Git LFS (i.e., filter=lfs)
 	Tracked bool
+	// Path has some other filter, or unsets or unspecifies the filter
+	// attribute (i.e., -filter or !filter), so that it excludes its files
+	// from any pattern of lower precedence which tracks them
+	Excluded bool
 }
 
 type AttributeSource struct {
@@ -170,6 +174,7 @@ func AttrPathsFromReader(mp *gitattr.MacroProcessor, fpath, workingDir string, r
 			Source:   source,
 			Lockable: lockable,
 			Tracked:  tracked,
+			Excluded: hasFilter && !tracked,
 		})
 	}
 
@@ -184,21 +189,50 @@ func AttrPathsFromReader(mp *gitattr.MacroProcessor, fpath, workingDir string, r
 // workingDir is the root of the working copy
 // gitDir is the root of the git repo
 func GetAttributeFilter(workingDir, gitDir string) *filepathfilter.Filter {
-	paths := GetAttributePaths(gitattr.NewMacroProcessor(), workingDir, gitDir)
-	patterns := make([]filepathfilter.Pattern, 0, len(paths))
+	return AttributeFilter(GetAttributePaths(gitattr.NewMacroProcessor(), workingDir, gitDir))
+}
 
-	for _, path := range paths {
-		// Convert all separators to `/` before creating a pattern to
-		// avoid characters being escaped in situations like `subtree\*.md`
-		patterns = append(patterns, filepathfilter.NewPattern(filepath.ToSlash(path.Path), filepathfilter.GitAttributes))
+// AttributeFilter returns a file path filter which allows the files tracked by
+// the given entries, as returned by GetAttributePaths.  As in Git, the last
+// entry in a file which matches a path takes precedence, and the entries of a
+// file take precedence over those of the files which paths lists after it, so
+// that a pattern which excludes files from Git LFS, such as one with
+// "-filter", overrides an earlier pattern which tracks them.
+func AttributeFilter(paths []AttributePath) *filepathfilter.Filter {
+	// Find the entries of each file, which are consecutive, and then
+	// order the files from the lowest precedence to the highest.
+	var files [][]AttributePath
+	for i, path := range paths {
+		if i == 0 || path.Source != paths[i-1].Source {
+			files = append(files, nil)
+		}
+		files[len(files)-1] = append(files[len(files)-1], path)
 	}
 
-	return filepathfilter.NewFromPatterns(patterns, nil)
+	rules := make([]filepathfilter.Rule, 0, len(paths))
+	for i := len(files) - 1; i >= 0; i-- {
+		for _, path := range files[i] {
+			if !path.Tracked && !path.Excluded {
+				continue
+			}
+
+			// Convert all separators to `/` before creating a pattern to
+			// avoid characters being escaped in situations like `subtree\*.md`
+			rules = append(rules, filepathfilter.Rule{
+				Pattern: filepathfilter.NewPattern(filepath.ToSlash(path.Path), filepathfilter.GitAttributes),
+				Negated: path.Excluded,
+			})
+		}
+	}
+
+	return filepathfilter.NewFromRules(rules, filepathfilter.DefaultValue(false))
 }
 
 func findAttributeFiles(workingDir, gitDir string) []attrFile {
 	var paths []attrFile
 
+	// The repository's own attributes take precedence over those of any
+	// .gitattributes file, so they are always found first.
 	repoAttributes := filepath.Join(gitDir, "info", "attributes")
 	if info, err := os.Stat(repoAttributes); err == nil && !info.IsDir() {
 		paths = append(paths, attrFile{path: repoAttributes, readMacros: true})
@@ -210,6 +244,8 @@ func findAttributeFiles(workingDir, gitDir string) []attrFile {
 		return paths
 	}
 
+	repoFiles := len(paths)
+
 	if gitattributesFiles, present := lsFiles.FilesByName[".gitattributes"]; present {
 		for _, f := range gitattributesFiles {
 			tracerx.Printf("findAttributeFiles: located %s", f.FullPath)
@@ -222,8 +258,9 @@ func findAttributeFiles(workingDir, gitDir string) []attrFile {
 
 	// reverse the order of the files so more specific entries are found first
 	// when iterating from the front (respects precedence)
-	sort.Slice(paths[:], func(i, j int) bool {
-		return len(paths[i].path) > len(paths[j].path)
+	gitattributes := paths[repoFiles:]
+	sort.SliceStable(gitattributes, func(i, j int) bool {
+		return len(gitattributes[i].path) > len(gitattributes[j].path)
 	})
 
 	return paths
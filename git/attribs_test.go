@@ -0,0 +1,98 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/v3/git/gitattr"
+	"github.com/stretchr/testify/assert"
+)
+
+func attributePathsFromStrings(files ...[2]string) []AttributePath {
+	var paths []AttributePath
+	mp := gitattr.NewMacroProcessor()
+	for _, f := range files {
+		paths = append(paths, AttrPathsFromReader(mp, f[0], "", strings.NewReader(f[1]), f[0] == ".gitattributes")...)
+	}
+	return paths
+}
+
+func TestAttrPathsFromReaderExcluded(t *testing.T) {
+	paths := attributePathsFromStrings([2]string{".gitattributes", strings.Join([]string{
+		"*.png filter=lfs diff=lfs merge=lfs -text",
+		"a/*.png -filter",
+		"b/*.png !filter",
+		"c/*.png filter=other",
+		"*.psd lockable",
+	}, "\n")})
+
+	assert.Len(t, paths, 5)
+	assert.True(t, paths[0].Tracked)
+	assert.False(t, paths[0].Excluded)
+	for _, p := range paths[1:4] {
+		assert.False(t, p.Tracked, p.Path)
+		assert.True(t, p.Excluded, p.Path)
+	}
+	assert.False(t, paths[4].Tracked)
+	assert.False(t, paths[4].Excluded)
+}
+
+func TestAttributeFilterLaterLineWins(t *testing.T) {
+	filter := AttributeFilter(attributePathsFromStrings([2]string{".gitattributes", strings.Join([]string{
+		"*.png filter=lfs diff=lfs merge=lfs -text",
+		"generated/** -filter -diff -merge text",
+		"generated/keep.png filter=lfs diff=lfs merge=lfs -text",
+		"*.psd lockable",
+	}, "\n")}))
+
+	assert.True(t, filter.Allows("a.png"))
+	assert.False(t, filter.Allows("generated/a.png"))
+	assert.True(t, filter.Allows("generated/keep.png"))
+	assert.False(t, filter.Allows("a.psd"))
+	assert.Equal(t, []string{"*.png", "generated/keep.png"}, filter.Include())
+}
+
+func TestAttributeFilterEarlierExclusionIsOverridden(t *testing.T) {
+	filter := AttributeFilter(attributePathsFromStrings([2]string{".gitattributes", strings.Join([]string{
+		"generated/** -filter",
+		"*.png filter=lfs diff=lfs merge=lfs -text",
+	}, "\n")}))
+
+	assert.True(t, filter.Allows("generated/a.png"))
+	assert.False(t, filter.Allows("generated/a.txt"))
+}
+
+func TestAttributeFilterLockableDoesNotExclude(t *testing.T) {
+	filter := AttributeFilter(attributePathsFromStrings([2]string{".gitattributes", strings.Join([]string{
+		"*.png filter=lfs diff=lfs merge=lfs -text",
+		"*.png lockable",
+	}, "\n")}))
+
+	assert.True(t, filter.Allows("a.png"))
+}
+
+func TestAttributeFilterDeeperFileWins(t *testing.T) {
+	// GetAttributePaths lists the files of highest precedence first.
+	filter := AttributeFilter(attributePathsFromStrings(
+		[2]string{"generated/.gitattributes", "*.png -filter"},
+		[2]string{".gitattributes", "*.png filter=lfs diff=lfs merge=lfs -text"},
+	))
+
+	assert.True(t, filter.Allows("a.png"))
+	assert.False(t, filter.Allows("generated/a.png"))
+
+	filter = AttributeFilter(attributePathsFromStrings(
+		[2]string{"vendor/.gitattributes", "*.png filter=lfs diff=lfs merge=lfs -text"},
+		[2]string{".gitattributes", "vendor/** -filter"},
+	))
+
+	assert.True(t, filter.Allows("vendor/a.png"))
+	assert.False(t, filter.Allows("vendor/a.txt"))
+}
+
+func TestAttributeFilterWithoutPatterns(t *testing.T) {
+	filter := AttributeFilter(nil)
+
+	assert.Empty(t, filter.Include())
+	assert.False(t, filter.Allows("a.png"))
+}
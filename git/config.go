@@ -4,11 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/git-lfs/git-lfs/v3/subprocess"
+	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 )
 
@@ -35,11 +37,38 @@ type Configuration struct {
 
 func NewConfig(workdir, gitdir string) *Configuration {
 	if len(gitdir) == 0 && len(workdir) > 0 {
-		gitdir = filepath.Join(workdir, ".git")
+		gitdir = resolveDotGit(filepath.Join(workdir, ".git"))
 	}
 	return &Configuration{WorkDir: workdir, GitDir: gitdir}
 }
 
+// resolveDotGit returns the Git directory given by dotGit, the ".git" entry of
+// a working tree.  In a linked worktree or a submodule this is a file of the
+// form "gitdir: <path>", in which case the path it contains is returned;
+// otherwise dotGit itself is returned.
+func resolveDotGit(dotGit string) string {
+	fi, err := os.Stat(dotGit)
+	if err != nil || !fi.Mode().IsRegular() {
+		return dotGit
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+
+	contents := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(contents, "gitdir:") {
+		return dotGit
+	}
+
+	dir := strings.TrimSpace(strings.TrimPrefix(contents, "gitdir:"))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(dotGit), dir)
+	}
+	return dir
+}
+
 // NewReadOnlyConfig creates a new configuration that returns an error if an
 // attempt to write to the configuration is made.
 func NewReadOnlyConfig(workdir, gitdir string) *Configuration {
@@ -168,13 +197,21 @@ func (c *Configuration) Sources(dir string, optionalFilename string) ([]*Configu
 			fileconfig, err = c.FileSource(filepath.Join(dir, optionalFilename))
 			if err != nil {
 				if !os.IsNotExist(err) {
-					return nil, err
+					// Still return the Git configuration, so
+					// that it is used despite the error.
+					return []*ConfigurationSource{gitconfig}, err
+				}
+				fileconfig, err = c.optionalRevisionSource(fmt.Sprintf(":%s", optionalFilename))
+				if err != nil {
+					return []*ConfigurationSource{gitconfig}, err
 				}
-				fileconfig, _ = c.RevisionSource(fmt.Sprintf(":%s", optionalFilename))
 			}
 		}
 		if fileconfig == nil {
-			fileconfig, _ = c.RevisionSource(fmt.Sprintf("HEAD:%s", optionalFilename))
+			fileconfig, err = c.optionalRevisionSource(fmt.Sprintf("HEAD:%s", optionalFilename))
+			if err != nil {
+				return []*ConfigurationSource{gitconfig}, err
+			}
 		}
 
 		if fileconfig != nil {
@@ -185,24 +222,131 @@ func (c *Configuration) Sources(dir string, optionalFilename string) ([]*Configu
 	return append(configs, gitconfig), nil
 }
 
+// FileSource returns the configuration in the given file. Any include.path
+// directives in the file are replaced with the configuration in the files
+// they name, which are resolved relative to the directory of the including
+// file.
 func (c *Configuration) FileSource(filename string) (*ConfigurationSource, error) {
 	if _, err := os.Stat(filename); err != nil {
 		return nil, err
 	}
 
-	out, err := c.gitConfig("-l", "-f", filename)
+	lines, err := c.includingLines("-f", filename, nil)
 	if err != nil {
 		return nil, err
 	}
-	return ParseConfigLines(out, true), nil
+	return &ConfigurationSource{Lines: lines, OnlySafeKeys: true}, nil
 }
 
+// RevisionSource returns the configuration in the given blob, such as
+// "HEAD:.lfsconfig". Any include.path directives in the blob are replaced
+// with the configuration in the blobs they name, which are resolved relative
+// to the directory of the including blob in the same revision.
 func (c *Configuration) RevisionSource(revision string) (*ConfigurationSource, error) {
-	out, err := c.gitConfig("-l", "--blob", revision)
+	lines, err := c.includingLines("--blob", revision, nil)
 	if err != nil {
 		return nil, err
 	}
-	return ParseConfigLines(out, true), nil
+	return &ConfigurationSource{Lines: lines, OnlySafeKeys: true}, nil
+}
+
+// optionalRevisionSource returns the configuration in the given blob as
+// RevisionSource does, or nil if the blob does not exist.
+func (c *Configuration) optionalRevisionSource(revision string) (*ConfigurationSource, error) {
+	source, err := c.RevisionSource(revision)
+	if err != nil && !c.configExists("--blob", revision) {
+		return nil, nil
+	}
+	return source, err
+}
+
+// includingLines returns the lines listed by "git config -l" for the file or
+// blob given by name, which is read using the given option, either "-f" or
+// "--blob". Each include.path directive is replaced by the lines of the file
+// or blob it names, so that later entries override earlier ones as they do
+// in Git; included files or blobs which do not exist are ignored. The names
+// of the files or blobs which include this one are given by seen, and an
+// error is returned if name is among them.
+func (c *Configuration) includingLines(option, name string, seen []string) ([]string, error) {
+	for _, s := range seen {
+		if s == name {
+			return nil, errors.New(tr.Tr.Get("circular include of %q from %q", name, seen[len(seen)-1]))
+		}
+	}
+	seen = append(seen, name)
+
+	out, err := c.gitConfigWithoutIncludes("-l", option, name)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		if strings.ToLower(key) != "include.path" {
+			lines = append(lines, line)
+			continue
+		}
+
+		included, err := includedName(option, name, value)
+		if err != nil {
+			return nil, err
+		}
+		if !c.configExists(option, included) {
+			continue
+		}
+
+		includedLines, err := c.includingLines(option, included, seen)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, includedLines...)
+	}
+	return lines, nil
+}
+
+// includedName returns the name of the file or blob given by the include.path
+// value in the file or blob given by name, which is read using the given
+// option as for includingLines.
+func includedName(option, name, value string) (string, error) {
+	if option == "--blob" {
+		if strings.HasPrefix(value, "/") {
+			return "", errors.New(tr.Tr.Get("cannot include absolute path %q from %q", value, name))
+		}
+
+		rev, blobPath, found := strings.Cut(name, ":")
+		if !found {
+			return "", errors.New(tr.Tr.Get("cannot include %q from %q", value, name))
+		}
+		return rev + ":" + path.Join(path.Dir(blobPath), value), nil
+	}
+
+	value, err := tools.ExpandPath(value, false)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(value) {
+		return filepath.Clean(value), nil
+	}
+	return filepath.Join(filepath.Dir(name), value), nil
+}
+
+// configExists returns whether the file or blob given by name, which is read
+// using the given option as for includingLines, exists.
+func (c *Configuration) configExists(option, name string) bool {
+	if option == "--blob" {
+		cmd, err := subprocess.ExecCommand("git", "cat-file", "-e", name)
+		if err != nil {
+			return false
+		}
+		if len(c.GitDir) > 0 {
+			cmd.Dir = c.GitDir
+		}
+		return cmd.Run() == nil
+	}
+
+	_, err := os.Stat(name)
+	return err == nil
 }
 
 func (c *Configuration) Source() (*ConfigurationSource, error) {
@@ -213,8 +357,39 @@ func (c *Configuration) Source() (*ConfigurationSource, error) {
 	return ParseConfigLines(out, false), nil
 }
 
+// Origins returns the origin of the value of each key which takes effect in
+// the Git configuration, such as "file:/home/user/.gitconfig", keyed by the
+// name of the key as given by Source.  Relative paths to files are resolved
+// against the Git directory.
+func (c *Configuration) Origins() (map[string]string, error) {
+	out, err := c.gitConfig("--show-origin", "-z", "-l")
+	if err != nil {
+		return nil, err
+	}
+
+	origins := make(map[string]string)
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		origin := fields[i]
+		if path, ok := strings.CutPrefix(origin, "file:"); ok && len(c.GitDir) > 0 && !filepath.IsAbs(path) {
+			origin = "file:" + filepath.Join(c.GitDir, path)
+		}
+		key, _, _ := strings.Cut(fields[i+1], "\n")
+		origins[key] = origin
+	}
+	return origins, nil
+}
+
 func (c *Configuration) gitConfig(args ...string) (string, error) {
-	args = append([]string{"config", "--includes"}, args...)
+	return c.runGitConfig(append([]string{"--includes"}, args...)...)
+}
+
+func (c *Configuration) gitConfigWithoutIncludes(args ...string) (string, error) {
+	return c.runGitConfig(append([]string{"--no-includes"}, args...)...)
+}
+
+func (c *Configuration) runGitConfig(args ...string) (string, error) {
+	args = append([]string{"config"}, args...)
 	cmd, err := subprocess.ExecCommand("git", args...)
 	if err != nil {
 		return "", err
@@ -1,6 +1,8 @@
 package git_test // to avoid import cycles
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/git-lfs/git-lfs/v3/git"
@@ -12,3 +14,21 @@ func TestReadOnlyConfig(t *testing.T) {
 	_, err := cfg.SetLocal("lfs.this.should", "fail")
 	assert.Equal(t, err, ErrReadOnly)
 }
+
+func TestNewConfigResolvesGitFile(t *testing.T) {
+	dir := t.TempDir()
+	workdir := filepath.Join(dir, "worktree")
+	gitdir := filepath.Join(dir, "repo", ".git", "worktrees", "worktree")
+	assert.Nil(t, os.MkdirAll(workdir, 0755))
+	assert.Nil(t, os.MkdirAll(gitdir, 0755))
+
+	assert.Equal(t, filepath.Join(workdir, ".git"), NewConfig(workdir, "").GitDir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(workdir, ".git"), []byte("gitdir: "+gitdir+"\n"), 0644))
+	assert.Equal(t, gitdir, NewConfig(workdir, "").GitDir)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(workdir, ".git"), []byte("gitdir: ../repo/.git/worktrees/worktree\n"), 0644))
+	assert.Equal(t, gitdir, NewConfig(workdir, "").GitDir)
+
+	assert.Equal(t, "other", NewConfig(workdir, "other").GitDir)
+}
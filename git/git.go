@@ -237,6 +237,8 @@ func CatFile() (*subprocess.BufferedCmd, error) {
 	return gitNoLFSBuffered("cat-file", "--batch-check")
 }
 
+// DiffIndex returns a scanner over the output of `git diff-index -z`, which
+// yields each of its NUL-separated fields in turn.
 func DiffIndex(ref string, cached bool, refresh bool, workingDir string) (*bufio.Scanner, error) {
 	if refresh {
 		_, err := gitSimple("update-index", "-q", "--refresh")
@@ -245,7 +247,7 @@ func DiffIndex(ref string, cached bool, refresh bool, workingDir string) (*bufio
 		}
 	}
 
-	args := []string{"diff-index", "-M"}
+	args := []string{"diff-index", "-M", "-z"}
 	if cached {
 		args = append(args, "--cached")
 	}
@@ -263,7 +265,38 @@ func DiffIndex(ref string, cached bool, refresh bool, workingDir string) (*bufio
 		return nil, err
 	}
 
-	return bufio.NewScanner(cmd.Stdout), nil
+	scanner := bufio.NewScanner(cmd.Stdout)
+	scanner.Split(scanNullLines)
+	return scanner, nil
+}
+
+// DiffFiles returns a scanner over the output of `git diff-files`, which lists
+// the differences between the index and the working tree, in the same format
+// as DiffIndex.
+func DiffFiles(refresh bool, workingDir string) (*bufio.Scanner, error) {
+	if refresh {
+		_, err := gitSimple("update-index", "-q", "--refresh")
+		if err != nil {
+			return nil, lfserrors.Wrap(err, tr.Tr.Get("Failed to run `git update-index`"))
+		}
+	}
+
+	args := []string{"diff-files", "-z"}
+	if workingDir != "" {
+		args = append([]string{"-C", workingDir}, args...)
+	}
+
+	cmd, err := gitBufferedStdout(args...)
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Stdin.Close(); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(cmd.Stdout)
+	scanner.Split(scanNullLines)
+	return scanner, nil
 }
 
 func DiffIndexWithPaths(ref string, cached bool, paths []string) (string, error) {
@@ -374,6 +407,17 @@ func ResolveRefs(refnames []string) ([]*Ref, error) {
 	return refs, nil
 }
 
+// ResolveObject returns the object ID of the object named by the given revision,
+// such as "<ref>:<path>" for a file in a tree or ":<path>" for an entry in the
+// index, which is usually a blob.
+func ResolveObject(rev string) (string, error) {
+	outp, err := gitNoLFSSimple("rev-parse", "--verify", "--quiet", rev)
+	if err != nil || outp == "" {
+		return "", errors.New(tr.Tr.Get("Git can't resolve file: %q", rev))
+	}
+	return outp, nil
+}
+
 func CurrentRef() (*Ref, error) {
 	return ResolveRef("HEAD")
 }
@@ -1009,6 +1053,30 @@ func IsBare() (bool, error) {
 	return strconv.ParseBool(s)
 }
 
+// IsShallow returns whether the current repository is a shallow clone, i.e.,
+// whether some of its history was left out with --depth or similar options.
+func IsShallow() (bool, error) {
+	// Versions before 2.15.0 don't have the --is-shallow-repository
+	// option, so look for the "shallow" file which lists the boundary
+	// commits instead.
+	if !IsGitVersionAtLeast("2.15.0") {
+		gitDir, err := GitCommonDir()
+		if err != nil {
+			return false, err
+		}
+		return tools.FileExists(filepath.Join(gitDir, "shallow")), nil
+	}
+
+	s, err := subprocess.SimpleExec(
+		"git", "rev-parse", "--is-shallow-repository")
+
+	if err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(s)
+}
+
 // For compatibility with git clone we must mirror all flags in CloneWithoutFilters
 type CloneFlags struct {
 	// --template <template_directory>
@@ -75,6 +75,9 @@ type RewriteOptions struct {
 	// been reassembled by calling the above BlobFn on all existing tree
 	// entries.
 	TreeCallbackFn TreeCallbackFn
+	// CommitCallbackFn specifies a function to be called after each
+	// commit has been rewritten.
+	CommitCallbackFn CommitCallbackFn
 }
 
 // blobFn returns a usable BlobRewriteFn, either the one that was given in the
@@ -145,6 +148,18 @@ type TreePreCallbackFn func(path string, t *gitobj.Tree) error
 // Rewrite() invocation.
 type TreeCallbackFn func(path string, t *gitobj.Tree) (*gitobj.Tree, error)
 
+// CommitCallbackFn specifies a function to call once a commit has been
+// rewritten, given the ID of the original commit and of its rewritten
+// counterpart, which are equal if the commit was unchanged. It is called once
+// for each commit, after the BlobFn and tree callbacks for that commit and in
+// the same reverse topological order in which commits are rewritten.
+//
+// CommitCallbackFn can be nil, in which case it is not called.
+//
+// If the CommitCallbackFn returns an error, it will be returned from the
+// Rewrite() invocation.
+type CommitCallbackFn func(original, rewritten []byte) error
+
 type rewriterOption func(*Rewriter)
 
 var (
@@ -300,6 +315,12 @@ func (r *Rewriter) Rewrite(opt *RewriteOptions) ([]byte, error) {
 		// commit.
 		r.cacheCommit(oid, newSha)
 
+		if opt.CommitCallbackFn != nil {
+			if err := opt.CommitCallbackFn(oid, newSha); err != nil {
+				return nil, err
+			}
+		}
+
 		// Increment the percentage displayed in the terminal.
 		perc.Count(1)
 
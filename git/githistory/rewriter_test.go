@@ -13,6 +13,7 @@ import (
 	"github.com/git-lfs/git-lfs/v3/filepathfilter"
 	"github.com/git-lfs/gitobj/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRewriterRewritesHistory(t *testing.T) {
@@ -395,6 +396,50 @@ func TestHistoryRewriterTreePreCallbackPropagatesErrors(t *testing.T) {
 	assert.Equal(t, err, expected)
 }
 
+func TestHistoryRewriterCommitCallback(t *testing.T) {
+	var originals, rewrittens []string
+
+	db := DatabaseFromFixture(t, "linear-history.git")
+	r := NewRewriter(db)
+
+	tip, err := r.Rewrite(&RewriteOptions{Include: []string{"refs/heads/master"},
+		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
+			return b, nil
+		},
+
+		CommitCallbackFn: func(original, rewritten []byte) error {
+			originals = append(originals, hex.EncodeToString(original))
+			rewrittens = append(rewrittens, hex.EncodeToString(rewritten))
+			return nil
+		},
+	})
+
+	assert.Nil(t, err)
+
+	require.Len(t, originals, 3)
+	assert.Equal(t, originals, rewrittens)
+	assert.Equal(t, hex.EncodeToString(tip), rewrittens[2])
+}
+
+func TestHistoryRewriterCommitCallbackPropagatesErrors(t *testing.T) {
+	expected := errors.Errorf("my error")
+
+	db := DatabaseFromFixture(t, "linear-history.git")
+	r := NewRewriter(db)
+
+	_, err := r.Rewrite(&RewriteOptions{Include: []string{"refs/heads/master"},
+		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
+			return b, nil
+		},
+
+		CommitCallbackFn: func(original, rewritten []byte) error {
+			return expected
+		},
+	})
+
+	assert.Equal(t, err, expected)
+}
+
 func TestHistoryRewriterUseOriginalParentsForPartialMigration(t *testing.T) {
 	db := DatabaseFromFixture(t, "linear-history-with-tags.git")
 	r := NewRewriter(db)
@@ -98,3 +98,29 @@ func NewLsFiles(workingDir string, standardExclude bool, untracked bool) (*LsFil
 
 	return rv, nil
 }
+
+// UntrackedFiles returns the paths, relative to workingDir, of the files in
+// the working tree which are neither in the index nor ignored.
+func UntrackedFiles(workingDir string) ([]string, error) {
+	args := []string{"ls-files", "-z", "--others", "--exclude-standard"}
+
+	cmd, err := gitNoLFS(args...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = workingDir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New(tr.Tr.Get("Error in `git %s`: %v",
+			strings.Join(args, " "), err))
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if len(file) > 0 {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
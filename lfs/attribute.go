@@ -3,8 +3,10 @@ package lfs
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/tr"
 )
@@ -51,15 +53,42 @@ func (o *FilterOptions) Uninstall() error {
 	if err := attrs.Uninstall(o); err != nil {
 		return err
 	}
+	keys := make([]string, 0, len(attrs.Properties))
 	for k := range attrs.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
 		name := fmt.Sprintf("%s.%s", attrs.Section, k)
 		if len(o.GitConfig.Find(name)) > 0 {
+			if scope := o.scopeOf(name); len(scope) > 0 {
+				return errors.New(tr.Tr.Get("Git LFS is still enabled by the %s Git configuration (found %s)", scope, name))
+			}
 			return errors.New(tr.Tr.Get("some filter configuration was not removed (found %s)", name))
 		}
 	}
 	return nil
 }
 
+// scopeOf returns the name of the most specific Git configuration scope which
+// sets the given key, or an empty string if it is set only in some other way,
+// such as by an environment variable.
+func (o *FilterOptions) scopeOf(key string) string {
+	if config.Bool(o.GitConfig.Find("extensions.worktreeconfig"), false) && len(o.GitConfig.FindWorktree(key)) > 0 {
+		return "worktree"
+	}
+	if len(o.GitConfig.FindLocal(key)) > 0 {
+		return "local"
+	}
+	if len(o.GitConfig.FindGlobal(key)) > 0 {
+		return "global"
+	}
+	if len(o.GitConfig.FindSystem(key)) > 0 {
+		return "system"
+	}
+	return ""
+}
+
 func filterAttribute() *Attribute {
 	return &Attribute{
 		Section: "filter.lfs",
@@ -1,6 +1,10 @@
 package lfs
 
-import "github.com/git-lfs/git-lfs/v3/config"
+import (
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/config"
+)
 
 // FetchPruneConfig collects together the config options that control fetching and pruning
 type FetchPruneConfig struct {
@@ -27,6 +31,12 @@ type FetchPruneConfig struct {
 	PruneRecent bool
 	// Whether to delete everything pushed.
 	PruneForce bool
+	// If not zero, ignore the recent options and retain only objects
+	// referenced by commits made at or after this time.
+	PruneOlderThan time.Time
+	// Glob patterns matching the full names of refs whose reachable
+	// objects are always retained.
+	PruneExcludeRefs []string
 }
 
 func NewFetchPruneConfig(git config.Environment) FetchPruneConfig {
@@ -0,0 +1,58 @@
+package lfs
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/subprocess"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+// countingWriter counts the bytes written through it to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// pipeContentTransform runs the given content transform command through the
+// shell, replacing "%f" with the quoted name of the file being filtered, and
+// pipes from through it to to.  It returns the number of bytes which the
+// command wrote.
+func pipeContentTransform(command, fileName string, to io.Writer, from io.Reader) (int64, error) {
+	name, args := subprocess.FormatForShell(subprocess.FormatPercentSequences(command, map[string]string{
+		"f": fileName,
+	}), "")
+	cmd, err := subprocess.ExecCommand(name, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	out := &countingWriter{w: to}
+	var stderr bytes.Buffer
+	cmd.Stdin = from
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			// The command succeeded, but its input could
+			// not be read or its output written.
+			return out.n, err
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) == 0 {
+			msg = err.Error()
+		}
+		return out.n, errors.New(tr.Tr.Get("content transform '%s' failed with: %s", command, msg))
+	}
+	return out.n, nil
+}
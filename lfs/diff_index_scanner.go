@@ -142,6 +142,20 @@ func NewDiffIndexScanner(ref string, cached bool, refresh bool, workingDir strin
 	}, nil
 }
 
+// NewDiffFilesScanner initializes a new `DiffIndexScanner` scanning for
+// differences between the index and the currently checked out tree, as
+// reported by `git diff-files`.  The "refresh" and "workingDir" arguments are
+// as for NewDiffIndexScanner.
+func NewDiffFilesScanner(refresh bool, workingDir string) (*DiffIndexScanner, error) {
+	scanner, err := git.DiffFiles(refresh, workingDir)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffIndexScanner{
+		from: scanner,
+	}, nil
+}
+
 // Scan advances the scan line and yields either a new value for Entry(), or an
 // Err(). It returns true or false, whether or not it can continue scanning for
 // more entries.
@@ -165,7 +179,7 @@ func (s *DiffIndexScanner) Entry() *DiffIndexEntry { return s.next }
 func (s *DiffIndexScanner) Err() error { return s.err }
 
 // prepareScan clears out the results from the last Scan() loop, and advances
-// the internal scanner to fetch a new line of Text().
+// the internal scanner to fetch a new field of Text().
 func (s *DiffIndexScanner) prepareScan() bool {
 	s.next, s.err = nil, nil
 	if !s.from.Scan() {
@@ -176,38 +190,48 @@ func (s *DiffIndexScanner) prepareScan() bool {
 	return true
 }
 
-// scan parses the given line and returns a `*DiffIndexEntry` or an error,
-// depending on whether or not the parse was successful.
-func (s *DiffIndexScanner) scan(line string) (*DiffIndexEntry, error) {
+// scan parses the given description, reading the file names which follow it,
+// and returns a `*DiffIndexEntry` or an error, depending on whether or not the
+// parse was successful.
+func (s *DiffIndexScanner) scan(desc string) (*DiffIndexEntry, error) {
 	// Format is:
-	//   :100644 100644 c5b3d83a7542255ec7856487baa5e83d65b1624c 9e82ac1b514be060945392291b5b3108c22f6fe3 M foo.gif
-	//   :<old mode> <new mode> <old sha1> <new sha1> <status>\t<file name>[\t<file name>]
-
-	parts := strings.Split(line, "\t")
-	if len(parts) < 2 {
-		return nil, errors.Errorf(tr.Tr.Get("invalid line: %s", line))
-	}
+	//   :100644 100644 c5b3d83a7542255ec7856487baa5e83d65b1624c 9e82ac1b514be060945392291b5b3108c22f6fe3 M\0foo.gif\0
+	//   :<old mode> <new mode> <old sha1> <new sha1> <status>\0<file name>\0[<file name>\0]
 
-	desc := strings.Fields(parts[0])
-	if len(desc) < 5 {
-		return nil, errors.Errorf(tr.Tr.Get("invalid description: %s", parts[0]))
+	fields := strings.Fields(desc)
+	if len(fields) < 5 {
+		return nil, errors.Errorf(tr.Tr.Get("invalid description: %s", desc))
 	}
 
 	entry := &DiffIndexEntry{
-		SrcMode: strings.TrimPrefix(desc[0], ":"),
-		DstMode: desc[1],
-		SrcSha:  desc[2],
-		DstSha:  desc[3],
-		Status:  DiffIndexStatus(rune(desc[4][0])),
-		SrcName: parts[1],
+		SrcMode: strings.TrimPrefix(fields[0], ":"),
+		DstMode: fields[1],
+		SrcSha:  fields[2],
+		DstSha:  fields[3],
+		Status:  DiffIndexStatus(rune(fields[4][0])),
 	}
 
-	if score, err := strconv.Atoi(desc[4][1:]); err != nil {
+	if score, err := strconv.Atoi(fields[4][1:]); err != nil {
 		entry.StatusScore = score
 	}
 
-	if len(parts) > 2 {
-		entry.DstName = parts[2]
+	if !s.from.Scan() {
+		if err := s.from.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.Errorf(tr.Tr.Get("missing file name: %s", desc))
+	}
+	entry.SrcName = s.from.Text()
+
+	// Only renames and copies are followed by a second file name.
+	if entry.Status == StatusRename || entry.Status == StatusCopy {
+		if !s.from.Scan() {
+			if err := s.from.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errors.Errorf(tr.Tr.Get("missing file name: %s", desc))
+		}
+		entry.DstName = s.from.Text()
 	}
 
 	return entry, nil
@@ -2,7 +2,6 @@ package lfs
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/hex"
 	"hash"
 	"io"
@@ -12,6 +11,7 @@ import (
 	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/subprocess"
+	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 )
 
@@ -19,6 +19,7 @@ type pipeRequest struct {
 	action     string
 	reader     io.Reader
 	fileName   string
+	hashAlgo   string
 	extensions []config.Extension
 }
 
@@ -85,7 +86,10 @@ func pipeExtensions(cfg *config.Configuration, request *pipeRequest) (response p
 		extcmds = append(extcmds, ec)
 	}
 
-	hasher := sha256.New()
+	var hasher hash.Hash
+	if hasher, err = tools.NewLfsContentHashFor(request.hashAlgo); err != nil {
+		return
+	}
 	pipeReader, pipeWriter := io.Pipe()
 	multiWriter := io.MultiWriter(hasher, pipeWriter)
 
@@ -101,7 +105,9 @@ func pipeExtensions(cfg *config.Configuration, request *pipeRequest) (response p
 
 	last := len(extcmds) - 1
 	for i, ec := range extcmds {
-		ec.hasher = sha256.New()
+		if ec.hasher, err = tools.NewLfsContentHashFor(request.hashAlgo); err != nil {
+			return
+		}
 
 		if i == last {
 			ec.cmd.Stdout = io.MultiWriter(ec.hasher, output)
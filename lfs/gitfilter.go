@@ -1,6 +1,8 @@
 package lfs
 
 import (
+	"sync"
+
 	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/fs"
 	"github.com/git-lfs/git-lfs/v3/git"
@@ -12,6 +14,11 @@ type GitFilter struct {
 	cfg *config.Configuration
 	fs  *fs.Filesystem
 	clk clock.Clock
+
+	// oidPolicy is loaded on first use by the clean filter.
+	oidPolicyOnce sync.Once
+	oidPolicy     *oidPolicy
+	oidPolicyErr  error
 }
 
 // NewGitFilter initializes a new *GitFilter
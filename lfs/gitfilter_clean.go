@@ -2,13 +2,13 @@ package lfs
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/hex"
 	"io"
 	"os"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tr"
 )
 
 type cleanedAsset struct {
@@ -22,12 +22,19 @@ func (f *GitFilter) Clean(reader io.Reader, fileName string, fileSize int64, cb
 		return nil, err
 	}
 
+	hashAlgo := f.cfg.HashAlgorithm()
+	transform := f.cfg.ContentTransform("clean")
+
 	var oid string
 	var size int64
 	var tmp *os.File
 	var exts []*PointerExtension
 	if len(extensions) > 0 {
-		request := &pipeRequest{"clean", reader, fileName, extensions}
+		if len(transform) > 0 {
+			return nil, errors.New(tr.Tr.Get("Git LFS extensions can not be used with `lfs.contenttransform.clean`"))
+		}
+
+		request := &pipeRequest{"clean", reader, fileName, hashAlgo, extensions}
 
 		var response pipeResponse
 		if response, err = pipeExtensions(f.cfg, request); err != nil {
@@ -45,21 +52,36 @@ func (f *GitFilter) Clean(reader io.Reader, fileName string, fileSize int64, cb
 		for _, result := range response.results {
 			if result.oidIn != result.oidOut {
 				ext := NewPointerExtension(result.name, len(exts), result.oidIn)
+				ext.OidType = hashAlgo
 				exts = append(exts, ext)
 			}
 		}
 	} else {
-		oid, size, tmp, err = f.copyToTemp(reader, fileSize, cb)
+		oid, size, tmp, err = f.copyToTemp(reader, fileName, fileSize, hashAlgo, transform, cb)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if err := f.checkOidPolicy(oid, fileName); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
 	pointer := NewPointer(oid, size, exts)
+	pointer.OidType = hashAlgo
 	return &cleanedAsset{tmp.Name(), pointer}, err
 }
 
-func (f *GitFilter) copyToTemp(reader io.Reader, fileSize int64, cb tools.CopyCallback) (oid string, size int64, tmp *os.File, err error) {
+// copyToTemp copies the contents of reader to a new temporary file, piping
+// them through the given content transform command, if any, and returns the
+// OID and size of the copied, and possibly transformed, contents.
+func (f *GitFilter) copyToTemp(reader io.Reader, fileName string, fileSize int64, hashAlgo, transform string, cb tools.CopyCallback) (oid string, size int64, tmp *os.File, err error) {
+	oidHash, err := tools.NewLfsContentHashFor(hashAlgo)
+	if err != nil {
+		return
+	}
+
 	tmp, err = TempFile(f.cfg, "")
 	if err != nil {
 		return
@@ -67,7 +89,6 @@ func (f *GitFilter) copyToTemp(reader io.Reader, fileSize int64, cb tools.CopyCa
 
 	defer tmp.Close()
 
-	oidHash := sha256.New()
 	writer := io.MultiWriter(oidHash, tmp)
 
 	if fileSize <= 0 {
@@ -92,7 +113,15 @@ func (f *GitFilter) copyToTemp(reader io.Reader, fileSize int64, cb tools.CopyCa
 		from = io.MultiReader(from, reader)
 	}
 
-	size, err = tools.CopyWithCallback(writer, from, fileSize, cb)
+	if len(transform) > 0 {
+		size, err = pipeContentTransform(transform, fileName, writer, &tools.CallbackReader{
+			C:         cb,
+			TotalSize: fileSize,
+			Reader:    from,
+		})
+	} else {
+		size, err = tools.CopyWithCallback(writer, from, fileSize, cb)
+	}
 
 	if err != nil {
 		return
@@ -1,6 +1,7 @@
 package lfs
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/fs"
 	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/git-lfs/git-lfs/v3/tq"
@@ -46,10 +48,15 @@ func (f *GitFilter) SmudgeToFile(filename string, ptr *Pointer, download bool, m
 	}
 	defer file.Close()
 	if _, err := f.Smudge(file, ptr, filename, download, manifest, cb); err != nil {
-		if errors.IsDownloadDeclinedError(err) {
-			// write placeholder data instead
+		if errors.IsDownloadDeclinedError(err) || errors.IsIntegrityError(err) {
+			// Write placeholder data instead of leaving a corrupt
+			// or partially written file behind.
 			file.Seek(0, io.SeekStart)
+			file.Truncate(0)
 			ptr.Encode(file)
+		}
+
+		if errors.IsDownloadDeclinedError(err) {
 			return err
 		} else {
 			return errors.New(tr.Tr.Get("could not write working directory file: %v", err))
@@ -94,8 +101,20 @@ func (f *GitFilter) Smudge(writer io.Writer, ptr *Pointer, workingfile string, d
 		} else {
 			return 0, errors.NewDownloadDeclinedError(statErr, tr.Tr.Get("smudge filter"))
 		}
+	} else if download && f.cfg.AutoRepair() {
+		// A corrupt object can only be replaced before any of it has
+		// been written out, so it is checked first, after which it
+		// need not be verified again as it is read.
+		err = f.repairLocalFile(ptr, workingfile, mediafile, manifest, cb)
+		if err == nil {
+			n, err = f.readLocalFile(writer, ptr, mediafile, workingfile, false, cb)
+		}
 	} else {
-		n, err = f.readLocalFile(writer, ptr, mediafile, workingfile, cb)
+		n, err = f.readLocalFile(writer, ptr, mediafile, workingfile, true, cb)
+		if errors.IsIntegrityError(err) {
+			tracerx.Printf("Removing %s, content is invalid: %s", mediafile, err)
+			os.RemoveAll(mediafile)
+		}
 	}
 
 	if err != nil {
@@ -105,7 +124,93 @@ func (f *GitFilter) Smudge(writer io.Writer, ptr *Pointer, workingfile string, d
 	return n, nil
 }
 
+// autoRepairAttempts is the number of times a corrupt object in the local
+// cache is downloaded again before repairing it is given up on.
+const autoRepairAttempts = 3
+
+// repairLocalFile verifies the cached object for the given pointer and, if
+// its content is corrupt, removes it and downloads it again from the current
+// remote, up to autoRepairAttempts times.
+func (f *GitFilter) repairLocalFile(ptr *Pointer, workingfile, mediafile string, manifest tq.Manifest, cb tools.CopyCallback) error {
+	verr := verifyLocalFile(ptr, mediafile)
+	attempts := 0
+	for ; verr != nil; attempts++ {
+		if !errors.IsIntegrityError(verr) {
+			return verr
+		}
+		if attempts == autoRepairAttempts {
+			return errors.Wrap(verr, tr.Tr.GetN(
+				"Could not repair %s after %d attempt",
+				"Could not repair %s after %d attempts",
+				attempts,
+				workingfile,
+				attempts))
+		}
+
+		tracerx.Printf("Removing %s, content is invalid: %s", mediafile, verr)
+		if err := os.RemoveAll(mediafile); err != nil {
+			return errors.Wrap(err, tr.Tr.Get("Could not remove corrupt object %s", ptr.Oid))
+		}
+
+		fmt.Fprintln(os.Stderr, tr.Tr.Get("Repairing %s: cached object %s is corrupt, downloading it again", workingfile, ptr.Oid))
+		if err := f.download(ptr, filepath.Base(workingfile), mediafile, manifest, cb); err != nil {
+			return errors.Wrapf(err, tr.Tr.Get("Error downloading %s (%s)", workingfile, ptr.Oid))
+		}
+		verr = verifyLocalFile(ptr, mediafile)
+	}
+
+	if attempts > 0 {
+		fmt.Fprintln(os.Stderr, tr.Tr.Get("Repaired %s", workingfile))
+	}
+	return nil
+}
+
+// verifyLocalFile checks that the content of the given cached object matches
+// the size and OID of the given pointer, returning an integrity error if it
+// does not.
+func verifyLocalFile(ptr *Pointer, mediafile string) error {
+	reader, err := tools.RobustOpen(mediafile)
+	if err != nil {
+		return errors.Wrapf(err, tr.Tr.Get("error opening media file"))
+	}
+	defer reader.Close()
+
+	verifier, err := newLocalFileVerifier(reader, ptr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, verifier)
+	return verificationError(verifier, err)
+}
+
+// newLocalFileVerifier returns a reader which verifies the content read from r
+// against the size and OID of the given pointer as it is read, failing as soon
+// as more content than expected has been read.
+func newLocalFileVerifier(r io.Reader, ptr *Pointer) (*tools.VerifyingReader, error) {
+	hash, err := tools.NewLfsContentHashFor(ptr.OidType)
+	if err != nil {
+		return nil, err
+	}
+	return tools.NewVerifyingReader(r, hash, ptr.Oid, ptr.Size), nil
+}
+
+// verificationError returns err, which was returned while reading from the
+// given verifier, as an integrity error if the content read did not match
+// the expected size or OID.
+func verificationError(verifier *tools.VerifyingReader, err error) error {
+	if err != nil && verifier.Mismatched() && !errors.IsIntegrityError(err) {
+		return errors.NewIntegrityError(err)
+	}
+	return err
+}
+
 func (f *GitFilter) downloadFile(writer io.Writer, ptr *Pointer, workingfile, mediafile string, manifest tq.Manifest, cb tools.CopyCallback) (int64, error) {
+	// Objects are requested from, and verified against, the server using
+	// the configured hash algorithm only.
+	if hashAlgo := f.cfg.HashAlgorithm(); ptr.OidType != hashAlgo {
+		return 0, errors.New(tr.Tr.Get("Unable to download %s: object uses the %q hash algorithm, but lfs.hashalgo is %q", workingfile, ptr.OidType, hashAlgo))
+	}
+
 	fmt.Fprintln(os.Stderr, tr.Tr.Get("Downloading %s (%s)", workingfile, humanize.FormatBytes(uint64(ptr.Size))))
 
 	// NOTE: if given, "cb" is a tools.CopyCallback which writes updates
@@ -114,27 +219,131 @@ func (f *GitFilter) downloadFile(writer io.Writer, ptr *Pointer, workingfile, me
 	// Either way, forward it into the *tq.TransferQueue so that updates are
 	// sent over correctly.
 
+	if err := f.download(ptr, filepath.Base(workingfile), mediafile, manifest, cb); err != nil {
+		return 0, errors.Wrapf(err, tr.Tr.Get("Error downloading %s (%s)", workingfile, ptr.Oid))
+	}
+
+	return f.readLocalFile(writer, ptr, mediafile, workingfile, false, nil)
+}
+
+// download downloads the object for the given pointer from the current remote
+// into mediafile, returning any errors from the transfer queue combined into
+// one.
+func (f *GitFilter) download(ptr *Pointer, name, mediafile string, manifest tq.Manifest, cb tools.CopyCallback) error {
 	q := tq.NewTransferQueue(tq.Download, manifest, f.cfg.Remote(),
 		tq.WithProgressCallback(cb),
 		tq.RemoteRef(f.RemoteRef()),
 	)
-	q.Add(filepath.Base(workingfile), mediafile, ptr.Oid, ptr.Size, false, nil)
+	q.Add(name, mediafile, ptr.Oid, ptr.Size, false, nil)
 	q.Wait()
 
-	if errs := q.Errors(); len(errs) > 0 {
-		var multiErr error
-		for _, e := range errs {
-			if multiErr != nil {
-				multiErr = fmt.Errorf("%v\n%v", multiErr, e)
-			} else {
-				multiErr = e
-			}
+	var multiErr error
+	for _, e := range q.Errors() {
+		if multiErr != nil {
+			multiErr = fmt.Errorf("%v\n%v", multiErr, e)
+		} else {
+			multiErr = e
 		}
+	}
+	return multiErr
+}
+
+// Open returns a reader for the content of the object referred to by the
+// given pointer. If the object is not in the local cache and download is
+// true, it is first downloaded from the current remote using the given
+// manifest; otherwise, an *fs.ObjectNotFoundError is returned. An error is
+// also returned if the stored content is not of the size given by the pointer.
+func (f *GitFilter) Open(ptr *Pointer, download bool, manifest tq.Manifest) (io.ReadCloser, error) {
+	LinkOrCopyFromReference(f.cfg, ptr.Oid, ptr.Size)
+
+	r, err := f.cfg.Filesystem().OpenObject(ptr.Oid, ptr.Size)
+	if _, ok := err.(*fs.ObjectNotFoundError); !ok || !download {
+		return r, err
+	}
+
+	if hashAlgo := f.cfg.HashAlgorithm(); ptr.OidType != hashAlgo {
+		return nil, errors.New(tr.Tr.Get("Unable to download %s: object uses the %q hash algorithm, but lfs.hashalgo is %q", ptr.Oid, ptr.OidType, hashAlgo))
+	}
+
+	mediafile, err := f.ObjectPath(ptr.Oid)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.download(ptr, ptr.Oid, mediafile, manifest, nil); err != nil {
+		return nil, errors.Wrapf(err, tr.Tr.Get("Error downloading %s", ptr.Oid))
+	}
+
+	return f.cfg.Filesystem().OpenObject(ptr.Oid, ptr.Size)
+}
+
+// ReadObjectRange returns a reader for length bytes of the content of the
+// object referred to by the given pointer, starting at offset, or for the
+// rest of its content if length is negative. If the object is in the local
+// cache, the range is read from it directly; otherwise, only the requested
+// bytes are downloaded from the current remote using the given manifest,
+// unless the remote cannot send part of an object, in which case the whole
+// object is downloaded as by Open.
+func (f *GitFilter) ReadObjectRange(ptr *Pointer, offset, length int64, manifest tq.Manifest) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, errors.New(tr.Tr.Get("invalid offset: %d", offset))
+	}
+	if offset > ptr.Size {
+		offset = ptr.Size
+	}
+	if length < 0 || length > ptr.Size-offset {
+		length = ptr.Size - offset
+	}
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	LinkOrCopyFromReference(f.cfg, ptr.Oid, ptr.Size)
+
+	r, err := f.cfg.Filesystem().OpenObject(ptr.Oid, ptr.Size)
+	if err == nil {
+		return limitObjectRange(r, offset, length)
+	}
+	if _, ok := err.(*fs.ObjectNotFoundError); !ok {
+		return nil, err
+	}
+
+	if hashAlgo := f.cfg.HashAlgorithm(); ptr.OidType != hashAlgo {
+		return nil, errors.New(tr.Tr.Get("Unable to download %s: object uses the %q hash algorithm, but lfs.hashalgo is %q", ptr.Oid, ptr.OidType, hashAlgo))
+	}
 
-		return 0, errors.Wrapf(multiErr, tr.Tr.Get("Error downloading %s (%s)", workingfile, ptr.Oid))
+	t := &tq.Transfer{Oid: ptr.Oid, Size: ptr.Size}
+	r, err = tq.DownloadRange(manifest, f.cfg.Remote(), f.RemoteRef(), t, offset, length)
+	if err == nil {
+		return r, nil
+	}
+	if !errors.IsNotImplementedError(err) {
+		return nil, errors.Wrapf(err, tr.Tr.Get("Error downloading %s", ptr.Oid))
 	}
 
-	return f.readLocalFile(writer, ptr, mediafile, workingfile, nil)
+	tracerx.Printf("git: download: cannot download part of %s, downloading whole object: %s", ptr.Oid, err)
+	r, err = f.Open(ptr, true, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return limitObjectRange(r, offset, length)
+}
+
+// limitObjectRange returns a reader for the length bytes of r starting at
+// offset, seeking to offset if r allows it.
+func limitObjectRange(r io.ReadCloser, offset, length int64) (io.ReadCloser, error) {
+	if s, ok := r.(io.Seeker); ok {
+		if _, err := s.Seek(offset, io.SeekStart); err != nil {
+			r.Close()
+			return nil, err
+		}
+	} else if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(r, length), r}, nil
 }
 
 func (f *GitFilter) downloadFileFallBack(writer io.Writer, ptr *Pointer, workingfile, mediafile string, manifest tq.Manifest, cb tools.CopyCallback) (int64, error) {
@@ -171,13 +380,18 @@ func (f *GitFilter) downloadFileFallBack(writer io.Writer, ptr *Pointer, working
 			// Set the remote persistent through all the operation as we found a valid one.
 			// This prevents multiple trial and error searches.
 			f.cfg.SetRemote(remote)
-			return f.readLocalFile(writer, ptr, mediafile, workingfile, nil)
+			return f.readLocalFile(writer, ptr, mediafile, workingfile, false, nil)
 		}
 	}
 	return 0, errors.Wrapf(errors.New("No known remotes"), tr.Tr.Get("Error downloading %s (%s)", workingfile, ptr.Oid))
 }
 
-func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile string, workingfile string, cb tools.CopyCallback) (int64, error) {
+// readLocalFile writes the content of the given cached object to writer,
+// passing it through any extensions or content transform. If verify is true,
+// the object is verified as it is read, and an integrity error is returned if
+// it does not match the pointer; objects which were just downloaded have
+// already been verified by the transfer queue.
+func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile string, workingfile string, verify bool, cb tools.CopyCallback) (int64, error) {
 	reader, err := tools.RobustOpen(mediafile)
 	if err != nil {
 		return 0, errors.Wrapf(err, tr.Tr.Get("error opening media file"))
@@ -214,7 +428,7 @@ func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile stri
 			extsR = append(extsR, ext)
 		}
 
-		request := &pipeRequest{"smudge", reader, workingfile, extsR}
+		request := &pipeRequest{"smudge", reader, workingfile, ptr.OidType, extsR}
 
 		response, err := pipeExtensions(f.cfg, request)
 		if err != nil {
@@ -251,11 +465,46 @@ func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile stri
 			return 0, errors.Wrapf(err, tr.Tr.Get("Error opening smudged file: %s", err))
 		}
 		defer reader.Close()
+
+		n, err := tools.CopyWithCallback(writer, reader, ptr.Size, cb)
+		if err != nil {
+			return n, errors.Wrapf(err, tr.Tr.Get("Error reading from media file: %s", err))
+		}
+		return n, nil
+	}
+
+	// Verify the object as it is copied, so that a corrupt object is
+	// detected without first having to read all of it.
+	var content io.Reader = reader
+	var verifier *tools.VerifyingReader
+	if verify {
+		if verifier, err = newLocalFileVerifier(reader, ptr); err != nil {
+			return 0, err
+		}
+		content = verifier
 	}
 
-	n, err := tools.CopyWithCallback(writer, reader, ptr.Size, cb)
+	var n int64
+	if transform := f.cfg.ContentTransform("smudge"); len(transform) > 0 {
+		n, err = pipeContentTransform(transform, workingfile, writer, &tools.CallbackReader{
+			C:         cb,
+			TotalSize: ptr.Size,
+			Reader:    content,
+		})
+		if err == nil && verifier != nil {
+			// Read any of the object which the command did not,
+			// so that all of it is verified.
+			_, err = io.Copy(io.Discard, verifier)
+		}
+	} else {
+		n, err = tools.CopyWithCallback(writer, content, ptr.Size, cb)
+	}
 	if err != nil {
-		return n, errors.Wrapf(err, tr.Tr.Get("Error reading from media file: %s", err))
+		err = errors.Wrapf(err, tr.Tr.Get("Error reading from media file: %s", err))
+		if verifier != nil {
+			return n, verificationError(verifier, err)
+		}
+		return n, err
 	}
 
 	return n, nil
@@ -2,13 +2,13 @@ package lfs
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"fmt"
 	"io"
 
 	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 )
 
@@ -69,10 +69,16 @@ func runCatFileBatch(pointerCh chan *WrappedPointer, lockableCh chan string, loc
 type PointerScanner struct {
 	scanner *git.ObjectScanner
 
-	blobSha     string
-	contentsSha string
-	pointer     *WrappedPointer
-	err         error
+	// hashAlgo is the hash algorithm, as set by lfs.hashalgo, with which
+	// the contents of blobs other than pointers are hashed.
+	hashAlgo string
+
+	blobSha      string
+	contentsSha  string
+	contentsSize int64
+	pointer      *WrappedPointer
+	pointerErr   error
+	err          error
 }
 
 func NewPointerScanner(gitEnv, osEnv config.Environment) (*PointerScanner, error) {
@@ -81,7 +87,8 @@ func NewPointerScanner(gitEnv, osEnv config.Environment) (*PointerScanner, error
 		return nil, err
 	}
 
-	return &PointerScanner{scanner: scanner}, nil
+	hashAlgo, _ := gitEnv.Get("lfs.hashalgo")
+	return &PointerScanner{scanner: scanner, hashAlgo: hashAlgo}, nil
 }
 
 func (s *PointerScanner) BlobSHA() string {
@@ -92,17 +99,29 @@ func (s *PointerScanner) ContentsSha() string {
 	return s.contentsSha
 }
 
+// ContentsSize returns the size of the contents of the last scanned blob. For
+// a pointer, this is the size of the object it refers to.
+func (s *PointerScanner) ContentsSize() int64 {
+	return s.contentsSize
+}
+
 func (s *PointerScanner) Pointer() *WrappedPointer {
 	return s.pointer
 }
 
+// PointerErr returns an error if the last scanned blob appeared to be a
+// pointer, but could not be parsed as one.
+func (s *PointerScanner) PointerErr() error {
+	return s.pointerErr
+}
+
 func (s *PointerScanner) Err() error {
 	return s.err
 }
 
 func (s *PointerScanner) Scan(sha string) bool {
-	s.pointer, s.err = nil, nil
-	s.blobSha, s.contentsSha = "", ""
+	s.pointer, s.pointerErr, s.err = nil, nil, nil
+	s.blobSha, s.contentsSha, s.contentsSize = "", "", 0
 
 	b, c, p, err := s.next(sha)
 	s.blobSha = b
@@ -116,6 +135,12 @@ func (s *PointerScanner) Scan(sha string) bool {
 		return false
 	}
 
+	if p != nil {
+		s.contentsSize = p.Size
+	} else {
+		s.contentsSize = s.scanner.Size()
+	}
+
 	return true
 }
 
@@ -134,7 +159,10 @@ func (s *PointerScanner) next(blob string) (string, string, *WrappedPointer, err
 	blobSha := s.scanner.Sha1()
 	size := s.scanner.Size()
 
-	sha := sha256.New()
+	sha, err := tools.NewLfsContentHashFor(s.hashAlgo)
+	if err != nil {
+		return blobSha, "", nil, err
+	}
 
 	var buf *bytes.Buffer
 	var to io.Writer = sha
@@ -158,6 +186,9 @@ func (s *PointerScanner) next(blob string) (string, string, *WrappedPointer, err
 	if size < blobSizeCutoff {
 		if p, err := DecodePointer(bytes.NewReader(buf.Bytes())); err != nil {
 			contentsSha = fmt.Sprintf("%x", sha.Sum(nil))
+			if looksLikePointer(buf.Bytes()) {
+				s.pointerErr = errors.NewInvalidPointerError(err)
+			}
 		} else {
 			pointer = &WrappedPointer{
 				Sha1:    blobSha,
@@ -3,7 +3,7 @@ package lfs
 import (
 	"io"
 	"path"
-	"path/filepath"
+	"sort"
 
 	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/errors"
@@ -177,19 +177,25 @@ func lsFilesBlobs(predicate func(*git.TreeBlob) bool) (*TreeBlobChannelWrapper,
 	}, predicate)
 }
 
-func catFileBatchTreeForPointers(treeblobs *TreeBlobChannelWrapper, gitEnv, osEnv config.Environment) (map[string]*WrappedPointer, *filepathfilter.Filter, error) {
+// catFileBatchTreeForPointers returns the pointers found in the given tree
+// blobs, keyed by path, along with a filter matching the paths which should be
+// pointers according to any .gitattributes files. Blobs which appear to be
+// pointers but can not be parsed are returned separately, with the error from
+// parsing them.
+func catFileBatchTreeForPointers(treeblobs *TreeBlobChannelWrapper, gitEnv, osEnv config.Environment) (map[string]*WrappedPointer, map[string]error, *filepathfilter.Filter, error) {
 	pscanner, err := NewPointerScanner(gitEnv, osEnv)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	oscanner, err := git.NewObjectScanner(gitEnv, osEnv)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	pointers := make(map[string]*WrappedPointer)
+	invalid := make(map[string]error)
 
-	paths := make([]git.AttributePath, 0)
+	var attrFiles []treeAttributes
 	processor := gitattr.NewMacroProcessor()
 
 	hasNext := true
@@ -198,17 +204,20 @@ func catFileBatchTreeForPointers(treeblobs *TreeBlobChannelWrapper, gitEnv, osEn
 			hasNext = oscanner.Scan(t.Oid)
 
 			if rdr := oscanner.Contents(); rdr != nil {
-				paths = append(paths, git.AttrPathsFromReader(
-					processor,
-					t.Filename,
-					"",
-					rdr,
-					t.Filename == ".gitattributes", // Read macros from the top-level attributes
-				)...)
+				attrFiles = append(attrFiles, treeAttributes{
+					filename: t.Filename,
+					paths: git.AttrPathsFromReader(
+						processor,
+						t.Filename,
+						"",
+						rdr,
+						t.Filename == ".gitattributes", // Read macros from the top-level attributes
+					),
+				})
 			}
 
 			if err := oscanner.Err(); err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 		} else if t.Size < blobSizeCutoff {
 			hasNext = pscanner.Scan(t.Oid)
@@ -221,9 +230,12 @@ func catFileBatchTreeForPointers(treeblobs *TreeBlobChannelWrapper, gitEnv, osEn
 				p.Name = t.Filename
 			}
 			pointers[t.Filename] = p
+			if err := pscanner.PointerErr(); err != nil {
+				invalid[t.Filename] = err
+			}
 
 			if err := pscanner.Err(); err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 		} else {
 			pointers[t.Filename] = nil
@@ -240,31 +252,35 @@ func catFileBatchTreeForPointers(treeblobs *TreeBlobChannelWrapper, gitEnv, osEn
 		// Deal with nested error from incoming treeblobs
 		err := treeblobs.Wait()
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 	}
 
 	if err = pscanner.Close(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if err = oscanner.Close(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	includes := make([]filepathfilter.Pattern, 0, len(paths))
-	excludes := make([]filepathfilter.Pattern, 0, len(paths))
-	for _, path := range paths {
-		// Convert all separators to `/` before creating a pattern to
-		// avoid characters being escaped in situations like `subtree\*.md`
-		pattern := filepathfilter.NewPattern(filepath.ToSlash(path.Path), filepathfilter.GitAttributes)
-		if path.Tracked {
-			includes = append(includes, pattern)
-		} else {
-			excludes = append(excludes, pattern)
-		}
+	// Order the .gitattributes files so that those in deeper directories,
+	// which take precedence, come first.
+	sort.SliceStable(attrFiles, func(i, j int) bool {
+		return len(attrFiles[i].filename) > len(attrFiles[j].filename)
+	})
+
+	var paths []git.AttributePath
+	for _, f := range attrFiles {
+		paths = append(paths, f.paths...)
 	}
 
-	return pointers, filepathfilter.NewFromPatterns(includes, excludes, filepathfilter.DefaultValue(false)), nil
+	return pointers, invalid, git.AttributeFilter(paths), nil
+}
+
+// treeAttributes holds the entries of a .gitattributes file found in a tree.
+type treeAttributes struct {
+	filename string
+	paths    []git.AttributePath
 }
 
 func runScanTreeForPointers(cb GitScannerFoundPointer, tree string, gitEnv, osEnv config.Environment) error {
@@ -275,12 +291,22 @@ func runScanTreeForPointers(cb GitScannerFoundPointer, tree string, gitEnv, osEn
 		return err
 	}
 
-	pointers, filter, err := catFileBatchTreeForPointers(treeShas, gitEnv, osEnv)
+	pointers, invalid, filter, err := catFileBatchTreeForPointers(treeShas, gitEnv, osEnv)
 	if err != nil {
 		return err
 	}
 
+	// Any file which appears to be a pointer but can not be parsed is
+	// reported as an error, whether or not it should be a pointer.
+	for name, err := range invalid {
+		cb(nil, errors.NewPointerScanError(err, tree, name))
+	}
+
 	for name, p := range pointers {
+		if _, ok := invalid[name]; ok {
+			continue
+		}
+
 		// This file matches the patterns in .gitattributes, so it
 		// should be a pointer.  If it is not, then it is a plain Git
 		// blob, which we report as an error.
@@ -49,6 +49,13 @@ func LoadHooks(hookDir string, cfg *config.Configuration) []*Hook {
 	}
 }
 
+// LoadPreCommitHook returns the pre-commit hook. Unlike the hooks returned by
+// LoadHooks, it is only installed on request, since many repositories already
+// have a pre-commit hook of their own.
+func LoadPreCommitHook(hookDir string, cfg *config.Configuration) *Hook {
+	return NewStandardHook("pre-commit", hookDir, nil, cfg)
+}
+
 // NewStandardHook creates a new hook using the template script calling 'git lfs theType'
 func NewStandardHook(theType, hookDir string, upgradeables []string, cfg *config.Configuration) *Hook {
 	formattedUpgradeables := make([]string, 0, len(upgradeables))
@@ -139,6 +146,13 @@ func (h *Hook) Uninstall() error {
 	return os.RemoveAll(h.Path())
 }
 
+// Installed returns whether the existing git hook is this hook, either in its
+// current or any of its past "upgrade-able" forms.
+func (h *Hook) Installed() bool {
+	upgradable, _, err := h.matchesCurrent()
+	return err == nil && upgradable
+}
+
 // matchesCurrent returns whether or not an existing git hook is able to be
 // written to or upgraded and additionally whether it is identical to the
 // current hook. A git hook matches those conditions if and only if its contents
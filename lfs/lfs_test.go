@@ -2,6 +2,7 @@ package lfs_test // avoid import cycle
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/git-lfs/git-lfs/v3/lfs"
 	test "github.com/git-lfs/git-lfs/v3/t/cmd/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAllCurrentObjectsNone(t *testing.T) {
@@ -69,3 +71,48 @@ func TestAllCurrentObjectsSome(t *testing.T) {
 	sort.Sort(test.PointersByOid(actual))
 	assert.Equal(t, expected, actual, "Oids from disk should be the same as in commits")
 }
+
+func TestGitFilterOpen(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	outputs := repo.AddCommits([]*test.CommitInput{
+		{Files: []*test.FileInput{{Filename: "file.dat", Size: 30}}},
+	})
+	ptr := outputs[0].Files[0]
+
+	filter := lfs.NewGitFilter(repo.Configuration())
+
+	r, err := filter.Open(ptr, false, nil)
+	require.NoError(t, err)
+	by, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Len(t, by, 30)
+
+	_, err = filter.Open(lfs.NewPointer(ptr.Oid, 31, nil), false, nil)
+	assert.EqualError(t, err, fmt.Sprintf("object %s has size 30, expected 31", ptr.Oid))
+}
+
+func TestGitFilterOpenMissing(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	oid := "4ae5a2cff5e1d1bd6a4c5b5d1a8c2f126dbb5d1d0c3f217e2dbb0d7fbcd5f29b"
+	filter := lfs.NewGitFilter(repo.Configuration())
+
+	_, err := filter.Open(lfs.NewPointer(oid, 10, nil), false, nil)
+	require.Error(t, err)
+
+	notFound, ok := err.(*fs.ObjectNotFoundError)
+	require.True(t, ok, "expected *fs.ObjectNotFoundError, got %T", err)
+	assert.Equal(t, oid, notFound.Oid)
+}
@@ -0,0 +1,115 @@
+package lfs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/config"
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+// oidPolicy restricts the objects which the clean filter may create. An
+// object is rejected if its OID is listed in the file named by
+// lfs.blockedoids or, if lfs.allowedoids names a file, if its OID is not
+// listed there.
+type oidPolicy struct {
+	blocked map[string]struct{}
+	// allowed is nil if every object not blocked is allowed.
+	allowed map[string]struct{}
+}
+
+// loadOidPolicy reads the OID lists configured in cfg. It returns nil if
+// neither is configured.
+func loadOidPolicy(cfg *config.Configuration) (*oidPolicy, error) {
+	blockedPath, _ := cfg.Git.Get("lfs.blockedoids")
+	allowedPath, _ := cfg.Git.Get("lfs.allowedoids")
+	if len(blockedPath) == 0 && len(allowedPath) == 0 {
+		return nil, nil
+	}
+
+	p := &oidPolicy{}
+	if len(blockedPath) > 0 {
+		oids, err := readOidList(cfg, "lfs.blockedoids", blockedPath)
+		if err != nil {
+			return nil, err
+		}
+		p.blocked = oids
+	}
+	if len(allowedPath) > 0 {
+		oids, err := readOidList(cfg, "lfs.allowedoids", allowedPath)
+		if err != nil {
+			return nil, err
+		}
+		p.allowed = oids
+	}
+	return p, nil
+}
+
+// readOidList reads the set of OIDs in the file at path, as given by the
+// configuration key. Each line holds an OID, optionally prefixed with
+// "sha256:"; blank lines and lines starting with "#" are ignored, as is
+// anything after the first field of a line. A relative path is taken to be
+// relative to the root of the working tree.
+func readOidList(cfg *config.Configuration, key, path string) (map[string]struct{}, error) {
+	path, err := tools.ExpandPath(path, false)
+	if err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("could not read %s", key))
+	}
+	if !filepath.IsAbs(path) && len(cfg.LocalWorkingDir()) > 0 {
+		path = filepath.Join(cfg.LocalWorkingDir(), path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("could not read %s", key))
+	}
+	defer f.Close()
+
+	oids := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		oid := strings.ToLower(strings.TrimPrefix(fields[0], "sha256:"))
+		oids[oid] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("could not read %s", key))
+	}
+	return oids, nil
+}
+
+// check returns an error if the object with the given OID, cleaned from the
+// named file, may not be created.
+func (p *oidPolicy) check(oid, fileName string) error {
+	if p == nil {
+		return nil
+	}
+	if _, ok := p.blocked[oid]; ok {
+		return errors.New(tr.Tr.Get("%s: object %s is blocked by `lfs.blockedoids`", fileName, oid))
+	}
+	if p.allowed != nil {
+		if _, ok := p.allowed[oid]; !ok {
+			return errors.New(tr.Tr.Get("%s: object %s is not allowed by `lfs.allowedoids`", fileName, oid))
+		}
+	}
+	return nil
+}
+
+// checkOidPolicy returns an error if the OID lists configured for f do not
+// permit the object with the given OID to be created.
+func (f *GitFilter) checkOidPolicy(oid, fileName string) error {
+	f.oidPolicyOnce.Do(func() {
+		f.oidPolicy, f.oidPolicyErr = loadOidPolicy(f.cfg)
+	})
+	if f.oidPolicyErr != nil {
+		return f.oidPolicyErr
+	}
+	return f.oidPolicy.check(oid, fileName)
+}
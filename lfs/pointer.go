@@ -13,6 +13,7 @@ import (
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/fs"
+	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/git-lfs/gitobj/v2"
 )
@@ -113,11 +114,93 @@ func DecodePointerFromFile(file string) (*Pointer, error) {
 	defer f.Close()
 	return DecodePointer(f)
 }
+
+// DecodePointer decodes an *lfs.Pointer from the given io.Reader. Whitespace
+// around the pointer and carriage returns at the ends of its lines are
+// ignored, as they are when Git LFS reads pointers from the repository; use
+// DecodePointerStrict or DecodePointerLenient for stricter or more lenient
+// parsing.
 func DecodePointer(reader io.Reader) (*Pointer, error) {
 	p, _, err := DecodeFrom(reader)
 	return p, err
 }
 
+// DecodePointerStrict decodes an *lfs.Pointer from the given io.Reader, which
+// must contain a pointer exactly as Git LFS would write it, returning a
+// NotAPointerError otherwise. As with DecodePointer, an empty reader yields
+// the pointer of an empty object.
+func DecodePointerStrict(reader io.Reader) (*Pointer, error) {
+	data, err := readPointerData(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := decodePointerData(data)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Canonical {
+		return nil, errors.NewNotAPointerError(errors.New(tr.Tr.Get("pointer is not in canonical form")))
+	}
+	return p, nil
+}
+
+// DecodePointerLenient decodes an *lfs.Pointer from the given io.Reader like
+// DecodePointer, but additionally ignores a leading UTF-8 byte order mark and
+// any whitespace at the end of each line, such as may be introduced by
+// editors or by Git's line ending conversion. The Canonical field of the
+// returned pointer reports whether the original data needed none of this.
+func DecodePointerLenient(reader io.Reader) (*Pointer, error) {
+	data, err := readPointerData(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := bytes.TrimPrefix(data, utf8BOM)
+	lines := bytes.Split(normalized, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t\r")
+	}
+	normalized = bytes.Join(lines, []byte("\n"))
+
+	p, err := decodePointerData(normalized)
+	if err != nil {
+		return nil, err
+	}
+	p.Canonical = p.Encoded() == string(data)
+	return p, nil
+}
+
+// utf8BOM is the UTF-8 encoding of the byte order mark.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readPointerData reads all of the given io.Reader, returning a
+// NotAPointerError if it is too large to be a pointer.
+func readPointerData(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, blobSizeCutoff))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) >= blobSizeCutoff {
+		return nil, errors.NewNotAPointerError(errors.New(tr.Tr.Get("data size exceeds Git LFS pointer size cutoff")))
+	}
+	return data, nil
+}
+
+// decodePointerData decodes an *lfs.Pointer from the given data, ignoring
+// surrounding whitespace, and sets its Canonical field.
+func decodePointerData(data []byte) (*Pointer, error) {
+	if len(data) == 0 {
+		return EmptyPointer(), nil
+	}
+
+	p, err := decodeKV(bytes.TrimSpace(data))
+	if err == nil && p != nil {
+		p.Canonical = p.Encoded() == string(data)
+	}
+	return p, err
+}
+
 // DecodeFrom decodes an *lfs.Pointer from the given io.Reader, "reader".
 // If the pointer encoded in the reader could successfully be read and decoded,
 // it will be returned with a nil error.
@@ -138,17 +221,18 @@ func DecodeFrom(reader io.Reader) (*Pointer, io.Reader, error) {
 		return nil, contents, err
 	}
 
-	if len(buf) == 0 {
-		return EmptyPointer(), contents, nil
-	}
-
-	p, err := decodeKV(bytes.TrimSpace(buf))
-	if err == nil && p != nil {
-		p.Canonical = p.Encoded() == string(buf)
-	}
+	p, err := decodePointerData(buf)
 	return p, contents, err
 }
 
+// looksLikePointer returns whether the given data appears to be intended as a
+// Git LFS pointer, that is, whether its first line is a version key naming a
+// Git LFS specification, regardless of whether it can actually be parsed.
+func looksLikePointer(data []byte) bool {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte("\n"))
+	return bytes.HasPrefix(line, []byte("version ")) && matcherRE.Match(line)
+}
+
 func verifyVersion(version string) error {
 	if len(version) == 0 {
 		return errors.NewNotAPointerError(errors.New(tr.Tr.Get("Missing version")))
@@ -181,7 +265,7 @@ func decodeKV(data []byte) (*Pointer, error) {
 		return nil, errors.New(tr.Tr.Get("Invalid OID"))
 	}
 
-	oid, err := parseOid(value)
+	oid, oidType, err := parseOid(value)
 	if err != nil {
 		return nil, err
 	}
@@ -207,22 +291,33 @@ func decodeKV(data []byte) (*Pointer, error) {
 		sort.Sort(ByPriority(extensions))
 	}
 
-	return NewPointer(oid, size, extensions), nil
+	p := NewPointer(oid, size, extensions)
+	p.OidType = oidType
+	return p, nil
 }
 
-func parseOid(value string) (string, error) {
+// parseOid parses an "<algorithm>:<hex>" OID value, returning the OID and
+// the name of the hash algorithm which produced it. An error is returned if
+// the value is malformed or the hash algorithm is not supported.
+func parseOid(value string) (string, string, error) {
 	parts := strings.SplitN(value, ":", 2)
 	if len(parts) != 2 {
-		return "", errors.New(tr.Tr.Get("Invalid OID value: %s", value))
+		return "", "", errors.New(tr.Tr.Get("Invalid OID value: %s", value))
 	}
-	if parts[0] != oidType {
-		return "", errors.New(tr.Tr.Get("Invalid OID type: %s", parts[0]))
+	if _, err := tools.NewLfsContentHashFor(parts[0]); err != nil {
+		return "", "", err
 	}
 	oid := parts[1]
 	if !oidRE.MatchString(oid) {
-		return "", errors.New(tr.Tr.Get("Invalid OID: %s", oid))
+		return "", "", errors.New(tr.Tr.Get("Invalid OID: %s", oid))
 	}
-	return oid, nil
+	return oid, parts[0], nil
+}
+
+// IsValidOid returns whether the given string is a well-formed OID, that is,
+// 64 lowercase hexadecimal characters.
+func IsValidOid(oid string) bool {
+	return oidRE.MatchString(oid)
 }
 
 func parsePointerExtension(key string, value string) (*PointerExtension, error) {
@@ -238,12 +333,14 @@ func parsePointerExtension(key string, value string) (*PointerExtension, error)
 
 	name := keyParts[2]
 
-	oid, err := parseOid(value)
+	oid, oidType, err := parseOid(value)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewPointerExtension(name, p, oid), nil
+	ext := NewPointerExtension(name, p, oid)
+	ext.OidType = oidType
+	return ext, nil
 }
 
 func validatePointerExtensions(exts []*PointerExtension) error {
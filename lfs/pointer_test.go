@@ -99,6 +99,35 @@ size 12345`
 	assertEqualWithExample(t, ex, int64(12345), p.Size)
 }
 
+func TestDecodeBlake3(t *testing.T) {
+	ex := `version https://git-lfs.github.com/spec/v1
+oid blake3:6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85
+size 3
+`
+
+	p, err := DecodePointer(bytes.NewBufferString(ex))
+	assertEqualWithExample(t, ex, nil, err)
+	assertEqualWithExample(t, ex, "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85", p.Oid)
+	assertEqualWithExample(t, ex, "blake3", p.OidType)
+	assertEqualWithExample(t, ex, int64(3), p.Size)
+	assertEqualWithExample(t, ex, true, p.Canonical)
+	assertEqualWithExample(t, ex, ex, p.Encoded())
+}
+
+func TestDecodeUnsupportedHashAlgorithm(t *testing.T) {
+	ex := `version https://git-lfs.github.com/spec/v1
+oid md5:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345`
+
+	p, err := DecodePointer(bytes.NewBufferString(ex))
+	if p != nil {
+		t.Errorf("Expected nil pointer, got %v", p)
+	}
+	if !errors.IsUnsupportedHashAlgorithmError(err) {
+		t.Errorf("error is not an UnsupportedHashAlgorithmError: %s: '%v'", reflect.TypeOf(err), err)
+	}
+}
+
 func TestDecodeExtensions(t *testing.T) {
 	ex := `version https://git-lfs.github.com/spec/v1
 ext-0-foo sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff
@@ -233,6 +262,73 @@ size 12345
 	}
 }
 
+func TestDecodePointerStrict(t *testing.T) {
+	ex := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+
+	p, err := DecodePointerStrict(strings.NewReader(ex))
+	assert.Nil(t, err)
+	assert.Equal(t, latest, p.Version)
+	assert.Equal(t, "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", p.Oid)
+	assert.Equal(t, int64(12345), p.Size)
+	assert.True(t, p.Canonical)
+
+	p, err = DecodePointerStrict(strings.NewReader(""))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), p.Size)
+
+	for desc, data := range map[string]string{
+		"crlf":                strings.Replace(ex, "\n", "\r\n", -1),
+		"bom":                 "\xef\xbb\xbf" + ex,
+		"trailing whitespace": strings.Replace(ex, "12345\n", "12345 \n", 1),
+		"no trailing newline": strings.TrimSuffix(ex, "\n"),
+		"old version":         strings.Replace(ex, latest, "https://hawser.github.com/spec/v1", 1),
+		"not a pointer":       "this is not a git-lfs file!",
+	} {
+		p, err := DecodePointerStrict(strings.NewReader(data))
+		assert.Nil(t, p, desc)
+		assert.True(t, errors.IsNotAPointerError(err), "%s: %v", desc, err)
+	}
+}
+
+func TestDecodePointerLenient(t *testing.T) {
+	ex := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+
+	p, err := DecodePointerLenient(strings.NewReader(ex))
+	assert.Nil(t, err)
+	assert.True(t, p.Canonical)
+
+	for desc, data := range map[string]string{
+		"crlf":                strings.Replace(ex, "\n", "\r\n", -1),
+		"bom":                 "\xef\xbb\xbf" + ex,
+		"bom and crlf":        "\xef\xbb\xbf" + strings.Replace(ex, "\n", "\r\n", -1),
+		"trailing whitespace": strings.Replace(ex, "\n", " \t\n", -1),
+		"trailing blank line": ex + "\r\n",
+	} {
+		p, err := DecodePointerLenient(strings.NewReader(data))
+		if assert.Nil(t, err, desc) {
+			assert.Equal(t, latest, p.Version, desc)
+			assert.Equal(t, "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", p.Oid, desc)
+			assert.Equal(t, int64(12345), p.Size, desc)
+			assert.False(t, p.Canonical, desc)
+		}
+	}
+
+	// DecodePointer does not ignore a byte order mark or whitespace within
+	// the pointer.
+	_, err = DecodePointer(strings.NewReader("\xef\xbb\xbf" + ex))
+	assert.NotNil(t, err)
+	_, err = DecodePointer(strings.NewReader(strings.Replace(ex, "2393\n", "2393 \n", 1)))
+	assert.NotNil(t, err)
+
+	p, err = DecodePointerLenient(strings.NewReader("\xef\xbb\xbfthis is not a git-lfs file!"))
+	assert.Nil(t, p)
+	assert.True(t, errors.IsNotAPointerError(err))
+
+	p, err = DecodePointerLenient(strings.NewReader(ex + strings.Repeat(" ", blobSizeCutoff)))
+	assert.Nil(t, p)
+	assert.True(t, errors.IsNotAPointerError(err))
+}
+
 func TestDecodeInvalid(t *testing.T) {
 	examples := []string{
 		"invalid stuff",
@@ -347,3 +443,12 @@ size 177735`,
 func assertEqualWithExample(t *testing.T, example string, expected, actual interface{}) {
 	assert.Equal(t, expected, actual, "Example:\n%s", strings.TrimSpace(example))
 }
+
+func TestLooksLikePointer(t *testing.T) {
+	assert.True(t, looksLikePointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:xyz\nsize 1\n")))
+	assert.True(t, looksLikePointer([]byte("version https://hawser.github.com/spec/v1\n")))
+	assert.True(t, looksLikePointer([]byte("\r\nversion https://git-lfs.github.com/spec/v1\r\n")))
+	assert.False(t, looksLikePointer([]byte("")))
+	assert.False(t, looksLikePointer([]byte("plain text about git-lfs\n")))
+	assert.False(t, looksLikePointer([]byte("version 1.0\nhttps://git-lfs.github.com/\n")))
+}
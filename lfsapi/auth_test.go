@@ -458,6 +458,29 @@ func TestGetCreds(t *testing.T) {
 				},
 			},
 		},
+		"basic access with url-specific usehttppath for scoped ipv6 host": getCredsTest{
+			Remote:   "origin",
+			Method:   "GET",
+			Href:     "https://[fe80::1%25eth0]/repo/lfs/locks",
+			Endpoint: "https://[fe80::1%25eth0]/repo/lfs",
+			Config: map[string]string{
+				"lfs.url": "https://[fe80::1%25eth0]/repo/lfs",
+				"lfs.https://[fe80::1%25eth0]/repo/lfs.access":    "basic",
+				"credential.https://[fe80::1%25eth0].usehttppath": "true",
+			},
+			Expected: getCredsExpected{
+				Access:        creds.BasicAccess,
+				Authorization: basicAuth("[fe80::1%eth0]", "monkey"),
+				CredsURL:      "https://[fe80::1%25eth0]/repo/lfs",
+				Creds: map[string][]string{
+					"protocol": []string{"https"},
+					"host":     []string{"[fe80::1%eth0]"},
+					"username": []string{"[fe80::1%eth0]"},
+					"password": []string{"monkey"},
+					"path":     []string{"repo/lfs"},
+				},
+			},
+		},
 		"custom auth": getCredsTest{
 			Remote:   "origin",
 			Method:   "GET",
@@ -101,6 +101,14 @@ func (e *endpointGitFinder) getEndpoint(operation, remote string) lfshttp.Endpoi
 		}
 	}
 
+	// Support a separate read URL, such as that of a caching proxy, if
+	// specified and downloading
+	if operation == "download" {
+		if url, ok := e.gitEnv.Get("lfs.readurl"); ok {
+			return e.NewEndpoint(operation, url)
+		}
+	}
+
 	if url, ok := e.gitEnv.Get("lfs.url"); ok {
 		return e.NewEndpoint(operation, url)
 	}
@@ -129,6 +137,11 @@ func (e *endpointGitFinder) RemoteEndpoint(operation, remote string) lfshttp.End
 			return e.NewEndpoint(operation, url)
 		}
 	}
+	if operation == "download" {
+		if url, ok := e.gitEnv.Get("remote." + remote + ".lfsreadurl"); ok {
+			return e.NewEndpoint(operation, url)
+		}
+	}
 	if url, ok := e.gitEnv.Get("remote." + remote + ".lfsurl"); ok {
 		return e.NewEndpoint(operation, url)
 	}
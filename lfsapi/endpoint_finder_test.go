@@ -98,6 +98,22 @@ func TestEndpointSeparateClonePushUrl(t *testing.T) {
 	assert.Equal(t, "", e.SSHMetadata.Path)
 }
 
+func TestEndpointOverriddenSeparateReadLfsUrl(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"remote.origin.url":        "https://example.com/foo/bar.git",
+		"remote.origin.lfsurl":     "https://examplelfs.com/foo/bar",
+		"remote.origin.lfsreadurl": "https://cache.example.com/foo/bar",
+	}))
+
+	e := finder.Endpoint("download", "")
+	assert.Equal(t, "https://cache.example.com/foo/bar", e.Url)
+	assert.Equal(t, "download", e.Operation)
+
+	e = finder.Endpoint("upload", "")
+	assert.Equal(t, "https://examplelfs.com/foo/bar", e.Url)
+	assert.Equal(t, "upload", e.Operation)
+}
+
 func TestEndpointOverriddenSeparateClonePushLfsUrl(t *testing.T) {
 	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
 		"remote.origin.url":        "https://example.com/foo/bar.git",
@@ -117,6 +133,26 @@ func TestEndpointOverriddenSeparateClonePushLfsUrl(t *testing.T) {
 	assert.Equal(t, "", e.SSHMetadata.Path)
 }
 
+func TestEndpointGlobalSeparateLfsRead(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"remote.origin.lfsreadurl":             "https://remotecache.com/foo/bar",
+		"lfs.url":                              "https://write.com/foo/bar",
+		"lfs.readurl":                          "https://cache.com/foo/bar",
+		"lfs.https://cache.com/foo/bar.access": "none",
+		"lfs.https://write.com/foo/bar.access": "basic",
+	}))
+
+	e := finder.Endpoint("download", "")
+	assert.Equal(t, "https://cache.com/foo/bar", e.Url)
+	access := finder.AccessFor(e.Url)
+	assert.Equal(t, creds.NoneAccess, access.Mode())
+
+	e = finder.Endpoint("upload", "")
+	assert.Equal(t, "https://write.com/foo/bar", e.Url)
+	access = finder.AccessFor(e.Url)
+	assert.Equal(t, creds.BasicAccess, access.Mode())
+}
+
 func TestEndpointGlobalSeparateLfsPush(t *testing.T) {
 	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
 		"lfs.url":     "https://readonly.com/foo/bar",
@@ -279,6 +315,76 @@ func TestBareHTTPEndpointAddsLfsSuffix(t *testing.T) {
 	assert.Equal(t, "", e.SSHMetadata.Port)
 }
 
+func TestHTTPEndpointWithIPv6Host(t *testing.T) {
+	for rawurl, expected := range map[string]string{
+		"https://[::1]/foo/bar":                  "https://[::1]/foo/bar.git/info/lfs",
+		"https://[2001:db8::1]:8080/foo/bar.git": "https://[2001:db8::1]:8080/foo/bar.git/info/lfs",
+		"https://[fe80::1%25eth0]:8080/foo/bar":  "https://[fe80::1%25eth0]:8080/foo/bar.git/info/lfs",
+	} {
+		finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+			"remote.origin.url": rawurl,
+		}))
+
+		e := finder.Endpoint("download", "")
+		assert.Equal(t, expected, e.Url, rawurl)
+		assert.Equal(t, "", e.SSHMetadata.UserAndHost, rawurl)
+	}
+}
+
+func TestSSHEndpointWithIPv6Host(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"remote.origin.url": "ssh://git@[fe80::1%25eth0]:2222/foo/bar",
+	}))
+
+	e := finder.Endpoint("download", "")
+	assert.Equal(t, "https://[fe80::1%25eth0]/foo/bar.git/info/lfs", e.Url)
+	assert.Equal(t, "git@fe80::1%eth0", e.SSHMetadata.UserAndHost)
+	assert.Equal(t, "/foo/bar", e.SSHMetadata.Path)
+	assert.Equal(t, "2222", e.SSHMetadata.Port)
+}
+
+func TestSSHEndpointWithIPv6HostWithoutPort(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"remote.origin.url": "ssh://[2001:db8::1]/foo/bar",
+	}))
+
+	e := finder.Endpoint("download", "")
+	assert.Equal(t, "https://[2001:db8::1]/foo/bar.git/info/lfs", e.Url)
+	assert.Equal(t, "2001:db8::1", e.SSHMetadata.UserAndHost)
+	assert.Equal(t, "/foo/bar", e.SSHMetadata.Path)
+	assert.Equal(t, "", e.SSHMetadata.Port)
+}
+
+func TestBareSSHEndpointWithIPv6Host(t *testing.T) {
+	for _, rawurl := range []string{
+		"git@[fe80::1%eth0]:foo/bar.git",
+		"git@[fe80::1%25eth0]:foo/bar.git",
+		"[git@fe80::1%eth0]:foo/bar.git",
+	} {
+		finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+			"remote.origin.url": rawurl,
+		}))
+
+		e := finder.Endpoint("download", "")
+		assert.Equal(t, "https://[fe80::1%25eth0]/foo/bar.git/info/lfs", e.Url, rawurl)
+		assert.Equal(t, "git@fe80::1%eth0", e.SSHMetadata.UserAndHost, rawurl)
+		assert.Equal(t, "foo/bar.git", e.SSHMetadata.Path, rawurl)
+		assert.Equal(t, "", e.SSHMetadata.Port, rawurl)
+	}
+}
+
+func TestBareSSHEndpointWithIPv6HostWithoutUser(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"remote.origin.url": "[::1]:foo/bar.git",
+	}))
+
+	e := finder.Endpoint("download", "")
+	assert.Equal(t, "https://[::1]/foo/bar.git/info/lfs", e.Url)
+	assert.Equal(t, "::1", e.SSHMetadata.UserAndHost)
+	assert.Equal(t, "foo/bar.git", e.SSHMetadata.Path)
+	assert.Equal(t, "", e.SSHMetadata.Port)
+}
+
 func TestGitEndpointAddsLfsSuffix(t *testing.T) {
 	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
 		"remote.origin.url": "git://example.com/foo/bar",
@@ -37,6 +37,7 @@ func isClientCertEnabledForHost(c *Client, host string) bool {
 }
 
 // decryptPEMBlock decrypts an encrypted PEM block representing a private key,
+// either a PKCS#1 key with legacy PEM encryption or an encrypted PKCS#8 key,
 // prompting for credentials using the credential helper, and returns a
 // decrypted PEM block representing that same private key.
 func decryptPEMBlock(c *Client, block *pem.Block, path string, key []byte) ([]byte, error) {
@@ -55,7 +56,14 @@ func decryptPEMBlock(c *Client, block *pem.Block, path string, key []byte) ([]by
 		return nil, err
 	}
 	pass := creds["password"][0]
-	decrypted, err := x509.DecryptPEMBlock(block, []byte(pass))
+	blockType := block.Type
+	var decrypted []byte
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		blockType = "PRIVATE KEY"
+		decrypted, err = decryptPKCS8PrivateKey(block.Bytes, []byte(pass))
+	} else {
+		decrypted, err = x509.DecryptPEMBlock(block, []byte(pass))
+	}
 	if err != nil {
 		credWrapper.CredentialHelper.Reject(creds)
 		return nil, err
@@ -63,7 +71,7 @@ func decryptPEMBlock(c *Client, block *pem.Block, path string, key []byte) ([]by
 	credWrapper.CredentialHelper.Approve(creds)
 
 	// decrypted is a DER blob, but we need a PEM-encoded block.
-	toEncode := &pem.Block{Type: block.Type, Headers: nil, Bytes: decrypted}
+	toEncode := &pem.Block{Type: blockType, Headers: nil, Bytes: decrypted}
 	buf := pem.EncodeToMemory(toEncode)
 	return buf, nil
 }
@@ -99,11 +107,11 @@ func getClientCertForHost(c *Client, host string) (*tls.Certificate, error) {
 	if block == nil {
 		return nil, errors.New(tr.Tr.Get("Error decoding PEM block from %q", hostSslKey))
 	}
-	if x509.IsEncryptedPEMBlock(block) {
+	if block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) {
 		key, err = decryptPEMBlock(c, block, hostSslKey, key)
 		if err != nil {
 			tracerx.Printf("Unable to decrypt client key file %q: %v", hostSslKey, err)
-			return nil, errors.Wrapf(err, tr.Tr.Get("Error reading client key file %q (not a PKCS#1 file?)", hostSslKey))
+			return nil, errors.Wrapf(err, tr.Tr.Get("Error decrypting client key file %q", hostSslKey))
 		}
 	}
 
@@ -148,8 +156,26 @@ func appendRootCAsForHostFromGitconfig(osEnv, gitEnv config.Environment, pool *x
 	if cafile, _ := osEnv.Get("GIT_SSL_CAINFO"); len(cafile) > 0 {
 		return appendCertsFromFile(pool, cafile)
 	}
-	// http.<url>/.sslcainfo or http.<url>.sslcainfo
-	if cafile, ok := uc.Get("http", url, "sslcainfo"); ok {
+	// http.<url>/.sslcainfo or http.<url>.sslcainfo, and likewise
+	// sslcapath, which add to the system certificates rather than
+	// replacing them, so that hosts with an internal CA can be used
+	// alongside those with a public one
+	cafile, hasCAFile := uc.GetSpecific("http", url, "sslcainfo")
+	cadir, hasCADir := uc.GetSpecific("http", url, "sslcapath")
+	if hasCAFile || hasCADir {
+		if pool == nil {
+			pool = loadSystemCerts()
+		}
+		if hasCAFile {
+			pool = appendCertsFromFile(pool, cafile)
+		}
+		if hasCADir {
+			pool = appendCertsFromFilesInDir(pool, cadir)
+		}
+		return pool
+	}
+	// http.sslcainfo
+	if cafile, ok := gitEnv.Get("http.sslcainfo"); ok {
 		return appendCertsFromFile(pool, cafile)
 	}
 	// GIT_SSL_CAPATH
@@ -164,6 +190,20 @@ func appendRootCAsForHostFromGitconfig(osEnv, gitEnv config.Environment, pool *x
 	return pool
 }
 
+// systemCertPool returns a copy of the system certificate pool.
+var systemCertPool = x509.SystemCertPool
+
+// loadSystemCerts returns a copy of the system certificate pool, or nil if it
+// could not be loaded.
+func loadSystemCerts() *x509.CertPool {
+	pool, err := systemCertPool()
+	if err != nil {
+		tracerx.Printf("Error loading system certs: %v", err)
+		return nil
+	}
+	return pool
+}
+
 func appendCertsFromFilesInDir(pool *x509.CertPool, dir string) *x509.CertPool {
 	dirpath, errpath := tools.TranslateCygwinPath(dir)
 	if errpath != nil {
@@ -1,15 +1,24 @@
 package lfshttp
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/git-lfs/git-lfs/v3/creds"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testCert = `-----BEGIN CERTIFICATE-----
@@ -206,6 +215,113 @@ func TestCertFromSSLCAPathEnv(t *testing.T) {
 	}
 }
 
+// newTestCA returns a self-signed CA certificate which is not otherwise
+// trusted.
+func newTestCA(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "system.git-lfs.local"},
+		NotBefore:             time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return cert
+}
+
+// stubSystemCertPool makes the system certificate pool contain only the given
+// certificate for the duration of the test.
+func stubSystemCertPool(t *testing.T, cert *x509.Certificate) {
+	old := systemCertPool
+	systemCertPool = func() (*x509.CertPool, error) {
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		return pool, nil
+	}
+	t.Cleanup(func() { systemCertPool = old })
+}
+
+// isTrustedBy returns whether the given self-signed certificate is trusted by
+// the given pool.
+func isTrustedBy(cert *x509.Certificate, pool *x509.CertPool) bool {
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	return err == nil
+}
+
+func TestCertFromSSLCAInfoConfigForHostAddsToSystemCerts(t *testing.T) {
+	system := newTestCA(t)
+	stubSystemCertPool(t, system)
+
+	block, _ := pem.Decode([]byte(testCert))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.Nil(t, err)
+
+	tempfile := filepath.Join(t.TempDir(), "testcert")
+	require.Nil(t, os.WriteFile(tempfile, []byte(testCert), 0644))
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"http.https://git-lfs.local.sslcainfo": tempfile,
+	}))
+	require.Nil(t, err)
+
+	pool := getRootCAsForHost(c, "git-lfs.local")
+	assert.True(t, isTrustedBy(cert, pool))
+	assert.True(t, isTrustedBy(system, pool))
+
+	assert.Nil(t, getRootCAsForHost(c, "wronghost.com"))
+
+	// The global setting replaces the system certificates.
+	c, err = NewClient(NewContext(nil, nil, map[string]string{
+		"http.sslcainfo": tempfile,
+	}))
+	require.Nil(t, err)
+
+	pool = getRootCAsForHost(c, "git-lfs.local")
+	assert.True(t, isTrustedBy(cert, pool))
+	assert.False(t, isTrustedBy(system, pool))
+}
+
+func TestCertFromSSLCAPathConfigForHost(t *testing.T) {
+	system := newTestCA(t)
+	stubSystemCertPool(t, system)
+
+	block, _ := pem.Decode([]byte(testCert))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.Nil(t, err)
+
+	tempdir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(tempdir, "cert1.pem"), []byte(testCert), 0644))
+
+	for _, hostName := range sslCAInfoConfigHostNames {
+		c, err := NewClient(NewContext(nil, nil, map[string]string{
+			fmt.Sprintf("http.https://%v.sslcapath", hostName): tempdir,
+		}))
+		require.Nil(t, err)
+
+		for _, matchedHostTest := range sslCAInfoMatchedHostTests {
+			pool := getRootCAsForHost(c, matchedHostTest.hostName)
+			if matchedHostTest.shouldMatch {
+				assert.True(t, isTrustedBy(cert, pool), "%s with %s", matchedHostTest.hostName, hostName)
+				assert.True(t, isTrustedBy(system, pool), "%s with %s", matchedHostTest.hostName, hostName)
+			} else {
+				assert.Nil(t, pool, "%s with %s", matchedHostTest.hostName, hostName)
+			}
+		}
+	}
+}
+
 func TestCertVerifyDisabledGlobalEnv(t *testing.T) {
 	empty, _ := NewClient(nil)
 	httpClient := clientForHost(empty, "anyhost.com")
@@ -278,3 +394,70 @@ func TestCertVerifyDisabledHostConfig(t *testing.T) {
 		assert.False(t, tr.TLSClientConfig.InsecureSkipVerify)
 	}
 }
+
+func writeClientCertFiles(t *testing.T, key string) (string, string) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "client.crt")
+	keyPath := filepath.Join(dir, "client.key")
+	assert.Nil(t, os.WriteFile(certPath, []byte(testPKCS8Cert), 0644))
+	assert.Nil(t, os.WriteFile(keyPath, []byte(key), 0600))
+	return certPath, keyPath
+}
+
+func TestClientCertFromHostConfig(t *testing.T) {
+	certPath, keyPath := writeClientCertFiles(t, testPKCS8Key)
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"http.https://specifichost.com/.sslcert": certPath,
+		"http.https://specifichost.com/.sslkey":  keyPath,
+	}))
+	assert.Nil(t, err)
+
+	assert.True(t, isClientCertEnabledForHost(c, "specifichost.com"))
+	assert.False(t, isClientCertEnabledForHost(c, "otherhost.com"))
+
+	cert, err := getClientCertForHost(c, "specifichost.com")
+	assert.Nil(t, err)
+	if assert.NotNil(t, cert) {
+		assert.NotNil(t, cert.PrivateKey)
+	}
+}
+
+func TestClientCertFromGlobalConfig(t *testing.T) {
+	certPath, keyPath := writeClientCertFiles(t, testPKCS8Key)
+	_, badKeyPath := writeClientCertFiles(t, "not a key")
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"http.sslcert":                          certPath,
+		"http.sslkey":                           keyPath,
+		"http.https://specifichost.com/.sslkey": badKeyPath,
+	}))
+	assert.Nil(t, err)
+
+	// The global certificate is used for hosts without their own.
+	assert.True(t, isClientCertEnabledForHost(c, "otherhost.com"))
+	cert, err := getClientCertForHost(c, "otherhost.com")
+	assert.Nil(t, err)
+	assert.NotNil(t, cert)
+
+	// A host-specific key takes precedence over the global one.
+	_, err = getClientCertForHost(c, "specifichost.com")
+	assert.NotNil(t, err)
+}
+
+func TestClientCertWithEncryptedPKCS8Key(t *testing.T) {
+	certPath, keyPath := writeClientCertFiles(t, testPKCS8KeyAES256)
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"http.https://specifichost.com/.sslcert": certPath,
+		"http.https://specifichost.com/.sslkey":  keyPath,
+		"lfs.credentialhelper":                   `!f() { test "$1" = get && echo password=pass; }; f`,
+	}))
+	assert.Nil(t, err)
+
+	cert, err := getClientCertForHost(c, "specifichost.com")
+	assert.Nil(t, err)
+	if assert.NotNil(t, cert) {
+		assert.NotNil(t, cert.PrivateKey)
+	}
+}
@@ -47,6 +47,10 @@ type Client struct {
 	KeepaliveTimeout    int
 	TLSTimeout          int
 	ConcurrentTransfers int
+	// MaxIdleConnsPerHost is the number of idle connections to keep open
+	// to each host for reuse, or zero to keep as many as there are
+	// concurrent transfers.
+	MaxIdleConnsPerHost int
 	SkipSSLVerify       bool
 
 	Verbose          bool
@@ -54,7 +58,12 @@ type Client struct {
 	VerboseOut       io.Writer
 
 	hostClients map[hostData]*http.Client
-	clientMu    sync.Mutex
+	// hostTransports holds the transport for each host which is shared
+	// by the clients for all access modes except Negotiate, so that the
+	// batch API requests and the transfers which follow reuse the same
+	// connections.
+	hostTransports map[string]http.RoundTripper
+	clientMu       sync.Mutex
 
 	httpLogger *syncLogger
 
@@ -65,6 +74,16 @@ type Client struct {
 	credHelperContext *creds.CredentialHelperContext
 
 	sshTries int
+
+	// RetryBackoff is the base delay before retrying a request, which is
+	// doubled on each subsequent retry, up to MaxRetryDelay. Retries are
+	// abandoned once RetryDeadline has passed since the first attempt.
+	RetryBackoff  time.Duration
+	MaxRetryDelay time.Duration
+	RetryDeadline time.Duration
+
+	jitter func(n int64) int64
+	sleep  func(d time.Duration)
 }
 
 func NewClient(ctx Context) (*Client, error) {
@@ -87,6 +106,7 @@ func NewClient(ctx Context) (*Client, error) {
 		KeepaliveTimeout:    gitEnv.Int("lfs.keepalive", 0),
 		TLSTimeout:          gitEnv.Int("lfs.tlstimeout", 0),
 		ConcurrentTransfers: gitEnv.Int("lfs.concurrenttransfers", 8),
+		MaxIdleConnsPerHost: gitEnv.Int("lfs.transfer.maxidleconnsperhost", 0),
 		SkipSSLVerify:       !gitEnv.Bool("http.sslverify", true) || osEnv.Bool("GIT_SSL_NO_VERIFY", false),
 		Verbose:             osEnv.Bool("GIT_CURL_VERBOSE", false),
 		DebuggingVerbose:    osEnv.Bool("LFS_DEBUG_HTTP", false),
@@ -95,6 +115,19 @@ func NewClient(ctx Context) (*Client, error) {
 		uc:                  config.NewURLConfig(gitEnv),
 		sshTries:            gitEnv.Int("lfs.ssh.retries", 5),
 		credHelperContext:   creds.NewCredentialHelperContext(gitEnv, osEnv),
+		RetryBackoff:        defaultRetryBackoff,
+		MaxRetryDelay:       defaultMaxRetryDelay,
+		RetryDeadline:       defaultRetryDeadline,
+	}
+
+	if v := gitEnv.Int("lfs.transfer.retrybackoff", -1); v > -1 {
+		c.RetryBackoff = time.Duration(v) * time.Millisecond
+	}
+	if v := gitEnv.Int("lfs.transfer.maxretrydelay", -1); v > -1 {
+		c.MaxRetryDelay = time.Duration(v) * time.Second
+	}
+	if v := gitEnv.Int("lfs.transfer.retrydeadline", -1); v > -1 {
+		c.RetryDeadline = time.Duration(v) * time.Second
 	}
 
 	return c, nil
@@ -293,18 +326,35 @@ func (c *Client) DoWithRedirect(cli *http.Client, req *http.Request, remote stri
 
 	var res *http.Response
 
+	start := time.Now()
 	requests := tools.MaxInt(0, retries) + 1
-	for i := 0; i < requests; i++ {
+	for i := 1; ; i++ {
 		res, err = cli.Do(req)
-		if err == nil {
+		if i >= requests || req.Context().Err() != nil || !isRetriableResponse(res, err) {
 			break
 		}
 
+		delay := c.retryDelay(i, res)
+		if c.RetryDeadline > 0 && time.Since(start)+delay > c.RetryDeadline {
+			tracerx.Printf("http: not retrying %s %s, deadline of %s exceeded", req.Method, req.URL, c.RetryDeadline)
+			break
+		}
+
+		if res != nil {
+			c.traceResponse(req, tracedReq, res)
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		} else {
+			c.traceResponse(req, tracedReq, nil)
+		}
+
 		if seek, ok := req.Body.(io.Seeker); ok {
 			seek.Seek(0, io.SeekStart)
 		}
 
-		c.traceResponse(req, tracedReq, nil)
+		tracerx.Printf("http: retrying %s %s in %s (retry %d of %d)", req.Method, req.URL, delay, i, requests-1)
+		c.httpLogger.LogRetry(req, i)
+		c.sleepFor(delay)
 	}
 
 	if err != nil {
@@ -378,6 +428,13 @@ func (c *Client) doWithRedirects(cli *http.Client, req *http.Request, remote str
 
 func (c *Client) configureProtocols(u *url.URL, transport *http.Transport) error {
 	version, _ := c.uc.Get("http", u.String(), "version")
+	if !c.gitEnv.Bool("lfs.transfer.http2", true) {
+		// HTTP/2 is used by default where the server supports
+		// it, unless it is disabled with this setting.
+		tracerx.Printf("http: HTTP/2 disabled by lfs.transfer.http2")
+		version = "HTTP/1.1"
+	}
+
 	switch version {
 	case "HTTP/1.1":
 		// This disables HTTP/2, according to the documentation.
@@ -426,10 +483,16 @@ func (c *Client) Transport(u *url.URL, access creds.AccessMode) (http.RoundTripp
 	if tlstime < 1 {
 		tlstime = 30
 	}
+
+	maxIdleConns := c.MaxIdleConnsPerHost
+	if maxIdleConns < 1 {
+		maxIdleConns = concurrentTransfers
+	}
+
 	tr := &http.Transport{
 		Proxy:               proxyFromClient(c),
 		TLSHandshakeTimeout: time.Duration(tlstime) * time.Second,
-		MaxIdleConnsPerHost: concurrentTransfers,
+		MaxIdleConnsPerHost: maxIdleConns,
 	}
 
 	activityTimeout := 30
@@ -514,7 +577,7 @@ func (c *Client) HttpClient(u *url.URL, access creds.AccessMode) (*http.Client,
 		return client, nil
 	}
 
-	tr, err := c.Transport(u, access)
+	tr, err := c.hostTransport(u, access)
 	if err != nil {
 		return nil, err
 	}
@@ -543,6 +606,29 @@ func (c *Client) HttpClient(u *url.URL, access creds.AccessMode) (*http.Client,
 	return httpClient, nil
 }
 
+// hostTransport returns the transport for requests to the host of the given
+// URL with the given access mode, which is shared with other access modes
+// unless it is Negotiate. The client's mutex must be held.
+func (c *Client) hostTransport(u *url.URL, access creds.AccessMode) (http.RoundTripper, error) {
+	if access == creds.NegotiateAccess {
+		return c.Transport(u, access)
+	}
+
+	if c.hostTransports == nil {
+		c.hostTransports = make(map[string]http.RoundTripper)
+	}
+	if tr, ok := c.hostTransports[u.Host]; ok {
+		return tr, nil
+	}
+
+	tr, err := c.Transport(u, access)
+	if err != nil {
+		return nil, err
+	}
+	c.hostTransports[u.Host] = tr
+	return tr, nil
+}
+
 func (c *Client) CurrentUser() (string, string) {
 	userName, _ := c.gitEnv.Get("user.name")
 	userEmail, _ := c.gitEnv.Get("user.email")
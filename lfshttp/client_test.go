@@ -7,9 +7,11 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sync/atomic"
 	"testing"
 
+	"github.com/git-lfs/git-lfs/v3/creds"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -409,3 +411,88 @@ func TestHttpVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestClientHTTP2(t *testing.T) {
+	for setting, proto := range map[string]string{
+		"":      "HTTP/2.0",
+		"true":  "HTTP/2.0",
+		"false": "HTTP/1.1",
+	} {
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, proto, r.Proto, setting)
+			w.WriteHeader(200)
+		}))
+		srv.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+		srv.StartTLS()
+		defer srv.Close()
+
+		gitEnv := map[string]string{"http.sslverify": "false"}
+		if len(setting) > 0 {
+			gitEnv["lfs.transfer.http2"] = setting
+		}
+		c, err := NewClient(NewContext(nil, nil, gitEnv))
+		require.Nil(t, err)
+
+		req, err := http.NewRequest("GET", srv.URL, nil)
+		require.Nil(t, err)
+
+		res, err := c.Do(req)
+		require.Nil(t, err, setting)
+		assert.Equal(t, proto, res.Proto, setting)
+	}
+}
+
+func TestClientMaxIdleConnsPerHost(t *testing.T) {
+	u, err := url.Parse("https://example.com")
+	require.Nil(t, err)
+
+	for setting, expected := range map[string]int{
+		"":   3,
+		"0":  3,
+		"16": 16,
+	} {
+		c, err := NewClient(NewContext(nil, nil, map[string]string{
+			"lfs.concurrenttransfers":          "3",
+			"lfs.transfer.maxidleconnsperhost": setting,
+		}))
+		require.Nil(t, err)
+
+		rt, err := c.Transport(u, creds.NoneAccess)
+		require.Nil(t, err)
+		assert.Equal(t, expected, rt.(*http.Transport).MaxIdleConnsPerHost, setting)
+	}
+}
+
+func TestClientReusesConnections(t *testing.T) {
+	var conns uint32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path})
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddUint32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	c, err := NewClient(nil)
+	require.Nil(t, err)
+
+	// A batch API request, made with credentials, followed by transfers
+	// made without them, should all use one connection.
+	for i, mode := range []creds.AccessMode{creds.BasicAccess, creds.NoneAccess, creds.NoneAccess} {
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/%d", srv.URL, i), nil)
+		require.Nil(t, err)
+
+		res, err := c.DoWithAccess(req, mode)
+		require.Nil(t, err)
+
+		var body map[string]string
+		require.Nil(t, DecodeJSON(res, &body))
+		assert.Equal(t, fmt.Sprintf("/%d", i), body["path"])
+	}
+
+	assert.EqualValues(t, 1, atomic.LoadUint32(&conns))
+}
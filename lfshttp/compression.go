@@ -0,0 +1,122 @@
+package lfshttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+// AcceptsContentEncoding returns whether the given value of an
+// "Accept-Encoding" header includes the given content coding, as a server may
+// send in a response to advertise the codings it accepts in requests.
+func AcceptsContentEncoding(header, coding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) {
+			continue
+		}
+
+		// A quality value of zero means that the coding is not
+		// acceptable.
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// CompressRequestBody replaces the body of req with a gzip-compressed copy of
+// it, and sets the "Content-Encoding" and "Content-Length" headers to match.
+func CompressRequestBody(req *http.Request) error {
+	if req.Body == nil {
+		return nil
+	}
+
+	by, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body.Close()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(by); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	clen := buf.Len()
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Content-Length", strconv.Itoa(clen))
+	req.ContentLength = int64(clen)
+	req.Body = NewByteBody(buf.Bytes())
+	return nil
+}
+
+// DecodeResponseBody replaces the body of res with a reader which decodes it
+// according to its "Content-Encoding" header, which may be "gzip" or
+// "deflate". This is only needed when the "Accept-Encoding" header of the
+// request was set explicitly, since otherwise net/http decodes gzip-encoded
+// responses itself.
+func DecodeResponseBody(res *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+	if len(encoding) == 0 || encoding == "identity" {
+		return nil
+	}
+
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch encoding {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(res.Body)
+	case "deflate":
+		r, err = zlib.NewReader(res.Body)
+	default:
+		return errors.New(tr.Tr.Get("unsupported Content-Encoding in response: %q", encoding))
+	}
+	if err == io.EOF {
+		// An empty body has nothing to decode.
+		r, err = io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	if err != nil {
+		return errors.Wrap(err, tr.Tr.Get("could not decode %s response", encoding))
+	}
+
+	res.Body = &decodedBody{ReadCloser: r, body: res.Body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return nil
+}
+
+// decodedBody reads from a decoder of an encoded response body, and closes
+// both the decoder and the body. Any of the body left unread by the decoder is
+// discarded first, so that the stats of the response are logged.
+type decodedBody struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	err := b.ReadCloser.Close()
+	io.Copy(io.Discard, b.body)
+	if berr := b.body.Close(); err == nil {
+		err = berr
+	}
+	return err
+}
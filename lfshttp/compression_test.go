@@ -0,0 +1,95 @@
+package lfshttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsContentEncoding(t *testing.T) {
+	assert.True(t, AcceptsContentEncoding("gzip", "gzip"))
+	assert.True(t, AcceptsContentEncoding("deflate, GZIP;q=0.5", "gzip"))
+	assert.False(t, AcceptsContentEncoding("gzip;q=0", "gzip"))
+	assert.False(t, AcceptsContentEncoding("deflate", "gzip"))
+	assert.False(t, AcceptsContentEncoding("", "gzip"))
+}
+
+func TestCompressRequestBody(t *testing.T) {
+	req, err := http.NewRequest("POST", "https://example.com/objects/batch", nil)
+	require.Nil(t, err)
+	require.Nil(t, MarshalToRequest(req, map[string]string{"operation": "upload"}))
+
+	require.Nil(t, CompressRequestBody(req))
+	assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+
+	by, err := io.ReadAll(req.Body)
+	require.Nil(t, err)
+	assert.EqualValues(t, len(by), req.ContentLength)
+
+	gz, err := gzip.NewReader(bytes.NewReader(by))
+	require.Nil(t, err)
+	decoded, err := io.ReadAll(gz)
+	require.Nil(t, err)
+	assert.Equal(t, `{"operation":"upload"}`, string(decoded))
+}
+
+func TestDecodeResponseBodyGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`{"objects":[]}`))
+	gz.Close()
+
+	res := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   io.NopCloser(&buf),
+	}
+	require.Nil(t, DecodeResponseBody(res))
+
+	by, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, `{"objects":[]}`, string(by))
+	assert.Empty(t, res.Header.Get("Content-Encoding"))
+	assert.True(t, res.Uncompressed)
+	assert.Nil(t, res.Body.Close())
+}
+
+func TestDecodeResponseBodyDeflate(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte(`{"objects":[]}`))
+	zw.Close()
+
+	res := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"deflate"}},
+		Body:   io.NopCloser(&buf),
+	}
+	require.Nil(t, DecodeResponseBody(res))
+
+	by, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, `{"objects":[]}`, string(by))
+}
+
+func TestDecodeResponseBodyIdentity(t *testing.T) {
+	body := io.NopCloser(bytes.NewBufferString("plain"))
+	res := &http.Response{Header: http.Header{}, Body: body}
+	require.Nil(t, DecodeResponseBody(res))
+	assert.Equal(t, body, res.Body)
+	assert.False(t, res.Uncompressed)
+}
+
+func TestDecodeResponseBodyUnsupported(t *testing.T) {
+	res := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"br"}},
+		Body:   io.NopCloser(bytes.NewBufferString("")),
+	}
+	err := DecodeResponseBody(res)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unsupported Content-Encoding in response: "br"`)
+}
@@ -2,8 +2,8 @@ package lfshttp
 
 import (
 	"fmt"
+	"net"
 	"net/url"
-	"regexp"
 	"strings"
 
 	"github.com/git-lfs/git-lfs/v3/git"
@@ -36,32 +36,29 @@ func endpointOperation(e Endpoint, method string) string {
 // EndpointFromSshUrl constructs a new endpoint from an ssh:// URL
 func EndpointFromSshUrl(u *url.URL) Endpoint {
 	var endpoint Endpoint
-	// Pull out port now, we need it separately for SSH
-	regex := regexp.MustCompile(`^([^\:]+)(?:\:(\d+))?$`)
-	match := regex.FindStringSubmatch(u.Host)
-	if match == nil || len(match) < 2 {
+	// Pull out port now, we need it separately for SSH. The host may be
+	// a bracketed IPv6 literal, which Hostname() returns without the
+	// brackets.
+	host := u.Hostname()
+	if len(host) == 0 {
 		endpoint.Url = UrlUnknown
 		return endpoint
 	}
 
 	endpoint.OriginalUrl = u.String()
 
-	host := match[1]
 	if u.User != nil && u.User.Username() != "" {
 		endpoint.SSHMetadata.UserAndHost = fmt.Sprintf("%s@%s", u.User.Username(), host)
 	} else {
 		endpoint.SSHMetadata.UserAndHost = host
 	}
 
-	if len(match) > 2 {
-		endpoint.SSHMetadata.Port = match[2]
-	}
-
+	endpoint.SSHMetadata.Port = u.Port()
 	endpoint.SSHMetadata.Path = u.Path
 
 	// Fallback URL for using HTTPS while still using SSH for git
 	// u.Host includes host & port so can't use SSH port
-	endpoint.Url = fmt.Sprintf("https://%s%s", host, u.Path)
+	endpoint.Url = fmt.Sprintf("https://%s%s", urlHost(host), u.Path)
 
 	return endpoint
 }
@@ -69,8 +66,13 @@ func EndpointFromSshUrl(u *url.URL) Endpoint {
 // EndpointFromBareSshUrl constructs a new endpoint from a bare SSH URL:
 //
 //	user@host.com:path/to/repo.git or
-//	[user@host.com:port]:path/to/repo.git
+//	[user@host.com:port]:path/to/repo.git or
+//	user@[fe80::1%eth0]:path/to/repo.git
 func EndpointFromBareSshUrl(rawurl string) Endpoint {
+	if u, ok := bareSshUrlWithIPv6Host(rawurl); ok {
+		return endpointFromBareSshUrl(u)
+	}
+
 	parts := strings.Split(rawurl, ":")
 	partsLen := len(parts)
 	if partsLen < 2 {
@@ -93,13 +95,69 @@ func EndpointFromBareSshUrl(rawurl string) Endpoint {
 		return Endpoint{Url: UrlUnknown}
 	}
 
-	endpoint := EndpointFromSshUrl(newu)
+	return endpointFromBareSshUrl(newu)
+}
+
+func endpointFromBareSshUrl(u *url.URL) Endpoint {
+	endpoint := EndpointFromSshUrl(u)
 	if strings.HasPrefix(endpoint.SSHMetadata.Path, "/") {
 		endpoint.SSHMetadata.Path = endpoint.SSHMetadata.Path[1:]
 	}
 	return endpoint
 }
 
+// bareSshUrlWithIPv6Host returns the ssh:// URL equivalent to a bare SSH URL
+// whose host is a bracketed IPv6 literal, such as "user@[::1]:path" or
+// "[user@::1]:path", and whether the URL has such a host. The IPv6 literal
+// may include a zone identifier, which may be percent-encoded.
+func bareSshUrlWithIPv6Host(rawurl string) (*url.URL, bool) {
+	start := strings.Index(rawurl, "[")
+	if start < 0 || strings.ContainsAny(rawurl[:start], ":/") {
+		return nil, false
+	}
+	end := strings.Index(rawurl[start:], "]:")
+	if end < 0 {
+		return nil, false
+	}
+	end += start
+
+	userAndHost := rawurl[:start] + rawurl[start+1:end]
+	path := rawurl[end+2:]
+
+	var user string
+	host := userAndHost
+	if at := strings.LastIndex(userAndHost, "@"); at >= 0 {
+		user, host = userAndHost[:at], userAndHost[at+1:]
+	}
+	if unescaped, err := url.PathUnescape(host); err == nil {
+		host = unescaped
+	}
+
+	ip, _, _ := strings.Cut(host, "%")
+	if !strings.Contains(ip, ":") || net.ParseIP(ip) == nil {
+		return nil, false
+	}
+
+	u := &url.URL{
+		Scheme: "ssh",
+		Host:   "[" + host + "]",
+		Path:   "/" + path,
+	}
+	if len(user) > 0 {
+		u.User = url.User(user)
+	}
+	return u, true
+}
+
+// urlHost returns the given host as it should appear in a URL, with an IPv6
+// literal enclosed in brackets and its zone identifier percent-encoded.
+func urlHost(host string) string {
+	if !strings.Contains(host, ":") {
+		return host
+	}
+	return "[" + strings.Replace(host, "%", "%25", 1) + "]"
+}
+
 // Construct a new endpoint from a HTTP URL
 func EndpointFromHttpUrl(u *url.URL) Endpoint {
 	// just pass this straight through
@@ -2,6 +2,7 @@ package lfshttp
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"regexp"
 
@@ -11,6 +12,10 @@ import (
 	"github.com/git-lfs/git-lfs/v3/tr"
 )
 
+// maxDrainedBytes is the most data which is read and discarded from the end of
+// a response body before it is closed, so that its connection may be reused.
+const maxDrainedBytes = 4096
+
 var (
 	lfsMediaTypeRE  = regexp.MustCompile(`\Aapplication/vnd\.git\-lfs\+json(;|\z)`)
 	jsonMediaTypeRE = regexp.MustCompile(`\Aapplication/json(;|\z)`)
@@ -81,6 +86,9 @@ func DecodeJSON(res *http.Response, obj interface{}) error {
 	}
 
 	err := json.NewDecoder(res.Body).Decode(obj)
+	// Read any trailing data, such as a final newline, so that the
+	// connection can be reused for a later request.
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainedBytes))
 	res.Body.Close()
 
 	if err != nil {
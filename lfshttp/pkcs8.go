@@ -0,0 +1,153 @@
+package lfshttp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"hash"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo structure of an
+// encrypted PKCS#8 private key, as defined in RFC 5208.
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPKCS8PrivateKey decrypts the DER-encoded contents of an "ENCRYPTED
+// PRIVATE KEY" PEM block with the given password, and returns the DER-encoded
+// unencrypted PKCS#8 private key.  Only keys encrypted using PBES2 with PBKDF2
+// and either AES-CBC or 3DES-CBC, which is what OpenSSL produces, are
+// supported.
+func decryptPKCS8PrivateKey(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("invalid encrypted PKCS#8 private key"))
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, errors.New(tr.Tr.Get("unsupported PKCS#8 encryption algorithm %s", info.Algorithm.Algorithm))
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("invalid PKCS#8 encryption parameters"))
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.New(tr.Tr.Get("unsupported PKCS#8 key derivation function %s", params.KeyDerivationFunc.Algorithm))
+	}
+
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("invalid PKCS#8 key derivation parameters"))
+	}
+	prf, err := pkcs8PRF(kdfParams.PRF.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	newCipher, keyLen, err := pkcs8Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, errors.Wrap(err, tr.Tr.Get("invalid PKCS#8 encryption parameters"))
+	}
+
+	key := pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, keyLen, prf)
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data := info.EncryptedData
+	if len(iv) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New(tr.Tr.Get("invalid encrypted PKCS#8 private key"))
+	}
+
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+
+	// Remove the PKCS#7 padding, which is where an incorrect password is
+	// usually first noticed.
+	pad := int(decrypted[len(decrypted)-1])
+	if pad == 0 || pad > block.BlockSize() {
+		return nil, errors.New(tr.Tr.Get("PKCS#8 decryption failed: incorrect password?"))
+	}
+	for _, b := range decrypted[len(decrypted)-pad:] {
+		if int(b) != pad {
+			return nil, errors.New(tr.Tr.Get("PKCS#8 decryption failed: incorrect password?"))
+		}
+	}
+	return decrypted[:len(decrypted)-pad], nil
+}
+
+// pkcs8PRF returns the hash function for the given PBKDF2 pseudorandom
+// function, which defaults to HMAC-SHA1 if none is specified.
+func pkcs8PRF(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACWithSHA224):
+		return sha256.New224, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, errors.New(tr.Tr.Get("unsupported PKCS#8 key derivation function %s", oid))
+}
+
+// pkcs8Cipher returns a constructor for the block cipher identified by the
+// given OID, and the length of its key.
+func pkcs8Cipher(oid asn1.ObjectIdentifier) (func([]byte) (cipher.Block, error), int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	}
+	return nil, 0, errors.New(tr.Tr.Get("unsupported PKCS#8 encryption algorithm %s", oid))
+}
@@ -23,6 +23,10 @@ func proxyFromClient(c *Client) func(req *http.Request) (*url.URL, error) {
 			proxy = httpProxy
 		}
 
+		if lfsProxy, ok := getLFSProxy(c, req.URL); ok {
+			proxy = lfsProxy
+		}
+
 		if len(proxy) == 0 {
 			return nil, nil
 		}
@@ -89,3 +93,13 @@ func getProxyServers(u *url.URL, urlCfg *config.URLConfig, osEnv config.Environm
 
 	return
 }
+
+// getLFSProxy returns the proxy given for the host of u by lfs.proxy.<host>,
+// if it is set.  This takes precedence over http.proxy and the proxy
+// environment variables.  An empty proxy means that none is to be used.
+func getLFSProxy(c *Client, u *url.URL) (proxy string, ok bool) {
+	if c.gitEnv == nil {
+		return "", false
+	}
+	return c.gitEnv.Get("lfs.proxy." + strings.ToLower(u.Hostname()))
+}
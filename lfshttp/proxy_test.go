@@ -169,3 +169,49 @@ func TestSocks5hProxyFromEnvironment(t *testing.T) {
 	assert.Equal(t, "proxy-from-env:3128", proxyURL.Host)
 	assert.Nil(t, err)
 }
+
+func TestProxyForHostFromLFSConfig(t *testing.T) {
+	c, err := NewClient(NewContext(nil, map[string]string{
+		"HTTPS_PROXY": "https://proxy-from-env:8080",
+	}, map[string]string{
+		"http.proxy":                 "https://proxy-from-git-config:8080",
+		"lfs.proxy.some-host.com":    "https://proxy-for-some-host:8080",
+		"lfs.proxy.direct-host.com":  "",
+		"lfs.proxy.another-host.com": "https://proxy-for-another-host:8080",
+	}))
+	require.Nil(t, err)
+
+	req, err := http.NewRequest("GET", "https://Some-Host.com:123/foo/bar", nil)
+	require.Nil(t, err)
+	proxyURL, err := proxyFromClient(c)(req)
+	assert.Equal(t, "proxy-for-some-host:8080", proxyURL.Host)
+	assert.Nil(t, err)
+
+	req, err = http.NewRequest("GET", "https://direct-host.com/foo/bar", nil)
+	require.Nil(t, err)
+	proxyURL, err = proxyFromClient(c)(req)
+	assert.Nil(t, proxyURL)
+	assert.Nil(t, err)
+
+	req, err = http.NewRequest("GET", "https://other-host.com/foo/bar", nil)
+	require.Nil(t, err)
+	proxyURL, err = proxyFromClient(c)(req)
+	assert.Equal(t, "proxy-from-git-config:8080", proxyURL.Host)
+	assert.Nil(t, err)
+}
+
+func TestProxyForHostNoProxy(t *testing.T) {
+	c, err := NewClient(NewContext(nil, map[string]string{
+		"NO_PROXY": "some-host.com",
+	}, map[string]string{
+		"lfs.proxy.some-host.com": "https://proxy-for-some-host:8080",
+	}))
+	require.Nil(t, err)
+
+	req, err := http.NewRequest("GET", "https://some-host.com/foo/bar", nil)
+	require.Nil(t, err)
+
+	proxyURL, err := proxyFromClient(c)(req)
+	assert.Nil(t, proxyURL)
+	assert.Nil(t, err)
+}
@@ -2,7 +2,11 @@ package lfshttp
 
 import (
 	"context"
+	"math/rand"
 	"net/http"
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
 )
 
 // ckey is a type that wraps a string for package-unique context.Context keys.
@@ -16,6 +20,19 @@ const (
 	// defaultRequestRetries is the default number of retries to perform on
 	// a given HTTP request.
 	defaultRequestRetries = 0
+
+	// defaultRetryBackoff is the default base delay between retries of a
+	// request, which is doubled on each subsequent retry.
+	defaultRetryBackoff = 250 * time.Millisecond
+
+	// defaultMaxRetryDelay is the default maximum delay between two
+	// retries of a request, unless a longer delay is requested by the
+	// server.
+	defaultMaxRetryDelay = 10 * time.Second
+
+	// defaultRetryDeadline is the default maximum total time to spend
+	// retrying a single request.
+	defaultRetryDeadline = 60 * time.Second
 )
 
 // WithRetries stores the desired number of retries "n" on the given
@@ -34,3 +51,65 @@ func Retries(req *http.Request) (int, bool) {
 
 	return n, ok
 }
+
+// isRetriableResponse returns whether a request which resulted in the given
+// response and error may be retried, either because it failed with a network
+// related error, or because the server is temporarily unable to handle it.
+func isRetriableResponse(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before making the given retry, numbered
+// from one, of a request which resulted in the response "res" (which may be
+// nil). If the server asked for a delay using the Retry-After header, that
+// delay is used. Otherwise, the delay is chosen at random between zero and a
+// limit which doubles on each retry, up to c.MaxRetryDelay, so that clients
+// which failed at the same time do not all retry at the same time.
+func (c *Client) retryDelay(retry int, res *http.Response) time.Duration {
+	if res != nil {
+		err := errors.NewRetriableLaterError(nil, res.Header.Get("Retry-After"))
+		if readyAt, ok := errors.IsRetriableLaterError(err); ok {
+			if delay := time.Until(readyAt); delay > 0 {
+				return delay
+			}
+			return 0
+		}
+	}
+
+	if c.RetryBackoff <= 0 {
+		return 0
+	}
+
+	limit := c.MaxRetryDelay
+	if retry < 32 {
+		if d := c.RetryBackoff << uint(retry-1); d > 0 && (limit <= 0 || d < limit) {
+			limit = d
+		}
+	}
+	if limit <= 0 {
+		return 0
+	}
+
+	jitter := c.jitter
+	if jitter == nil {
+		jitter = rand.Int63n
+	}
+	return time.Duration(jitter(int64(limit) + 1))
+}
+
+// sleepFor waits for the given delay before a request is retried.
+func (c *Client) sleepFor(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
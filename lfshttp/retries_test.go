@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -84,3 +85,119 @@ func TestRequestWithRetries(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 }
+
+func TestRetryDelayBacksOffExponentially(t *testing.T) {
+	c, err := NewClient(nil)
+	require.NoError(t, err)
+
+	// Always choose the longest possible delay.
+	c.jitter = func(n int64) int64 { return n - 1 }
+	c.RetryBackoff = 100 * time.Millisecond
+	c.MaxRetryDelay = time.Second
+
+	assert.Equal(t, 100*time.Millisecond, c.retryDelay(1, nil))
+	assert.Equal(t, 200*time.Millisecond, c.retryDelay(2, nil))
+	assert.Equal(t, 400*time.Millisecond, c.retryDelay(3, nil))
+	assert.Equal(t, 800*time.Millisecond, c.retryDelay(4, nil))
+	assert.Equal(t, time.Second, c.retryDelay(5, nil))
+	assert.Equal(t, time.Second, c.retryDelay(64, nil))
+}
+
+func TestRetryDelayIsJittered(t *testing.T) {
+	c, err := NewClient(nil)
+	require.NoError(t, err)
+
+	var limit int64
+	c.jitter = func(n int64) int64 {
+		limit = n
+		return n / 2
+	}
+	c.RetryBackoff = 100 * time.Millisecond
+
+	assert.Equal(t, 100*time.Millisecond, c.retryDelay(2, nil))
+	assert.EqualValues(t, 200*time.Millisecond+1, limit)
+}
+
+func TestRetryDelayWithoutBackoff(t *testing.T) {
+	c, err := NewClient(nil)
+	require.NoError(t, err)
+
+	c.RetryBackoff = 0
+
+	assert.Equal(t, time.Duration(0), c.retryDelay(3, nil))
+}
+
+func TestRetryDelayPrefersRetryAfter(t *testing.T) {
+	c, err := NewClient(nil)
+	require.NoError(t, err)
+
+	c.jitter = func(n int64) int64 { return n - 1 }
+	c.RetryBackoff = 100 * time.Millisecond
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "30")
+
+	delay := c.retryDelay(1, res)
+	assert.True(t, delay > 29*time.Second && delay <= 30*time.Second,
+		"lfshttp: expected delay of about 30s, got %s", delay)
+}
+
+func TestRequestRetriesUnavailableWithBackoff(t *testing.T) {
+	var requests uint32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddUint32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(nil)
+	require.NoError(t, err)
+
+	var delays []time.Duration
+	c.jitter = func(n int64) int64 { return n - 1 }
+	c.sleep = func(d time.Duration) { delays = append(delays, d) }
+	c.RetryBackoff = 100 * time.Millisecond
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	require.NoError(t, err)
+
+	res, err := c.Do(WithRetries(req, 8))
+	assert.NoError(t, err)
+	require.NotNil(t, res, "lfsapi: expected response")
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadUint32(&requests))
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, delays)
+}
+
+func TestRequestStopsRetryingAfterDeadline(t *testing.T) {
+	var requests uint32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&requests, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(nil)
+	require.NoError(t, err)
+
+	c.sleep = func(d time.Duration) {
+		t.Fatalf("lfshttp: expected no retries, slept for %s", d)
+	}
+	c.RetryDeadline = time.Minute
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	require.NoError(t, err)
+
+	res, err := c.Do(WithRetries(req, 8))
+	assert.Error(t, err)
+	require.NotNil(t, res, "lfsapi: expected response")
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadUint32(&requests))
+}
@@ -149,6 +149,18 @@ func (l *syncLogger) LogResponse(req *http.Request, status int, bodySize int64)
 	}
 }
 
+// LogRetry sends an event to the http log noting that the request is about to
+// be retried, where retry is the number of this retry, counting from one.
+func (l *syncLogger) LogRetry(req *http.Request, retry int) {
+	if l == nil {
+		return
+	}
+
+	if v := req.Context().Value(transferKey); v != nil {
+		l.logTransfer(v.(*httpTransfer), "retry", fmt.Sprintf(" retry=%d", retry))
+	}
+}
+
 func (l *syncLogger) logTransfer(t *httpTransfer, event, extra string) {
 	l.wg.Add(1)
 	l.ch <- fmt.Sprintf("key=%s event=%s url=%s method=%s%s\n",
@@ -0,0 +1,146 @@
+package lfshttp
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPStatsSummary summarizes the requests recorded in an HTTP stats log, as
+// written when GIT_LOG_STATS is set.
+type HTTPStatsSummary struct {
+	// Requests is the number of requests made, including retries.
+	Requests int `json:"requests"`
+	// Retries is the number of requests which were retries of an
+	// earlier request.
+	Retries int `json:"retries"`
+	// BytesSent and BytesReceived are the total sizes of the request and
+	// response bodies, respectively.
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	// LatencyP50 and LatencyP95 are percentiles of the time taken by each
+	// request which received a response, from the start of the request
+	// until its response body was read.
+	LatencyP50 time.Duration `json:"latency_p50_ns"`
+	LatencyP95 time.Duration `json:"latency_p95_ns"`
+	// Endpoints summarizes the requests for each endpoint, in the order
+	// in which each endpoint was first requested.
+	Endpoints []*HTTPEndpointStats `json:"endpoints"`
+}
+
+// HTTPEndpointStats summarizes the requests made for one purpose, identified
+// by the key given to Client.LogRequest, with one method to one host.
+type HTTPEndpointStats struct {
+	Key           string        `json:"key"`
+	Method        string        `json:"method"`
+	Host          string        `json:"host"`
+	Requests      int           `json:"requests"`
+	Retries       int           `json:"retries"`
+	BytesSent     int64         `json:"bytes_sent"`
+	BytesReceived int64         `json:"bytes_received"`
+	LatencyP50    time.Duration `json:"latency_p50_ns"`
+	LatencyP95    time.Duration `json:"latency_p95_ns"`
+
+	latencies []time.Duration
+}
+
+// SummarizeHTTPStats reads an HTTP stats log from r and returns a summary of
+// the requests recorded in it. Lines which are not request events, such as
+// the header line of the log, are ignored.
+func SummarizeHTTPStats(r io.Reader) (*HTTPStatsSummary, error) {
+	summary := &HTTPStatsSummary{Endpoints: make([]*HTTPEndpointStats, 0)}
+	endpoints := make(map[string]*HTTPEndpointStats)
+	var latencies []time.Duration
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := parseStatsLine(scanner.Text())
+		event := fields["event"]
+		if len(event) == 0 {
+			continue
+		}
+
+		host := fields["url"]
+		if u, err := url.Parse(host); err == nil && len(u.Host) > 0 {
+			host = u.Host
+		}
+
+		id := strings.Join([]string{fields["key"], fields["method"], host}, " ")
+		e, ok := endpoints[id]
+		if !ok {
+			e = &HTTPEndpointStats{Key: fields["key"], Method: fields["method"], Host: host}
+			endpoints[id] = e
+			summary.Endpoints = append(summary.Endpoints, e)
+		}
+
+		body, _ := strconv.ParseInt(fields["body"], 10, 64)
+		switch event {
+		case "request":
+			e.BytesSent += body
+			summary.BytesSent += body
+		case "response":
+			e.Requests++
+			summary.Requests++
+			e.BytesReceived += body
+			summary.BytesReceived += body
+
+			// A status of -1 is recorded when no response was
+			// received, in which case the timings are not
+			// meaningful.
+			if fields["status"] != "-1" {
+				if ns, err := strconv.ParseInt(fields["time"], 10, 64); err == nil {
+					e.latencies = append(e.latencies, time.Duration(ns))
+					latencies = append(latencies, time.Duration(ns))
+				}
+			}
+		case "retry":
+			e.Retries++
+			summary.Retries++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	summary.LatencyP50, summary.LatencyP95 = latencyPercentiles(latencies)
+	for _, e := range summary.Endpoints {
+		e.LatencyP50, e.LatencyP95 = latencyPercentiles(e.latencies)
+	}
+	return summary, nil
+}
+
+// parseStatsLine parses a line of space-separated "key=value" fields.
+func parseStatsLine(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range strings.Fields(line) {
+		if k, v, ok := strings.Cut(field, "="); ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// latencyPercentiles returns the 50th and 95th percentiles of the given
+// latencies, using the nearest-rank method, or zero if there are none.
+func latencyPercentiles(latencies []time.Duration) (p50, p95 time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := func(p int) time.Duration {
+		i := (p*len(sorted)+99)/100 - 1
+		if i < 0 {
+			i = 0
+		}
+		return sorted[i]
+	}
+	return rank(50), rank(95)
+}
@@ -0,0 +1,63 @@
+package lfshttp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStatsLog = `concurrent=8 time=1700000000 version=git-lfs/3.5.0
+key=lfs.batch event=request url=https://lfs.example.com/repo.git/info/lfs/objects/batch method=POST body=100
+key=lfs.batch event=response url=https://lfs.example.com/repo.git/info/lfs/objects/batch method=POST status=503 body=10 conntime=0 dnstime=0 tlstime=0 restime=0 time=30000000
+key=lfs.batch event=retry url=https://lfs.example.com/repo.git/info/lfs/objects/batch method=POST retry=1
+key=lfs.batch event=request url=https://lfs.example.com/repo.git/info/lfs/objects/batch method=POST body=200
+key=lfs.batch event=response url=https://lfs.example.com/repo.git/info/lfs/objects/batch method=POST status=200 body=300 conntime=0 dnstime=0 tlstime=0 restime=0 time=10000000
+key=lfs.data.download event=response url=https://storage.example.com/objects/1 method=GET status=200 body=1000 conntime=0 dnstime=0 tlstime=0 restime=0 time=20000000
+key=lfs.data.download event=response url=https://storage.example.com/objects/2 method=GET status=-1 body=0 conntime=0 dnstime=0 tlstime=0 restime=0 time=99000000000
+`
+
+func TestSummarizeHTTPStats(t *testing.T) {
+	summary, err := SummarizeHTTPStats(strings.NewReader(testStatsLog))
+	require.Nil(t, err)
+
+	assert.Equal(t, 4, summary.Requests)
+	assert.Equal(t, 1, summary.Retries)
+	assert.EqualValues(t, 300, summary.BytesSent)
+	assert.EqualValues(t, 1310, summary.BytesReceived)
+	assert.Equal(t, 20*time.Millisecond, summary.LatencyP50)
+	assert.Equal(t, 30*time.Millisecond, summary.LatencyP95)
+
+	require.Len(t, summary.Endpoints, 2)
+
+	batch := summary.Endpoints[0]
+	assert.Equal(t, "lfs.batch", batch.Key)
+	assert.Equal(t, "POST", batch.Method)
+	assert.Equal(t, "lfs.example.com", batch.Host)
+	assert.Equal(t, 2, batch.Requests)
+	assert.Equal(t, 1, batch.Retries)
+	assert.EqualValues(t, 300, batch.BytesSent)
+	assert.EqualValues(t, 310, batch.BytesReceived)
+	assert.Equal(t, 10*time.Millisecond, batch.LatencyP50)
+	assert.Equal(t, 30*time.Millisecond, batch.LatencyP95)
+
+	download := summary.Endpoints[1]
+	assert.Equal(t, "lfs.data.download", download.Key)
+	assert.Equal(t, "storage.example.com", download.Host)
+	assert.Equal(t, 2, download.Requests)
+	assert.Equal(t, 0, download.Retries)
+	assert.EqualValues(t, 1000, download.BytesReceived)
+	assert.Equal(t, 20*time.Millisecond, download.LatencyP50)
+	assert.Equal(t, 20*time.Millisecond, download.LatencyP95)
+}
+
+func TestSummarizeEmptyHTTPStats(t *testing.T) {
+	summary, err := SummarizeHTTPStats(strings.NewReader("concurrent=8 time=1700000000 version=git-lfs/3.5.0\n"))
+	require.Nil(t, err)
+
+	assert.Equal(t, 0, summary.Requests)
+	assert.Equal(t, time.Duration(0), summary.LatencyP50)
+	assert.Empty(t, summary.Endpoints)
+}
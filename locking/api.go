@@ -33,6 +33,9 @@ type lockRequest struct {
 	// Path is the path that the client would like to obtain a lock against.
 	Path string   `json:"path"`
 	Ref  *lockRef `json:"ref,omitempty"`
+	// ExpiresIn is the optional number of seconds after which the client
+	// would like the lock to be released by the server.
+	ExpiresIn int `json:"expires_in,omitempty"`
 }
 
 // LockResponse encapsulates the information sent over the API in response to
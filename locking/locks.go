@@ -47,6 +47,8 @@ type Client struct {
 	cacheDir  string
 	cfg       *config.Configuration
 
+	concurrency int
+
 	lockablePatterns []string
 	lockableFilter   *filepathfilter.Filter
 	lockableMutex    sync.Mutex
@@ -55,6 +57,17 @@ type Client struct {
 	LocalGitDir              string
 	SetLockableFilesReadOnly bool
 	ModifyIgnoredFiles       bool
+
+	// LeaseDuration, if non-zero, is the length of time for which new
+	// locks are requested, after which the server may release them.  It
+	// is sent to the server rounded up to a whole number of seconds.
+	LeaseDuration time.Duration
+
+	// RequireLocksCache is whether a failure to write the cached results
+	// of a search for locks on the server is returned as an error.
+	// Otherwise the failure is only traced, since the results themselves
+	// are still valid.
+	RequireLocksCache bool
 }
 
 // NewClient creates a new locking client with the given configuration
@@ -66,6 +79,7 @@ func NewClient(remote string, lfsClient *lfsapi.Client, cfg *config.Configuratio
 		client:             newGenericLockClient(lfsClient),
 		cache:              &nilLockCacher{},
 		cfg:                cfg,
+		concurrency:        lfsClient.ConcurrentTransfers(),
 		ModifyIgnoredFiles: lfsClient.GitEnv().Bool("lfs.lockignoredfiles", false),
 		LocalWorkingDir:    cfg.LocalWorkingDir(),
 		LocalGitDir:        cfg.LocalGitDir(),
@@ -101,10 +115,14 @@ func (c *Client) Close() error {
 // LockFile attempts to lock a file on the current remote
 // path must be relative to the root of the repository
 // Returns the lock id if successful, or an error
+//
+// If the client has a LeaseDuration but the server does not support lock
+// leases, the returned lock will have a nil ExpiresAt.
 func (c *Client) LockFile(path string) (Lock, error) {
 	lockRes, _, err := c.client.Lock(c.Remote, &lockRequest{
-		Path: path,
-		Ref:  &lockRef{Name: c.RemoteRef.Refspec()},
+		Path:      path,
+		Ref:       &lockRef{Name: c.RemoteRef.Refspec()},
+		ExpiresIn: int((c.LeaseDuration + time.Second - 1) / time.Second),
 	})
 	if err != nil {
 		return Lock{}, errors.Wrap(err, tr.Tr.Get("locking API"))
@@ -137,6 +155,50 @@ func (c *Client) LockFile(path string) (Lock, error) {
 	return lock, nil
 }
 
+// LockFiles attempts to lock each of the given files on the current remote,
+// issuing up to "lfs.concurrenttransfers" requests at once.  Paths must be
+// relative to the root of the repository.
+//
+// The returned locks and errors are parallel to the given set of paths; for
+// each path, either the lock or the error will be set.  A failure to lock one
+// path does not prevent the remaining paths from being locked.
+func (c *Client) LockFiles(paths []string) ([]Lock, []error) {
+	locks := make([]Lock, len(paths))
+	errs := make([]error, len(paths))
+	if len(paths) == 0 {
+		return locks, errs
+	}
+
+	// Lock the first path on its own so that any credentials we need are
+	// obtained once, rather than by every worker at the same time.
+	locks[0], errs[0] = c.LockFile(paths[0])
+
+	workers := c.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int, len(paths)-1)
+	for i := 1; i < len(paths); i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				locks[i], errs[i] = c.LockFile(paths[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return locks, errs
+}
+
 // getAbsolutePath takes a repository-relative path and makes it absolute.
 //
 // For instance, given a repository in /usr/local/src/my-repo and a file called
@@ -150,10 +212,11 @@ func (c *Client) getAbsolutePath(p string) (string, error) {
 // UnlockFile attempts to unlock a file on the current remote
 // path must be relative to the root of the repository
 // Force causes the file to be unlocked from other users as well
-func (c *Client) UnlockFile(path string, force bool) error {
+// Returns the lock which was removed, if the server reported it
+func (c *Client) UnlockFile(path string, force bool) (*Lock, error) {
 	id, err := c.lockIdFromPath(path)
 	if err != nil {
-		return errors.New(tr.Tr.Get("unable to get lock ID: %v", err))
+		return nil, errors.New(tr.Tr.Get("unable to get lock ID: %v", err))
 	}
 
 	return c.UnlockFileById(id, force)
@@ -161,36 +224,39 @@ func (c *Client) UnlockFile(path string, force bool) error {
 
 // UnlockFileById attempts to unlock a lock with a given id on the current remote
 // Force causes the file to be unlocked from other users as well
-func (c *Client) UnlockFileById(id string, force bool) error {
+// Returns the lock which was removed, if the server reported it
+func (c *Client) UnlockFileById(id string, force bool) (*Lock, error) {
 	unlockRes, _, err := c.client.Unlock(c.RemoteRef, c.Remote, id, force)
 	if err != nil {
-		return errors.Wrap(err, tr.Tr.Get("locking API"))
+		return nil, errors.Wrap(err, tr.Tr.Get("locking API"))
 	}
 
 	if len(unlockRes.Message) > 0 {
 		if len(unlockRes.RequestID) > 0 {
 			tracerx.Printf("Server Request ID: %s", unlockRes.RequestID)
 		}
-		return errors.New(tr.Tr.Get("server unable to unlock: %s", unlockRes.Message))
+		return nil, errors.New(tr.Tr.Get("server unable to unlock: %s", unlockRes.Message))
 	}
 
 	if err := c.cache.RemoveById(id); err != nil {
-		return errors.New(tr.Tr.Get("error caching unlock information: %v", err))
+		return nil, errors.New(tr.Tr.Get("error caching unlock information: %v", err))
 	}
 
 	if unlockRes.Lock != nil {
 		abs, err := c.getAbsolutePath(unlockRes.Lock.Path)
 		if err != nil {
-			return errors.Wrap(err, tr.Tr.Get("make lock path absolute"))
+			return nil, errors.Wrap(err, tr.Tr.Get("make lock path absolute"))
 		}
 
 		// Make non-writeable if required
 		if c.SetLockableFilesReadOnly && c.IsFileLockable(unlockRes.Lock.Path) {
-			return tools.SetFileWriteFlag(abs, false)
+			if err := tools.SetFileWriteFlag(abs, false); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return nil
+	return unlockRes.Lock, nil
 }
 
 // Lock is a record of a locked file
@@ -205,6 +271,9 @@ type Lock struct {
 	Owner *User `json:"owner,omitempty"`
 	// LockedAt is the time at which this lock was acquired.
 	LockedAt time.Time `json:"locked_at"`
+	// ExpiresAt is the optional time at which this lock's lease expires,
+	// after which the server will release the lock.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // SearchLocks returns a channel of locks which match the given name/value filter
@@ -230,7 +299,7 @@ func (c *Client) SearchLocks(filter map[string]string, limit int, localOnly bool
 		}
 
 		if len(filter) == 0 && limit == 0 {
-			err = c.writeLocksToCacheFile("remote", func(writer io.Writer) error {
+			err = c.writeLocksCache("remote", func(writer io.Writer) error {
 				return c.EncodeLocks(locks, writer)
 			})
 		}
@@ -310,7 +379,7 @@ func (c *Client) SearchLocksVerifiable(limit int, cached bool) (ourLocks, theirL
 		}
 
 		if limit == 0 {
-			err = c.writeLocksToCacheFile("verifiable", func(writer io.Writer) error {
+			err = c.writeLocksCache("verifiable", func(writer io.Writer) error {
 				return c.EncodeLocksVerifiable(ourLocks, theirLocks, writer)
 			})
 		}
@@ -479,6 +548,31 @@ func (c *Client) readLocksFromCacheFile(kind string, decoder func(*json.Decoder)
 	return decoder(json.NewDecoder(file))
 }
 
+// LocksCacheTime returns the time at which the cached results of a search for
+// locks were last written, either of SearchLocksVerifiable if verifiable is
+// true, or of SearchLocks otherwise.  The cache is written by every search of
+// the server which is not restricted by a filter or limit.
+func (c *Client) LocksCacheTime(verifiable bool) (time.Time, error) {
+	kind := "remote"
+	if verifiable {
+		kind = "verifiable"
+	}
+
+	cacheFile, err := c.prepareCacheDirectory(kind)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	fi, err := os.Stat(cacheFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, errors.New(tr.Tr.Get("no cached locks present"))
+		}
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
+
 func (c *Client) EncodeLocks(locks []Lock, writer io.Writer) error {
 	return json.NewEncoder(writer).Encode(locks)
 }
@@ -490,19 +584,43 @@ func (c *Client) EncodeLocksVerifiable(ourLocks, theirLocks []Lock, writer io.Wr
 	})
 }
 
+// writeLocksCache replaces the cached results of a search for locks of the
+// given kind, returning any error in doing so only if RequireLocksCache is set.
+func (c *Client) writeLocksCache(kind string, writer func(io.Writer) error) error {
+	err := c.writeLocksToCacheFile(kind, writer)
+	if err == nil {
+		return nil
+	}
+	if c.RequireLocksCache {
+		return errors.New(tr.Tr.Get("could not write lock cache: %v", err))
+	}
+
+	tracerx.Printf("locking: unable to write %s lock cache: %s", kind, err)
+	return nil
+}
+
 func (c *Client) writeLocksToCacheFile(kind string, writer func(io.Writer) error) error {
 	cacheFile, err := c.prepareCacheDirectory(kind)
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Create(cacheFile)
+	// Write to a temporary file first, so that a failed write doesn't
+	// leave a truncated cache behind.
+	file, err := os.CreateTemp(filepath.Dir(cacheFile), kind)
 	if err != nil {
 		return err
 	}
+	defer os.Remove(file.Name())
 
-	defer file.Close()
-	return writer(file)
+	if err := writer(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), cacheFile)
 }
 
 type nilLockCacher struct{}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"sort"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -258,6 +259,9 @@ func TestSearchLocksVerifiableWithCache(t *testing.T) {
 	assert.Empty(t, theirLocks)
 	assert.Equal(t, 0, remoteQueries)
 
+	_, err = client.LocksCacheTime(true)
+	assert.NotNil(t, err)
+
 	// REMOTE QUERY: No cache file will be created when querying with a limit
 	ourLocks, theirLocks, err = client.SearchLocksVerifiable(1, false)
 	assert.Nil(t, err)
@@ -279,6 +283,10 @@ func TestSearchLocksVerifiableWithCache(t *testing.T) {
 	const size int64 = 478
 	assert.Equal(t, size, fi.Size())
 
+	cacheTime, err := client.LocksCacheTime(true)
+	assert.Nil(t, err)
+	assert.Equal(t, fi.ModTime(), cacheTime)
+
 	// Need to include zero time in structure for equal to work
 	zeroTime := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
 
@@ -310,3 +318,59 @@ func TestSearchLocksVerifiableWithCache(t *testing.T) {
 	sort.Sort(LocksById(theirLocks))
 	assert.Equal(t, expectedTheirLocks, theirLocks)
 }
+
+func TestLockFiles(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/locks", r.URL.Path)
+
+		lockReq := &lockRequest{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(lockReq))
+
+		w.Header().Set("Content-Type", "application/json")
+		if lockReq.Path == "locked.dat" {
+			w.WriteHeader(http.StatusConflict)
+			assert.Nil(t, json.NewEncoder(w).Encode(&lockResponse{
+				Lock:    &Lock{Id: "0", Path: lockReq.Path},
+				Message: "already created lock",
+			}))
+			return
+		}
+
+		assert.Nil(t, json.NewEncoder(w).Encode(&lockResponse{
+			Lock: &Lock{Id: "id-" + lockReq.Path, Path: lockReq.Path},
+		}))
+	}))
+	defer srv.Close()
+
+	lfsclient, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":                 srv.URL + "/api",
+		"lfs.concurrenttransfers": "2",
+	}))
+	require.Nil(t, err)
+
+	client, err := NewClient("", lfsclient, config.New())
+	require.Nil(t, err)
+	client.RemoteRef = &git.Ref{Name: "refs/heads/master"}
+
+	paths := []string{"a.dat", "locked.dat", "b.dat", "c.dat"}
+	locks, errs := client.LockFiles(paths)
+	require.Len(t, locks, len(paths))
+	require.Len(t, errs, len(paths))
+
+	assert.EqualValues(t, len(paths), atomic.LoadInt32(&requests))
+	for i, path := range paths {
+		if path == "locked.dat" {
+			assert.NotNil(t, errs[i])
+			assert.Empty(t, locks[i].Id)
+			continue
+		}
+
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "id-"+path, locks[i].Id)
+		assert.Equal(t, path, locks[i].Path)
+	}
+}
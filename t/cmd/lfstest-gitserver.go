@@ -34,6 +34,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/git-lfs/git-lfs/v3/tools/delta"
+	"github.com/klauspost/compress/zstd"
 )
 
 var (
@@ -58,8 +61,8 @@ var (
 		"status-storage-403", "status-storage-404", "status-storage-410", "status-storage-422", "status-storage-500", "status-storage-503",
 		"status-batch-resume-206", "batch-resume-fail-fallback", "return-expired-action", "return-expired-action-forever", "return-invalid-size",
 		"object-authenticated", "storage-download-retry", "storage-upload-retry", "storage-upload-retry-later", "storage-upload-retry-later-no-header", "unknown-oid",
-		"send-verify-action", "send-deprecated-links", "redirect-storage-upload", "storage-compress", "batch-hash-algo-empty", "batch-hash-algo-invalid",
-		"auth-bearer", "auth-multistage",
+		"send-verify-action", "send-deprecated-links", "redirect-storage-upload", "storage-compress", "batch-hash-algo-empty", "batch-hash-algo-invalid", "storage-upload-part-retry",
+		"auth-bearer", "auth-multistage", "storage-download-stall",
 	}
 
 	reqCookieReposRE = regexp.MustCompile(`\A/require-cookie-`)
@@ -95,6 +98,9 @@ func main() {
 
 	mux.HandleFunc("/storage/", storageHandler)
 	mux.HandleFunc("/verify", verifyHandler)
+	mux.HandleFunc("/storage-part/", storagePartHandler)
+	mux.HandleFunc("/storage-complete/", storageCompleteHandler)
+	mux.HandleFunc("/storage-delta/", storageDeltaHandler)
 	mux.HandleFunc("/redirect307/", redirect307Handler)
 	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "%s\n", time.Now().String())
@@ -184,10 +190,21 @@ type lfsObject struct {
 }
 
 type lfsLink struct {
-	Href      string            `json:"href"`
-	Header    map[string]string `json:"header,omitempty"`
-	ExpiresAt time.Time         `json:"expires_at,omitempty"`
-	ExpiresIn int               `json:"expires_in,omitempty"`
+	Href          string            `json:"href"`
+	Header        map[string]string `json:"header,omitempty"`
+	ExpiresAt     time.Time         `json:"expires_at,omitempty"`
+	ExpiresIn     int               `json:"expires_in,omitempty"`
+	Parts         []*lfsPart        `json:"parts,omitempty"`
+	Base          string            `json:"base,omitempty"`
+	UploadSession string            `json:"upload_session,omitempty"`
+	Compression   string            `json:"compression,omitempty"`
+}
+
+type lfsPart struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+	Pos    int64             `json:"pos"`
+	Size   int64             `json:"size"`
 }
 
 type lfsError struct {
@@ -342,10 +359,23 @@ func lfsDeleteHandler(w http.ResponseWriter, r *http.Request, id, repo string) {
 }
 
 type batchReq struct {
-	Transfers []string    `json:"transfers"`
-	Operation string      `json:"operation"`
-	Objects   []lfsObject `json:"objects"`
-	Ref       *Ref        `json:"ref,omitempty"`
+	Transfers   []string    `json:"transfers"`
+	Operation   string      `json:"operation"`
+	Objects     []lfsObject `json:"objects"`
+	Ref         *Ref        `json:"ref,omitempty"`
+	ChunkSize   int64       `json:"chunk_size,omitempty"`
+	Compression []string    `json:"compression,omitempty"`
+}
+
+// AcceptsCompression returns whether the client is able to compress and
+// decompress object bodies with the given algorithm.
+func (r *batchReq) AcceptsCompression(compression string) bool {
+	for _, c := range r.Compression {
+		if c == compression {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *batchReq) RefName() string {
@@ -432,16 +462,39 @@ func lfsBatchHandler(w http.ResponseWriter, r *http.Request, id, repo string) {
 		}
 	}
 
+	if strings.HasSuffix(repo, "clock-skew") {
+		w.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	}
+
+	if strings.HasSuffix(repo, "batch-unavailable") {
+		retriesMu.Lock()
+		retries["batch:"+repo]++
+		attempt := retries["batch:"+repo]
+		retriesMu.Unlock()
+
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("temporarily unavailable"))
+			return
+		}
+	}
+
 	res := []lfsObject{}
 	testingChunked := testingChunkedTransferEncoding(r)
 	testingTus := testingTusUploadInBatchReq(r)
 	testingTusInterrupt := testingTusUploadInterruptedInBatchReq(r)
+	testingDelta := testingDeltaUploadInBatchReq(r)
+	testingUploadSession := testingUploadSessionInBatchReq(r)
+	testingUploadSessionInterrupt := testingUploadSessionInterruptedInBatchReq(r)
+	testingCompression := testingCompressionInBatchReq(r) && objs.AcceptsCompression("zstd")
 	testingCustomTransfer := testingCustomTransfer(r)
 	var transferChoice string
 	var searchForTransfer string
 	hashAlgo := "sha256"
 	if testingTus {
 		searchForTransfer = "tus"
+	} else if testingDelta {
+		searchForTransfer = "delta"
 	} else if testingCustomTransfer {
 		searchForTransfer = "testcustom"
 	}
@@ -520,6 +573,10 @@ func lfsBatchHandler(w http.ResponseWriter, r *http.Request, id, repo string) {
 				}
 				a = serveExpired(a, repo, handler)
 
+				if action == "upload" && objs.ChunkSize > 0 && obj.Size > objs.ChunkSize {
+					a = multipartUpload(a, repo, obj, objs.ChunkSize, handler)
+				}
+
 				if handler == "send-deprecated-links" {
 					o.Links[action] = a
 				} else {
@@ -544,6 +601,15 @@ func lfsBatchHandler(w http.ResponseWriter, r *http.Request, id, repo string) {
 				o.Actions[action].Header["Transfer-Encoding"] = "chunked"
 			}
 		}
+		if transferChoice == "delta" && action == "upload" && addAction {
+			if base, ok := largeObjects.Largest(repo); ok {
+				o.Actions["delta"] = &lfsLink{
+					Href:   server.URL + "/storage-delta/" + obj.Oid + "?r=" + repo + "&base=" + base,
+					Header: map[string]string{},
+					Base:   base,
+				}
+			}
+		}
 		if testingTusInterrupt && addAction {
 			if handler == "send-deprecated-links" {
 				o.Links[action].Header["Lfs-Tus-Interrupt"] = "true"
@@ -551,6 +617,16 @@ func lfsBatchHandler(w http.ResponseWriter, r *http.Request, id, repo string) {
 				o.Actions[action].Header["Lfs-Tus-Interrupt"] = "true"
 			}
 		}
+		if testingUploadSession && action == "upload" && addAction && handler != "send-deprecated-links" {
+			o.Actions[action].UploadSession = "session-" + obj.Oid
+			if testingUploadSessionInterrupt {
+				o.Actions[action].Header["Lfs-Upload-Session-Interrupt"] = "true"
+			}
+		}
+		if testingCompression && addAction && handler != "send-deprecated-links" {
+			o.Actions[action].Compression = "zstd"
+			o.Actions[action].Header["Lfs-Compression"] = "zstd"
+		}
 
 		res = append(res, o)
 	}
@@ -662,6 +738,182 @@ func verifyHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// multipartUpload returns an upload action which splits the upload of obj into
+// parts of at most chunkSize bytes, each of which is uploaded to
+// /storage-part/{oid}, and are then reassembled by a request to
+// /storage-complete/{oid}. With the "storage-upload-part-retry" handler, the
+// final part fails to upload the first two times it is attempted.
+func multipartUpload(a *lfsLink, repo string, obj lfsObject, chunkSize int64, handler string) *lfsLink {
+	a.Href = server.URL + "/storage-complete/" + obj.Oid + "?r=" + repo
+	for pos := int64(0); pos < obj.Size; pos += chunkSize {
+		size := chunkSize
+		if pos+size > obj.Size {
+			size = obj.Size - pos
+		}
+
+		href := fmt.Sprintf("%s/storage-part/%s?r=%s&pos=%d", server.URL, obj.Oid, repo, pos)
+		if handler == "storage-upload-part-retry" && pos+size == obj.Size {
+			href += "&retry=1"
+		}
+
+		a.Parts = append(a.Parts, &lfsPart{
+			Href:   href,
+			Header: map[string]string{},
+			Pos:    pos,
+			Size:   size,
+		})
+	}
+	return a
+}
+
+var (
+	uploadParts   = make(map[string]map[int64][]byte)
+	uploadPartsMu sync.Mutex
+)
+
+// handles any /storage-part/{oid} requests
+func storagePartHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := reqId(w)
+	if !ok {
+		return
+	}
+	repo := r.URL.Query().Get("r")
+	parts := strings.Split(r.URL.Path, "/")
+	oid := parts[len(parts)-1]
+	if missingRequiredCreds(w, r, repo) {
+		return
+	}
+
+	if r.Method != "PUT" {
+		w.WriteHeader(405)
+		return
+	}
+
+	pos, err := strconv.ParseInt(r.URL.Query().Get("pos"), 10, 64)
+	if err != nil {
+		w.WriteHeader(400)
+		return
+	}
+
+	debug(id, "storage part %s %s pos: %d repo: %s", r.Method, oid, pos, repo)
+
+	if r.URL.Query().Get("retry") == "1" {
+		if retries, ok := incrementRetriesFor("storage", "upload-part", repo, oid, false); ok && retries < 3 {
+			w.WriteHeader(500)
+			w.Write([]byte("malformed content"))
+			return
+		}
+	}
+
+	by, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(500)
+		return
+	}
+
+	key := strings.Join([]string{repo, oid}, ":")
+
+	uploadPartsMu.Lock()
+	if uploadParts[key] == nil {
+		uploadParts[key] = make(map[int64][]byte)
+	}
+	uploadParts[key][pos] = by
+	uploadPartsMu.Unlock()
+}
+
+// handles any /storage-complete/{oid} requests
+func storageCompleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := reqId(w)
+	if !ok {
+		return
+	}
+	repo := r.URL.Query().Get("r")
+	parts := strings.Split(r.URL.Path, "/")
+	oid := parts[len(parts)-1]
+	if missingRequiredCreds(w, r, repo) {
+		return
+	}
+
+	if r.Method != "POST" {
+		w.WriteHeader(405)
+		return
+	}
+
+	debug(id, "storage complete %s %s repo: %s", r.Method, oid, repo)
+
+	key := strings.Join([]string{repo, oid}, ":")
+
+	uploadPartsMu.Lock()
+	uploaded := uploadParts[key]
+	delete(uploadParts, key)
+	uploadPartsMu.Unlock()
+
+	hash := sha256.New()
+	buf := &bytes.Buffer{}
+	for pos := int64(0); ; {
+		by, ok := uploaded[pos]
+		if !ok || len(by) == 0 {
+			break
+		}
+		io.Copy(io.MultiWriter(hash, buf), bytes.NewReader(by))
+		pos += int64(len(by))
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != oid {
+		writeLFSError(w, http.StatusUnprocessableEntity, "uploaded parts do not match object")
+		return
+	}
+
+	largeObjects.Set(repo, oid, buf.Bytes())
+}
+
+// handles any /storage-delta/{oid} requests
+func storageDeltaHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := reqId(w)
+	if !ok {
+		return
+	}
+	repo := r.URL.Query().Get("r")
+	base := r.URL.Query().Get("base")
+	parts := strings.Split(r.URL.Path, "/")
+	oid := parts[len(parts)-1]
+	if missingRequiredCreds(w, r, repo) {
+		return
+	}
+
+	if r.Method != "PUT" {
+		w.WriteHeader(405)
+		return
+	}
+
+	debug(id, "storage delta %s %s against %s repo: %s", r.Method, oid, base, repo)
+
+	if strings.HasPrefix(repo, "test-delta-upload-reject") {
+		writeLFSError(w, http.StatusUnprocessableEntity, "delta base is not available")
+		return
+	}
+
+	by, ok := largeObjects.Get(repo, base)
+	if !ok {
+		writeLFSError(w, http.StatusUnprocessableEntity, "delta base is not available")
+		return
+	}
+
+	hash := sha256.New()
+	buf := &bytes.Buffer{}
+	if _, err := delta.Apply(bytes.NewReader(by), r.Body, io.MultiWriter(hash, buf)); err != nil {
+		writeLFSError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != oid {
+		writeLFSError(w, http.StatusUnprocessableEntity, "delta does not match object")
+		return
+	}
+
+	largeObjects.Set(repo, oid, buf.Bytes())
+}
+
 // handles any /storage/{oid} requests
 func storageHandler(w http.ResponseWriter, r *http.Request) {
 	id, ok := reqId(w)
@@ -748,6 +1000,26 @@ func storageHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if len(r.Header.Get("X-Upload-Session")) > 0 {
+			uploadSessionPut(w, r, id, repo, oid)
+			return
+		}
+
+		if r.Header.Get("Lfs-Compression") == "zstd" {
+			if r.Header.Get("Content-Encoding") != "zstd" {
+				w.WriteHeader(400)
+				w.Write([]byte("not compressed"))
+				return
+			}
+			dec, err := zstd.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+			defer dec.Close()
+			r.Body = io.NopCloser(dec)
+		}
+
 		hash := sha256.New()
 		buf := &bytes.Buffer{}
 
@@ -807,6 +1079,29 @@ func storageHandler(w http.ResponseWriter, r *http.Request) {
 				} else {
 					byteLimit = 10
 				}
+			} else if string(by) == "storage-download-stall" {
+				// Send part of the object and then stop sending
+				// anything, without closing the connection, unless a
+				// Range is requested to resume the download.
+				if rangeHdr := r.Header.Get("Range"); rangeHdr != "" {
+					match := regexp.MustCompile(`bytes=(\d+)\-.*`).FindStringSubmatch(rangeHdr)
+					if match != nil {
+						statusCode = 206
+						resumeAt, _ = strconv.ParseInt(match[1], 10, 32)
+						w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", resumeAt, len(by)-1, len(by)))
+					}
+				} else {
+					w.WriteHeader(200)
+					w.Write(by[0:10])
+					if f, ok := w.(http.Flusher); ok {
+						f.Flush()
+					}
+					select {
+					case <-r.Context().Done():
+					case <-time.After(time.Minute):
+					}
+					return
+				}
 			} else if len(by) == len("batch-resume-fail-fallback") && string(by) == "batch-resume-fail-fallback" {
 				// Fail any Range: request even though we said we supported it
 				// To make sure client can fall back
@@ -859,6 +1154,15 @@ func storageHandler(w http.ResponseWriter, r *http.Request) {
 				defer gz.Close()
 
 				wrtr = gz
+			} else if r.Header.Get("Lfs-Compression") == "zstd" {
+				enc, err := zstd.NewWriter(w)
+				if err != nil {
+					w.WriteHeader(500)
+					return
+				}
+				defer enc.Close()
+
+				wrtr = enc
 			}
 			w.WriteHeader(statusCode)
 			if byteLimit > 0 {
@@ -873,6 +1177,16 @@ func storageHandler(w http.ResponseWriter, r *http.Request) {
 
 		w.WriteHeader(404)
 	case "HEAD":
+		if len(r.Header.Get("X-Upload-Session")) > 0 {
+			var offset int64
+			if by, ok := largeObjects.GetIncomplete(repo, oid); ok {
+				offset = int64(len(by))
+			}
+			w.Header().Set("X-Upload-Offset", strconv.FormatInt(offset, 10))
+			w.WriteHeader(200)
+			return
+		}
+
 		// tus.io
 		if !validateTusHeaders(r, id) {
 			w.WriteHeader(400)
@@ -967,6 +1281,57 @@ func storageHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// uploadSessionPut handles an upload to /storage/{oid} in an upload session,
+// which resumes an earlier interrupted upload if it has a Content-Range header.
+// With the Lfs-Upload-Session-Interrupt header, an upload from the start of the
+// object is cut off after a third of it has been received.
+func uploadSessionPut(w http.ResponseWriter, r *http.Request, id, repo, oid string) {
+	hash := sha256.New()
+	buf := &bytes.Buffer{}
+	out := io.MultiWriter(hash, buf)
+
+	var offset int64
+	if cr := r.Header.Get("Content-Range"); len(cr) > 0 {
+		var last, size int64
+		if _, err := fmt.Sscanf(cr, "bytes %d-%d/%d", &offset, &last, &size); err != nil {
+			debug(id, "Invalid Content-Range %q in upload session", cr)
+			w.WriteHeader(400)
+			return
+		}
+		by, _ := largeObjects.GetIncomplete(repo, oid)
+		if offset != int64(len(by)) {
+			debug(id, "Incorrect offset in upload session, got %d expected %d", offset, len(by))
+			w.WriteHeader(400)
+			return
+		}
+		out.Write(by)
+		debug(id, "Resuming upload of %v at byte %d", oid, offset)
+	}
+
+	var copyErr error
+	if r.Header.Get("Lfs-Upload-Session-Interrupt") == "true" && offset == 0 {
+		io.CopyN(out, r.Body, r.ContentLength/3)
+		copyErr = fmt.Errorf("Simulated copy error")
+	} else {
+		_, copyErr = io.Copy(out, r.Body)
+	}
+	if copyErr != nil {
+		if b := buf.Bytes(); len(b) > 0 {
+			debug(id, "Incomplete upload of %v, %d bytes", oid, len(b))
+			largeObjects.SetIncomplete(repo, oid, b)
+		}
+		w.WriteHeader(500)
+		return
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != oid {
+		w.WriteHeader(403)
+		return
+	}
+	largeObjects.DeleteIncomplete(repo, oid)
+	largeObjects.Set(repo, oid, buf.Bytes())
+}
+
 func validateTusHeaders(r *http.Request, id string) bool {
 	if len(r.Header.Get("Tus-Resumable")) == 0 {
 		debug(id, "Missing Tus-Resumable header in request")
@@ -1055,15 +1420,17 @@ type User struct {
 }
 
 type Lock struct {
-	Id       string    `json:"id"`
-	Path     string    `json:"path"`
-	Owner    User      `json:"owner"`
-	LockedAt time.Time `json:"locked_at"`
+	Id        string     `json:"id"`
+	Path      string     `json:"path"`
+	Owner     User       `json:"owner"`
+	LockedAt  time.Time  `json:"locked_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 type LockRequest struct {
-	Path string `json:"path"`
-	Ref  *Ref   `json:"ref,omitempty"`
+	Path      string `json:"path"`
+	Ref       *Ref   `json:"ref,omitempty"`
+	ExpiresIn int    `json:"expires_in,omitempty"`
 }
 
 func (r *LockRequest) RefName() string {
@@ -1310,6 +1677,19 @@ func locksHandler(w http.ResponseWriter, r *http.Request, repo string) {
 				}
 			}
 
+			// Locks on paths containing "theirs" are treated as
+			// belonging to another user, and so may only be removed
+			// with force.
+			if !unlockRequest.Force {
+				for _, l := range getLocks(repo) {
+					if l.Id == lockId && strings.Contains(l.Path, "theirs") {
+						w.WriteHeader(403)
+						enc.Encode(&UnlockResponse{Message: "lock is owned by another user"})
+						return
+					}
+				}
+			}
+
 			if l := delLock(repo, lockId); l != nil {
 				enc.Encode(&UnlockResponse{Lock: l})
 			} else {
@@ -1421,6 +1801,11 @@ func locksHandler(w http.ResponseWriter, r *http.Request, repo string) {
 				LockedAt: time.Now(),
 			}
 
+			if lockRequest.ExpiresIn > 0 && !strings.HasSuffix(repo, "no-lease") {
+				expiresAt := lock.LockedAt.Add(time.Duration(lockRequest.ExpiresIn) * time.Second)
+				lock.ExpiresAt = &expiresAt
+			}
+
 			addLocks(repo, *lock)
 
 			// TODO(taylor): commit_needed case
@@ -1471,6 +1856,18 @@ func testingTusUploadInBatchReq(r *http.Request) bool {
 func testingTusUploadInterruptedInBatchReq(r *http.Request) bool {
 	return strings.HasPrefix(r.URL.String(), "/test-tus-upload-interrupt")
 }
+func testingDeltaUploadInBatchReq(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.String(), "/test-delta-upload")
+}
+func testingCompressionInBatchReq(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.String(), "/test-compression")
+}
+func testingUploadSessionInBatchReq(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.String(), "/test-upload-session")
+}
+func testingUploadSessionInterruptedInBatchReq(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.String(), "/test-upload-session-interrupt")
+}
 func testingCustomTransfer(r *http.Request) bool {
 	return strings.HasPrefix(r.URL.String(), "/test-custom-transfer")
 }
@@ -1531,6 +1928,22 @@ func (s *lfsStorage) Set(repo, oid string, by []byte) {
 	repoObjects[oid] = by
 }
 
+// Largest returns the OID of the largest object stored for the given repo,
+// which is used as the base for delta uploads.
+func (s *lfsStorage) Largest(repo string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var largest string
+	for oid, by := range s.objects[repo] {
+		if len(largest) == 0 || len(by) > len(s.objects[repo][largest]) ||
+			(len(by) == len(s.objects[repo][largest]) && oid < largest) {
+			largest = oid
+		}
+	}
+	return largest, len(largest) > 0
+}
+
 func (s *lfsStorage) Delete(repo, oid string) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -153,3 +153,28 @@ begin_test "alternates (OS environment, multiple)"
     git lfs push "$(git config remote.origin.url)" main
 )
 end_test
+
+begin_test "alternates (lfs.readonlycachedir)"
+(
+  set -e
+
+  reponame="alternates-readonly-cache-dir"
+  setup_remote_repo_with_file "$reponame" "a.txt"
+
+  pushd "$TRASHDIR" > /dev/null
+    clone_repo "$reponame" "${reponame}_cache"
+    cache="$TRASHDIR/${reponame}_cache/.git/lfs/objects"
+    chmod -R a-w "$cache"
+  popd > /dev/null
+
+  rm -rf .git/lfs/objects
+  git config lfs.readonlycachedir "$(native_path "$cache")"
+
+  GIT_TRACE=1 git lfs fetch origin main 2>&1 | tee fetch.log
+  [ "0" -eq "$(grep -c "sending batch of size 1" fetch.log)" ]
+
+  assert_local_object "$(calc_oid_file a.txt)" 6
+
+  chmod -R u+w "$cache"
+)
+end_test
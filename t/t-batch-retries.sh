@@ -77,3 +77,126 @@ begin_test "batch storage download causes retries"
   popd
 )
 end_test
+
+begin_test "batch storage download retries per object"
+(
+  set -e
+
+  contents="storage-download-retry"
+  oid="$(calc_oid "$contents")"
+
+  for name in exhausted retried; do
+    reponame="batch-storage-download-retry-per-object-$name"
+    setup_remote_repo "$reponame"
+    clone_repo "$reponame" "$reponame"
+
+    printf "%s" "$contents" > a.dat
+    git lfs track "*.dat"
+    git add .gitattributes a.dat
+    git commit -m "initial commit"
+
+    git push origin main
+    assert_server_object "$reponame" "$oid"
+
+    rm -rf .git/lfs/objects
+  done
+
+  # The object fails to download twice, so a single retry is not enough.
+  cd "$TRASHDIR/batch-storage-download-retry-per-object-exhausted"
+  git config --local lfs.transfer.maxretries 3
+  git lfs fetch --retries-per-object 1 origin main 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch\` to fail ..."
+    exit 1
+  fi
+  grep "1 object could not be fetched after exhausting its retries:" fetch.log
+  grep "  a.dat ($oid)" fetch.log
+  refute_local_object "$oid"
+
+  # The retries of each object are independent of those of the Batch API.
+  cd "$TRASHDIR/batch-storage-download-retry-per-object-retried"
+  git config --local lfs.transfer.maxretries 1
+  git config --local lfs.transfer.maxretriesperobject 2
+  GIT_TRACE=1 git lfs fetch origin main 2>&1 | tee fetch.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch\` to succeed ..."
+    exit 1
+  fi
+  [ "2" -eq "$(grep -c "tq: retrying object $oid" fetch.log)" ]
+  grep "exhausting" fetch.log && exit 1
+  assert_local_object "$oid" "${#contents}"
+
+  git lfs fetch --retries-per-object=-1 origin main 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch\` to fail ..."
+    exit 1
+  fi
+  grep "Invalid value for --retries-per-object: -1" fetch.log
+)
+end_test
+
+begin_test "batch storage multipart upload retries failed parts"
+(
+  set -e
+
+  reponame="batch-storage-upload-part-retry"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" batch-storage-repo-upload-part
+
+  contents="storage-upload-part-retry"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+
+  git lfs track "*.dat"
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  git config --local lfs.transfer.chunksize 10
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git push origin main\` to succeed ..."
+    exit 1
+  fi
+
+  actual_count="$(grep -c "xfer: retrying part 3 of 3 for \"$oid\"" push.log)"
+  [ "2" = "$actual_count" ]
+
+  # Only the failed part is uploaded again; the object is not restarted.
+  [ "0" = "$(grep -c "tq: retrying object $oid" push.log)" ]
+  [ "1" = "$(grep -c "xfer: uploaded part 1 of 3 for \"$oid\"" push.log)" ]
+
+  assert_server_object "$reponame" "$oid"
+)
+end_test
+
+begin_test "batch API request retries with backoff while unavailable"
+(
+  set -e
+
+  reponame="batch-unavailable"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" batch-unavailable-repo
+
+  contents="batch-unavailable"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+
+  git lfs track "*.dat"
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  git config --local lfs.transfer.retrybackoff 10
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git push origin main\` to succeed ..."
+    exit 1
+  fi
+
+  actual_count="$(grep -c "http: retrying POST .*/objects/batch" push.log)"
+  [ "2" = "$actual_count" ]
+
+  assert_server_object "$reponame" "$oid"
+)
+end_test
@@ -101,6 +101,54 @@ begin_test "batch transfers occur in reverse order by size"
 )
 end_test
 
+begin_test "batch transfers use the cached transfer adapter"
+(
+  set -e
+
+  reponame="batch-adapter-cache"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # The cache is disabled by default.
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  [ ! -e .git/lfs/adaptercache.json ]
+
+  git config lfs.transfer.adaptercache true
+  printf "b" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "tq: using cached transfer adapter" push.log && exit 1
+  grep "\"upload [^\"]*/$reponame.git/info/lfs\":{\"adapter\":\"\"" .git/lfs/adaptercache.json
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep "tq: using cached transfer adapter" fetch.log && exit 1
+  grep "\"download [^\"]*/$reponame.git/info/lfs\":{\"adapter\":\"\"" .git/lfs/adaptercache.json
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep "tq: using cached transfer adapter \"\"" fetch.log
+  assert_local_object "$(calc_oid "a")" 1
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 git lfs fetch --no-cache 2>&1 | tee fetch.log
+  grep "tq: using cached transfer adapter" fetch.log && exit 1
+
+  rm -rf .git/lfs/objects
+  git config lfs.transfer.adaptercache false
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep "tq: using cached transfer adapter" fetch.log && exit 1
+  assert_local_object "$(calc_oid "a")" 1
+)
+end_test
+
 begin_test "batch transfers succeed with an empty hash algorithm"
 (
   set -e
@@ -142,6 +190,25 @@ begin_test "batch transfers fail with an unknown hash algorithm"
 )
 end_test
 
+begin_test "batch transfers fail when the server does not use lfs.hashalgo"
+(
+  set -e
+
+  reponame="batch-test-blake3-algo"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git config lfs.hashalgo blake3
+  git lfs track "*.dat"
+  printf "hi" > good.dat
+  git add .gitattributes good.dat
+  git commit -m "hi"
+
+  git push origin main 2>&1 | tee push.log
+  grep 'unsupported hash algorithm' push.log
+)
+end_test
+
 begin_test "batch transfers with ssh endpoint (git-lfs-authenticate)"
 (
   set -e
@@ -190,3 +257,37 @@ begin_test "batch transfers with ssh endpoint (git-lfs-transfer)"
   git lfs fsck
 )
 end_test
+
+begin_test "batch transfers with ssh endpoint (git-lfs-transfer; multiplexed)"
+(
+  set -e
+
+  setup_pure_ssh
+
+  reponame="batch-ssh-transfer-multiplexed"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  sshurl=$(ssh_remote "$reponame")
+  git config lfs.url "$sshurl"
+  git config lfs.ssh.automultiplex true
+  git config lfs.concurrenttransfers 2
+
+  git lfs track "*.dat"
+  for i in $(seq 1 10); do
+    printf "%s" "contents $i" > "$i.dat"
+  done
+  git add .gitattributes *.dat
+  git commit -m "add objects"
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+
+  # The second transfer worker reuses the master connection.
+  grep "lfs-ssh-echo.*-oControlMaster=yes" push.log
+  grep "lfs-ssh-echo.*-oControlMaster=no" push.log
+
+  for i in $(seq 1 10); do
+    assert_server_object "$reponame" "$(calc_oid "contents $i")"
+  done
+)
+end_test
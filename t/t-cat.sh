@@ -0,0 +1,171 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "cat"
+(
+  set -e
+
+  reponame="cat"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "first" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  printf "second" > a.dat
+  git add a.dat
+  git commit -m "update a.dat"
+
+  git push origin main
+
+  [ "second" = "$(git lfs cat a.dat)" ]
+  [ "second" = "$(git lfs cat :a.dat)" ]
+  [ "second" = "$(git lfs cat HEAD:a.dat)" ]
+  [ "first" = "$(git lfs cat HEAD^:a.dat)" ]
+
+  mkdir dir
+  cd dir
+  [ "second" = "$(git lfs cat ../a.dat)" ]
+  [ "first" = "$(git lfs cat HEAD^:a.dat)" ]
+)
+end_test
+
+begin_test "cat: pointer in working tree"
+(
+  set -e
+
+  cd cat
+
+  git lfs pointer --file=a.dat > a.ptr
+  [ "second" = "$(git lfs cat a.ptr)" ]
+
+  # a modified pointer in the working tree takes precedence over the index
+  git lfs pointer --file=<(printf "first") > a.dat
+  [ "first" = "$(git lfs cat a.dat)" ]
+  [ "second" = "$(git lfs cat :a.dat)" ]
+
+  git checkout -- a.dat
+  rm a.ptr
+)
+end_test
+
+begin_test "cat: downloads missing objects"
+(
+  set -e
+
+  cd cat
+
+  rm -rf .git/lfs/objects
+
+  [ "first" = "$(git lfs cat HEAD^:a.dat)" ]
+  assert_local_object "$(calc_oid "first")" 5
+  refute_local_object "$(calc_oid "second")"
+
+  git lfs cat --check a.dat >check.log
+  [ ! -s check.log ]
+  assert_local_object "$(calc_oid "second")" 6
+)
+end_test
+
+begin_test "cat --check: missing object"
+(
+  set -e
+
+  cd cat
+
+  printf "unpushed" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  git lfs cat --check b.dat
+  rm -rf .git/lfs/objects
+
+  git lfs cat --check b.dat 2>&1 | tee check.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs cat --check' to fail ..."
+    exit 1
+  fi
+  grep "Could not read object for b.dat" check.log
+
+  git lfs cat b.dat >cat.log 2>&1 && exit 1
+  grep "Could not read object for b.dat" cat.log
+)
+end_test
+
+begin_test "cat: not a Git LFS file"
+(
+  set -e
+
+  cd cat
+
+  [ "*.dat filter=lfs diff=lfs merge=lfs -text" = "$(git lfs cat HEAD:.gitattributes 2>/dev/null)" ] && exit 1
+  git lfs cat HEAD:.gitattributes 2>&1 | tee cat.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs cat' to fail ..."
+    exit 1
+  fi
+  grep "HEAD:.gitattributes is not a Git LFS file" cat.log
+
+  git lfs cat missing.dat 2>&1 | tee cat.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs cat' to fail ..."
+    exit 1
+  fi
+  grep "missing.dat: no such file" cat.log
+
+  git lfs cat HEAD:missing.dat 2>&1 | tee cat.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs cat' to fail ..."
+    exit 1
+  fi
+  grep "Git can't resolve file: \"HEAD:missing.dat\"" cat.log
+)
+end_test
+
+begin_test "cat --offset --length"
+(
+  set -e
+
+  reponame="cat-range"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "0123456789" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  contents_oid="$(calc_oid "0123456789")"
+
+  # objects present locally are read directly
+  [ "3456" = "$(git lfs cat --offset=3 --length=4 a.dat)" ]
+  [ "789" = "$(git lfs cat --offset=7 a.dat)" ]
+  [ "012" = "$(git lfs cat --length=3 a.dat)" ]
+  [ "" = "$(git lfs cat --offset=20 a.dat)" ]
+
+  # only the requested range of a missing object is downloaded
+  rm -rf .git/lfs/objects
+  GIT_CURL_VERBOSE=1 git lfs cat --offset=3 --length=4 a.dat >cat.log 2>trace.log
+  [ "3456" = "$(cat cat.log)" ]
+  grep "Range: bytes=3-6" trace.log
+  refute_local_object "$contents_oid"
+
+  git lfs cat --check --offset=3 a.dat 2>&1 | tee cat.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs cat' to fail ..."
+    exit 1
+  fi
+  grep "Cannot combine --check with --offset or --length" cat.log
+
+  git lfs cat --length=-1 a.dat 2>&1 | tee cat.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs cat' to fail ..."
+    exit 1
+  fi
+  grep "Invalid length: -1" cat.log
+)
+end_test
@@ -214,7 +214,7 @@ begin_test "checkout: conflicts"
     git commit -m "first"
 
     git lfs checkout --to base.txt 2>&1 | tee output.txt
-    grep -- '--to and exactly one of --theirs, --ours, and --base must be used together' output.txt
+    grep -- '--to requires exactly one Git LFS object file path' output.txt
 
     git lfs checkout --base 2>&1 | tee output.txt
     grep -- '--to and exactly one of --theirs, --ours, and --base must be used together' output.txt
@@ -256,6 +256,74 @@ begin_test "checkout: conflicts"
 end_test
 
 
+begin_test "checkout: --to"
+(
+  set -e
+
+  reponame="checkout-to"
+  filename="file1.dat"
+
+  setup_remote_repo_with_file "$reponame" "$filename"
+
+  pushd "$TRASHDIR" > /dev/null
+    GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "${reponame}_checkout"
+    git lfs fetch
+
+    contents_oid="$(calc_oid "file1.dat\n")"
+    mkdir dir
+    cd dir
+
+    git lfs checkout --to "$TRASHDIR/out.bin" ../file1.dat
+    echo "file1.dat" | cmp - "$TRASHDIR/out.bin"
+
+    # the working tree is left untouched
+    cd ..
+    grep "oid sha256:$contents_oid" file1.dat
+    git diff --exit-code
+
+    git lfs checkout --to out.bin file1.dat file2.dat 2>&1 | tee output.txt
+    grep -- '--to requires exactly one Git LFS object file path' output.txt
+    [ ! -e out.bin ]
+
+    git lfs checkout --to out.bin missing.dat 2>&1 | tee output.txt
+    grep 'Could not checkout "missing.dat"' output.txt
+    [ ! -e out.bin ]
+  popd > /dev/null
+)
+end_test
+
+begin_test "checkout: corrupt local object"
+(
+  set -e
+
+  reponame="checkout-corrupt-object"
+  filename="file1.dat"
+
+  setup_remote_repo_with_file "$reponame" "$filename"
+
+  pushd "$TRASHDIR" > /dev/null
+    GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "${reponame}_checkout"
+    git lfs fetch
+
+    contents_oid="$(calc_oid "file1.dat\n")"
+    assert_local_object "$contents_oid" 10
+
+    # replace the object with corrupt data of the same size
+    objpath="$(git lfs env | grep LocalMediaDir | cut -d= -f2)/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+    chmod u+w "$objpath"
+    printf "corrupted\n" > "$objpath"
+
+    git lfs checkout file1.dat 2>&1 | tee output.txt
+    grep 'could not check out "file1.dat"' output.txt
+
+    # the pointer is left in place and the corrupt object is removed
+    grep "oid sha256:$contents_oid" file1.dat
+    git diff --exit-code
+    refute_local_object "$contents_oid"
+  popd > /dev/null
+)
+end_test
+
 begin_test "checkout: GIT_WORK_TREE"
 (
   set -e
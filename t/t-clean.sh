@@ -87,3 +87,64 @@ begin_test "clean stdin"
   fi
 )
 end_test
+
+begin_test "clean with lfs.blockedoids"
+(
+  set -e
+  clean_setup "blocked-oids"
+
+  oid="cd293be6cea034bd45a0352775a219ef5dc7825ce55d1f7dae9762d80ce64411"
+  printf "# known bad objects\nsha256:%s\n" "$oid" > blocked.txt
+  git config lfs.blockedoids blocked.txt
+
+  echo "whatever" | git lfs clean a.dat 2>&1 | tee clean.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected \`git lfs clean\` to fail ..."
+    exit 1
+  fi
+  grep "a.dat: object $oid is blocked by \`lfs.blockedoids\`" clean.log
+  refute_local_object "$oid"
+
+  git lfs track "*.dat"
+  echo "whatever" > a.dat
+  git add a.dat 2>&1 | tee add.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git add\` to fail ..."
+    exit 1
+  fi
+  grep "a.dat: object $oid is blocked by \`lfs.blockedoids\`" add.log
+
+  echo "something else" | git lfs clean | tee clean.log
+  grep "oid sha256:" clean.log
+)
+end_test
+
+begin_test "clean with lfs.allowedoids"
+(
+  set -e
+  clean_setup "allowed-oids"
+
+  oid="cd293be6cea034bd45a0352775a219ef5dc7825ce55d1f7dae9762d80ce64411"
+  echo "$oid 9" > "$TRASHDIR/allowed.txt"
+  git config lfs.allowedoids "$TRASHDIR/allowed.txt"
+
+  echo "whatever" | git lfs clean | tee clean.log
+  [ "$(pointer "$oid" 9)" = "$(cat clean.log)" ]
+
+  echo "something else" | git lfs clean b.dat 2>&1 | tee clean.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected \`git lfs clean\` to fail ..."
+    exit 1
+  fi
+  grep "b.dat: object [0-9a-f]\{64\} is not allowed by \`lfs.allowedoids\`" clean.log
+
+  # A missing list rejects every object.
+  git config lfs.allowedoids missing.txt
+  echo "whatever" | git lfs clean 2>&1 | tee clean.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected \`git lfs clean\` to fail ..."
+    exit 1
+  fi
+  grep "could not read lfs.allowedoids" clean.log
+)
+end_test
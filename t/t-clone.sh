@@ -419,6 +419,137 @@ begin_test "clone (with include/exclude args)"
 )
 end_test
 
+begin_test "clone (with --include-path)"
+(
+  set -e
+
+  reponame="clone_include_path"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  contents_a="a"
+  contents_a_oid=$(calc_oid "$contents_a")
+  contents_b="b"
+  contents_b_oid=$(calc_oid "$contents_b")
+  contents_c="c"
+  contents_c_oid=$(calc_oid "$contents_c")
+
+  mkdir -p dir1/sub dir2 dir3
+  printf "%s" "$contents_a" > "dir1/sub/a.dat"
+  printf "%s" "$contents_b" > "dir2/b.dat"
+  printf "%s" "$contents_c" > "dir3/c.dat"
+
+  git add .gitattributes dir1 dir2 dir3
+  git commit -m "add files"
+
+  git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (3/3), 3 B" push.log
+
+  cd "$TRASHDIR"
+
+  # Paths outside the included set should be left as pointers, even if
+  # lfs.fetchinclude would otherwise have included them.
+  local_reponame="clone_with_include_path"
+  git -c lfs.fetchinclude="dir3" lfs clone "$GITSERVER/$reponame" "$local_reponame" \
+    --include-path "dir1/**" --include-path "dir2"
+  pushd "$local_reponame"
+  assert_local_object "$contents_a_oid" 1
+  assert_local_object "$contents_b_oid" 1
+  refute_local_object "$contents_c_oid"
+  [ "a" = "$(cat dir1/sub/a.dat)" ]
+  [ "b" = "$(cat dir2/b.dat)" ]
+  [ "$(pointer $contents_c_oid 1)" = "$(cat dir3/c.dat)" ]
+  popd
+
+  local_reponame="clone_with_include_path_and_include"
+  git lfs clone "$GITSERVER/$reponame" "$local_reponame" \
+    -I "dir3" --include-path "dir1/**" -X "dir1/sub/a.dat"
+  pushd "$local_reponame"
+  refute_local_object "$contents_a_oid"
+  refute_local_object "$contents_b_oid"
+  assert_local_object "$contents_c_oid" 1
+  [ "$(pointer $contents_a_oid 1)" = "$(cat dir1/sub/a.dat)" ]
+  [ "$(pointer $contents_b_oid 1)" = "$(cat dir2/b.dat)" ]
+  [ "c" = "$(cat dir3/c.dat)" ]
+  popd
+)
+end_test
+
+begin_test "clone (with --depth)"
+(
+  set -e
+
+  reponame="clone_shallow"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "track *.dat"
+
+  contents_1="1"
+  contents_1_oid=$(calc_oid "$contents_1")
+  contents_2="22"
+  contents_2_oid=$(calc_oid "$contents_2")
+  contents_3="333"
+  contents_3_oid=$(calc_oid "$contents_3")
+
+  printf "%s" "$contents_1" > a.dat
+  git add a.dat
+  git commit -m "add a.dat"
+
+  printf "%s" "$contents_2" > a.dat
+  git add a.dat
+  git commit -m "update a.dat"
+
+  printf "%s" "$contents_3" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (3/3), 6 B" push.log
+
+  cd "$TRASHDIR"
+
+  local_reponame="clone_with_depth"
+  git lfs clone --depth 1 "$GITSERVER/$reponame" "$local_reponame" 2>&1 | tee clone.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected clone to succeed ..."
+    exit 1
+  fi
+  grep "Downloading LFS objects: 100% (2/2), 5 B" clone.log
+  grep -i "error" clone.log && exit 1
+
+  pushd "$local_reponame"
+    [ "true" = "$(git rev-parse --is-shallow-repository)" ]
+    [ "1" -eq "$(git rev-list --count HEAD)" ]
+
+    refute_local_object "$contents_1_oid"
+    assert_local_object "$contents_2_oid" 2
+    assert_local_object "$contents_3_oid" 3
+    [ "22" = "$(cat a.dat)" ]
+    [ "333" = "$(cat b.dat)" ]
+    assert_clean_status
+  popd
+
+  local_reponame="clone_with_depth_no_checkout"
+  git lfs clone --depth 1 --no-checkout "$GITSERVER/$reponame" "$local_reponame" 2>&1 | tee clone.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected clone to succeed ..."
+    exit 1
+  fi
+  grep -i "error" clone.log && exit 1
+
+  pushd "$local_reponame"
+    refute_local_object "$contents_1_oid"
+    assert_local_object "$contents_2_oid" 2
+    assert_local_object "$contents_3_oid" 3
+  popd
+)
+end_test
+
 begin_test "clone (with .lfsconfig)"
 (
   set -e
@@ -87,6 +87,70 @@ begin_test "config reads from repository"
 )
 end_test
 
+begin_test "config reads includes from .lfsconfig"
+(
+  set -e
+  reponame="lfsconfig-include"
+  mkdir $reponame
+  cd $reponame
+  git init
+  git remote add origin "$GITSERVER/$reponame"
+
+  mkdir -p shared/lfs
+  git config --file=shared/lfs/common.lfsconfig lfs.url http://lfsconfig-common
+  git config --file=shared/lfs/common.lfsconfig lfs.http://lfsconfig-common.access lfsconfig
+  git config --file=shared/lfs/common.lfsconfig lfs.fetchexclude common
+  git config --file=.lfsconfig include.path shared/lfs/common.lfsconfig
+  git config --file=.lfsconfig lfs.fetchexclude override
+
+  git lfs env 2>&1 | tee env.log
+  grep "Endpoint=http://lfsconfig-common (auth=lfsconfig)" env.log
+  grep "FetchExclude=override" env.log
+  if grep "unsafe" env.log; then
+    echo >&2 "fatal: include.path reported as an unsafe key"
+    exit 1
+  fi
+
+  # includes in a committed .lfsconfig are resolved in the same revision
+  git add .lfsconfig shared
+  git commit -m "add .lfsconfig"
+  rm -rf .lfsconfig shared
+  git rm -q --cached .lfsconfig shared/lfs/common.lfsconfig
+
+  git lfs env 2>&1 | tee env.log
+  grep "Endpoint=http://lfsconfig-common (auth=lfsconfig)" env.log
+
+  # missing includes are ignored
+  git reset -q --hard
+  git config --file=.lfsconfig --add include.path missing.lfsconfig
+  git lfs env 2>&1 | tee env.log
+  grep "Endpoint=http://lfsconfig-common (auth=lfsconfig)" env.log
+)
+end_test
+
+begin_test "config reports circular includes in .lfsconfig"
+(
+  set -e
+  reponame="lfsconfig-include-circular"
+  mkdir $reponame
+  cd $reponame
+  git init
+  git remote add origin "$GITSERVER/$reponame"
+  git config lfs.fetchexclude local
+
+  mkdir sub
+  git config --file=.lfsconfig include.path sub/a.lfsconfig
+  git config --file=sub/a.lfsconfig include.path ../.lfsconfig
+
+  git lfs env 2>&1 | tee env.log
+  grep "circular include of " env.log
+
+  # the Git configuration is still used
+  grep "FetchExclude=local" env.log
+  grep "Endpoint=$GITSERVER/$reponame.git/info/lfs (auth=none)" env.log
+)
+end_test
+
 begin_test "can read LFS file with name before .lfsconfig"
 (
   set -e
@@ -276,3 +340,105 @@ begin_test "config respects include.* directives when GIT_CONFIG is set"
   grep "Endpoint=http://other-url/rest (auth=none)" env.log
 )
 end_test
+
+begin_test "config command: get and set"
+(
+  set -e
+
+  reponame="config-command-get-set"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs config lfs.locksverify YES
+  [ "true" = "$(git config --local lfs.locksverify)" ]
+  [ "true" = "$(git lfs config lfs.locksverify)" ]
+
+  git lfs config lfs.transfer.maxbytespersecond "10 MB"
+  [ "10000000" = "$(git config --local lfs.transfer.maxbytespersecond)" ]
+
+  git lfs config lfs.https://git.example.com.access Basic
+  [ "basic" = "$(git config --local lfs.https://git.example.com.access)" ]
+
+  git lfs config --global lfs.concurrenttransfers 4
+  [ "4" = "$(git config --global lfs.concurrenttransfers)" ]
+  [ "4" = "$(git lfs config --global lfs.concurrenttransfers)" ]
+  [ -z "$(git config --local lfs.concurrenttransfers)" ]
+  git config --global --unset lfs.concurrenttransfers
+
+  git lfs config --file .lfsconfig lfs.url https://lfs.example.com/repo
+  [ "https://lfs.example.com/repo" = "$(git config --file .lfsconfig lfs.url)" ]
+  [ "https://lfs.example.com/repo" = "$(git lfs config lfs.url)" ]
+
+  git lfs config lfs.fetchinclude 2>&1 && exit 1
+  true
+)
+end_test
+
+begin_test "config command: invalid values"
+(
+  set -e
+
+  reponame="config-command-invalid"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs config lfs.concurrenttransfers many 2>&1 | tee config.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs config' to fail ..."
+    exit 1
+  fi
+  grep 'Invalid value "many" for lfs.concurrenttransfers' config.log
+
+  git lfs config lfs.transfer.compression gzip 2>&1 | tee config.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs config' to fail ..."
+    exit 1
+  fi
+  grep "expected one of: zstd, none" config.log
+
+  [ -z "$(git config --local lfs.concurrenttransfers)" ]
+  [ -z "$(git config --local lfs.transfer.compression)" ]
+)
+end_test
+
+begin_test "config command: warnings"
+(
+  set -e
+
+  reponame="config-command-warnings"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs config lfs.notasetting value 2>&1 | tee config.log
+  grep 'warning: unknown Git LFS configuration key "lfs.notasetting"' config.log
+  [ "value" = "$(git config --local lfs.notasetting)" ]
+
+  git lfs config --file .lfsconfig lfs.storage /tmp/lfs 2>&1 | tee config.log
+  grep "warning: lfs.storage is ignored when read from a .lfsconfig file" config.log
+  [ "/tmp/lfs" = "$(git config --file .lfsconfig lfs.storage)" ]
+)
+end_test
+
+begin_test "config command: list"
+(
+  set -e
+
+  reponame="config-command-list"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config --local lfs.locksverify true
+  git config --local user.name "Not LFS"
+  git config --file .lfsconfig lfs.url https://lfs.example.com/repo
+  git config --file .lfsconfig lfs.fetchinclude "*.dat"
+  git config --local lfs.fetchinclude "*.bin"
+
+  git lfs config --list | tee config.log
+  grep "	lfs.locksverify=true" config.log
+  grep "^\.lfsconfig	lfs.url=https://lfs.example.com/repo$" config.log
+  grep "	lfs.fetchinclude=\*\.bin$" config.log
+  grep "^\.lfsconfig	lfs.fetchinclude" config.log && exit 1
+  grep "user.name" config.log && exit 1
+  grep "^file:.*config	lfs.locksverify=true$" config.log
+)
+end_test
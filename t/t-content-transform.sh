@@ -0,0 +1,129 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+rot13="tr A-Za-z N-ZA-Mn-za-m"
+
+begin_test "content transform: clean and smudge"
+(
+  set -e
+
+  reponame="content-transform"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git config lfs.contenttransform.clean "$rot13"
+  git config lfs.contenttransform.smudge "$rot13"
+
+  git lfs track "*.dat"
+  contents="plaintext contents"
+  transformed="cynvagrkg pbagragf"
+  oid="$(calc_oid "$transformed")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # The pointer and the object refer only to the transformed contents.
+  git cat-file -p :a.dat | grep "oid sha256:$oid"
+  git cat-file -p :a.dat | grep "size ${#transformed}"
+  assert_local_object "$oid" "${#transformed}"
+  [ "$transformed" = "$(cat ".git/lfs/objects/${oid:0:2}/${oid:2:2}/$oid")" ]
+  [ "$contents" = "$(cat a.dat)" ]
+
+  # Cleaning the same contents again results in the same object.
+  [ "$(git cat-file -p :a.dat)" = "$(git lfs clean < a.dat)" ]
+
+  git push origin main
+  assert_server_object "$reponame" "$oid"
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-clone"
+  git config lfs.contenttransform.clean "$rot13"
+  git config lfs.contenttransform.smudge "$rot13"
+  git lfs pull
+  [ "$contents" = "$(cat a.dat)" ]
+  [ -z "$(git status --porcelain --untracked-files=no)" ]
+
+  rm a.dat
+  git checkout -- a.dat
+  [ "$contents" = "$(cat a.dat)" ]
+
+  [ "$contents" = "$(git cat-file -p :a.dat | git lfs smudge a.dat)" ]
+)
+end_test
+
+begin_test "content transform: failing clean command"
+(
+  set -e
+
+  reponame="content-transform-failing-clean"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.contenttransform.clean "echo oops >&2; exit 1"
+
+  git lfs track "*.dat"
+  printf "%s" "contents" > a.dat
+  git add a.dat 2>&1 | tee add.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git add\` to fail ..."
+    exit 1
+  fi
+  grep "content transform 'echo oops >&2; exit 1' failed with: oops" add.log
+)
+end_test
+
+begin_test "content transform: corrupt object is not smudged"
+(
+  set -e
+
+  reponame="content-transform-corrupt"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.contenttransform.clean "$rot13"
+  git config lfs.contenttransform.smudge "$rot13"
+
+  git lfs track "*.dat"
+  transformed="gval"
+  oid="$(calc_oid "$transformed")"
+  printf "%s" "tiny" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  path=".git/lfs/objects/${oid:0:2}/${oid:2:2}/$oid"
+  chmod u+w "$path"
+  printf "%s" "bad!" > "$path"
+
+  git cat-file -p :a.dat | git lfs smudge a.dat 2>&1 | tee smudge.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected \`git lfs smudge\` to fail ..."
+    exit 1
+  fi
+  refute_local_object "$oid"
+)
+end_test
+
+begin_test "content transform: not used with extensions"
+(
+  set -e
+
+  reponame="content-transform-extensions"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.contenttransform.clean "$rot13"
+  git config lfs.extension.foo.clean "cat"
+  git config lfs.extension.foo.smudge "cat"
+  git config lfs.extension.foo.priority 0
+
+  git lfs track "*.dat"
+  printf "%s" "contents" > a.dat
+  git add a.dat 2>&1 | tee add.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git add\` to fail ..."
+    exit 1
+  fi
+  grep "Git LFS extensions can not be used with \`lfs.contenttransform.clean\`" add.log
+)
+end_test
@@ -47,6 +47,32 @@ begin_test "content-type: is disabled by configuration"
 )
 end_test
 
+begin_test "content-type: mapped by extension"
+(
+  set -e
+
+  reponame="content-type-mapped-by-extension"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.tar.gz" "*.PSD"
+  printf "aaaaaaaaaa" > a.txt
+  tar -czf a.tar.gz a.txt
+  rm a.txt
+  printf "design" > b.PSD
+
+  git add .gitattributes a.tar.gz b.PSD
+  git commit -m "initial commit"
+  git config "lfs.contenttype.psd" "image/vnd.adobe.photoshop"
+  git config "lfs.$GITSERVER.contenttype" 0
+  GIT_CURL_VERBOSE=1 git push origin main 2>&1 | tee push.log
+
+  # the mapping applies even if detection is disabled
+  [ 1 -eq "$(grep -c "Content-Type: image/vnd.adobe.photoshop" push.log)" ]
+  [ 1 -eq "$(grep -c "Content-Type: application/octet-stream" push.log)" ]
+)
+end_test
+
 begin_test "content-type: warning message"
 (
   set -e
@@ -67,3 +93,39 @@ begin_test "content-type: warning message"
   grep "info:   $ git config lfs.contenttype false" push.log
 )
 end_test
+
+begin_test "content-type: accept encoding on download"
+(
+  set -e
+
+  reponame="content-type-accept-encoding"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  # The test server only sends this content gzip-encoded.
+  contents="storage-compress"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+
+  git lfs track "*.dat"
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 git clone "$GITSERVER/$reponame" "$reponame-assert"
+  cd "$reponame-assert"
+
+  git config lfs.transfer.acceptencoding "gzip, br"
+  GIT_CURL_VERBOSE=1 git lfs pull 2>&1 | tee pull.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs pull\` to succeed ..."
+    exit 1
+  fi
+
+  grep "> Accept-Encoding: gzip$" pull.log
+  grep "< Content-Encoding: gzip" pull.log
+  assert_local_object "$oid" "${#contents}"
+  [ "$contents" = "$(cat a.dat)" ]
+)
+end_test
@@ -674,6 +674,41 @@ begin_test "credentials from lfs.url"
 )
 end_test
 
+begin_test "credentials are not sent to pre-signed URLs"
+(
+  set -e
+
+  reponame="requirecreds-presignedurls"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  echo "push c" > c.dat
+  git add .gitattributes c.dat
+  git commit -m "add c.dat"
+
+  gitserverhost=$(echo "$GITSERVER" | cut -d'/' -f3)
+  git config lfs.url http://requirecreds:pass@$gitserverhost/$reponame.git/info/lfs
+  git lfs push origin main
+
+  # The storage endpoint requires credentials, so treating its URLs as
+  # pre-signed makes the download fail, without retrying it endlessly.
+  rm -rf .git/lfs/objects
+  git -c lfs.transfer.presignedurls=always lfs fetch --all 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch\` to fail ..."
+    exit 1
+  fi
+  refute_local_object "$(calc_oid "push c\n")"
+
+  # Its URLs are not signed, and are on the same host as the LFS API, so
+  # credentials are sent by default.
+  git lfs fetch --all
+  git -c lfs.transfer.presignedurls=never lfs fetch --all
+  git lfs fsck
+)
+end_test
+
 begin_test "credentials from remote.origin.url"
 (
   set -e
@@ -739,3 +774,40 @@ begin_test "credentials from remote.origin.url"
   git lfs fsck
 )
 end_test
+
+begin_test "credentials from lfs.credentialhelper"
+(
+  set -e
+
+  reponame="lfs-credential-helper"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  # Git's credential helpers are not consulted for Git LFS credentials.
+  git config credential.helper ""
+  git config lfs.credentialhelper "lfstest"
+
+  git lfs track "*.dat"
+  echo "hello" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TERMINAL_PROMPT=0 GIT_TRACE=1 git lfs push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (1/1), 6 B" push.log
+
+  grep "creds: lfs.credentialhelper get" push.log
+  grep "creds: lfs.credentialhelper store" push.log
+  [ "0" -eq "$(grep -c "creds: git credential \(fill\|approve\)" push.log)" ]
+
+  echo "fallback to git credential helpers"
+  git config --unset credential.helper
+  git config lfs.credentialhelper "!true"
+  rm -rf .git/lfs/objects
+
+  GIT_TRACE=1 git lfs fetch --all 2>&1 | tee fetch.log
+  grep "creds: lfs.credentialhelper get" fetch.log
+  grep "creds: git credential fill" fetch.log
+  [ "0" -eq "$(grep -c "creds: lfs.credentialhelper store" fetch.log)" ]
+  git lfs fsck
+)
+end_test
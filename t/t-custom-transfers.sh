@@ -38,6 +38,42 @@ begin_test "custom-transfer-wrong-path"
 )
 end_test
 
+begin_test "custom-transfer-required-env"
+(
+  set -e
+
+  # this repo name is the indicator to the server to support custom transfer
+  reponame="test-custom-transfer-env"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" $reponame
+
+  git config lfs.customtransfer.testcustom.path lfstest-customadapter
+  git config lfs.customtransfer.testcustom.env "LFSTEST_TOKEN,LFSTEST_USER"
+
+  git lfs track "*.dat"
+  printf "%s" "required env" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  unset LFSTEST_TOKEN LFSTEST_USER
+  LFSTEST_USER=user GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee push.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected push to fail without the required environment variable ..."
+    exit 1
+  fi
+  grep 'custom transfer adapter "testcustom" requires the environment variable LFSTEST_TOKEN, which is not set' push.log
+  if grep "starting up custom transfer process" push.log; then
+    echo >&2 "fatal: expected no custom transfer process to be started ..."
+    exit 1
+  fi
+
+  LFSTEST_TOKEN=token LFSTEST_USER=user GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "xfer: started custom adapter process" push.log
+  assert_server_object "$reponame" "$(calc_oid "required env")"
+)
+end_test
+
 begin_test "custom-transfer-upload-download"
 (
   set -e
@@ -110,6 +146,48 @@ begin_test "custom-transfer-upload-download"
 )
 end_test
 
+begin_test "custom-transfer-dir"
+(
+  set -e
+
+  # this repo name is the indicator to the server to support custom transfer
+  reponame="test-custom-transfer-dir"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" $reponame
+
+  # set up custom transfer adapter from a directory
+  mkdir "$TRASHDIR/adapters"
+  cp "$(command -v lfstest-customadapter)" "$TRASHDIR/adapters/testcustom"
+  echo '{"direction": "both", "concurrent": false}' > "$TRASHDIR/adapters/testcustom.json"
+  git config lfs.customtransfer.dir "$TRASHDIR/adapters"
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "Tracking \"\*.dat\"" track.log
+
+  contents="custom transfer dir"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee pushcustom.log
+  [ ${PIPESTATUS[0]} = "0" ]
+
+  grep "custom transfer: found adapter \"testcustom\"" pushcustom.log
+  grep "xfer: started custom adapter process" pushcustom.log
+  grep "xfer\[testcustom\]:" pushcustom.log
+  assert_server_object "$reponame" "$contents_oid"
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch 2>&1 | tee fetchcustom.log
+  [ ${PIPESTATUS[0]} = "0" ]
+
+  grep "xfer: started custom adapter process" fetchcustom.log
+  assert_local_object "$contents_oid" "${#contents}"
+)
+end_test
+
 begin_test "custom-transfer-standalone"
 (
   set -e
@@ -105,3 +105,45 @@ begin_test "dedup dirty workdir"
   echo "$result" | grep 'Working tree is dirty. Please commit or reset your change.'
 )
 end_test
+
+begin_test "dedup --dry-run"
+(
+  set -e
+
+  reponame="dedup_dry_run"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  echo "test data" > a.dat
+  echo "test data 2" > b.dat
+  echo "test data 3" > c.dat
+  git add .gitattributes *.dat
+  git commit -m "first commit"
+
+  # Delete the object for b.dat and modify c.dat
+  bOid="$(calc_oid "test data 2\n")"
+  rm ".git/lfs/objects/${bOid:0:2}/${bOid:2:2}/$bOid"
+  echo "modified" > c.dat
+
+  git lfs dedup --dry-run >dedup.log 2>&1
+  cat dedup.log
+
+  grep -A 1 "Skipped: b.dat" dedup.log | grep "Git LFS object file does not exist"
+  grep -A 1 "Skipped: c.dat" dedup.log | grep "Working tree file does not match Git LFS object"
+  grep "Dry run finished; no files were changed." dedup.log
+
+  if grep "Would de-duplicate: a.dat" dedup.log; then
+    grep "Reclaimable size: 10 bytes" dedup.log
+    grep "count: 1" dedup.log
+    grep "skipped: 2" dedup.log
+  else
+    grep -A 1 "Skipped: a.dat" dedup.log | grep "This system does not support de-duplication."
+    grep "Reclaimable size: 0 bytes" dedup.log
+    grep "skipped: 3" dedup.log
+  fi
+
+  [ "modified" = "$(cat c.dat)" ]
+  [ "test data" = "$(cat a.dat)" ]
+)
+end_test
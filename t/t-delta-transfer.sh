@@ -0,0 +1,103 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "delta-upload"
+(
+  set -e
+
+  # this repo name is the indicator to the server to use delta uploads
+  reponame="test-delta-upload"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" $reponame
+  git config lfs.deltatransfers true
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "Tracking \"\*.dat\"" track.log
+
+  seq 1 50000 > a.dat
+  oid1="$(calc_oid_file a.dat)"
+  git add a.dat .gitattributes
+  git commit -m "add a.dat"
+
+  # The server has no objects to use as a base yet.
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "xfer: no delta base for \"$oid1\", uploading in full" push.log
+  assert_server_object "$reponame" "$oid1"
+
+  seq 1 50000 | sed -e 's/^25000$/changed/' > a.dat
+  oid2="$(calc_oid_file a.dat)"
+  git commit -am "change a.dat"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "xfer: uploading delta of \"$oid2\" against \"$oid1\"" push.log
+  assert_server_object "$reponame" "$oid2"
+
+  cd ..
+  git clone "$GITSERVER/$reponame" "$reponame-clone"
+  cd "$reponame-clone"
+  [ "$oid2" = "$(calc_oid_file a.dat)" ]
+)
+end_test
+
+begin_test "delta-upload rejected by server"
+(
+  set -e
+
+  # this repo name is the indicator to the server to use delta uploads, but
+  # to reject every delta
+  reponame="test-delta-upload-reject"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" $reponame
+  git config lfs.deltatransfers true
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "Tracking \"\*.dat\"" track.log
+
+  seq 1 50000 > a.dat
+  oid1="$(calc_oid_file a.dat)"
+  git add a.dat .gitattributes
+  git commit -m "add a.dat"
+  git push origin main
+
+  seq 1 50000 | sed -e 's/^25000$/changed/' > a.dat
+  oid2="$(calc_oid_file a.dat)"
+  git commit -am "change a.dat"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "xfer: delta of \"$oid2\" rejected, uploading in full" push.log
+  assert_server_object "$reponame" "$oid2"
+)
+end_test
+
+begin_test "delta-upload disabled"
+(
+  set -e
+
+  # the server would use delta uploads for this repo, but the client does not
+  # offer them
+  reponame="test-delta-upload-disabled"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" $reponame
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "Tracking \"\*.dat\"" track.log
+
+  seq 1 50000 > a.dat
+  git add a.dat .gitattributes
+  git commit -m "add a.dat"
+  git push origin main
+
+  seq 1 50000 | sed -e 's/^25000$/changed/' > a.dat
+  oid2="$(calc_oid_file a.dat)"
+  git commit -am "change a.dat"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "xfer: adapter \"delta\"" push.log && exit 1
+  grep "xfer: adapter \"basic\" started" push.log
+  assert_server_object "$reponame" "$oid2"
+)
+end_test
@@ -0,0 +1,95 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "doctor: all checks pass"
+(
+  set -e
+
+  reponame="doctor-pass"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  git lfs doctor 2>&1 | tee doctor.log
+  [ "0" -eq "${PIPESTATUS[0]}" ]
+  grep "PASS filters: Git LFS filters are configured" doctor.log
+  grep "PASS attributes: 1 pattern is tracked by Git LFS" doctor.log
+  grep "PASS storage:" doctor.log
+  grep "PASS endpoint: $GITSERVER/$reponame.git/info/lfs is reachable" doctor.log
+  grep "PASS credentials:" doctor.log
+  grep "PASS batch: Batch API request succeeded" doctor.log
+  grep "PASS clock: The local clock agrees with the endpoint's" doctor.log
+  [ "0" -eq "$(grep -c "FAIL" doctor.log)" ]
+)
+end_test
+
+begin_test "doctor: failing checks"
+(
+  set -e
+
+  reponame="doctor-fail"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config --local filter.lfs.process ""
+  git config --local filter.lfs.smudge ""
+
+  git lfs doctor 2>&1 | tee doctor.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs doctor\` to fail ..."
+    exit 1
+  fi
+  grep "FAIL filters: Git LFS filters are not configured: filter.lfs.process, filter.lfs.smudge" doctor.log
+  grep "Run \`git lfs install\` to configure them." doctor.log
+  grep "FAIL attributes: No files are tracked by Git LFS" doctor.log
+  grep "FAIL endpoint: No Git LFS endpoint is configured for remote \"origin\"" doctor.log
+  grep "SKIP credentials:" doctor.log
+  grep "SKIP batch:" doctor.log
+  grep "SKIP clock:" doctor.log
+
+  git remote add origin "http://127.0.0.1:1/$reponame.git"
+  git lfs doctor 2>&1 | tee doctor.log
+  grep "FAIL endpoint: Unable to reach http://127.0.0.1:1/$reponame.git/info/lfs" doctor.log
+)
+end_test
+
+begin_test "doctor: --json"
+(
+  set -e
+
+  reponame="doctor-json"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs doctor --json 2>&1 | tee doctor.json
+  [ "1" -eq "${PIPESTATUS[0]}" ]
+
+  grep '"checks": \[' doctor.json
+  grep -A3 '"name": "attributes"' doctor.json | grep '"status": "fail"'
+  grep -A4 '"name": "attributes"' doctor.json | grep '"hint": "Run `git lfs track`'
+  grep -A2 '"name": "batch"' doctor.json | grep '"status": "pass"'
+)
+end_test
+
+begin_test "doctor: clock skew"
+(
+  set -e
+
+  reponame="doctor-clock-skew"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  git lfs doctor 2>&1 | tee doctor.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs doctor\` to fail ..."
+    exit 1
+  fi
+  grep "PASS batch: Batch API request succeeded" doctor.log
+  grep "FAIL clock: The local clock differs from the endpoint's by" doctor.log
+  grep "Set the system clock to the correct time" doctor.log
+)
+end_test
@@ -1146,3 +1146,37 @@ EOF
   grep 'warning.*same alias' test.log
 )
 end_test
+
+begin_test "env --json"
+(
+  set -e
+  reponame="env-json"
+  unset_vars
+  mkdir $reponame
+  cd $reponame
+  git init
+  git remote add origin "$GITSERVER/env-origin-remote"
+  git remote add other "$GITSERVER/env-other-remote"
+
+  localmedia=$(canonical_path "$TRASHDIR/$reponame/.git/lfs/objects")
+  lfsversion="$(git lfs version | sed -e 's|^git-lfs/\([^ ]*\) .*|\1|')"
+  gitversion="$(git version | sed -e 's/^git version //')"
+
+  git lfs env --json 2>&1 | tee env.json
+
+  grep "\"version\": \"$lfsversion\"" env.json
+  grep "\"git_version\": \"$gitversion\"" env.json
+  grep '"os": "' env.json
+  grep '"remote": "origin"' env.json
+  grep "\"url\": \"$GITSERVER/env-origin-remote.git/info/lfs\"" env.json
+  grep '"remote": "other"' env.json
+  grep "\"url\": \"$GITSERVER/env-other-remote.git/info/lfs\"" env.json
+  grep "\"LocalMediaDir\": \"$localmedia\"" env.json
+  grep '"ConcurrentTransfers": "8"' env.json
+  grep '"download_transfers": \[' env.json
+  grep '"filter.lfs.clean": "git-lfs clean -- %f"' env.json
+
+  # the default remote is listed first
+  [ "$(grep '"remote"' env.json | head -n 1)" = '   "remote": "origin",' ]
+)
+end_test
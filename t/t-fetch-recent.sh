@@ -200,3 +200,51 @@ begin_test "fetch-recent remote refs"
   refute_local_object "$oid1"
 )
 end_test
+
+begin_test "fetch-recent --include-ref/--exclude-ref"
+(
+  set -e
+
+  cd clone
+  git config lfs.fetchrecentcommitsdays 0
+  git config lfs.fetchrecentremoterefs true
+  git config lfs.fetchrecentrefsdays 6
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --recent --exclude-ref='refs/remotes/origin/other*' origin
+  assert_local_object "$oid2" "${#content2}"
+  assert_local_object "$oid3" "${#content3}"
+  refute_local_object "$oid4"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --recent --include-ref='refs/heads/*' origin
+  assert_local_object "$oid2" "${#content2}"
+  refute_local_object "$oid4"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --recent --include-ref='refs/remotes/origin/*' origin
+  assert_local_object "$oid2" "${#content2}"
+  assert_local_object "$oid4" "${#content4}"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --recent --include-ref='refs/remotes/origin/*' \
+    --exclude-ref='refs/remotes/origin/other_branch' origin
+  refute_local_object "$oid4"
+)
+end_test
+
+begin_test "fetch-recent --include-ref without --recent"
+(
+  set -e
+
+  cd clone
+  git config lfs.fetchrecentalways false
+
+  git lfs fetch --include-ref='refs/heads/*' origin 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fetch --include-ref' to fail"
+    exit 1
+  fi
+  grep "Cannot use --include-ref or --exclude-ref without --recent" fetch.log
+)
+end_test
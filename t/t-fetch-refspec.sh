@@ -86,3 +86,69 @@ begin_test "fetch with bad ref"
   grep 'Expected ref "refs/heads/other", got "refs/heads/main"' fetch.log
 )
 end_test
+
+begin_test "fetch with detached HEAD"
+(
+  set -e
+
+  reponame="fetch-detached-branch-required"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  echo "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git push origin main:detached
+
+  # $ echo "a" | shasum -a 256
+  oid="87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7"
+  assert_local_object "$oid" 2
+  assert_server_object "$reponame" "$oid" "refs/heads/detached"
+
+  sha="$(git rev-parse HEAD)"
+  git checkout --detach
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --all 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fetch' to fail"
+    exit 1
+  fi
+
+  grep "Expected ref \"refs/heads/detached\", got \"$sha\"" fetch.log
+)
+end_test
+
+begin_test "fetch with lfs.transfer.sendref disabled"
+(
+  set -e
+
+  reponame="fetch-sendref-branch-required"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  echo "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git push origin main:sendref
+
+  # $ echo "a" | shasum -a 256
+  oid="87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7"
+  assert_local_object "$oid" 2
+  assert_server_object "$reponame" "$oid" "refs/heads/sendref"
+
+  rm -rf .git/lfs/objects
+  git config lfs.transfer.sendref false
+  git lfs fetch --all 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fetch' to fail"
+    exit 1
+  fi
+
+  grep 'Expected ref "refs/heads/sendref", got ""' fetch.log
+)
+end_test
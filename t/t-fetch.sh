@@ -137,6 +137,35 @@ begin_test "fetch with remote and branches"
 )
 end_test
 
+begin_test "fetch in bare repository"
+(
+  set -e
+
+  git clone --mirror "$GITSERVER/$reponame" bare-mirror
+  cd bare-mirror
+
+  # Without any arguments, only the objects for HEAD are fetched.
+  git lfs fetch 2>&1 | tee fetch.log
+  grep "fetch: Fetching reference refs/heads/main" fetch.log
+  assert_local_object "$contents_oid" 1
+  refute_local_object "$b_oid" 1
+
+  git lfs fetch origin newbranch
+  assert_local_object "$b_oid" 1
+
+  git lfs fsck 2>&1 | tee fsck.log
+  grep "Git LFS fsck OK" fsck.log
+
+  # Checking out objects needs a work tree.
+  git lfs checkout 2>&1 | tee checkout.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs checkout\` to fail ..."
+    exit 1
+  fi
+  grep "This operation must be run in a work tree." checkout.log
+)
+end_test
+
 begin_test "fetch with main commit sha1"
 (
   set -e
@@ -619,6 +648,44 @@ begin_test "fetch --prune"
 )
 end_test
 
+begin_test "fetch --dry-run"
+(
+  set -e
+  clone_repo "$reponame" dry-run-clone
+  rm -rf .git/lfs/objects
+
+  git lfs fetch --dry-run origin main origin/newbranch 2>&1 | tee fetch.log
+  grep "missing $contents_oid => a.dat (1 B)" fetch.log
+  grep "missing $b_oid => b.dat (1 B)" fetch.log
+  grep "Would download 2 objects (2 B)" fetch.log
+  grep "0 objects already present locally (0 B)" fetch.log
+  refute_local_object "$contents_oid"
+  refute_local_object "$b_oid"
+
+  git lfs fetch origin main
+  assert_local_object "$contents_oid" 1
+
+  git lfs fetch --dry-run origin main origin/newbranch 2>&1 | tee fetch.log
+  grep "present $contents_oid => a.dat (1 B)" fetch.log
+  grep "missing $b_oid => b.dat (1 B)" fetch.log
+  grep "Would download 1 object (1 B)" fetch.log
+  grep "1 object already present locally (1 B)" fetch.log
+  refute_local_object "$b_oid"
+
+  git lfs fetch --dry-run --exclude="b*" origin main origin/newbranch 2>&1 | tee fetch.log
+  grep "present $contents_oid => a.dat (1 B)" fetch.log
+  [ "0" -eq "$(grep -c "b.dat" fetch.log)" ]
+  grep "Would download 0 objects (0 B)" fetch.log
+
+  git lfs fetch --dry-run --prune 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch --dry-run --prune\` to fail ..."
+    exit 1
+  fi
+  grep "Cannot combine --dry-run with --prune" fetch.log
+)
+end_test
+
 begin_test "fetch raw remote url"
 (
   set -e
@@ -652,6 +719,21 @@ begin_test "fetch with invalid ref"
 )
 end_test
 
+begin_test "fetch with unreachable endpoint"
+(
+  set -e
+  cd repo
+
+  rm -rf .git/lfs/objects
+  git -c lfs.url=http://127.0.0.1:1/ lfs fetch 2>&1 | tee fetch.log
+  if [ "4" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fetch' to exit with code 4"
+    exit 1
+  fi
+  grep "connection refused" fetch.log
+)
+end_test
+
 begin_test "fetch with invalid remote"
 (
   set -e
@@ -680,3 +762,189 @@ begin_test "fetch fails when LFS directory has wrong permissions"
   grep "error trying to create local storage directory" fetch.log
 )
 end_test
+
+begin_test "fetch with per-URL concurrent transfers"
+(
+  set -e
+  cd repo
+  rm -rf .git/lfs/objects
+
+  git config lfs.concurrenttransfers 5
+  git config "lfs.$GITSERVER.concurrenttransfers" 3
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep 'xfer: adapter "basic" Begin() with 3 workers' fetch.log
+  assert_local_object "$contents_oid" 1
+
+  rm -rf .git/lfs/objects
+  git config --unset "lfs.$GITSERVER.concurrenttransfers"
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep 'xfer: adapter "basic" Begin() with 5 workers' fetch.log
+  assert_local_object "$contents_oid" 1
+
+  git config --unset lfs.concurrenttransfers
+)
+end_test
+
+begin_test "fetch --sort-by-size"
+(
+  set -e
+
+  reponame="fetch-sort-by-size"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "medium" > medium.dat
+  printf "%s" "s" > small.dat
+  printf "%s" "largest file" > large.dat
+  git add .gitattributes *.dat
+  git commit -m "add files of different sizes"
+  git push origin main
+
+  small_oid="$(calc_oid "s")"
+  medium_oid="$(calc_oid "medium")"
+  large_oid="$(calc_oid "largest file")"
+
+  git config lfs.concurrenttransfers 1
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch --sort-by-size=asc 2>&1 | tee fetch.log
+  grep "processing job for" fetch.log | sed -e 's/.*for "\(.*\)"/\1/' > order.log
+  printf "%s\n%s\n%s\n" "$small_oid" "$medium_oid" "$large_oid" | diff -u - order.log
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch --sort-by-size=desc 2>&1 | tee fetch.log
+  grep "processing job for" fetch.log | sed -e 's/.*for "\(.*\)"/\1/' > order.log
+  printf "%s\n%s\n%s\n" "$large_oid" "$medium_oid" "$small_oid" | diff -u - order.log
+
+  assert_local_object "$small_oid" 1
+  assert_local_object "$medium_oid" 6
+  assert_local_object "$large_oid" 12
+
+  git lfs fetch --sort-by-size=random 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch --sort-by-size=random\` to fail ..."
+    exit 1
+  fi
+  grep "Invalid value for --sort-by-size" fetch.log
+)
+end_test
+
+begin_test "fetch --include-deleted"
+(
+  set -e
+
+  reponame="fetch-include-deleted"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "track files"
+  base="$(git rev-parse HEAD)"
+
+  printf "%s" "kept" > kept.dat
+  printf "%s" "deleted" > deleted.dat
+  git add *.dat
+  git commit -m "add files"
+
+  git rm deleted.dat
+  git commit -m "delete file"
+  git push origin main
+
+  kept_oid="$(calc_oid "kept")"
+  deleted_oid="$(calc_oid "deleted")"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch
+  assert_local_object "$kept_oid" 4
+  refute_local_object "$deleted_oid"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --include-deleted origin "$base..HEAD" 2>&1 | tee fetch.log
+  grep "Fetching reference range $base..HEAD" fetch.log
+  assert_local_object "$kept_oid" 4
+  assert_local_object "$deleted_oid" 7
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --include-deleted
+  assert_local_object "$deleted_oid" 7
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --include-deleted origin "HEAD..HEAD"
+  refute_local_object "$deleted_oid"
+
+  git lfs fetch --include-deleted --all 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch --include-deleted --all\` to fail ..."
+    exit 1
+  fi
+  grep "Cannot combine --include-deleted with --all or --recent" fetch.log
+)
+end_test
+
+begin_test "fetch with multiple remotes"
+(
+  set -e
+
+  reponame="fetch-multiple-remotes"
+  setup_remote_repo "$reponame"
+  setup_remote_repo "$reponame-mirror"
+  clone_repo "$reponame" "$reponame"
+  git remote add mirror "$GITSERVER/$reponame-mirror"
+
+  git lfs track "*.dat"
+  printf "%s" "primary" > a.dat
+  printf "%s" "mirrored" > b.dat
+  git add .gitattributes *.dat
+  git commit -m "add files"
+  git push origin main
+  git push mirror main
+
+  a_oid="$(calc_oid "primary")"
+  b_oid="$(calc_oid "mirrored")"
+
+  delete_server_object "$reponame" "$b_oid"
+  refute_server_object "$reponame" "$b_oid"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch origin 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch origin\` to fail ..."
+    exit 1
+  fi
+  refute_local_object "$b_oid"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --remote origin --remote mirror 2>&1 | tee fetch.log
+  assert_local_object "$a_oid" 7
+  assert_local_object "$b_oid" 8
+  grep "1 object was fetched from a fallback remote:" fetch.log
+  grep "b.dat ($b_oid) <= mirror" fetch.log
+  grep "a.dat ($a_oid)" fetch.log && exit 1
+
+  # All arguments are refs when --remote is given.
+  rm -rf .git/lfs/objects
+  git lfs fetch --remote origin,mirror main 2>&1 | tee fetch.log
+  assert_local_object "$b_oid" 8
+
+  delete_server_object "$reponame-mirror" "$b_oid"
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --remote origin,mirror 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch --remote origin,mirror\` to fail ..."
+    exit 1
+  fi
+  assert_local_object "$a_oid" 7
+  refute_local_object "$b_oid"
+  grep "failed to fetch some objects from 'origin', 'mirror'" fetch.log
+
+  git lfs fetch --remote origin --remote "not a remote" 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs fetch\` with an invalid remote to fail ..."
+    exit 1
+  fi
+  grep "Invalid remote name \"not a remote\"" fetch.log
+)
+end_test
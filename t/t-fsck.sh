@@ -255,6 +255,47 @@ begin_test "fsck detects invalid pointers with GIT_OBJECT_DIRECTORY"
 )
 end_test
 
+begin_test "fsck detects broken pointers"
+(
+  set -e
+
+  reponame="fsck-broken-pointers"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  echo "test data" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "first commit"
+
+  oid="$(calc_oid "test data\n")"
+  printf "version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize abc\n" "$oid" >bad-size.dat
+  printf "version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize 99\n" "$oid" >wrong-size.dat
+  printf "version https://git-lfs.github.com/spec/v1\noid sha256:xyz\nsize 10\n" >untracked.txt
+  printf "plain text\n" >plain.dat
+  git \
+    -c "filter.lfs.process=" \
+    -c "filter.lfs.clean=cat" \
+    -c "filter.lfs.required=false" \
+    add bad-size.dat wrong-size.dat untracked.txt plain.dat
+  git commit -m "broken pointers"
+
+  set +e
+  git lfs fsck --pointers >test.log 2>&1
+  RET=$?
+  set -e
+
+  cat test.log
+  [ "$RET" -eq 1 ]
+  grep 'pointer: brokenPointer: "bad-size.dat".*looks like a pointer but could not be parsed: invalid size: "abc"' test.log
+  grep 'pointer: brokenPointer: "untracked.txt".*looks like a pointer but could not be parsed: Invalid OID: xyz' test.log
+  grep "pointer: brokenPointer: Pointer for $oid (blob .*) has size 99, but the object is 10 bytes" test.log
+  grep 'pointer: unexpectedGitObject: "plain.dat".*should have been a pointer but was not' test.log
+  [ $(grep -c "brokenPointer" test.log) -eq 3 ]
+  [ $(grep -c "unexpectedGitObject" test.log) -eq 1 ]
+)
+end_test
+
 begin_test "fsck does not detect invalid pointers with no LFS objects"
 (
   set -e
@@ -410,6 +451,34 @@ begin_test "fsck detects invalid objects"
 )
 end_test
 
+begin_test "fsck reports invalid objects in order with concurrent hashing"
+(
+  set -e
+
+  reponame="fsck-objects-concurrency"
+  setup_invalid_objects
+
+  set +e
+  git -c lfs.fsck.concurrency=1 lfs fsck --objects --dry-run >serial.log 2>/dev/null
+  RET=$?
+  git -c lfs.fsck.concurrency=4 lfs fsck --objects --dry-run >concurrent.log 2>/dev/null
+  RET2=$?
+  git -c lfs.concurrenttransfers=3 lfs fsck --objects --dry-run >transfers.log 2>/dev/null
+  RET3=$?
+  set -e
+
+  [ "$RET" -eq 1 ]
+  [ "$RET2" -eq 1 ]
+  [ "$RET3" -eq 1 ]
+  [ $(grep -c 'objects: ' serial.log) -eq 4 ]
+  diff -u serial.log concurrent.log
+  diff -u serial.log transfers.log
+
+  git lfs fsck --objects --dry-run 2>progress.log || true
+  grep "fsck: Checking objects: 100% (4/4), done." progress.log
+)
+end_test
+
 begin_test "fsck detects invalid objects except in excluded paths"
 (
   set -e
@@ -0,0 +1,95 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+blake3_abc="6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"
+
+begin_test "hash algorithm: clean and smudge with blake3"
+(
+  set -e
+
+  reponame="hash-algorithm-blake3"
+  git init $reponame
+  cd $reponame
+
+  git config lfs.hashalgo blake3
+  git lfs track "*.dat"
+  printf "abc" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  pointer="$(git cat-file -p :a.dat)"
+  echo "$pointer" | grep "^oid blake3:$blake3_abc$"
+  assert_local_object "$blake3_abc" 3
+
+  [ "$pointer" = "$(git lfs pointer --file a.dat 2>/dev/null)" ]
+
+  rm a.dat
+  git checkout -- a.dat
+  [ "abc" = "$(cat a.dat)" ]
+
+  [ "Git LFS fsck OK" = "$(git lfs fsck)" ]
+
+  # Corrupt the object and ensure it is detected using the same algorithm.
+  printf "abd" > ".git/lfs/objects/${blake3_abc:0:2}/${blake3_abc:2:2}/$blake3_abc"
+  git lfs fsck --objects 2>&1 | tee fsck.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fsck' to fail ..."
+    exit 1
+  fi
+  grep "objects: corruptObject: a.dat ($blake3_abc) is corrupt" fsck.log
+)
+end_test
+
+begin_test "hash algorithm: unknown lfs.hashalgo fails clean"
+(
+  set -e
+
+  reponame="hash-algorithm-unknown-config"
+  git init $reponame
+  cd $reponame
+
+  git config lfs.hashalgo md5
+  git lfs track "*.dat"
+  printf "abc" > a.dat
+
+  git add a.dat 2>&1 | tee add.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git add' to fail ..."
+    exit 1
+  fi
+  grep 'Unsupported hash algorithm: "md5"' add.log
+)
+end_test
+
+begin_test "hash algorithm: pointer with unknown algorithm fails smudge"
+(
+  set -e
+
+  reponame="hash-algorithm-unknown-pointer"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+
+  oid="$(calc_oid "abc")"
+  sha="$(printf "version https://git-lfs.github.com/spec/v1\noid md5:%s\nsize 3\n" "$oid" | git hash-object -w --stdin)"
+  git update-index --add --cacheinfo 100644 "$sha" a.dat
+
+  git checkout -- a.dat 2>&1 | tee checkout.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git checkout' to fail ..."
+    exit 1
+  fi
+  grep 'Unable to smudge "a.dat": Pointer file error: Unsupported hash algorithm: "md5"' checkout.log
+
+  git cat-file -p :a.dat | git lfs smudge a.dat 2>&1 | tee smudge.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected 'git lfs smudge' to fail ..."
+    exit 1
+  fi
+  grep 'Unsupported hash algorithm: "md5"' smudge.log
+)
+end_test
@@ -0,0 +1,81 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "import-objects"
+(
+  set -e
+
+  reponame="import-objects"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  printf "bb" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "initial commit"
+
+  a_oid="$(calc_oid "a")"
+  b_oid="$(calc_oid "bb")"
+  c_oid="$(calc_oid "c")"
+
+  mkdir -p "$TRASHDIR/store/nested"
+  printf "a" > "$TRASHDIR/store/$a_oid"
+  printf "bb" > "$TRASHDIR/store/nested/$b_oid"
+  printf "not c" > "$TRASHDIR/store/$c_oid"
+  printf "readme" > "$TRASHDIR/store/README"
+
+  cd "$TRASHDIR"
+  GIT_LFS_SKIP_SMUDGE=1 git clone "$reponame" "$reponame-clone"
+  cd "$reponame-clone"
+
+  [ "$(pointer "$a_oid" 1)" = "$(cat a.dat)" ]
+  refute_local_object "$a_oid"
+  refute_local_object "$b_oid"
+
+  git lfs import-objects "$TRASHDIR/store" 2>&1 | tee import.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs import-objects' to fail ..."
+    exit 1
+  fi
+  grep "imported: 2 (3 B)" import.log
+  grep "already present: 0" import.log
+  grep "skipped: 2" import.log
+  grep "Skipped: $TRASHDIR/store/$c_oid" import.log
+  grep "file contents have Git LFS object ID $(calc_oid "not c")" import.log
+  grep "Skipped: $TRASHDIR/store/README" import.log
+  grep "file name is not a Git LFS object ID" import.log
+
+  assert_local_object "$a_oid" 1
+  assert_local_object "$b_oid" 2
+  refute_local_object "$c_oid"
+
+  git lfs checkout
+  [ "a" = "$(cat a.dat)" ]
+  [ "bb" = "$(cat b.dat)" ]
+
+  rm "$TRASHDIR/store/$c_oid" "$TRASHDIR/store/README"
+  git lfs import-objects "$TRASHDIR/store" 2>&1 | tee import.log
+  grep "imported: 0 (0 B)" import.log
+  grep "already present: 2" import.log
+  grep "skipped: 0" import.log
+)
+end_test
+
+begin_test "import-objects (not a directory)"
+(
+  set -e
+
+  reponame="import-objects-not-a-directory"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs import-objects missing 2>&1 | tee import.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs import-objects' to fail ..."
+    exit 1
+  fi
+  grep "Not a directory: missing" import.log
+)
+end_test
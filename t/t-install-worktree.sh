@@ -130,13 +130,27 @@ begin_test "install --worktree without worktreeConfig extension"
   cd "$treename"
 
   set +e
-  git lfs install --worktree >out.log
+  git lfs install --worktree >out.log 2>err.log
   res=$?
   set -e
 
-  cat out.log
-  grep -E "error running.*git.*config" out.log
+  cat out.log err.log
+  grep "The --worktree option requires the \`worktreeConfig\` extension" err.log
+  grep "git config extensions.worktreeConfig true" err.log
   [ "$res" -eq 2 ]
+
+  # Nothing should have been changed.
+  [ -z "$(git config --local filter.lfs.smudge)" ]
+  [ -z "$(git config --local filter.lfs.clean)" ]
+  [ -z "$(git config --local filter.lfs.process)" ]
+  [ ! -e "$(git rev-parse --git-common-dir)/hooks/pre-push" ]
+  [ "Git LFS initialized." != "$(cat out.log)" ]
+
+  git config extensions.worktreeConfig true
+  git lfs install --worktree
+
+  [ "git-lfs smudge -- %f" = "$(git config --worktree filter.lfs.smudge)" ]
+  [ -z "$(git config --local filter.lfs.smudge)" ]
 )
 end_test
 
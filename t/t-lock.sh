@@ -113,7 +113,92 @@ begin_test "lock multiple files (JSON)"
   git push origin main:other
 
   git lfs lock --json *.dat | tee lock.json
-  grep -E '\[\{"id":"[^"]+","path":"a\.dat","owner":\{"name":"Git LFS Tests"\},"locked_at":"[^"]+"\},\{"id":"[^"]+","path":"b\.dat","owner":\{"name":"Git LFS Tests"\},"locked_at":"[^"]+"\}\]' lock.json
+  grep -E '\[\{"id":"[^"]+","path":"a\.dat","owner":\{"name":"Git LFS Tests"\},"locked_at":"[^"]+","locked":true\},\{"id":"[^"]+","path":"b\.dat","owner":\{"name":"Git LFS Tests"\},"locked_at":"[^"]+","locked":true\}\]' lock.json
+)
+end_test
+
+begin_test "lock multiple files with partial failure (JSON)"
+(
+  set -e
+
+  reponame="lock-multiple-files-partial-json"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  echo "a" > a.dat
+  echo "b" > b.dat
+  echo "c" > c.dat
+  git add .gitattributes a.dat b.dat c.dat
+  git commit -m "add dat files"
+  git push origin main:other
+
+  git lfs lock --json b.dat | tee lock.json
+  assert_lock lock.json b.dat
+
+  git lfs lock --json a.dat b.dat c.dat 2>lock.err | tee lock.json
+  if [ "2" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs lock' to exit with status 2"
+    exit 1
+  fi
+
+  grep -E '^\[\{"id":"[^"]+","path":"a\.dat",.*"locked":true\},\{"path":"b\.dat","locked":false,"reason":"[^"]+"\},\{"id":"[^"]+","path":"c\.dat",.*"locked":true\}\]$' lock.json
+  grep "Locking b.dat failed" lock.err
+)
+end_test
+
+begin_test "lock with lease duration"
+(
+  set -e
+
+  reponame="lock-lease-duration"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  git lfs lock --json --lease-duration=2h "a.dat" 2>lock.err | tee lock.json
+  id=$(assert_lock lock.json a.dat)
+  assert_server_lock "$reponame" "$id"
+  grep '"expires_at":"[^"]\+"' lock.json
+  grep "does not support lock leases" lock.err && exit 1
+
+  git lfs locks | tee locks.log
+  grep -E "a\.dat.*ID:$id.*expires in (1h59m[0-9]+s|2h0m0s)" locks.log
+)
+end_test
+
+begin_test "lock with lease duration (server without leases)"
+(
+  set -e
+
+  reponame="lock-lease-duration-no-lease"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  git lfs lock --lease-duration=30m "a.dat" 2>lock.err | tee lock.log
+  grep "Locked a.dat" lock.log
+  grep "server does not support lock leases; lock on a.dat will not expire" lock.err
+
+  git lfs locks | tee locks.log
+  grep "expires in" locks.log && exit 1
+  true
+)
+end_test
+
+begin_test "lock with invalid lease duration"
+(
+  set -e
+
+  reponame="lock-invalid-lease-duration"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  for duration in 500ms -1h0m0s; do
+    git lfs lock --lease-duration=$duration "a.dat" 2>&1 | tee lock.log
+    if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+      echo >&2 "fatal: expected 'git lfs lock --lease-duration=$duration' to fail ..."
+      exit 1
+    fi
+    grep "Invalid lease duration: $duration (must be at least 1s)" lock.log
+  done
+
+  [ "0" -eq "$(git lfs locks | grep -c "a.dat")" ]
 )
 end_test
 
@@ -139,6 +139,97 @@ begin_test "list a single lock (--json)"
 )
 end_test
 
+begin_test "list no locks (--json)"
+(
+  set -e
+
+  reponame="locks_list_none_json"
+  setup_remote_repo_with_file "$reponame" "f_none_json.dat"
+
+  git lfs locks --json | tee locks.log
+  [ "[]" = "$(cat locks.log)" ]
+
+  git lfs locks --json --local | tee locks.log
+  [ "[]" = "$(cat locks.log)" ]
+)
+end_test
+
+begin_test "list locks (--json) with --cached"
+(
+  set -e
+
+  reponame="locks_list_cached_json"
+  setup_remote_repo_with_file "$reponame" "f_cached_json.dat"
+
+  git lfs locks --json --cached 2>&1 | tee locks.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs locks --json --cached' to fail"
+    exit 1
+  fi
+  grep -- "--cached option can't be combined with --json" locks.log
+)
+end_test
+
+begin_test "list locks with --refresh and --cached"
+(
+  set -e
+
+  reponame="locks_list_refresh"
+  setup_remote_repo_with_file "$reponame" "f_refresh.dat"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs locks --cached 2>&1 | tee locks.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs locks --cached' to fail without a cache"
+    exit 1
+  fi
+  grep "no cached locks present" locks.log
+
+  git lfs lock --json "f_refresh.dat" | tee lock.log
+  id=$(assert_lock lock.log f_refresh.dat)
+
+  git lfs locks --refresh | tee locks.log
+  grep "f_refresh.dat" locks.log
+
+  # Unlocking does not update the cache, so the lock is still listed.
+  git lfs unlock --id="$id"
+  git lfs locks --cached 2>locks.err | tee locks.log
+  grep "f_refresh.dat" locks.log
+  grep "Showing locks cached .* ago; use --refresh to update them." locks.err
+
+  git lfs locks --refresh | tee locks.log
+  [ "0" -eq "$(wc -l < locks.log)" ]
+  git lfs locks --cached | tee locks.log
+  [ "0" -eq "$(wc -l < locks.log)" ]
+
+  git lfs locks --refresh --limit 1 2>&1 | tee locks.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs locks --refresh --limit 1' to fail"
+    exit 1
+  fi
+  grep -- "--refresh option can't be combined with --limit" locks.log
+
+  # Replace the cache file with a directory, so that it can't be written.
+  cachefile="$(find .git/lfs -type f -path "*locks*" -name remote)"
+  [ -n "$cachefile" ]
+  rm "$cachefile"
+  mkdir -p "$cachefile/blocked"
+
+  git lfs locks 2>&1 | tee locks.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs locks' to ignore the cache failure"
+    exit 1
+  fi
+
+  git lfs locks --refresh 2>&1 | tee locks.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs locks --refresh' to fail"
+    exit 1
+  fi
+  grep "could not write lock cache" locks.log
+)
+end_test
+
 begin_test "list locks with a limit"
 (
   set -e
@@ -291,3 +382,53 @@ begin_test "cached locks with failed lock"
   [ $(wc -l < locks.log) -eq 0 ]
 )
 end_test
+
+begin_test "list stale locks"
+(
+  set -e
+
+  reponame="locks_list_stale"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "clone_$reponame"
+
+  git lfs track "*.dat"
+  echo "old" > "old.dat"
+  echo "leased" > "leased.dat"
+  echo "new" > "new.dat"
+  git add "old.dat" "leased.dat" "new.dat" ".gitattributes"
+  git commit -m "add files"
+  git push origin main
+
+  git lfs lock --json "old.dat" | tee lock.log
+  old_id="$(assert_lock lock.log old.dat)"
+  sleep 1
+  git lfs lock --json --lease-duration=1s "leased.dat" | tee lock.log
+  leased_id="$(assert_lock lock.log leased.dat)"
+  sleep 2
+  git lfs lock --json "new.dat" | tee lock.log
+  new_id="$(assert_lock lock.log new.dat)"
+
+  # The oldest lock is listed first.
+  git lfs locks --stale=2s | tee locks.log
+  [ 2 -eq "$(wc -l < locks.log)" ]
+  head -n 1 locks.log | grep "old.dat.*ID:$old_id"
+  tail -n 1 locks.log | grep "leased.dat.*ID:$leased_id"
+
+  git lfs locks --stale=2s --json | tee locks.log
+  grep "\"id\":\"$old_id\"" locks.log
+  grep "\"id\":\"$leased_id\"" locks.log
+  [ 0 -eq "$(grep -c "\"id\":\"$new_id\"" locks.log)" ]
+
+  # A lock whose lease has expired is stale however recently it was acquired.
+  git lfs locks --stale=1h | tee locks.log
+  grep "leased.dat" locks.log
+  [ 1 -eq "$(wc -l < locks.log)" ]
+
+  git lfs locks --stale=forever 2>&1 | tee locks.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs locks --stale=forever' to fail"
+    exit 1
+  fi
+  grep "Invalid value for --stale: \"forever\"" locks.log
+)
+end_test
@@ -27,3 +27,48 @@ begin_test "logs"
   [ "$(cat "$logfile")" = "$(git lfs logs last)" ]
 )
 end_test
+
+begin_test "logs parse"
+(
+  set -e
+
+  reponame="logs-parse-batch-unavailable"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" logs-parse
+
+  git lfs logs parse 2>&1 | tee parse.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs logs parse\` to fail without logs ..."
+    exit 1
+  fi
+  grep "No HTTP stats logs to parse" parse.log
+
+  contents="logs parse"
+  printf "%s" "$contents" > a.dat
+  git lfs track "*.dat"
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  git config --local lfs.transfer.retrybackoff 10
+  GIT_LOG_STATS=1 git push origin main
+
+  git lfs logs parse 2>&1 | tee parse.log
+  grep "^Log: http-[0-9]*\.log" parse.log
+  grep "^lfs.batch POST " parse.log
+  grep "^lfs.data.upload PUT " parse.log
+  grep "^  3 requests, 2 retries" parse.log
+
+  logname="$(ls .git/lfs/logs/http | tail -n 1)"
+  git lfs logs parse --json "$logname" 2>&1 | tee parse.json
+  grep '"retries":2,' parse.json
+  grep '"key":"lfs.batch","method":"POST"' parse.json
+  grep '"key":"lfs.data.upload","method":"PUT"' parse.json
+
+  git lfs logs parse missing.log 2>&1 | tee parse.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git lfs logs parse\` to fail for a missing log ..."
+    exit 1
+  fi
+  grep "Error reading log: missing.log" parse.log
+)
+end_test
@@ -593,6 +593,48 @@ begin_test "ls-files: reference with --deleted"
 )
 end_test
 
+begin_test "ls-files: --missing and --not-downloaded"
+(
+  set -e
+
+  reponame="ls-files-missing-not-downloaded"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  printf "b" > b.dat
+  printf "c" > c.dat
+  git add .gitattributes a.dat b.dat c.dat
+  git commit -m "initial commit"
+
+  # b.dat is missing from the working tree but present in the cache, and c.dat
+  # is a pointer in the working tree whose object is missing from the cache,
+  # as if it had not been smudged.
+  rm b.dat
+  c_oid="$(calc_oid "c")"
+  git cat-file -p :c.dat > c.dat
+  rm -f ".git/lfs/objects/${c_oid:0:2}/${c_oid:2:2}/$c_oid"
+
+  [ "b.dat" = "$(git lfs ls-files --name-only --missing)" ]
+  [ "c.dat" = "$(git lfs ls-files --name-only --not-downloaded)" ]
+
+  # b.dat is also not downloaded once its object is gone.
+  b_oid="$(calc_oid "b")"
+  rm -f ".git/lfs/objects/${b_oid:0:2}/${b_oid:2:2}/$b_oid"
+  [ "b.dat" = "$(git lfs ls-files --name-only --missing --not-downloaded)" ]
+
+  git lfs ls-files --json --missing > actual
+  grep '"name": "b.dat"' actual
+  grep "a.dat\|c.dat" actual && exit 1
+
+  git lfs ls-files --not-downloaded > ls-files.log
+  [ 2 -eq "$(wc -l < ls-files.log)" ]
+  grep "${b_oid:0:10} - b.dat" ls-files.log
+  grep "${c_oid:0:10} - c.dat" ls-files.log
+)
+end_test
+
 begin_test "ls-files: invalid --all ordering"
 (
   set -e
@@ -729,6 +771,68 @@ begin_test "ls-files: history with reference range"
 )
 end_test
 
+begin_test "ls-files: history with A..B reference range"
+(
+  set -e
+
+  reponame="ls-files-history-with-dot-range"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m 'intial commit'
+
+  echo "content of a-file" > a.dat
+  git add a.dat
+  git commit -m 'add a.dat'
+
+  git checkout -b feature
+
+  echo "content of b-file" > b.dat
+  git add b.dat
+  git commit -m 'add b.dat'
+
+  echo "content of b-file and later modified" > b.dat
+  git add b.dat
+  git commit -m 'modify b.dat'
+
+  git checkout main
+
+  echo "content of c-file" > c.dat
+  git add c.dat
+  git commit -m 'add c.dat'
+
+  git lfs ls-files main..feature 2>&1 | tee ls-files.log
+  [ 0 -eq $(grep -c "a\.dat" ls-files.log) ]
+  [ 2 -eq $(grep -c "b\.dat" ls-files.log) ]
+  [ 0 -eq $(grep -c "c\.dat" ls-files.log) ]
+
+  git lfs ls-files --json feature..main 2>&1 | tee ls-files.log
+  [ 1 -eq $(grep -c '"name": "c.dat"' ls-files.log) ]
+  [ 1 -eq $(grep -c '"name"' ls-files.log) ]
+
+  # An omitted side of the range is HEAD.
+  git lfs ls-files feature.. 2>&1 | tee ls-files.log
+  [ 1 -eq $(grep -c "c\.dat" ls-files.log) ]
+  [ 0 -eq $(grep -c "b\.dat" ls-files.log) ]
+
+  git lfs ls-files main...feature 2>&1 | tee ls-files.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected ls-files to fail with a symmetric difference ..."
+    exit 1
+  fi
+  grep "Cannot use symmetric difference" ls-files.log
+
+  git lfs ls-files --deleted main..feature 2>&1 | tee ls-files.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected ls-files to fail with --deleted and a range ..."
+    exit 1
+  fi
+  grep "Cannot use --deleted with reference range" ls-files.log
+)
+end_test
+
 begin_test "ls-files: not affected by lfs.fetchexclude"
 (
   set -e
@@ -768,6 +872,7 @@ begin_test "ls-files --json"
  "files": [
   {
    "name": "missing.dat",
+   "path": "missing.dat",
    "size": 8,
    "checkout": true,
    "downloaded": true,
@@ -790,6 +895,7 @@ EOF
  "files": [
   {
    "name": "some.dat",
+   "path": "some.dat",
    "size": 10,
    "checkout": true,
    "downloaded": true,
@@ -838,6 +944,7 @@ begin_test "ls-files: files in subdirectory (--json)"
  "files": [
   {
    "name": "subdir/some.dat",
+   "path": "subdir/some.dat",
    "size": 9,
    "checkout": true,
    "downloaded": true,
@@ -880,6 +987,7 @@ begin_test "ls-files: run within subdirectory (--json)"
  "files": [
   {
    "name": "a.dat",
+   "path": "a.dat",
    "size": 1,
    "checkout": true,
    "downloaded": true,
@@ -889,6 +997,7 @@ begin_test "ls-files: run within subdirectory (--json)"
   },
   {
    "name": "subdir/b.dat",
+   "path": "subdir/b.dat",
    "size": 1,
    "checkout": true,
    "downloaded": true,
@@ -934,6 +1043,7 @@ begin_test "ls-files: checkout and download status (--json)"
  "files": [
   {
    "name": "a.dat",
+   "path": "a.dat",
    "size": 1,
    "checkout": false,
    "downloaded": true,
@@ -943,6 +1053,7 @@ begin_test "ls-files: checkout and download status (--json)"
   },
   {
    "name": "b.dat",
+   "path": "b.dat",
    "size": 1,
    "checkout": false,
    "downloaded": false,
@@ -999,6 +1110,7 @@ begin_test "ls-files: checkout and download status (run within subdirectory) (--
  "files": [
   {
    "name": "a.dat",
+   "path": "a.dat",
    "size": 1,
    "checkout": false,
    "downloaded": true,
@@ -1008,6 +1120,7 @@ begin_test "ls-files: checkout and download status (run within subdirectory) (--
   },
   {
    "name": "b.dat",
+   "path": "b.dat",
    "size": 1,
    "checkout": false,
    "downloaded": false,
@@ -1017,6 +1130,7 @@ begin_test "ls-files: checkout and download status (run within subdirectory) (--
   },
   {
    "name": "subdir/c.dat",
+   "path": "subdir/c.dat",
    "size": 1,
    "checkout": false,
    "downloaded": true,
@@ -1026,6 +1140,7 @@ begin_test "ls-files: checkout and download status (run within subdirectory) (--
   },
   {
    "name": "subdir/d.dat",
+   "path": "subdir/d.dat",
    "size": 1,
    "checkout": false,
    "downloaded": false,
@@ -1038,3 +1153,26 @@ begin_test "ls-files: checkout and download status (run within subdirectory) (--
 EOF)
 )
 end_test
+
+begin_test "ls-files: no files (--json)"
+(
+  set -e
+
+  reponame="ls-files-empty-json"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  echo "some text" > some.txt
+  git add .gitattributes some.txt
+  git commit -m "initial commit"
+
+  git lfs ls-files --json > actual
+  cat > expected <<-EOF
+{
+ "files": []
+}
+EOF
+  diff -u actual expected
+)
+end_test
@@ -0,0 +1,76 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "metrics: push and fetch"
+(
+  set -e
+
+  reponame="metrics"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "abc" > a.dat
+  printf "defgh" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add files"
+
+  mkdir "$TRASHDIR/prom"
+  metrics="$TRASHDIR/prom/metrics.prom"
+
+  GIT_LFS_METRICS_FILE="$metrics" git lfs push origin main
+  cat "$metrics"
+  grep '^git_lfs_transfer_bytes_total{command="push",direction="upload"} 8$' "$metrics"
+  grep '^git_lfs_transfer_bytes_total{command="push",direction="download"} 0$' "$metrics"
+  grep '^git_lfs_transfer_objects_total{command="push",direction="upload"} 2$' "$metrics"
+  grep '^git_lfs_transfer_retries_total{command="push"} 0$' "$metrics"
+  grep '^git_lfs_command_duration_seconds{command="push"} [0-9]*\.[0-9]*$' "$metrics"
+  grep "^# TYPE git_lfs_transfer_bytes_total counter$" "$metrics"
+
+  rm -rf .git/lfs/objects
+  GIT_LFS_METRICS_FILE="$metrics" git lfs fetch
+  cat "$metrics"
+  grep '^git_lfs_transfer_bytes_total{command="fetch",direction="download"} 8$' "$metrics"
+  grep '^git_lfs_transfer_objects_total{command="fetch",direction="download"} 2$' "$metrics"
+  grep '^git_lfs_transfer_objects_total{command="fetch",direction="upload"} 0$' "$metrics"
+
+  # no temporary files are left behind
+  [ "metrics.prom" = "$(ls -A "$TRASHDIR/prom")" ]
+)
+end_test
+
+begin_test "metrics: not written without transfers"
+(
+  set -e
+
+  cd metrics
+
+  metrics="$TRASHDIR/prom/metrics-status.prom"
+  GIT_LFS_METRICS_FILE="$metrics" git lfs status
+  [ ! -e "$metrics" ]
+)
+end_test
+
+begin_test "metrics: written when the command fails"
+(
+  set -e
+
+  cd metrics
+
+  printf "missing" > missing.dat
+  git add missing.dat
+  git commit -m "add missing.dat"
+  rm -rf .git/lfs/objects/*/*/"$(calc_oid "missing")"
+
+  metrics="$TRASHDIR/prom/metrics-fail.prom"
+  GIT_LFS_METRICS_FILE="$metrics" git lfs push origin main 2>&1 | tee push.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs push' to fail ..."
+    exit 1
+  fi
+
+  cat "$metrics"
+  grep '^git_lfs_transfer_objects_total{command="push",direction="upload"} 0$' "$metrics"
+)
+end_test
@@ -466,6 +466,35 @@ begin_test "migrate export (--object-map)"
 )
 end_test
 
+begin_test "migrate export (--orphans)"
+(
+  set -e
+
+  setup_multiple_local_branches_tracked
+
+  # Add b.md, a pointer existing only on main
+  base64 < /dev/urandom | head -c 160 > b.md
+  git add b.md
+  git commit -m "add b.md"
+  b_md_oid="$(calc_oid "$(cat b.md)")"
+
+  output_dir=$(mktemp -d)
+
+  git lfs migrate export --include="*.md, *.txt" --orphans "${output_dir}/orphans.txt" 2>&1 | tee "${output_dir}/migrate.log"
+  grep "1 object is no longer referenced by any local branch or tag" "${output_dir}/migrate.log"
+
+  # The other objects are still referenced by pointers in `my-feature`.
+  [ "$b_md_oid 160" = "$(cat "${output_dir}/orphans.txt")" ]
+
+  # Exporting everything leaves every object orphaned.
+  git lfs migrate export --everything --include="*.md, *.txt" --orphans "${output_dir}/orphans.txt"
+  [ 3 -eq "$(wc -l < "${output_dir}/orphans.txt")" ]
+  md_feature_oid="$(git show my-feature:a.md | calc_oid_file /dev/stdin)"
+  grep "^$md_feature_oid 30\$" "${output_dir}/orphans.txt"
+  sort -c "${output_dir}/orphans.txt"
+)
+end_test
+
 begin_test "migrate export (--verbose)"
 (
   set -e
@@ -22,6 +22,34 @@ begin_test "migrate import (--fixup)"
 )
 end_test
 
+begin_test "migrate import (--fixup, mixed pointers and contents)"
+(
+  set -e
+
+  setup_single_local_branch_tracked_corrupt
+
+  printf "pointer" > b.txt
+  git add b.txt
+  git commit -m "add b.txt"
+
+  txt_oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+  b_oid="$(calc_oid "pointer")"
+  b_blob="$(git rev-parse main:b.txt)"
+
+  first="$(git rev-parse --short=7 main~1)"
+  second="$(git rev-parse --short=7 main)"
+
+  git lfs migrate import --everything --fixup --yes >migrate.log
+
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+  assert_pointer "refs/heads/main" "b.txt" "$b_oid" "7"
+  [ "$b_blob" = "$(git rev-parse main:b.txt)" ]
+
+  grep "^$first -> [0-9a-f]\{7\}: 1 fixed, 0 already in Git LFS" migrate.log
+  grep "^$second -> [0-9a-f]\{7\}: 0 fixed, 1 already in Git LFS" migrate.log
+)
+end_test
+
 begin_test "migrate import (--fixup, complex nested)"
 (
   set -e
@@ -263,3 +263,93 @@ EOF
   assert_local_object "$bar_oid" "3"
 )
 end_test
+
+begin_test "migrate import --no-rewrite (--include)"
+(
+  set -e
+
+  setup_single_local_branch_untracked
+
+  mkdir -p dir
+  base64 < /dev/urandom | head -c 160 > dir/b.txt
+  git add dir/b.txt
+  git commit -m "add dir/b.txt"
+
+  txt_oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+  b_oid="$(calc_oid "$(git cat-file -p :dir/b.txt)")"
+  md_oid="$(calc_oid "$(git cat-file -p :a.md)")"
+  prev_commit_oid="$(git rev-parse HEAD)"
+
+  git lfs migrate import --no-rewrite --yes --include="*.txt"
+
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+  assert_pointer "refs/heads/main" "dir/b.txt" "$b_oid" "160"
+  refute_pointer "refs/heads/main" "a.md"
+  assert_local_object "$txt_oid" "120"
+  assert_local_object "$b_oid" "160"
+
+  # Ensure the git history remained the same
+  [ "$prev_commit_oid" = "$(git rev-parse HEAD~1)" ]
+
+  git cat-file -p HEAD:.gitattributes | grep -xF "*.txt filter=lfs diff=lfs merge=lfs -text"
+  git log -1 --pretty=format:%s | grep -xF "*.txt: convert to Git LFS"
+
+  # Files already converted are not imported again.
+  prev_commit_oid="$(git rev-parse HEAD)"
+  git lfs migrate import --no-rewrite --yes --include="*.txt" 2>&1 | tee migrate.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected migrate import to fail with no files to import ..."
+    exit 1
+  fi
+  grep "No files to import with --no-rewrite" migrate.log
+  [ "$prev_commit_oid" = "$(git rev-parse HEAD)" ]
+
+  git fsck
+)
+end_test
+
+begin_test "migrate import --no-rewrite (--above)"
+(
+  set -e
+
+  setup_single_local_branch_untracked
+
+  md_oid="$(calc_oid "$(git cat-file -p :a.md)")"
+  prev_commit_oid="$(git rev-parse HEAD)"
+
+  git lfs migrate import --no-rewrite --yes --above=130b
+
+  assert_pointer "refs/heads/main" "a.md" "$md_oid" "140"
+  refute_pointer "refs/heads/main" "a.txt"
+  assert_local_object "$md_oid" "140"
+
+  [ "$prev_commit_oid" = "$(git rev-parse HEAD~1)" ]
+
+  git cat-file -p HEAD:.gitattributes | grep -xF "/a.md filter=lfs diff=lfs merge=lfs -text"
+  git log -1 --pretty=format:%s | grep -xF "a.md: convert to Git LFS"
+
+  git fsck
+)
+end_test
+
+begin_test "migrate import --no-rewrite (invalid selection)"
+(
+  set -e
+
+  setup_single_local_branch_untracked
+
+  git lfs migrate import --no-rewrite --yes --include="*.txt" a.md 2>&1 | tee migrate.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected migrate import to fail with --include and files ..."
+    exit 1
+  fi
+  grep "Cannot use --include, --exclude, or --above with files given with --no-rewrite" migrate.log
+
+  git lfs migrate import --no-rewrite --yes --include="*.txt" --above=1b 2>&1 | tee migrate.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected migrate import to fail with --include and --above ..."
+    exit 1
+  fi
+  grep "Cannot use --above with --include, --exclude, --fixup" migrate.log
+)
+end_test
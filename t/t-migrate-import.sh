@@ -513,6 +513,69 @@ begin_test "migrate import (above with include or exclude)"
 )
 end_test
 
+begin_test "migrate import (above with --respect-attributes)"
+(
+  set -e
+
+  remove_and_create_local_repo "migrate-import-above-respect-attributes"
+
+  echo "*.bin filter=lfs diff=lfs merge=lfs -text" > .gitattributes
+  printf "small tracked" > a.bin
+  git add .gitattributes a.bin
+  git commit -m "add a.bin"
+
+  # Track *.dat in a later commit, but add c.dat without running the clean
+  # filter so that it is stored in Git even though it should not be.
+  echo "*.dat filter=lfs diff=lfs merge=lfs -text" >> .gitattributes
+  printf "small untracked" > c.dat
+  base64 < /dev/urandom | head -c 140 > big.txt
+  base64 < /dev/urandom | head -c 20 > small.txt
+  git add .gitattributes big.txt small.txt
+  git update-index --add --cacheinfo \
+    "100644,$(git hash-object -w --no-filters c.dat),c.dat"
+  git commit -m "add c.dat, big.txt, small.txt"
+
+  a_oid="$(calc_oid "small tracked")"
+  c_oid="$(calc_oid "small untracked")"
+  big_oid="$(calc_oid "$(git cat-file -p "refs/heads/main:big.txt")")"
+  small_oid="$(calc_oid "$(git cat-file -p "refs/heads/main:small.txt")")"
+  first="$(git rev-parse --short=7 refs/heads/main~1)"
+
+  git lfs migrate import --yes --above=121B --respect-attributes >../migrate.log
+
+  # The existing pointer is left alone, the small file matching the
+  # historical .gitattributes is imported despite being below the
+  # threshold, and only the large file is added to .gitattributes.
+  assert_pointer "refs/heads/main" "a.bin" "$a_oid" "13"
+  assert_pointer "refs/heads/main" "c.dat" "$c_oid" "15"
+  assert_pointer "refs/heads/main" "big.txt" "$big_oid" "140"
+  refute_pointer "refs/heads/main" "small.txt" "$small_oid" "20"
+
+  main_attrs="$(git cat-file -p "refs/heads/main:.gitattributes")"
+  echo "$main_attrs" | grep -q "/big.txt filter=lfs diff=lfs merge=lfs"
+  echo "$main_attrs" | grep -q "/c.dat" && exit 1
+
+  [ 2 -eq "$(wc -l < ../migrate.log)" ]
+  grep "^$first -> [0-9a-f]\{7\}: 0 converted, 1 already in Git LFS$" ../migrate.log
+  sed -n 2p ../migrate.log | grep ": 2 converted, 0 already in Git LFS$"
+)
+end_test
+
+begin_test "migrate import (--respect-attributes without --above)"
+(
+  set -e
+  setup_single_local_branch_untracked
+
+  git lfs migrate import --respect-attributes 2>&1 | tee ../migrate.log
+  if [ "${PIPESTATUS[0]}" -eq 0 ]; then
+    echo >&2 "fatal: expected 'git lfs migrate import' to fail ..."
+    exit 1
+  fi
+
+  grep "Cannot use --respect-attributes without --above" ../migrate.log
+)
+end_test
+
 begin_test "migrate import (existing .gitattributes)"
 (
   set -e
@@ -310,6 +310,62 @@ begin_test "migrate info (above threshold, top)"
 )
 end_test
 
+begin_test "migrate info (--json)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  original_head="$(git rev-parse HEAD)"
+
+  git lfs migrate info --json --above=130B 2>/dev/null >info.json
+
+  grep '"above": 130' info.json
+  grep '"qualifier": "\*.md"' info.json
+  grep '"bytes_above": 140' info.json
+  grep '"bytes": 140' info.json
+  grep '"total_above": 1' info.json
+  [ "0" -eq "$(grep -c '"qualifier": "\*.txt"' info.json)" ]
+  [ "0" -eq "$(grep -c '"histogram"' info.json)" ]
+  [ "0" -eq "$(grep -c '"lfs_objects"' info.json)" ]
+
+  migrated_head="$(git rev-parse HEAD)"
+
+  assert_ref_unmoved "HEAD" "$original_head" "$migrated_head"
+)
+end_test
+
+begin_test "migrate info (--histogram)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  base64 < /dev/urandom | head -c 1500 > b.bin
+  git add b.bin
+  git commit -m "b.bin"
+
+  original_head="$(git rev-parse HEAD)"
+
+  diff -u <(git lfs migrate info --histogram 2>&1 | tail -n 5) <(cat <<-EOF
+	64 B - 128 B 	1 file 	120 B
+	128 B - 256 B	1 file 	140 B
+	256 B - 512 B	0 files	0 B
+	512 B - 1 KiB	0 files	0 B
+	1 KiB - 2 KiB	1 file 	1.5 KB
+	EOF)
+
+  git lfs migrate info --json --histogram 2>/dev/null >info.json
+  grep '"min": 1024' info.json
+  grep '"max": 2048' info.json
+  grep '"bytes": 1500' info.json
+
+  migrated_head="$(git rev-parse HEAD)"
+
+  assert_ref_unmoved "HEAD" "$original_head" "$migrated_head"
+)
+end_test
+
 begin_test "migrate info (top)"
 (
   set -e
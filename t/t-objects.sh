@@ -0,0 +1,120 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "objects gc"
+(
+  set -e
+
+  reponame="objects-gc"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "old" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  printf "current" > a.dat
+  git add a.dat
+  git commit -m "update a.dat"
+  git push origin main
+
+  git checkout -b topic
+  printf "topic" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+  git checkout main
+
+  printf "amended" > c.dat
+  git add c.dat
+  git commit -m "add c.dat"
+  printf "staged" > c.dat
+  git add c.dat
+  git commit --amend -m "add c.dat"
+
+  printf "stashed" > a.dat
+  git stash
+
+  printf "index" > d.dat
+  git add d.dat
+
+  old_oid="$(calc_oid "old")"
+  current_oid="$(calc_oid "current")"
+  topic_oid="$(calc_oid "topic")"
+  amended_oid="$(calc_oid "amended")"
+  staged_oid="$(calc_oid "staged")"
+  stashed_oid="$(calc_oid "stashed")"
+  index_oid="$(calc_oid "index")"
+
+  git lfs objects gc --dry-run 2>&1 | tee gc.log
+  grep "1 file would be pruned (7 B)" gc.log
+  grep "$amended_oid" gc.log
+  assert_local_object "$amended_oid" 7
+
+  git lfs objects gc 2>&1 | tee gc.log
+  refute_local_object "$amended_oid"
+
+  assert_local_object "$old_oid" 3
+  assert_local_object "$current_oid" 7
+  assert_local_object "$topic_oid" 5
+  assert_local_object "$staged_oid" 6
+  assert_local_object "$stashed_oid" 7
+  assert_local_object "$index_oid" 5
+
+  # old versions on deleted branches are unreachable too
+  git branch -D topic
+  git lfs objects gc --verbose 2>&1 | tee gc.log
+  grep "$topic_oid" gc.log
+  refute_local_object "$topic_oid"
+  assert_local_object "$old_oid" 3
+)
+end_test
+
+begin_test "objects relayout"
+(
+  set -e
+
+  reponame="objects-relayout"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "legacy" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  legacy_oid="$(calc_oid "legacy")"
+  legacy_dir=".git/lfs/objects/${legacy_oid:0:2}/${legacy_oid:2:2}"
+  [ -f "$legacy_dir/$legacy_oid" ]
+
+  git config lfs.objectsharddepth 3
+  printf "sharded" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+  sharded_oid="$(calc_oid "sharded")"
+  sharded_path=".git/lfs/objects/${sharded_oid:0:2}/${sharded_oid:2:2}/${sharded_oid:4:2}/$sharded_oid"
+  [ -f "$sharded_path" ]
+
+  # Objects at the default depth are still found.
+  rm a.dat
+  git checkout -- a.dat
+  [ "legacy" = "$(cat a.dat)" ]
+  git push origin main
+  assert_server_object "$reponame" "$legacy_oid"
+  assert_server_object "$reponame" "$sharded_oid"
+
+  git lfs objects relayout 2>&1 | tee relayout.log
+  grep "Moved 1 object" relayout.log
+  [ -f "$legacy_dir/${legacy_oid:4:2}/$legacy_oid" ]
+  [ ! -e "$legacy_dir/$legacy_oid" ]
+
+  git lfs fsck --objects
+
+  git config --unset lfs.objectsharddepth
+  git lfs objects relayout 2>&1 | tee relayout.log
+  grep "Moved 2 objects" relayout.log
+  assert_local_object "$legacy_oid" 6
+  assert_local_object "$sharded_oid" 7
+  [ ! -e "$legacy_dir/${legacy_oid:4:2}" ]
+)
+end_test
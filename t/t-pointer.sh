@@ -32,6 +32,40 @@ Git blob OID: e18acd45d7e3ce0451d1d637f9697aa508e07dee"
 )
 end_test
 
+begin_test "pointer --oid --size"
+(
+  set -e
+
+  echo "simple" > some-file
+  oid="6c17f2007cbe934aee6e309b28b2dba3c119c5dff2ef813ed124699efe319868"
+
+  git lfs pointer --oid "$oid" --size 7 >pointer.txt 2>/dev/null
+  git lfs pointer --file=some-file >expected.txt 2>/dev/null
+  diff -u expected.txt pointer.txt
+
+  input="$(cat pointer.txt)"
+  echo "$input" | git lfs pointer --oid "$oid" --size 7 --stdin
+
+  [ -z "$(git lfs pointer --oid "$oid" --size 0 2>/dev/null)" ]
+
+  git lfs pointer --oid "$oid" 2>&1 | tee pointer.log
+  grep -- "--oid and --size must be given together" pointer.log
+  git lfs pointer --size 7 2>&1 | tee pointer.log
+  grep -- "--oid and --size must be given together" pointer.log
+  git lfs pointer --oid "6C17F2" --size 7 2>&1 | tee pointer.log
+  grep "Invalid OID: 6C17F2" pointer.log
+  git lfs pointer --oid "$oid" --size -1 2>&1 | tee pointer.log
+  grep "Invalid size: -1" pointer.log
+  git lfs pointer --oid "$oid" --size 7 --file=some-file 2>&1 | tee pointer.log
+  grep "Cannot combine --file with --oid or --size" pointer.log
+
+  git lfs pointer --oid "$oid" --size 7 --check && exit 1
+
+  # Make the result of the subshell a success.
+  true
+)
+end_test
+
 begin_test "pointer --file --stdin mismatch"
 (
   set -e
@@ -437,3 +471,53 @@ begin_test "pointer --check (with invalid arguments)"
   true
 )
 end_test
+
+begin_test "pointer --check (with paths)"
+(
+  set -e
+
+  reponame="pointer---check-paths"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  oid="$(calc_oid "contents")"
+  git cat-file blob ":a.dat" > good.ptr
+  printf '%s\n' \
+    'version https://git-lfs.github.com/spec/v1' \
+    "oid sha256:$oid" \
+    'size 5' \
+    >bad-size.ptr
+  echo "missing" > missing.txt
+  git lfs pointer --file missing.txt >missing.ptr 2>/dev/null
+  echo "not an object" > other.bin
+
+  git lfs pointer --check good.ptr a.dat missing.ptr 2>&1 | tee check.log
+  grep "good.ptr: pointer, object present locally" check.log
+  grep "a.dat: Git LFS object" check.log
+  grep "missing.ptr: pointer, object not present locally" check.log
+
+  git lfs pointer --check good.ptr bad-size.ptr other.bin 2>&1 | tee check.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pointer --check' to fail ..."
+    exit 1
+  fi
+  grep "bad-size.ptr: pointer, size mismatch: pointer has size 5 but object has size 8" check.log
+  grep "other.bin: neither a pointer nor a Git LFS object" check.log
+
+  printf "%s\n" good.ptr a.dat | git lfs pointer --check - 2>&1 | tee check.log
+  [ "2" -eq "$(wc -l < check.log)" ]
+  grep "good.ptr: pointer, object present locally" check.log
+  grep "a.dat: Git LFS object" check.log
+
+  printf "%s\n" other.bin | git lfs pointer --check - && exit 1
+
+  git lfs pointer --check --file good.ptr good.ptr && exit 1
+  # Make the result of the subshell a success.
+  true
+)
+end_test
@@ -0,0 +1,151 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "pre-commit: no threshold"
+(
+  set -e
+
+  reponame="pre-commit-no-threshold"
+  git init "$reponame"
+  cd "$reponame"
+
+  base64 < /dev/urandom | head -c 2048 > large.txt
+  git add large.txt
+
+  git lfs pre-commit 2>&1 | tee pre-commit.log
+  [ ! -s pre-commit.log ]
+
+  # a boolean controls only the warning about files of 4 GiB or more
+  git config lfs.largefilewarning true
+  git lfs pre-commit 2>&1 | tee pre-commit.log
+  [ ! -s pre-commit.log ]
+)
+end_test
+
+begin_test "pre-commit: warns about large files"
+(
+  set -e
+
+  reponame="pre-commit-warn"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  base64 < /dev/urandom | head -c 2048 > tracked.dat
+  base64 < /dev/urandom | head -c 512 > small.txt
+  git add .gitattributes tracked.dat small.txt
+  git commit -m "initial commit"
+
+  git config lfs.largefilewarning 1KB
+
+  base64 < /dev/urandom | head -c 2048 > large.txt
+  base64 < /dev/urandom | head -c 2048 > other.dat
+  printf "small" > small.txt
+  git add large.txt other.dat small.txt
+
+  git lfs pre-commit 2>&1 | tee pre-commit.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pre-commit' to succeed ..."
+    exit 1
+  fi
+
+  grep "Warning: committing 1 file larger than 1.0 KB which is not tracked by Git LFS:" pre-commit.log
+  grep "large.txt (2.0 KB)" pre-commit.log
+  grep "other.dat" pre-commit.log && exit 1
+  grep "tracked.dat" pre-commit.log && exit 1
+  grep "small.txt" pre-commit.log && exit 1
+
+  # files committed before are not reported again
+  git commit -m "add large file"
+  git lfs pre-commit 2>&1 | tee pre-commit.log
+  [ ! -s pre-commit.log ]
+)
+end_test
+
+begin_test "pre-commit: refuses large files"
+(
+  set -e
+
+  reponame="pre-commit-refuse"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.largefilewarning 1KB
+  git config lfs.largefilerefuse true
+
+  git lfs install --local --pre-commit
+  grep "git lfs pre-commit" .git/hooks/pre-commit
+
+  base64 < /dev/urandom | head -c 2048 > a.txt
+  base64 < /dev/urandom | head -c 3072 > b.txt
+  git add a.txt b.txt
+
+  git commit -m "add large files" 2>&1 | tee commit.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git commit' to fail ..."
+    exit 1
+  fi
+
+  grep "Refusing to commit 2 files larger than 1.0 KB which are not tracked by Git LFS:" commit.log
+  grep "a.txt (2.0 KB)" commit.log
+  grep "b.txt (3.1 KB)" commit.log
+  [ "0" -eq "$(git rev-list --all | wc -l)" ]
+
+  git commit --no-verify -m "add large files"
+  [ "1" -eq "$(git rev-list --all | wc -l)" ]
+)
+end_test
+
+begin_test "pre-commit: invalid threshold"
+(
+  set -e
+
+  reponame="pre-commit-invalid-threshold"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.largefilewarning lots
+
+  git lfs pre-commit 2>&1 | tee pre-commit.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pre-commit' to fail ..."
+    exit 1
+  fi
+  grep 'Invalid value for `lfs.largefilewarning`: "lots"' pre-commit.log
+)
+end_test
+
+begin_test "pre-commit: hook is only installed on request"
+(
+  set -e
+
+  reponame="pre-commit-install"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs install --local
+  [ ! -e .git/hooks/pre-commit ]
+
+  git lfs update --pre-commit
+  grep "git lfs pre-commit" .git/hooks/pre-commit
+
+  git lfs uninstall --local
+  [ ! -e .git/hooks/pre-commit ]
+
+  # an existing pre-commit hook is left alone
+  printf "#!/bin/sh\necho custom\n" > .git/hooks/pre-commit
+  git lfs install --local
+  grep "echo custom" .git/hooks/pre-commit
+
+  git lfs install --local --pre-commit 2>&1 | tee install.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs install --pre-commit' to fail ..."
+    exit 1
+  fi
+  grep "Hook already exists: pre-commit" install.log
+
+  git lfs uninstall --local
+  grep "echo custom" .git/hooks/pre-commit
+)
+end_test
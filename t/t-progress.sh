@@ -52,3 +52,41 @@ begin_test "GIT_LFS_PROGRESS"
   grep "checkout 5/5" ../progress.log
 )
 end_test
+
+begin_test "progress events with --progress-fd"
+(
+  set -e
+  reponame="$reponame-fd"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" repo-fd
+
+  git lfs track "*.dat"
+  contents="a"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git lfs push --progress-fd=3 origin main 3>events.log 2>&1 | tee push.log
+  cat events.log
+  grep "{\"oid\":\"$oid\",\"name\":\"a.dat\",\"direction\":\"upload\",\"bytes_so_far\":1,\"bytes_total\":1}" events.log
+  grep "Uploading LFS objects: 100% (1/1), 1 B" push.log
+  [ "0" -eq "$(grep -c "bytes_so_far" push.log)" ]
+
+  rm -rf .git/lfs/objects
+  git lfs pull --progress-fd=3 3>events.log 2>&1 | tee pull.log
+  cat events.log
+  grep "{\"oid\":\"$oid\",\"name\":\"a.dat\",\"direction\":\"download\",\"bytes_so_far\":1,\"bytes_total\":1}" events.log
+  assert_local_object "$oid" 1
+
+  rm -rf .git/lfs/objects
+  GIT_LFS_PROGRESS_FD=3 git lfs fetch 3>events.log
+  cat events.log
+  grep "\"oid\":\"$oid\",\"name\":\"a.dat\",\"direction\":\"download\"" events.log
+
+  rm -rf .git/lfs/objects
+  git lfs fetch --progress-fd=42 2>&1 | tee fetch.log
+  grep "Error creating progress logger" fetch.log
+  assert_local_object "$oid" 1
+)
+end_test
@@ -681,6 +681,81 @@ begin_test "prune verify"
 )
 end_test
 
+begin_test "prune --verify-remote-only"
+(
+  set -e
+
+  reponame="prune_verify_remote_only"
+  setup_remote_repo "remote_$reponame"
+
+  clone_repo "remote_$reponame" "clone_$reponame"
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "Tracking \"\*.dat\"" track.log
+
+  content_head="HEAD content"
+  content_commit2_failverify="Content for commit 2 (prune - fail verify)"
+  content_commit1="Content for commit 1 (prune)"
+  oid_head=$(calc_oid "$content_head")
+  oid_commit2_failverify=$(calc_oid "$content_commit2_failverify")
+  oid_commit1=$(calc_oid "$content_commit1")
+
+  echo "[
+  {
+    \"CommitDate\":\"$(get_date -50d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_commit1}, \"Data\":\"$content_commit1\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -40d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_commit2_failverify}, \"Data\":\"$content_commit2_failverify\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -25d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_head}, \"Data\":\"$content_head\"}]
+  }
+  ]" | lfstest-testutils addcommits
+
+  git push origin main
+
+  git config lfs.fetchrecentrefsdays 0
+  git config lfs.fetchrecentremoterefs true
+  git config lfs.fetchrecentcommitsdays 0
+  git config lfs.pruneoffsetdays 1
+
+  git lfs prune --verify-remote-only 2>&1 | tee prune.log
+  grep "prune: 3 local objects, 1 retained, 2 verified with remote, done." prune.log
+  grep "prune: 2 files would be pruned" prune.log
+  assert_local_object "$oid_commit1" "${#content_commit1}"
+  assert_local_object "$oid_commit2_failverify" "${#content_commit2_failverify}"
+
+  delete_server_object "remote_$reponame" "$oid_commit2_failverify"
+
+  git lfs prune --verify-remote-only --when-unverified=continue 2>&1 | tee prune.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected prune --verify-remote-only to fail ..."
+    exit 1
+  fi
+  grep "prune: 3 local objects, 1 retained, 1 verified with remote, 1 not on remote, done." prune.log
+  grep "missing on remote:" prune.log
+  grep " \* $oid_commit2_failverify" prune.log
+  [ 0 -eq "$(grep -c "$oid_commit1" prune.log)" ]
+
+  # Nothing should have been deleted
+  assert_local_object "$oid_commit1" "${#content_commit1}"
+  assert_local_object "$oid_commit2_failverify" "${#content_commit2_failverify}"
+
+  git lfs prune --verify-remote-only --no-verify-remote 2>&1 | tee prune.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected prune to fail with conflicting flags ..."
+    exit 1
+  fi
+  grep "Cannot specify both --verify-remote-only and --no-verify-remote" prune.log
+)
+end_test
+
 begin_test "prune verify large numbers of refs"
 (
   set -e
@@ -1420,6 +1495,211 @@ begin_test "prune --force"
 )
 end_test
 
+begin_test "prune --older-than"
+(
+  set -e
+
+  reponame="prune_older_than"
+  setup_remote_repo "remote_$reponame"
+
+  clone_repo "remote_$reponame" "clone_$reponame"
+
+  git lfs track "*.dat"
+
+  content_v1="replaced before the cutoff"
+  content_v2="replaced after the cutoff"
+  content_v3="current version"
+  content_stale="only on a stale branch"
+  oid_v1=$(calc_oid "$content_v1")
+  oid_v2=$(calc_oid "$content_v2")
+  oid_v3=$(calc_oid "$content_v3")
+  oid_stale=$(calc_oid "$content_stale")
+
+  echo "[
+  {
+    \"CommitDate\":\"$(get_date -60d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_v1}, \"Data\":\"$content_v1\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -40d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_v2}, \"Data\":\"$content_v2\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -40d)\",
+    \"NewBranch\":\"stale\",
+    \"Files\":[
+      {\"Filename\":\"stale.dat\",\"Size\":${#content_stale}, \"Data\":\"$content_stale\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -10d)\",
+    \"ParentBranches\":[\"main\"],
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_v3}, \"Data\":\"$content_v3\"}]
+  }
+  ]" | lfstest-testutils addcommits
+
+  git push origin main stale
+
+  # Keep everything by default, so that only --older-than prunes.
+  git config lfs.fetchrecentrefsdays 100
+  git config lfs.fetchrecentcommitsdays 100
+
+  git lfs prune --older-than=30d --dry-run 2>&1 | tee prune.log
+  grep "prune: 2 files would be pruned" prune.log
+  grep "$oid_v1" prune.log
+  grep "$oid_stale" prune.log
+  grep "$oid_v2" prune.log && exit 1
+  assert_local_object "$oid_v1" "${#content_v1}"
+  assert_local_object "$oid_stale" "${#content_stale}"
+
+  git lfs prune --older-than=30d
+  refute_local_object "$oid_v1"
+  refute_local_object "$oid_stale"
+  assert_local_object "$oid_v2" "${#content_v2}"
+  assert_local_object "$oid_v3" "${#content_v3}"
+
+  git lfs prune --older-than=1w
+  refute_local_object "$oid_v2"
+  assert_local_object "$oid_v3" "${#content_v3}"
+
+  git lfs prune --older-than=soon 2>&1 | tee prune.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected prune to fail with an invalid --older-than ..."
+    exit 1
+  fi
+  grep "Invalid value for --older-than: soon" prune.log
+
+  git lfs prune --older-than=30d --recent 2>&1 | tee prune.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected prune to fail with --older-than and --recent ..."
+    exit 1
+  fi
+  grep "Cannot specify both --older-than and --recent" prune.log
+)
+end_test
+
+begin_test "prune --older-than keeps unpushed unless --force"
+(
+  set -e
+
+  reponame="prune_older_than_unpushed"
+  setup_remote_repo "remote_$reponame"
+
+  clone_repo "remote_$reponame" "clone_$reponame"
+
+  git lfs track "*.dat"
+
+  content_old="old and unpushed"
+  content_new="new and unpushed"
+  oid_old=$(calc_oid "$content_old")
+  oid_new=$(calc_oid "$content_new")
+
+  echo "[
+  {
+    \"CommitDate\":\"$(get_date -60d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_old}, \"Data\":\"$content_old\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -40d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_new}, \"Data\":\"$content_new\"}]
+  }
+  ]" | lfstest-testutils addcommits
+
+  # Both objects are older than the cutoff, but have not been pushed.
+  git lfs prune --older-than=30d
+  assert_local_object "$oid_old" "${#content_old}"
+  assert_local_object "$oid_new" "${#content_new}"
+
+  git lfs prune --older-than=30d --force
+  refute_local_object "$oid_old"
+  refute_local_object "$oid_new"
+)
+end_test
+
+begin_test "prune --exclude-ref"
+(
+  set -e
+
+  reponame="prune_exclude_ref"
+  setup_remote_repo "remote_$reponame"
+
+  clone_repo "remote_$reponame" "clone_$reponame"
+
+  git lfs track "*.dat"
+
+  content_maint_v1="replaced on the maintenance branch"
+  content_maint_v2="current on the maintenance branch"
+  content_stale="only on a stale branch"
+  content_main_old="replaced on main"
+  content_main="current version"
+  oid_maint_v1=$(calc_oid "$content_maint_v1")
+  oid_maint_v2=$(calc_oid "$content_maint_v2")
+  oid_stale=$(calc_oid "$content_stale")
+  oid_main_old=$(calc_oid "$content_main_old")
+  oid_main=$(calc_oid "$content_main")
+
+  echo "[
+  {
+    \"CommitDate\":\"$(get_date -70d)\",
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_main_old}, \"Data\":\"$content_main_old\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -60d)\",
+    \"NewBranch\":\"maintenance\",
+    \"Files\":[
+      {\"Filename\":\"maint.dat\",\"Size\":${#content_maint_v1}, \"Data\":\"$content_maint_v1\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -50d)\",
+    \"Files\":[
+      {\"Filename\":\"maint.dat\",\"Size\":${#content_maint_v2}, \"Data\":\"$content_maint_v2\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -40d)\",
+    \"ParentBranches\":[\"main\"],
+    \"NewBranch\":\"stale\",
+    \"Files\":[
+      {\"Filename\":\"stale.dat\",\"Size\":${#content_stale}, \"Data\":\"$content_stale\"}]
+  },
+  {
+    \"CommitDate\":\"$(get_date -1d)\",
+    \"ParentBranches\":[\"main\"],
+    \"Files\":[
+      {\"Filename\":\"file.dat\",\"Size\":${#content_main}, \"Data\":\"$content_main\"}]
+  }
+  ]" | lfstest-testutils addcommits
+
+  git push origin main maintenance stale
+
+  git lfs prune --exclude-ref="refs/heads/maint*" --dry-run --verbose 2>&1 | tee prune.log
+  # The first commit on main is reachable from the maintenance branch.
+  grep "prune: 1 file would be pruned" prune.log
+  grep "$oid_stale" prune.log
+  grep "$oid_main_old" prune.log && exit 1
+  grep "$oid_maint_v1" prune.log && exit 1
+  grep "$oid_maint_v2" prune.log && exit 1
+  assert_local_object "$oid_stale" "${#content_stale}"
+
+  # The refs are excluded from pruning even with --force.
+  git lfs prune --force --exclude-ref=refs/heads/maintenance
+  refute_local_object "$oid_stale"
+  refute_local_object "$oid_main"
+  assert_local_object "$oid_maint_v1" "${#content_maint_v1}"
+  assert_local_object "$oid_maint_v2" "${#content_maint_v2}"
+  assert_local_object "$oid_main_old" "${#content_main_old}"
+
+  git lfs prune --force
+  refute_local_object "$oid_main_old"
+  refute_local_object "$oid_maint_v1"
+  refute_local_object "$oid_maint_v2"
+)
+end_test
+
 begin_test "prune does not fail on empty files"
 (
   set -e
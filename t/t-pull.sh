@@ -392,3 +392,123 @@ begin_test "pull with empty file doesn't modify mtime"
   diff -u foo.mtime foo.mtime2
 )
 end_test
+
+begin_test "pull --stdin"
+(
+  set -e
+
+  reponame="pull-stdin"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  contents_a="a"
+  contents_a_oid=$(calc_oid "$contents_a")
+  contents_b="b"
+  contents_b_oid=$(calc_oid "$contents_b")
+  missing_oid=$(calc_oid "missing")
+
+  mkdir dir
+  printf "%s" "$contents_a" > dir/a.dat
+  printf "%s" "$contents_b" > b.dat
+  git add .gitattributes dir b.dat
+  git commit -m "add files"
+  git push origin main
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-clone"
+
+  assert_pointer "main" "dir/a.dat" "$contents_a_oid" 1
+  refute_local_object "$contents_a_oid"
+  refute_local_object "$contents_b_oid"
+
+  cd dir
+  printf "%s dir/a.dat\n%s 1\n" "$contents_a_oid" "$contents_b_oid" |
+    git lfs pull --stdin 2>&1 | tee pull.log
+  cd ..
+
+  [ "a" = "$(cat dir/a.dat)" ]
+  assert_local_object "$contents_a_oid" 1
+  assert_local_object "$contents_b_oid" 1
+  # objects without a path are only downloaded, not checked out
+  grep "oid sha256:$contents_b_oid" b.dat
+  git diff --exit-code
+
+  printf "%s 7\n" "$missing_oid" | git lfs pull --stdin 2>&1 | tee pull.log
+  grep "$missing_oid" pull.log
+  grep "1 object was not found on the server" pull.log
+
+  # the size of an object without a pointer file or local copy is unknown
+  printf "%s\n" "$missing_oid" | git lfs pull --stdin 2>&1 | tee pull.log
+  grep "Unable to determine the size of object $missing_oid, skipping" pull.log
+  [ "0" -eq "$(grep -c "not found on the server" pull.log)" ]
+
+  printf "%s\n" "$missing_oid" | git lfs pull --stdin --strict 2>&1 | tee pull.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pull --stdin --strict' to fail ..."
+    exit 1
+  fi
+  grep "1 object has an unknown size" pull.log
+
+  printf "%s\n%s 7\n" "$contents_b_oid" "$missing_oid" |
+    git lfs pull --stdin --strict 2>&1 | tee pull.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pull --stdin --strict' to fail ..."
+    exit 1
+  fi
+  grep "$missing_oid" pull.log
+  grep "Failed to fetch some objects" pull.log
+
+  printf "not-an-oid\n" | git lfs pull --stdin 2>&1 | tee pull.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pull --stdin' to fail ..."
+    exit 1
+  fi
+  grep "Invalid object ID" pull.log
+)
+end_test
+
+begin_test "pull with multiple remotes"
+(
+  set -e
+
+  reponame="pull-multiple-remotes"
+  setup_remote_repo "$reponame"
+  setup_remote_repo "$reponame-mirror"
+  clone_repo "$reponame" "$reponame"
+  git remote add mirror "$GITSERVER/$reponame-mirror"
+
+  git lfs track "*.dat"
+  printf "%s" "primary" > a.dat
+  printf "%s" "mirrored" > b.dat
+  git add .gitattributes *.dat
+  git commit -m "add files"
+  git push origin main
+  git push mirror main
+
+  a_oid="$(calc_oid "primary")"
+  b_oid="$(calc_oid "mirrored")"
+
+  delete_server_object "$reponame" "$b_oid"
+
+  rm -rf a.dat b.dat .git/lfs/objects
+  git lfs pull --remote origin,mirror 2>&1 | tee pull.log
+  [ "primary" = "$(cat a.dat)" ]
+  [ "mirrored" = "$(cat b.dat)" ]
+  grep "b.dat ($b_oid) <= mirror" pull.log
+
+  rm -rf .git/lfs/objects
+  printf "%s 7\n%s 8\n" "$a_oid" "$b_oid" |
+    git lfs pull --stdin --strict --remote origin --remote mirror 2>&1 | tee pull.log
+  assert_local_object "$a_oid" 7
+  assert_local_object "$b_oid" 8
+
+  git lfs pull --remote mirror origin 2>&1 | tee pull.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs pull --remote mirror origin' to fail ..."
+    exit 1
+  fi
+  grep "Cannot combine --remote with a remote argument" pull.log
+)
+end_test
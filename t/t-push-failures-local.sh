@@ -190,6 +190,12 @@ begin_test "push reject corrupt objects (lfs.allowincompletepush default)"
   grep "LFS upload failed:" push.log
   grep "  (corrupt) corrupt.dat ($corrupt_oid)" push.log
 
+  git lfs push origin main 2>&1 | tee push.log
+  if [ "5" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs push origin main' to exit with code 5"
+    exit 1
+  fi
+
   refute_server_object "$reponame" "$corrupt_oid"
   assert_server_object "$reponame" "$present_oid"
 )
@@ -917,6 +917,29 @@ begin_test "push --object-id (invalid value)"
 )
 end_test
 
+begin_test "push --object-id (missing object)"
+(
+  set -e
+
+  push_all_setup "push-missing-oid"
+
+  missing="$(calc_oid "missing")"
+  printf "%s\n%s\n" "$oid1" "$missing" | \
+    git lfs push --object-id origin --stdin 2>&1 | tee push.log
+  if [ "0" -eq "${PIPESTATUS[1]}" ]; then
+    echo >&2 "fatal: expected \`git lfs push\` to fail ..."
+    exit 1
+  fi
+
+  # Nothing is uploaded if any object is missing.
+  grep "Uploading LFS objects" push.log && exit 1
+  grep "Object $oid1 not found" push.log && exit 1
+
+  grep "Object $missing not found in local storage" push.log
+  grep "Unable to push 1 missing object" push.log
+)
+end_test
+
 begin_test "storage upload with compression"
 (
   set -e
@@ -958,3 +981,35 @@ begin_test "storage upload with compression"
   popd
 )
 end_test
+
+begin_test "push with multipart upload"
+(
+  set -e
+
+  reponame="push-multipart-upload"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" push-multipart-upload
+
+  contents="contents of a large object uploaded in parts"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+
+  git lfs track "*.dat"
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  git config --local lfs.transfer.chunksize 10
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  if [ "0" -ne "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected \`git push origin main\` to succeed ..."
+    exit 1
+  fi
+
+  for i in 1 2 3 4 5; do
+    grep "xfer: uploaded part $i of 5 for \"$oid\"" push.log
+  done
+
+  assert_server_object "$reponame" "$oid"
+)
+end_test
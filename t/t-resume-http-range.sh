@@ -126,3 +126,49 @@ begin_test "resume-http-range-retry"
   assert_local_object "$contents_oid" "${#contents}"
 )
 end_test
+
+begin_test "resume-http-range-corrupt-partial"
+(
+  set -e
+
+  reponame="resume-http-range-corrupt-partial"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" $reponame
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "Tracking \"\*.dat\"" track.log
+
+  # The server interrupts transfers which start from zero, but sends the
+  # remainder when a Range is requested.
+  contents="status-batch-resume-206"
+  contents_oid=$(calc_oid "$contents")
+
+  printf "%s" "$contents" > a.dat
+  git add a.dat
+  git add .gitattributes
+  git commit -m "add a.dat" 2>&1 | tee commit.log
+  git push origin main
+
+  assert_server_object "$reponame" "$contents_oid"
+
+  rm -rf .git/lfs/objects
+  refute_local_object "$contents_oid"
+
+  # Create a corrupt partial download which the server will accept resuming.
+  mkdir -p .git/lfs/incomplete
+  printf "%s" "xxxxxx" >".git/lfs/incomplete/$contents_oid.part"
+
+  # The resumed content fails verification, so the partial file must be
+  # discarded and the download restarted, which the server interrupts.
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetchresume.log
+  grep "xfer: server accepted resume" fetchresume.log
+  grep "failed verification; re-downloading from start" fetchresume.log
+  refute_local_object "$contents_oid"
+
+  # The partial file kept from the restarted download is now valid.
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetchresume2.log
+  grep "xfer: server accepted resume" fetchresume2.log
+  assert_local_object "$contents_oid" "${#contents}"
+)
+end_test
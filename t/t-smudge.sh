@@ -61,6 +61,44 @@ begin_test "smudge with invalid pointer"
 )
 end_test
 
+begin_test "smudge with skip download"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-skip-download"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  echo "smudge a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  # The pointer is passed through even though the object is present
+  # locally, and nothing is downloaded when it is not.
+  pointer="$(pointer fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254 9)"
+  [ "$pointer" = "$(echo "$pointer" | git lfs smudge --skip-download a.dat)" ]
+
+  rm -rf .git/lfs/objects
+  echo "$pointer" | GIT_TRACE=1 git lfs smudge --skip-download a.dat >smudge.out 2>smudge.log
+  [ "$pointer" = "$(cat smudge.out)" ]
+  grep "objects/batch" smudge.log && exit 1
+  refute_local_object fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254
+
+  # The pointer is written exactly as it was given.
+  printf "%s" "$pointer" | git lfs smudge --skip-download a.dat >smudge.out
+  printf "%s" "$pointer" | cmp - smudge.out
+
+  # Empty files are passed through, but other input is an error.
+  [ -z "$(printf "" | git lfs smudge --skip-download a.dat)" ]
+
+  echo "not a git-lfs file" | git lfs smudge --skip-download b.dat >smudge.out 2>smudge.log && exit 1
+  grep 'Unable to parse pointer at: "b.dat"' smudge.log
+  [ ! -s smudge.out ]
+)
+end_test
+
 begin_test "smudge include/exclude"
 (
   set -e
@@ -156,6 +194,56 @@ begin_test "smudge with skip"
 )
 end_test
 
+begin_test "smudge with skipped paths"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-skip-paths"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "skip-paths"
+
+  git lfs track "*.dat" "*.psd"
+  printf "config" > a.dat
+  mkdir -p design
+  printf "design" > design/b.psd
+  git add .gitattributes a.dat design
+  git commit -m "add files"
+  git push origin main
+
+  design_ptr="$(git cat-file -p "HEAD:design/b.psd")"
+
+  clone="$TRASHDIR/clone_$reponame"
+  git -c lfs.skipsmudgepaths="*.psd" clone "$GITSERVER/$reponame" "$clone"
+  pushd "$clone"
+    [ "config" = "$(cat a.dat)" ]
+    [ "$design_ptr" = "$(cat design/b.psd)" ]
+    refute_local_object "$(calc_oid "design")"
+
+    # the skipped files can be downloaded and checked out later
+    git lfs pull
+    [ "design" = "$(cat design/b.psd)" ]
+  popd
+  rm -rf "$clone"
+
+  # the environment variable takes precedence over the configuration
+  GIT_LFS_SKIP_SMUDGE_PATHS="design" git -c lfs.skipsmudgepaths="*.dat" \
+    clone "$GITSERVER/$reponame" "$clone"
+  pushd "$clone"
+    [ "config" = "$(cat a.dat)" ]
+    [ "$design_ptr" = "$(cat design/b.psd)" ]
+  popd
+  rm -rf "$clone"
+
+  # GIT_LFS_SKIP_SMUDGE still skips every file
+  GIT_LFS_SKIP_SMUDGE=1 git -c lfs.skipsmudgepaths="*.psd" \
+    clone "$GITSERVER/$reponame" "$clone"
+  pushd "$clone"
+    [ "$(git cat-file -p "HEAD:a.dat")" = "$(cat a.dat)" ]
+    [ "$design_ptr" = "$(cat design/b.psd)" ]
+  popd
+)
+end_test
+
 begin_test "smudge clone with include/exclude"
 (
   set -e
@@ -231,6 +319,63 @@ begin_test "smudge clone with include/exclude"
 )
 end_test
 
+begin_test "smudge with corrupt object and autorepair"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-autorepair"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" autorepair
+
+  git lfs track "*.dat"
+  echo "smudge a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  oid="fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254"
+  pointer="$(pointer "$oid" 9)"
+  objpath=".git/lfs/objects/fc/f5/$oid"
+
+  # corrupt the cached object without changing its size
+  chmod u+w "$objpath"
+  printf "smudge b\n" > "$objpath"
+
+  set +e
+  echo "$pointer" | git lfs smudge a.dat > smudge.log 2>&1
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  [ ! -e "$objpath" ]
+
+  printf "smudge b\n" > "$objpath"
+  git config lfs.autorepair true
+
+  echo "$pointer" | git lfs smudge a.dat > smudge.log 2> repair.log
+  [ "smudge a" = "$(cat smudge.log)" ]
+  grep "Repairing a.dat: cached object $oid is corrupt" repair.log
+  grep "Repaired a.dat" repair.log
+  assert_local_object "$oid" 9
+
+  # an object which is not corrupt is used without downloading it again
+  echo "$pointer" | git lfs smudge a.dat > smudge.log 2> repair.log
+  [ "smudge a" = "$(cat smudge.log)" ]
+  grep "Repair" repair.log && exit 1
+
+  # the repair is given up on if the object cannot be downloaded again
+  printf "smudge b\n" > "$objpath"
+  git remote set-url origin httpnope://nope.com/nope
+  set +e
+  echo "$pointer" | git lfs smudge a.dat > smudge.log 2> repair.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  grep "Repairing a.dat" repair.log
+  grep "Repaired" repair.log && exit 1
+  [ ! -e "$objpath" ]
+)
+end_test
+
 begin_test "smudge skip download failure"
 (
   set -e
@@ -0,0 +1,34 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "stall timeout: stalled download is retried"
+(
+  set -e
+
+  reponame="stall-timeout-download"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  # this string announces to the server that it should stop sending the
+  # object part of the way through, unless the download is resumed
+  contents="storage-download-stall"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+  assert_server_object "$reponame" "$contents_oid"
+
+  rm -rf .git/lfs/objects
+  refute_local_object "$contents_oid"
+
+  git config lfs.transfer.stalltimeout 1
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep "transfer of $contents_oid stalled: no progress for 1s" fetch.log
+  grep "Attempting to resume download of \"$contents_oid\" from byte 10" fetch.log
+  assert_local_object "$contents_oid" "${#contents}"
+)
+end_test
@@ -85,6 +85,88 @@ A  file2.dat"
 )
 end_test
 
+begin_test "status --porcelain=v2"
+(
+  set -e
+
+  mkdir repo-porcelain-v2
+  cd repo-porcelain-v2
+  git init
+  git lfs track "*.dat"
+  git add .gitattributes
+  echo "some data" > file1.dat
+  echo "moved data" > file4.dat
+  echo "deleted data" > file5.dat
+  echo "git data" > file6.txt
+  git add file1.dat file4.dat file5.dat file6.txt
+  git commit -m "initial commit"
+
+  echo "other data" > file1.dat
+  echo "file2 data" > file2.dat
+  git add file2.dat
+
+  echo "file3 data" > file3.dat
+  git add file3.dat
+  echo "file3 other data" > file3.dat
+
+  git mv file4.dat file7.dat
+  rm file5.dat
+  echo "other git data" > file6.txt
+
+  other_oid="$(calc_oid "other data\n" | cut -c1-7)"
+  file2_oid="$(calc_oid "file2 data\n" | cut -c1-7)"
+  file3_oid="$(calc_oid "file3 other data\n" | cut -c1-7)"
+  deleted_oid="$(calc_oid "deleted data\n" | cut -c1-7)"
+  moved_oid="$(calc_oid "moved data\n" | cut -c1-7)"
+
+  expected=".M $other_oid file1.dat
+A. $file2_oid file2.dat
+AM $file3_oid file3.dat
+.D $deleted_oid file5.dat
+R. $moved_oid file7.dat	file4.dat"
+
+  [ "$expected" = "$(git lfs status --porcelain=v2)" ]
+
+  git lfs status -z | tr '\0' '\n' > status.log
+  expected=".M $other_oid file1.dat
+A. $file2_oid file2.dat
+AM $file3_oid file3.dat
+.D $deleted_oid file5.dat
+R. $moved_oid file7.dat
+file4.dat"
+
+  [ "$expected" = "$(cat status.log)" ]
+
+  git lfs status --porcelain -z 2>&1 | tee status.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs status --porcelain -z' to fail ..."
+    exit 1
+  fi
+  grep -- "-z requires --porcelain=v2" status.log
+)
+end_test
+
+begin_test "status --porcelain=v2 with non-ASCII paths"
+(
+  set -e
+
+  mkdir repo-porcelain-v2-non-ascii
+  cd repo-porcelain-v2-non-ascii
+  git init
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+
+  echo "unicode data" > "füle.dat"
+  git add "füle.dat"
+
+  oid="$(calc_oid "unicode data\n" | cut -c1-7)"
+
+  [ "A. $oid füle.dat" = "$(git lfs status --porcelain=v2)" ]
+  [ "A. $oid füle.dat" = "$(git lfs status -z | tr -d '\0')" ]
+)
+end_test
+
 begin_test "status --json"
 (
   set -e
@@ -99,14 +181,17 @@ begin_test "status --json"
 
   echo "other data" > file1.dat
 
-  expected='{"files":{"file1.dat":{"status":"M"}}}'
+  some_oid="$(calc_oid "some data\n")"
+  other_oid="$(calc_oid "other data\n")"
+
+  expected='{"files":{"file1.dat":{"status":"M"}},"staged":[],"not_staged":[{"name":"file1.dat","status":"M","from_oid":"'$some_oid'","to_oid":"'$other_oid'","size":11}],"untracked":[]}'
   [ "$expected" = "$(git lfs status --json)" ]
 
   git add file1.dat
   git commit -m "file1.dat changed"
   git mv file1.dat file2.dat
 
-  expected='{"files":{"file2.dat":{"status":"R","from":"file1.dat"}}}'
+  expected='{"files":{"file2.dat":{"status":"R","from":"file1.dat"}},"staged":[{"name":"file2.dat","from":"file1.dat","status":"R","from_oid":"'$other_oid'","to_oid":"'$other_oid'","size":11}],"not_staged":[],"untracked":[]}'
   [ "$expected" = "$(git lfs status --json)" ]
 
   git commit -m "file1.dat -> file2.dat"
@@ -114,7 +199,60 @@ begin_test "status --json"
   # Ensure status --json does not include non-lfs files
   echo hi > test1.txt
   git add test1.txt
-  expected='{"files":{}}'
+  expected='{"files":{},"staged":[],"not_staged":[],"untracked":[]}'
+  [ "$expected" = "$(git lfs status --json)" ]
+)
+end_test
+
+begin_test "status --json with untracked files"
+(
+  set -e
+
+  mkdir repo-json-untracked
+  cd repo-json-untracked
+  git init
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+
+  echo "new data" > new.dat
+  echo "new text" > new.txt
+
+  new_oid="$(calc_oid "new data\n")"
+
+  expected='{"files":{},"staged":[],"not_staged":[],"untracked":[{"name":"new.dat","status":"?","from_oid":"","to_oid":"'$new_oid'","size":9}]}'
+  [ "$expected" = "$(git lfs status --json)" ]
+
+  git add new.dat
+
+  expected='{"files":{"new.dat":{"status":"A"}},"staged":[{"name":"new.dat","status":"A","from_oid":"","to_oid":"'$new_oid'","size":9}],"not_staged":[],"untracked":[]}'
+  [ "$expected" = "$(git lfs status --json)" ]
+)
+end_test
+
+begin_test "status --json with untracked files excluded by a later pattern"
+(
+  set -e
+
+  mkdir repo-json-untracked-excluded
+  cd repo-json-untracked-excluded
+  git init
+  git lfs track "*.dat"
+  echo "generated/** -filter -diff -merge text" >> .gitattributes
+  mkdir -p generated sub
+  echo "generated/keep.dat filter=lfs diff=lfs merge=lfs -text" >> .gitattributes
+  echo "*.dat -filter -diff -merge text" > sub/.gitattributes
+  git add .gitattributes sub/.gitattributes
+  git commit -m "initial commit"
+
+  echo "new data" > new.dat
+  echo "new data" > generated/new.dat
+  echo "new data" > generated/keep.dat
+  echo "new data" > sub/new.dat
+
+  new_oid="$(calc_oid "new data\n")"
+
+  expected='{"files":{},"staged":[],"not_staged":[],"untracked":[{"name":"generated/keep.dat","status":"?","from_oid":"","to_oid":"'$new_oid'","size":9},{"name":"new.dat","status":"?","from_oid":"","to_oid":"'$new_oid'","size":9}]}'
   [ "$expected" = "$(git lfs status --json)" ]
 )
 end_test
@@ -117,6 +117,45 @@ begin_test "track --dry-run"
 )
 end_test
 
+begin_test "track --dry-run lists newly tracked files"
+(
+  set -e
+
+  reponame="track_dry_run_files"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p dir keep
+  touch a.bin b.txt dir/c.bin keep/d.bin ignored.bin
+  echo "ignored.bin" > .gitignore
+  echo "*.bin -filter" > keep/.gitattributes
+  git add a.bin b.txt keep .gitignore
+
+  git lfs track --dry-run "*.bin" 2>&1 | tee track.log
+  grep 'Would track "a.bin"$' track.log
+  grep 'Would track "dir/c.bin" (untracked)' track.log
+  grep "2 files would be newly tracked by Git LFS" track.log
+  grep 'Would track "keep/d.bin"' track.log && exit 1
+  grep 'Would track "ignored.bin"' track.log && exit 1
+  grep 'Would track "b.txt"' track.log && exit 1
+  [ ! -e .gitattributes ]
+
+  # Files which are already tracked are not listed.
+  git lfs track "a.bin"
+  git lfs track --dry-run "*.bin" 2>&1 | tee track.log
+  grep 'Would track "a.bin"' track.log && exit 1
+  grep "1 file would be newly tracked by Git LFS" track.log
+  [ "a.bin filter=lfs diff=lfs merge=lfs -text" = "$(cat .gitattributes)" ]
+
+  # Patterns given in a subdirectory match from that directory.
+  cd dir
+  git lfs track --dry-run "*.bin" 2>&1 | tee track.log
+  grep 'Would track "c.bin" (untracked)' track.log
+  grep "1 file would be newly tracked by Git LFS" track.log
+  [ ! -e .gitattributes ]
+)
+end_test
+
 begin_test "track directory"
 (
   set -e
@@ -446,6 +485,64 @@ begin_test "track lockable"
 )
 end_test
 
+begin_test "track lockable preserves other attributes"
+(
+  set -e
+
+  repo="track_lockable_preserve"
+  mkdir "$repo"
+  cd "$repo"
+  git init
+
+  printf '*.psd filter=lfs diff=lfs merge=lfs -text eol=lf\n' > .gitattributes
+
+  git lfs track --lockable "*.psd" | grep "Tracking \"\*.psd\""
+  grep -x '\*.psd filter=lfs diff=lfs merge=lfs -text eol=lf lockable' .gitattributes
+
+  git lfs track --lockable "*.psd" | grep "\"\*.psd\" already supported"
+  assert_attributes_count "psd" "lockable" 1
+
+  git lfs track --not-lockable "*.psd" | grep "Tracking \"\*.psd\""
+  grep -x '\*.psd filter=lfs diff=lfs merge=lfs -text eol=lf' .gitattributes
+
+  git lfs track --lockable --not-lockable "*.psd" 2>&1 && exit 1
+  git lfs track --lockable --not-lockable "*.psd" 2>&1 |
+    grep -- "--lockable option can't be combined with --not-lockable"
+  assert_attributes_count "psd" "lockable" 0
+)
+end_test
+
+begin_test "track --list-lockable"
+(
+  set -e
+
+  repo="track_list_lockable"
+  mkdir "$repo"
+  cd "$repo"
+  git init
+
+  git lfs track "*.png"
+  git lfs track --lockable "*.psd" "*.jpg"
+  printf '*.gen -filter -diff -merge text\n' >> .gitattributes
+
+  out=$(git lfs track --list-lockable)
+  echo "$out" | grep "Listing lockable patterns"
+  echo "$out" | grep "    \*.psd (.gitattributes)"
+  echo "$out" | grep "    \*.jpg (.gitattributes)"
+  echo "$out" | grep "png" && exit 1
+  echo "$out" | grep "gen" && exit 1
+
+  git lfs track --list-lockable --json > actual.json
+  [ "2" -eq "$(grep -c '"pattern"' actual.json)" ]
+  grep '"lockable": false' actual.json && exit 1
+
+  git lfs track --list-lockable "*.bin" 2>&1 && exit 1
+  git lfs track --list-lockable "*.bin" 2>&1 |
+    grep -- "--list-lockable option can't be combined with arguments"
+  assert_attributes_count "bin" "filter=lfs" 0
+)
+end_test
+
 begin_test "track lockable read-only/read-write"
 (
   set -e
@@ -738,6 +835,34 @@ begin_test "track: escaped glob pattern with spaces in .gitattributes"
 )
 end_test
 
+begin_test "track: --filename with leading special characters and tabs"
+(
+  set -e
+
+  # Neither quotes nor tabs are valid in the Win32 subsystem.
+  [ "$IS_WINDOWS" -eq 1 ] && exit 0
+
+  reponame="track-filename-leading-special"
+  git init "$reponame"
+  cd "$reponame"
+
+  for filename in '!data[1].bin' '"quoted data".bin' $'tab\tdata.bin'; do
+    git lfs track --filename "$filename"
+    git lfs track --filename "$filename" | grep 'already supported'
+    printf "%s" "contents of $filename" > "$filename"
+  done
+  cat .gitattributes
+
+  git add .
+  git commit -m 'Add unusually named files'
+
+  for filename in '!data[1].bin' '"quoted data".bin' $'tab\tdata.bin'; do
+    contents="contents of $filename"
+    [ "$(pointer "$(calc_oid "$contents")" "${#contents}")" = "$(git cat-file -p "main:$filename")" ]
+  done
+)
+end_test
+
 begin_test "track: verbose logging"
 (
   set -e
@@ -804,3 +929,62 @@ EOF
   diff -u actual expected
 )
 end_test
+
+begin_test "track --migrate"
+(
+  set -e
+
+  reponame="track-migrate"
+  git init "$reponame"
+  cd "$reponame"
+
+  contents="a psd file"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.psd
+  printf "%s" "a text file" > a.txt
+  git add a.psd a.txt
+  git commit -m "initial commit"
+
+  git lfs track --migrate --dry-run "*.psd" 2>&1 | tee ../track.log
+  grep "1 file (10 B) would be converted to Git LFS objects" ../track.log
+  refute_pointer "main" "a.psd"
+  [ ! -e .gitattributes ]
+
+  git lfs track --migrate "*.psd" </dev/null 2>&1 | tee ../track.log
+  grep "1 file (10 B) would be converted to Git LFS objects" ../track.log
+  grep "Not converting existing files" ../track.log
+  refute_pointer "main" "a.psd"
+  git checkout -- .gitattributes 2>/dev/null || rm .gitattributes
+
+  git lfs track --migrate --yes "*.psd" 2>&1 | tee ../track.log
+  grep "Tracking \"\*.psd\"" ../track.log
+  assert_pointer "main" "a.psd" "$contents_oid" 10
+  refute_pointer "main" "a.txt"
+
+  git cat-file -p main:.gitattributes | grep "^\*.psd filter=lfs diff=lfs merge=lfs -text$"
+  [ -z "$(git status --porcelain)" ]
+)
+end_test
+
+begin_test "track --migrate (dirty working copy)"
+(
+  set -e
+
+  reponame="track-migrate-dirty"
+  git init "$reponame"
+  cd "$reponame"
+
+  echo "a psd file" > a.psd
+  git add a.psd
+  git commit -m "initial commit"
+  echo "changed" > a.psd
+
+  git lfs track --migrate --yes "*.psd" 2>&1 | tee ../track.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs track --migrate' to fail"
+    exit 1
+  fi
+  grep -- "--migrate option requires a clean working copy" ../track.log
+  [ ! -e .gitattributes ]
+)
+end_test
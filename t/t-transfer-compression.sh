@@ -0,0 +1,70 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "transfer compression: zstd upload and download"
+(
+  set -e
+
+  # this repo name is the indicator to the server to ask for compressed
+  # object bodies, if the client offers them
+  reponame="test-compression"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git config lfs.transfer.compression zstd
+  git lfs track "*.log"
+
+  for i in $(seq 1 200); do
+    echo "2024-01-01 12:00:00 INFO request $i handled successfully"
+  done > a.log
+  contents_oid="$(calc_oid_file a.log)"
+  contents_size="$(wc -c < a.log | tr -d ' ')"
+  git add .gitattributes a.log
+  git commit -m "add a.log"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 GIT_CURL_VERBOSE=1 git push origin main 2>&1 | tee push.log
+  grep '"compression":\["zstd"\]' push.log
+  grep "> Content-Encoding: zstd" push.log
+  grep "xfer: uploading \"$contents_oid\" compressed from $contents_size to [0-9]* bytes" push.log
+
+  # the server stores the uncompressed object under the same OID
+  assert_server_object "$reponame" "$contents_oid"
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-clone"
+  git config lfs.transfer.compression zstd
+  GIT_TRACE=1 git lfs pull 2>&1 | tee pull.log
+  grep '"compression":"zstd"' pull.log
+  assert_local_object "$contents_oid" "$contents_size"
+  [ "$contents_oid" = "$(calc_oid_file a.log)" ]
+)
+end_test
+
+begin_test "transfer compression: not offered"
+(
+  set -e
+
+  reponame="test-compression-not-offered"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git config lfs.transfer.compression none
+  git lfs track "*.log"
+
+  echo "uncompressed contents" > a.log
+  contents_oid="$(calc_oid_file a.log)"
+  git add .gitattributes a.log
+  git commit -m "add a.log"
+
+  GIT_TRACE=1 GIT_CURL_VERBOSE=1 git push origin main 2>&1 | tee push.log
+  grep '"compression"' push.log && exit 1
+  grep "> Content-Encoding: zstd" push.log && exit 1
+
+  assert_server_object "$reponame" "$contents_oid"
+
+  cd ..
+  clone_repo "$reponame" "$reponame-clone"
+  [ "uncompressed contents" = "$(cat a.log)" ]
+)
+end_test
@@ -139,6 +139,7 @@ begin_test "uninstall --worktree with multiple working trees"
     exit 1
   fi
   grep -v "Global Git LFS configuration has been removed." uninstall.log
+  grep "warning: Git LFS is still enabled by the local Git configuration (found filter.lfs.clean)" uninstall.log
 
   # global configs
   [ "global smudge" = "$(git config --global filter.lfs.smudge)" ]
@@ -157,6 +158,21 @@ begin_test "uninstall --worktree with multiple working trees"
   [ "" = "$(git config --worktree filter.lfs.smudge)" ]
   [ "" = "$(git config --worktree filter.lfs.clean)" ]
   [ "" = "$(git config --worktree filter.lfs.process)" ]
+
+  # the warning names the scope which still enables Git LFS
+  git lfs install --worktree
+  git config --local --remove-section filter.lfs
+  git lfs uninstall --worktree 2>&1 | tee uninstall.log
+  grep "warning: Git LFS is still enabled by the global Git configuration (found filter.lfs.clean)" uninstall.log
+  [ "global smudge" = "$(git config filter.lfs.smudge)" ]
+  [ "" = "$(git config --worktree filter.lfs.smudge)" ]
+
+  # .gitattributes files are left untouched
+  git lfs track "*.dat"
+  cp .gitattributes attrs.before
+  git lfs install --worktree
+  git lfs uninstall --worktree
+  cmp .gitattributes attrs.before
 )
 end_test
 
@@ -505,6 +505,63 @@ begin_test "unlocking a lock while uncommitted with --force"
 )
 end_test
 
+begin_test "unlocking another user's lock"
+(
+  set -e
+
+  reponame="unlock_theirs"
+  setup_repo "$reponame" "theirs.dat"
+
+  git lfs lock --json "theirs.dat" | tee lock.log
+
+  id=$(assert_lock lock.log theirs.dat)
+  assert_server_lock "$reponame" "$id"
+
+  git lfs unlock "theirs.dat" 2>&1 | tee unlock.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs unlock' to fail"
+    exit 1
+  fi
+  grep "lock is owned by another user" unlock.log
+  grep "theirs.dat is locked by Git LFS Tests (ID: $id)" unlock.log
+
+  git lfs unlock --json "theirs.dat" 2>/dev/null | tee unlock.json
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs unlock' to fail"
+    exit 1
+  fi
+  grep "\"id\":\"$id\",\"path\":\"theirs.dat\",\"unlocked\":false" unlock.json
+  grep '"reason":"lock is owned by another user","owner":{"name":"Git LFS Tests"}' unlock.json
+
+  assert_server_lock "$reponame" "$id"
+)
+end_test
+
+begin_test "unlocking another user's lock with --force"
+(
+  set -e
+
+  reponame="unlock_theirs_force"
+  setup_repo "$reponame" "theirs.dat"
+
+  git lfs lock --json "theirs.dat" | tee lock.log
+
+  id=$(assert_lock lock.log theirs.dat)
+  assert_server_lock "$reponame" "$id"
+
+  git lfs unlock --force --json "theirs.dat" | tee unlock.json
+  grep -F "[{\"id\":\"$id\",\"path\":\"theirs.dat\",\"unlocked\":true,\"owner\":{\"name\":\"Git LFS Tests\"}}]" unlock.json
+  refute_server_lock "$reponame" "$id"
+
+  git lfs lock --json "theirs.dat" | tee lock.log
+  id=$(assert_lock lock.log theirs.dat)
+
+  git lfs unlock --force --id="$id" | tee unlock.log
+  grep "Unlocked Lock $id (locked by Git LFS Tests)" unlock.log
+  refute_server_lock "$reponame" "$id"
+)
+end_test
+
 begin_test "unlocking a lock while untracked"
 (
   set -e
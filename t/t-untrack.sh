@@ -176,3 +176,63 @@ begin_test "untrack works with GIT_WORK_TREE"
   [ ! -s "$reponame/.gitattributes" ]
 )
 end_test
+
+begin_test "untrack --prune"
+(
+  set -e
+
+  reponame="untrack-prune"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  contents="a dat file"
+  printf "%s" "$contents" > a.dat
+  printf "%s" "a bin file" > a.bin
+  git lfs track "*.bin"
+  git add .gitattributes a.dat a.bin
+  git commit -m "initial commit"
+
+  assert_pointer "main" "a.dat" "$(calc_oid "$contents")" 10
+
+  git lfs untrack --prune "*.dat" 2>&1 | tee ../untrack.log
+  grep "Untracking \"\*.dat\"" ../untrack.log
+  grep "Converted 1 file from a Git LFS object" ../untrack.log
+  grep "^    a.dat$" ../untrack.log
+
+  refute_pointer "main" "a.dat"
+  [ "$contents" = "$(git cat-file -p main:a.dat)" ]
+  assert_pointer "main" "a.bin" "$(calc_oid "a bin file")" 10
+
+  git cat-file -p main:.gitattributes | grep '^\*.dat !text !filter !merge !diff$'
+  [ -z "$(git status --porcelain)" ]
+)
+end_test
+
+begin_test "untrack --prune (dirty working copy)"
+(
+  set -e
+
+  reponame="untrack-prune-dirty"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "a dat file" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  printf "%s" "changed" > a.dat
+
+  git lfs untrack --prune "*.dat" 2>&1 | tee ../untrack.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs untrack --prune' to fail"
+    exit 1
+  fi
+  grep -- "--prune option requires a clean working copy" ../untrack.log
+  grep "\*.dat filter=lfs" .gitattributes
+
+  git lfs untrack --prune --force "*.dat" 2>&1 | tee ../untrack.log
+  grep "Converted 1 file from a Git LFS object" ../untrack.log
+  refute_pointer "main" "a.dat"
+)
+end_test
@@ -0,0 +1,84 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "upload session: uninterrupted"
+(
+  set -e
+
+  # this repo name is the indicator to the server to return upload sessions
+  reponame="test-upload-session"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  contents="uninterrupted upload session"
+  contents_oid="$(calc_oid "$contents")"
+
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "HEAD http://127.0.0.1:[0-9]*/storage/$contents_oid" push.log
+  grep "resuming upload" push.log && exit 1
+
+  assert_server_object "$reponame" "$contents_oid"
+)
+end_test
+
+begin_test "upload session: interrupted and resumed"
+(
+  set -e
+
+  # this repo name is the indicator to the server to return upload sessions,
+  # AND to interrupt the first upload part way
+  reponame="test-upload-session-interrupt"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  contents="234587134187634598o634857619384765b747qcvtuedvoaicwtvseudtvcoqi7280r7qvow4i7r8c46pr9q6v9pri6ioq2r8"
+  contents_oid="$(calc_oid "$contents")"
+
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 GIT_CURL_VERBOSE=1 git push origin main 2>&1 | tee push.log
+  # the first attempt fails part way through
+  grep "HTTP: 500" push.log
+  # and the retry sends only the rest of the object
+  grep "xfer: resuming upload of \"$contents_oid\" from 32" push.log
+  grep "> Content-Range: bytes 32-97/98" push.log
+
+  assert_server_object "$reponame" "$contents_oid"
+)
+end_test
+
+begin_test "upload session: not advertised"
+(
+  set -e
+
+  reponame="upload-session-not-advertised"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+
+  contents="no upload session"
+  contents_oid="$(calc_oid "$contents")"
+
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 GIT_CURL_VERBOSE=1 git push origin main 2>&1 | tee push.log
+  grep "X-Upload-Session" push.log && exit 1
+  grep "HEAD http://127.0.0.1:[0-9]*/storage/" push.log && exit 1
+
+  assert_server_object "$reponame" "$contents_oid"
+)
+end_test
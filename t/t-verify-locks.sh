@@ -0,0 +1,79 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "verify-locks"
+(
+  set -e
+
+  reponame="verify-locks"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track --lockable "*.dat"
+  git lfs track "*.bin"
+  for f in mine.dat unlocked.dat locked_theirs.dat untouched.dat other.bin; do
+    printf "%s" "$f" > "$f"
+  done
+  git add .gitattributes *.dat *.bin
+  git commit -m "add files"
+  git push origin main
+
+  git lfs lock --json "mine.dat" | tee lock.log
+  # any lock path with "theirs" is returned as "their" lock by /locks/verify
+  git lfs lock --json "locked_theirs.dat" | tee lock.log
+  id=$(assert_lock lock.log locked_theirs.dat)
+
+  git lfs verify-locks 2>&1 | tee verify.log
+  grep "All modified files are locked by you or are not lockable" verify.log
+
+  chmod +w *.dat
+  printf "changed" > mine.dat
+  printf "changed" > other.bin
+  git lfs verify-locks 2>&1 | tee verify.log
+  grep "All modified files are locked by you or are not lockable" verify.log
+
+  printf "changed" > unlocked.dat
+  git add unlocked.dat
+  printf "changed" > locked_theirs.dat
+
+  git lfs verify-locks | tee verify.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs verify-locks' to fail"
+    exit 1
+  fi
+
+  cat > expected.log <<EOF
+Modified files not locked by you:
+* unlocked.dat
+Modified files locked by others:
+* locked_theirs.dat - Git LFS Tests (ID: $id)
+EOF
+  diff -u expected.log verify.log
+)
+end_test
+
+begin_test "verify-locks (deleted file)"
+(
+  set -e
+
+  reponame="verify-locks-deleted"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track --lockable "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  rm -f a.dat
+
+  git lfs verify-locks 2>&1 | tee verify.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs verify-locks' to fail"
+    exit 1
+  fi
+  grep "^\* a.dat$" verify.log
+)
+end_test
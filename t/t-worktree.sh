@@ -124,3 +124,57 @@ begin_test "git worktree with hooks"
     [ -x "$TRASHDIR/$reponame/.git/hooks/pre-push" ]
 )
 end_test
+
+begin_test "git worktree: checkout tracked file in linked worktree"
+(
+    set -e
+    reponame="worktree-checkout"
+    unset_vars
+    setup_remote_repo "$reponame"
+    clone_repo "$reponame" "$reponame"
+
+    git lfs track "*.dat"
+    printf "main" > a.dat
+    git add .gitattributes a.dat
+    git commit -m "add a.dat"
+    git push origin main
+
+    git checkout -b topic
+    printf "topic" > a.dat
+    git add a.dat
+    git commit -m "update a.dat"
+    git push origin topic
+    git checkout main
+
+    main_oid="$(calc_oid "main")"
+    topic_oid="$(calc_oid "topic")"
+    rm -rf .git/lfs/objects
+
+    worktreename="worktree-checkout-2"
+    gitdir="$TRASHDIR/$reponame/.git/worktrees/$worktreename"
+    GIT_LFS_SKIP_SMUDGE=1 git worktree add "$TRASHDIR/$worktreename" topic
+    cd "$TRASHDIR/$worktreename"
+    [ -f .git ]
+
+    git lfs pull
+    [ "topic" = "$(cat a.dat)" ]
+
+    # The object is cached in the common Git directory, and the primary
+    # worktree is left alone.
+    [ -f "$TRASHDIR/$reponame/.git/lfs/objects/${topic_oid:0:2}/${topic_oid:2:2}/$topic_oid" ]
+    [ ! -e "$gitdir/lfs" ]
+    [ "main" = "$(cat "$TRASHDIR/$reponame/a.dat")" ]
+
+    # Checking out a file again in the linked worktree uses the cache.
+    rm a.dat
+    git checkout -- a.dat
+    [ "topic" = "$(cat a.dat)" ]
+
+    # The same holds when Git passes the directories in the environment.
+    rm a.dat
+    GIT_DIR="$gitdir" GIT_COMMON_DIR="$TRASHDIR/$reponame/.git" git lfs checkout a.dat
+    [ "topic" = "$(cat a.dat)" ]
+    [ ! -e "$gitdir/lfs" ]
+    [ "main" = "$(cat "$TRASHDIR/$reponame/a.dat")" ]
+)
+end_test
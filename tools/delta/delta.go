@@ -0,0 +1,311 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+const (
+	// MediaType is the media type of a delta.
+	MediaType = "application/vnd.git-lfs.delta"
+
+	// minBlockSize is the smallest size of block into which a base is
+	// divided. Larger bases use larger blocks, so that the index of the
+	// base has at most maxBlocks entries.
+	minBlockSize = 4096
+	maxBlocks    = 1 << 20
+
+	// maxCandidates is the number of blocks of the base with the same
+	// checksum which are compared against the target, so that repetitive
+	// data, like runs of zeros, is not compared against every block.
+	maxCandidates = 4
+
+	opCopy   = byte(1)
+	opInsert = byte(2)
+)
+
+// magic begins every delta, and identifies the version of the format.
+var magic = []byte("git-lfs-delta/1\n")
+
+// Diff writes to w a delta which describes the contents of target in terms of
+// the baseSize bytes of base.
+//
+// A delta is the magic header "git-lfs-delta/1\n" followed by a sequence of
+// operations, each of which is either a copy, the byte 0x01 followed by the
+// offset within the base and the length of the data to copy from it, or an
+// insert, the byte 0x02 followed by the length of the data which follows it.
+// Offsets and lengths are encoded as unsigned varints.
+func Diff(base io.ReaderAt, baseSize int64, target io.Reader, w io.Writer) error {
+	bs := blockSizeFor(baseSize)
+	index, err := indexBase(base, baseSize, bs)
+	if err != nil {
+		return err
+	}
+
+	e := &encoder{w: bufio.NewWriter(w)}
+	if _, err := e.w.Write(magic); err != nil {
+		return err
+	}
+
+	// buf holds the target from the start of the pending insert, lit, to
+	// the end of what has been read; the block being matched against the
+	// base starts at pos.
+	buf := make([]byte, 0, 16*bs)
+	baseBlock := make([]byte, bs)
+	var (
+		lit, pos int
+		sum      rollsum
+		summed   bool
+		eof      bool
+	)
+
+	for {
+		if len(buf)-pos <= bs && !eof {
+			if pos-lit >= cap(buf)/2 {
+				if err := e.insert(buf[lit:pos]); err != nil {
+					return err
+				}
+				lit = pos
+			}
+			n := copy(buf[:cap(buf)], buf[lit:])
+			buf = buf[:n]
+			pos -= lit
+			lit = 0
+
+			n, err := io.ReadFull(target, buf[len(buf):cap(buf)])
+			buf = buf[:len(buf)+n]
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if len(buf)-pos < bs {
+			break
+		}
+		if !summed {
+			sum.init(buf[pos : pos+bs])
+			summed = true
+		}
+
+		if off, ok := index.match(base, sum.digest(), buf[pos:pos+bs], baseBlock); ok {
+			if err := e.insert(buf[lit:pos]); err != nil {
+				return err
+			}
+			if err := e.copy(off, int64(bs)); err != nil {
+				return err
+			}
+			pos += bs
+			lit = pos
+			summed = false
+			continue
+		}
+
+		if pos+bs == len(buf) {
+			if eof {
+				break
+			}
+			continue
+		}
+		sum.roll(buf[pos], buf[pos+bs])
+		pos++
+	}
+
+	if err := e.insert(buf[lit:]); err != nil {
+		return err
+	}
+	if err := e.flushCopy(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// Apply reconstructs the object described by the delta read from r, writing it
+// to w, and returns the number of bytes written. The delta must have been
+// computed against base.
+func Apply(base io.ReaderAt, r io.Reader, w io.Writer) (int64, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.Equal(header, magic) {
+		return 0, errors.New(tr.Tr.Get("invalid delta header"))
+	}
+
+	var written int64
+	for {
+		op, err := br.ReadByte()
+		if err == io.EOF {
+			return written, nil
+		} else if err != nil {
+			return written, err
+		}
+
+		switch op {
+		case opCopy:
+			off, err := binary.ReadUvarint(br)
+			if err != nil {
+				return written, errors.Wrap(err, tr.Tr.Get("invalid delta copy"))
+			}
+			length, err := binary.ReadUvarint(br)
+			if err != nil {
+				return written, errors.Wrap(err, tr.Tr.Get("invalid delta copy"))
+			}
+			n, err := io.Copy(w, io.NewSectionReader(base, int64(off), int64(length)))
+			written += n
+			if err != nil {
+				return written, err
+			}
+			if n != int64(length) {
+				return written, errors.New(tr.Tr.Get("delta copies beyond the end of the base"))
+			}
+		case opInsert:
+			length, err := binary.ReadUvarint(br)
+			if err != nil {
+				return written, errors.Wrap(err, tr.Tr.Get("invalid delta insert"))
+			}
+			n, err := io.CopyN(w, br, int64(length))
+			written += n
+			if err == io.EOF {
+				return written, errors.New(tr.Tr.Get("delta insert is truncated"))
+			} else if err != nil {
+				return written, err
+			}
+		default:
+			return written, errors.New(tr.Tr.Get("invalid delta operation: %d", op))
+		}
+	}
+}
+
+// blockSizeFor returns the size of the blocks into which a base of the given
+// size is divided.
+func blockSizeFor(baseSize int64) int {
+	bs := minBlockSize
+	for baseSize/int64(bs) > maxBlocks {
+		bs *= 2
+	}
+	return bs
+}
+
+// baseIndex maps the checksums of the blocks of a base to their offsets.
+type baseIndex map[uint32][]int64
+
+func indexBase(base io.ReaderAt, baseSize int64, bs int) (baseIndex, error) {
+	index := make(baseIndex, baseSize/int64(bs))
+	r := bufio.NewReaderSize(io.NewSectionReader(base, 0, baseSize), 16*bs)
+	block := make([]byte, bs)
+
+	var sum rollsum
+	for off := int64(0); off+int64(bs) <= baseSize; off += int64(bs) {
+		if _, err := io.ReadFull(r, block); err != nil {
+			return nil, err
+		}
+		sum.init(block)
+		if offs := index[sum.digest()]; len(offs) < maxCandidates {
+			index[sum.digest()] = append(offs, off)
+		}
+	}
+	return index, nil
+}
+
+// match returns the offset of a block of the base whose contents are the same
+// as block, which has the given checksum, using scratch to read the base.
+func (i baseIndex) match(base io.ReaderAt, digest uint32, block, scratch []byte) (int64, bool) {
+	for _, off := range i[digest] {
+		if _, err := base.ReadAt(scratch, off); err != nil {
+			continue
+		}
+		if bytes.Equal(scratch, block) {
+			return off, true
+		}
+	}
+	return 0, false
+}
+
+// encoder writes the operations of a delta, combining copies of adjacent
+// blocks of the base into a single operation.
+type encoder struct {
+	w       *bufio.Writer
+	copyOff int64
+	copyLen int64
+	varint  [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) copy(off, length int64) error {
+	if e.copyLen > 0 && e.copyOff+e.copyLen == off {
+		e.copyLen += length
+		return nil
+	}
+	if err := e.flushCopy(); err != nil {
+		return err
+	}
+	e.copyOff, e.copyLen = off, length
+	return nil
+}
+
+func (e *encoder) flushCopy() error {
+	if e.copyLen == 0 {
+		return nil
+	}
+	if err := e.op(opCopy, uint64(e.copyOff), uint64(e.copyLen)); err != nil {
+		return err
+	}
+	e.copyLen = 0
+	return nil
+}
+
+func (e *encoder) insert(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := e.flushCopy(); err != nil {
+		return err
+	}
+	if err := e.op(opInsert, uint64(len(data))); err != nil {
+		return err
+	}
+	_, err := e.w.Write(data)
+	return err
+}
+
+func (e *encoder) op(op byte, args ...uint64) error {
+	if err := e.w.WriteByte(op); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		n := binary.PutUvarint(e.varint[:], arg)
+		if _, err := e.w.Write(e.varint[:n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollsum is the rolling checksum used by rsync, which can be updated in
+// constant time as the block it covers moves forward by one byte.
+type rollsum struct {
+	a, b uint32
+	n    uint32
+}
+
+func (r *rollsum) init(block []byte) {
+	r.a, r.b, r.n = 0, 0, uint32(len(block))
+	for i, c := range block {
+		r.a += uint32(c)
+		r.b += (r.n - uint32(i)) * uint32(c)
+	}
+}
+
+func (r *rollsum) roll(out, in byte) {
+	r.a += uint32(in) - uint32(out)
+	r.b += r.a - r.n*uint32(out)
+}
+
+func (r *rollsum) digest() uint32 {
+	return r.b<<16 | r.a&0xffff
+}
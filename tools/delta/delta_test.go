@@ -0,0 +1,103 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func roundTrip(t *testing.T, base, target []byte) []byte {
+	var d bytes.Buffer
+	require.Nil(t, Diff(bytes.NewReader(base), int64(len(base)), bytes.NewReader(target), &d))
+
+	var out bytes.Buffer
+	n, err := Apply(bytes.NewReader(base), bytes.NewReader(d.Bytes()), &out)
+	require.Nil(t, err)
+	assert.Equal(t, int64(len(target)), n)
+	assert.True(t, bytes.Equal(target, out.Bytes()))
+	return d.Bytes()
+}
+
+func TestDiffIdentical(t *testing.T) {
+	base := randomBytes(1, 256*1024)
+	d := roundTrip(t, base, base)
+
+	// A single copy of the whole base.
+	assert.Less(t, len(d), len(magic)+16)
+}
+
+func TestDiffSmallChange(t *testing.T) {
+	base := randomBytes(2, 1024*1024)
+	target := append([]byte(nil), base...)
+	copy(target[500000:], []byte("a small change in the middle"))
+
+	d := roundTrip(t, base, target)
+	assert.Less(t, len(d), 3*minBlockSize)
+}
+
+func TestDiffInsertionAndDeletion(t *testing.T) {
+	base := randomBytes(3, 1024*1024)
+	target := append([]byte(nil), base[:300001]...)
+	target = append(target, randomBytes(4, 10000)...)
+	target = append(target, base[400000:]...)
+
+	d := roundTrip(t, base, target)
+	assert.Less(t, len(d), 10000+3*minBlockSize)
+}
+
+func TestDiffUnrelated(t *testing.T) {
+	base := randomBytes(5, 100*1024)
+	target := randomBytes(6, 300*1024)
+
+	d := roundTrip(t, base, target)
+	assert.Less(t, len(d), len(target)+len(target)/100)
+}
+
+func TestDiffEmpty(t *testing.T) {
+	roundTrip(t, nil, randomBytes(7, 10000))
+	roundTrip(t, randomBytes(8, 10000), nil)
+	roundTrip(t, nil, nil)
+}
+
+func TestDiffRepetitive(t *testing.T) {
+	base := make([]byte, 512*1024)
+	target := make([]byte, 600*1024+17)
+
+	d := roundTrip(t, base, target)
+	assert.Less(t, len(d), minBlockSize)
+}
+
+func TestApplyInvalidHeader(t *testing.T) {
+	_, err := Apply(bytes.NewReader(nil), bytes.NewReader([]byte("not a delta")), &bytes.Buffer{})
+	assert.EqualError(t, err, "invalid delta header")
+}
+
+func TestApplyCopyBeyondBase(t *testing.T) {
+	d := append(append([]byte(nil), magic...), opCopy, 0, 10)
+
+	_, err := Apply(bytes.NewReader([]byte("short")), bytes.NewReader(d), &bytes.Buffer{})
+	assert.EqualError(t, err, "delta copies beyond the end of the base")
+}
+
+func TestApplyTruncatedInsert(t *testing.T) {
+	d := append(append([]byte(nil), magic...), opInsert, 10, 'a', 'b')
+
+	_, err := Apply(bytes.NewReader(nil), bytes.NewReader(d), &bytes.Buffer{})
+	assert.EqualError(t, err, "delta insert is truncated")
+}
+
+func TestApplyInvalidOperation(t *testing.T) {
+	d := append(append([]byte(nil), magic...), 9)
+
+	_, err := Apply(bytes.NewReader(nil), bytes.NewReader(d), &bytes.Buffer{})
+	assert.EqualError(t, err, "invalid delta operation: 9")
+}
@@ -0,0 +1,7 @@
+// package delta computes and applies binary deltas, which describe an object in
+// terms of the blocks it shares with a base object followed by the data which
+// is new, as used by the "delta" transfer adapter.
+//
+// Based on the rsync algorithm: the base is divided into fixed-size blocks,
+// and a rolling checksum is used to find those blocks anywhere in the target.
+package delta
@@ -210,16 +210,21 @@ func ExpandConfigPath(path, defaultPath string) (string, error) {
 	return ExpandPath(fmt.Sprintf("~/.config/%s", defaultPath), false)
 }
 
-// VerifyFileHash reads a file and verifies whether the SHA is correct
+// VerifyFileHash reads a file and verifies whether its hash, computed with the
+// named hash algorithm, is correct
 // Returns an error if there is a problem
-func VerifyFileHash(oid, path string) error {
+func VerifyFileHash(algorithm, oid, path string) error {
+	h, err := NewLfsContentHashFor(algorithm)
+	if err != nil {
+		return err
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	h := NewLfsContentHash()
 	_, err = io.Copy(h, f)
 	if err != nil {
 		return err
@@ -333,3 +333,13 @@ func TestExecutablePermissions(t *testing.T) {
 	assert.EqualValues(t, os.FileMode(0750), ExecutablePermissions(0640))
 	assert.EqualValues(t, os.FileMode(0700), ExecutablePermissions(0600))
 }
+
+func TestVerifyFileHash(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "abc")
+	assert.Nil(t, os.WriteFile(filename, []byte("abc"), 0644))
+
+	assert.Nil(t, VerifyFileHash(HashAlgorithmSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", filename))
+	assert.Nil(t, VerifyFileHash(HashAlgorithmBLAKE3, "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85", filename))
+	assert.NotNil(t, VerifyFileHash(HashAlgorithmBLAKE3, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", filename))
+	assert.NotNil(t, VerifyFileHash("md5", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", filename))
+}
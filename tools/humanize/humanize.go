@@ -164,6 +164,27 @@ func FormatByteRate(s uint64, d time.Duration) string {
 		FormatBytesUnit(uint64(math.Ceil(f)), unit), suffix)
 }
 
+// FormatDuration outputs the given duration "d" rounded to the nearest second
+// in a compact, human-readable form, such as "42s", "3m05s", or "1h02m".
+// Durations of an hour or more are rounded to the nearest minute.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		m := int64(d.Round(time.Minute) / time.Minute)
+		return fmt.Sprintf("%dh%02dm", m/60, m%60)
+	}
+
+	s := int64(d / time.Second)
+	if s < 60 {
+		return fmt.Sprintf("%ds", s)
+	}
+	return fmt.Sprintf("%dm%02ds", s/60, s%60)
+}
+
 // log takes the log base "b" of "n" (\log_b{n})
 func log(n, b float64) float64 {
 	return math.Log(n) / math.Log(b)
@@ -70,6 +70,15 @@ func (c *FormatByteRateTestCase) Assert(t *testing.T) {
 	assert.Equal(t, c.Expected, humanize.FormatByteRate(c.Given, c.Over))
 }
 
+type FormatDurationTestCase struct {
+	Given    time.Duration
+	Expected string
+}
+
+func (c *FormatDurationTestCase) Assert(t *testing.T) {
+	assert.Equal(t, c.Expected, humanize.FormatDuration(c.Given))
+}
+
 func TestParseBytes(t *testing.T) {
 	for desc, c := range map[string]*ParseBytesTestCase{
 		"parse byte (zero, empty)": {"", uint64(0), nil},
@@ -279,3 +288,19 @@ func TestFormateByteRate(t *testing.T) {
 		t.Run(desc, c.Assert)
 	}
 }
+
+func TestFormatDuration(t *testing.T) {
+	for desc, c := range map[string]*FormatDurationTestCase{
+		"format zero":     {0, "0s"},
+		"format negative": {-time.Second, "0s"},
+		"format seconds":  {42 * time.Second, "42s"},
+		"format rounded":  {1500 * time.Millisecond, "2s"},
+		"format minutes":  {3*time.Minute + 5*time.Second, "3m05s"},
+		"format minute":   {59*time.Second + 600*time.Millisecond, "1m00s"},
+		"format hours":    {time.Hour + 2*time.Minute + 10*time.Second, "1h02m"},
+		"format rollover": {59*time.Minute + 59*time.Second + 600*time.Millisecond, "1h00m"},
+		"format days":     {50*time.Hour + 31*time.Minute, "50h31m"},
+	} {
+		t.Run(desc, c.Assert)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/zeebo/blake3"
 )
 
 const (
@@ -39,19 +40,45 @@ func CopyWithCallback(writer io.Writer, reader io.Reader, totalSize int64, cb Co
 	return io.Copy(writer, cbReader)
 }
 
-// Get a new Hash instance of the type used to hash LFS content
+// The hash algorithms which may be used to compute the OIDs of LFS content.
+const (
+	HashAlgorithmSHA256 = "sha256"
+	HashAlgorithmBLAKE3 = "blake3"
+)
+
+// Get a new Hash instance of the default type used to hash LFS content
 func NewLfsContentHash() hash.Hash {
 	return sha256.New()
 }
 
+// NewLfsContentHashFor returns a new Hash instance for the named hash
+// algorithm, or an error if the algorithm is not supported.  An empty name
+// means the default algorithm, SHA-256.
+func NewLfsContentHashFor(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "", HashAlgorithmSHA256:
+		return sha256.New(), nil
+	case HashAlgorithmBLAKE3:
+		return blake3.New(), nil
+	}
+	return nil, errors.NewUnsupportedHashAlgorithmError(algorithm)
+}
+
 // HashingReader wraps a reader and calculates the hash of the data as it is read
 type HashingReader struct {
 	reader io.Reader
 	hasher hash.Hash
 }
 
-func NewHashingReader(r io.Reader) *HashingReader {
-	return &HashingReader{r, NewLfsContentHash()}
+// NewHashingReader returns a HashingReader which hashes the data read from r
+// with the named hash algorithm, or an error if the algorithm is not
+// supported.
+func NewHashingReader(r io.Reader, algorithm string) (*HashingReader, error) {
+	hash, err := NewLfsContentHashFor(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &HashingReader{r, hash}, nil
 }
 
 func NewHashingReaderPreloadHash(r io.Reader, hash hash.Hash) *HashingReader {
@@ -74,6 +101,58 @@ func (r *HashingReader) Read(b []byte) (int, error) {
 	return w, err
 }
 
+// VerifyingReader wraps a reader and verifies that the data read from it has
+// the expected size and hash. It returns an error as soon as more data than
+// expected has been read, or at EOF if the size or hash of the data does not
+// match, so that a caller copying from it can stop early.
+type VerifyingReader struct {
+	reader io.Reader
+	hasher hash.Hash
+	oid    string
+	size   int64
+
+	read     int64
+	mismatch bool
+}
+
+func NewVerifyingReader(r io.Reader, hash hash.Hash, oid string, size int64) *VerifyingReader {
+	return &VerifyingReader{reader: r, hasher: hash, oid: oid, size: size}
+}
+
+// Mismatched returns whether the data read so far was found not to match the
+// expected size or hash.
+func (r *VerifyingReader) Mismatched() bool {
+	return r.mismatch
+}
+
+func (r *VerifyingReader) Read(b []byte) (int, error) {
+	w, err := r.reader.Read(b)
+	if err != nil && err != io.EOF {
+		return w, err
+	}
+
+	r.read += int64(w)
+	if r.read > r.size {
+		r.mismatch = true
+		return w, errors.New(tr.Tr.Get("expected %d bytes for OID %s, got at least %d", r.size, r.oid, r.read))
+	}
+	if _, e := r.hasher.Write(b[0:w]); e != nil {
+		return w, e
+	}
+
+	if err == io.EOF {
+		if r.read != r.size {
+			r.mismatch = true
+			return w, errors.New(tr.Tr.Get("expected %d bytes for OID %s, got %d", r.size, r.oid, r.read))
+		}
+		if actual := hex.EncodeToString(r.hasher.Sum(nil)); actual != r.oid {
+			r.mismatch = true
+			return w, errors.NewIntegrityError(errors.New(tr.Tr.Get("expected OID %s, got %s after %d bytes read", r.oid, actual, r.read)))
+		}
+	}
+	return w, err
+}
+
 // RetriableReader wraps a error response of reader as RetriableError()
 type RetriableReader struct {
 	reader io.Reader
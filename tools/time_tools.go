@@ -1,7 +1,12 @@
 package tools
 
 import (
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
 )
 
 // IsExpiredAtOrIn returns whether or not the result of calling TimeAtOrIn is
@@ -24,3 +29,25 @@ func TimeAtOrIn(from, at time.Time, in time.Duration) time.Time {
 	}
 	return from.Add(in)
 }
+
+// ParseDuration parses a duration in the same format as time.ParseDuration,
+// but additionally accepts a whole number of days or weeks, given with a "d"
+// or "w" suffix, such as "30d" or "2w". Those may not be combined with other
+// units. Days are treated as 24 hours long.
+func ParseDuration(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.ParseDuration(s)
+	}
+
+	n, err := strconv.ParseUint(s[:len(s)-1], 10, 32)
+	if err != nil {
+		return 0, errors.New(tr.Tr.Get("invalid duration: %q", s))
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -94,3 +94,32 @@ func TestIsExpiredAtOrInWithAmbiguousTime(t *testing.T) {
 	assert.Equal(t, now.Add(in), expired)
 	assert.False(t, ok)
 }
+
+func TestParseDurationDays(t *testing.T) {
+	d, err := ParseDuration("30d")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 30*24*time.Hour, d)
+}
+
+func TestParseDurationWeeks(t *testing.T) {
+	d, err := ParseDuration("2w")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 14*24*time.Hour, d)
+}
+
+func TestParseDurationStandardUnits(t *testing.T) {
+	d, err := ParseDuration("1h30m")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	for _, s := range []string{"", "d", "1.5d", "-1w", "2h1d", "abc"} {
+		_, err := ParseDuration(s)
+
+		assert.NotNil(t, err, s)
+	}
+}
@@ -0,0 +1,129 @@
+package tq
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/fs"
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// adapterCacheTTL is the length of time for which the transfer
+	// adapter chosen by the server for an endpoint is cached.
+	adapterCacheTTL = 5 * time.Minute
+)
+
+// adapterCacheEntry records the transfer adapter which the server chose in
+// response to a batch request.
+type adapterCacheEntry struct {
+	Adapter      string    `json:"adapter"`
+	NegotiatedAt time.Time `json:"negotiated_at"`
+}
+
+// adapterCache records the transfer adapter most recently chosen by the server
+// for each endpoint and direction, in a file in the LFS storage directory, so
+// that the adapter can be started while the first batch request of a transfer
+// queue is made.
+//
+// Since the server chooses an adapter again in response to every batch
+// request, an entry which has become stale only costs the time spent starting
+// an adapter which is not used.
+type adapterCache struct {
+	path string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+// newAdapterCache returns an adapter cache stored in the LFS storage directory
+// of the given filesystem, or nil if there is none.
+func newAdapterCache(f *fs.Filesystem) *adapterCache {
+	if f == nil || len(f.LFSStorageDir) == 0 {
+		return nil
+	}
+	return &adapterCache{
+		path: filepath.Join(f.LFSStorageDir, "adaptercache.json"),
+		now:  time.Now,
+	}
+}
+
+// Get returns the name of the adapter which the server chose for the given
+// endpoint URL and direction, if it was chosen within the TTL of the cache.
+func (c *adapterCache) Get(dir Direction, url string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.read()[adapterCacheKey(dir, url)]
+	if !ok || c.expired(e) {
+		return "", false
+	}
+	return e.Adapter, true
+}
+
+// Set records that the server chose the named adapter for the given endpoint
+// URL and direction, and removes any expired entries.
+func (c *adapterCache) Set(dir Direction, url, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.read()
+	for key, e := range entries {
+		if c.expired(e) {
+			delete(entries, key)
+		}
+	}
+	entries[adapterCacheKey(dir, url)] = &adapterCacheEntry{
+		Adapter:      name,
+		NegotiatedAt: c.now(),
+	}
+
+	by, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "adaptercache")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(by); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// read returns the entries of the cache, or no entries if the cache file does
+// not exist or cannot be parsed.
+func (c *adapterCache) read() map[string]*adapterCacheEntry {
+	entries := make(map[string]*adapterCacheEntry)
+
+	by, err := os.ReadFile(c.path)
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(by, &entries); err != nil {
+		tracerx.Printf("tq: ignoring invalid adapter cache %s: %s", c.path, err)
+		return make(map[string]*adapterCacheEntry)
+	}
+	return entries
+}
+
+func (c *adapterCache) expired(e *adapterCacheEntry) bool {
+	return e == nil || c.now().Sub(e.NegotiatedAt) > adapterCacheTTL
+}
+
+func adapterCacheKey(dir Direction, url string) string {
+	return dir.String() + " " + url
+}
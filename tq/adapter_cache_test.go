@@ -0,0 +1,85 @@
+package tq
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/fs"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/lfshttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdapterCacheGetSet(t *testing.T) {
+	c := newAdapterCache(&fs.Filesystem{LFSStorageDir: t.TempDir()})
+	require.NotNil(t, c)
+
+	_, ok := c.Get(Download, "https://example.com/lfs")
+	assert.False(t, ok)
+
+	require.Nil(t, c.Set(Download, "https://example.com/lfs", "tus"))
+	name, ok := c.Get(Download, "https://example.com/lfs")
+	assert.True(t, ok)
+	assert.Equal(t, "tus", name)
+
+	_, ok = c.Get(Upload, "https://example.com/lfs")
+	assert.False(t, ok)
+	_, ok = c.Get(Download, "https://example.org/lfs")
+	assert.False(t, ok)
+}
+
+func TestAdapterCacheExpires(t *testing.T) {
+	now := time.Now()
+	c := newAdapterCache(&fs.Filesystem{LFSStorageDir: t.TempDir()})
+	c.now = func() time.Time { return now }
+
+	require.Nil(t, c.Set(Download, "https://example.com/lfs", "tus"))
+
+	now = now.Add(adapterCacheTTL - time.Second)
+	_, ok := c.Get(Download, "https://example.com/lfs")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get(Download, "https://example.com/lfs")
+	assert.False(t, ok)
+
+	// Setting another entry removes the expired one.
+	require.Nil(t, c.Set(Upload, "https://example.com/lfs", "basic"))
+	assert.Len(t, c.read(), 1)
+}
+
+func TestAdapterCacheIgnoresInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "adaptercache.json"), []byte("not json"), 0644))
+
+	c := newAdapterCache(&fs.Filesystem{LFSStorageDir: dir})
+	_, ok := c.Get(Download, "https://example.com/lfs")
+	assert.False(t, ok)
+
+	require.Nil(t, c.Set(Download, "https://example.com/lfs", "tus"))
+	name, ok := c.Get(Download, "https://example.com/lfs")
+	assert.True(t, ok)
+	assert.Equal(t, "tus", name)
+}
+
+func TestAdapterCacheWithoutStorageDir(t *testing.T) {
+	assert.Nil(t, newAdapterCache(nil))
+	assert.Nil(t, newAdapterCache(&fs.Filesystem{}))
+}
+
+func TestManifestAdapterCacheOptIn(t *testing.T) {
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{}))
+	require.Nil(t, err)
+	assert.Nil(t, NewManifest(f, cli, "", "").Upgrade().adapterCache())
+
+	cli, err = lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.transfer.adaptercache": "true",
+	}))
+	require.Nil(t, err)
+	assert.NotNil(t, NewManifest(f, cli, "", "").Upgrade().adapterCache())
+}
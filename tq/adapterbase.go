@@ -1,10 +1,13 @@
 package tq
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/fs"
@@ -26,6 +29,21 @@ type adapterBase struct {
 	jobChan      chan *job
 	debugging    bool
 	cb           ProgressCallback
+	hooks        *TransferHooks
+	bandwidth    *bandwidthLimiter
+	hashAlgo     string
+	concurrency  int
+	maxRetries   int
+	// stallTimeout is the longest time for which a transfer may make no
+	// progress before it is cancelled, or zero if there is no limit.
+	stallTimeout time.Duration
+	// ctx is the context.Context of the queue, whose cancellation stops
+	// the transfers of the adapter.
+	ctx context.Context
+	// transferContexts holds the context.Context of each *Transfer in
+	// progress, which its HTTP requests use when stallTimeout is set or
+	// ctx may be cancelled.
+	transferContexts sync.Map
 	// WaitGroup to sync the completion of all workers
 	workerWait sync.WaitGroup
 	// WaitGroup to sync the completion of all in-flight jobs
@@ -55,8 +73,26 @@ type transferImplementation interface {
 const (
 	enableHrefRewriteKey     = "lfs.transfer.enablehrefrewrite"
 	defaultEnableHrefRewrite = false
+
+	stallTimeoutKey = "lfs.transfer.stalltimeout"
+
+	// presignedURLsKey is whether the hrefs of actions are treated as
+	// pre-signed URLs, to which no credentials are sent: "always",
+	// "never", or by default "auto" to detect them.
+	presignedURLsKey = "lfs.transfer.presignedurls"
 )
 
+// presignedQueryParams are sets of query parameters which, when all are
+// present in a URL, show that it carries its own authorization, as with the
+// pre-signed URLs of Amazon S3, Google Cloud Storage, and Azure Blob Storage.
+var presignedQueryParams = [][]string{
+	{"x-amz-signature"},
+	{"signature", "awsaccesskeyid"},
+	{"x-goog-signature"},
+	{"signature", "googleaccessid"},
+	{"sig", "sv"},
+}
+
 func newAdapterBase(f *fs.Filesystem, name string, dir Direction, ti transferImplementation) *adapterBase {
 	return &adapterBase{
 		fs:           f,
@@ -79,10 +115,20 @@ func (a *adapterBase) Begin(cfg AdapterConfig, cb ProgressCallback) error {
 	a.apiClient = cfg.APIClient()
 	a.remote = cfg.Remote()
 	a.cb = cb
+	a.ctx = context.Background()
+	if c, ok := cfg.(*adapterConfig); ok {
+		a.hooks = c.transferHooks()
+		a.bandwidth = c.bandwidthLimiter()
+		a.hashAlgo = c.hashAlgorithm()
+		a.ctx = c.transferContext()
+		a.maxRetries = c.maxRetries()
+	}
 	a.jobChan = make(chan *job, 100)
 	a.debugging = a.apiClient.OSEnv().Bool("GIT_TRANSFER_TRACE", false) ||
 		a.apiClient.OSEnv().Bool("GIT_CURL_VERBOSE", false)
 	maxConcurrency := cfg.ConcurrentTransfers()
+	a.concurrency = maxConcurrency
+	a.stallTimeout = time.Duration(a.apiClient.GitEnv().Int(stallTimeoutKey, 0)) * time.Second
 
 	a.Trace("xfer: adapter %q Begin() with %d workers", a.Name(), maxConcurrency)
 
@@ -177,11 +223,13 @@ func (a *adapterBase) worker(workerNum int, ctx interface{}) {
 
 		// Actual transfer happens here
 		var err error
+		a.hooks.start(t)
 		if t.Size < 0 {
 			err = errors.New(tr.Tr.Get("object %q has invalid size (got: %d)", t.Oid, t.Size))
 		} else {
-			err = a.transferImpl.DoTransfer(ctx, t, a.cb, authCallback)
+			err = a.doTransfer(ctx, t, a.hooks.progressCallback(t, a.cb), authCallback)
 		}
+		a.hooks.complete(t, err)
 
 		// Mark the job as completed, and alter all listeners
 		job.Done(err)
@@ -197,6 +245,52 @@ func (a *adapterBase) worker(workerNum int, ctx interface{}) {
 	a.workerWait.Done()
 }
 
+// doTransfer performs the transfer t, cancelling its HTTP requests if it makes
+// no progress for longer than the stall timeout. A transfer which is cancelled
+// for having stalled may be retried. If the context of the adapter is
+// cancelled, the transfer is not started, or its HTTP requests are aborted,
+// and the context's error is returned.
+func (a *adapterBase) doTransfer(ctx interface{}, t *Transfer, cb ProgressCallback, authOkFunc func()) error {
+	if err := a.ctx.Err(); err != nil {
+		return err
+	}
+
+	if a.stallTimeout <= 0 {
+		if a.ctx.Done() == nil {
+			return a.transferImpl.DoTransfer(ctx, t, cb, authOkFunc)
+		}
+
+		a.transferContexts.Store(t, a.ctx)
+		err := a.transferImpl.DoTransfer(ctx, t, cb, authOkFunc)
+		a.transferContexts.Delete(t)
+
+		if err != nil && a.ctx.Err() != nil {
+			return a.ctx.Err()
+		}
+		return err
+	}
+
+	reqCtx, watchdog := newStallWatchdog(a.ctx, a.stallTimeout)
+	a.transferContexts.Store(t, reqCtx)
+	err := a.transferImpl.DoTransfer(ctx, t, watchdog.progressCallback(cb), authOkFunc)
+	a.transferContexts.Delete(t)
+
+	stalled := watchdog.Stop()
+	if err != nil && a.ctx.Err() != nil {
+		return a.ctx.Err()
+	}
+	if stalled && err != nil {
+		a.Trace("xfer: transfer of %q stalled: %s", t.Oid, err)
+		return errors.NewRetriableError(errors.New(tr.Tr.Get("transfer of %s stalled: no progress for %s", t.Oid, a.stallTimeout)))
+	}
+	return err
+}
+
+// cancelled returns whether the context of the adapter has been cancelled.
+func (a *adapterBase) cancelled() bool {
+	return a.ctx != nil && a.ctx.Err() != nil
+}
+
 var httpRE = regexp.MustCompile(`\Ahttps?://`)
 
 func (a *adapterBase) newHTTPRequest(method string, rel *Action) (*http.Request, error) {
@@ -225,13 +319,74 @@ func (a *adapterBase) newHTTPRequest(method string, rel *Action) (*http.Request,
 }
 
 func (a *adapterBase) doHTTP(t *Transfer, req *http.Request) (*http.Response, error) {
-	if t.Authenticated {
+	if ctx, ok := a.transferContexts.Load(t); ok {
+		req = req.WithContext(ctx.(context.Context))
+	}
+	if !a.sendsCredentials(t, req) {
 		return a.apiClient.Do(req)
 	}
 	endpoint := endpointURL(req.URL.String(), t.Oid)
 	return a.apiClient.DoWithAuthNoRetry(a.remote, a.apiClient.Endpoints.AccessFor(endpoint), req)
 }
 
+// sendsCredentials returns whether credentials may be added to the given
+// request for the transfer t.  They are not if the server says the transfer's
+// actions are already authenticated, or if the request's URL is pre-signed,
+// since some storage services reject a request which is authorized twice.
+//
+// A URL is taken to be pre-signed if its query carries a signature, or if its
+// action gives no headers and it is on a different host from the LFS API,
+// unless "lfs.transfer.presignedurls" says otherwise.
+func (a *adapterBase) sendsCredentials(t *Transfer, req *http.Request) bool {
+	if t.Authenticated {
+		return false
+	}
+
+	mode, _ := a.apiClient.GitEnv().Get(presignedURLsKey)
+	switch strings.ToLower(mode) {
+	case "always":
+		return false
+	case "never":
+		return true
+	}
+
+	if hasSignedQuery(req.URL) {
+		a.Trace("xfer: not sending credentials to pre-signed URL for %q", t.Oid)
+		return false
+	}
+
+	rel, err := t.Rel(a.direction.String())
+	if err != nil || rel == nil || len(rel.Header) > 0 {
+		return true
+	}
+	apiURL, err := url.Parse(a.apiClient.Endpoints.Endpoint(a.direction.String(), a.remote).Url)
+	if err != nil || apiURL.Host == req.URL.Host {
+		return true
+	}
+	a.Trace("xfer: not sending credentials to %s for %q, which has no action headers", req.URL.Host, t.Oid)
+	return false
+}
+
+// hasSignedQuery returns whether the query of the given URL includes any of
+// the presignedQueryParams.
+func hasSignedQuery(u *url.URL) bool {
+	params := make(map[string]bool)
+	for key := range u.Query() {
+		params[strings.ToLower(key)] = true
+	}
+
+	for _, set := range presignedQueryParams {
+		signed := true
+		for _, key := range set {
+			signed = signed && params[key]
+		}
+		if signed {
+			return true
+		}
+	}
+	return false
+}
+
 func advanceCallbackProgress(cb ProgressCallback, t *Transfer, numBytes int64) {
 	if cb != nil {
 		// Must split into max int sizes since read count is int
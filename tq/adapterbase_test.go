@@ -0,0 +1,79 @@
+package tq
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/lfshttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasSignedQuery(t *testing.T) {
+	for _, rawurl := range []string{
+		"https://bucket.s3.amazonaws.com/a?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=x&X-Amz-Signature=abc",
+		"https://bucket.s3.amazonaws.com/a?AWSAccessKeyId=x&Expires=1&Signature=abc",
+		"https://storage.googleapis.com/bucket/a?X-Goog-Signature=abc",
+		"https://storage.googleapis.com/bucket/a?GoogleAccessId=x&Signature=abc",
+		"https://account.blob.core.windows.net/c/a?sv=2020-08-04&se=2030-01-01&sig=abc",
+		"https://example.com/a?x-amz-signature=abc",
+	} {
+		u, err := url.Parse(rawurl)
+		require.Nil(t, err)
+		assert.True(t, hasSignedQuery(u), rawurl)
+	}
+
+	for _, rawurl := range []string{
+		"https://example.com/a",
+		"https://example.com/a?r=repo",
+		"https://example.com/a?Signature=abc",
+		"https://example.com/a?sig=abc",
+	} {
+		u, err := url.Parse(rawurl)
+		require.Nil(t, err)
+		assert.False(t, hasSignedQuery(u), rawurl)
+	}
+}
+
+func TestAdapterSendsCredentials(t *testing.T) {
+	for _, c := range []struct {
+		Desc     string
+		Mode     string
+		Href     string
+		Header   map[string]string
+		Auth     bool
+		Expected bool
+	}{
+		{"same host", "", "https://git.example.com/storage/a", nil, false, true},
+		{"authenticated", "", "https://git.example.com/storage/a", nil, true, false},
+		{"signed query", "", "https://git.example.com/storage/a?X-Amz-Signature=abc", map[string]string{"X": "y"}, false, false},
+		{"other host without headers", "", "https://bucket.s3.amazonaws.com/a", nil, false, false},
+		{"other host with headers", "", "https://bucket.s3.amazonaws.com/a", map[string]string{"Authorization": "Bearer x"}, false, true},
+		{"never", "never", "https://bucket.s3.amazonaws.com/a?X-Amz-Signature=abc", nil, false, true},
+		{"always", "always", "https://git.example.com/storage/a", map[string]string{"X": "y"}, false, false},
+		{"always, case-insensitive", "Always", "https://git.example.com/storage/a", nil, false, false},
+	} {
+		gitConf := map[string]string{"lfs.url": "https://git.example.com/repo.git/info/lfs"}
+		if len(c.Mode) > 0 {
+			gitConf["lfs.transfer.presignedurls"] = c.Mode
+		}
+		client, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, gitConf))
+		require.Nil(t, err)
+
+		a := newAdapterBase(nil, "basic", Download, nil)
+		a.apiClient = client
+		a.remote = "origin"
+
+		tr := &Transfer{
+			Oid:           "a",
+			Authenticated: c.Auth,
+			Actions:       ActionSet{"download": &Action{Href: c.Href, Header: c.Header}},
+		}
+		req, err := http.NewRequest("GET", c.Href, nil)
+		require.Nil(t, err)
+
+		assert.Equal(t, c.Expected, a.sendsCredentials(tr, req), c.Desc)
+	}
+}
@@ -1,31 +1,79 @@
 package tq
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/git-lfs/git-lfs/v3/config"
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfsapi"
 	"github.com/git-lfs/git-lfs/v3/lfshttp"
+	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/rubyist/tracerx"
 )
 
+// batchCompressionThreshold is the size in bytes above which batch request
+// bodies are compressed, when compression is enabled and the server has
+// advertised support for it.
+const batchCompressionThreshold = 4096
+
 type tqClient struct {
 	maxRetries int
 	*lfsapi.Client
+
+	// gzipEndpoints records the URLs of the endpoints which have
+	// advertised that they accept gzip-compressed batch requests.
+	gzipEndpoints map[string]bool
+	gzipMu        sync.Mutex
+
+	// cache records the batch responses given an ETag by the server, or
+	// is nil if the cache is disabled.
+	cache *batchCache
 }
 
 type batchRef struct {
 	Name string `json:"name,omitempty"`
 }
 
+// newBatchRef returns the ref to send in a batch request for the given ref, or
+// nil if no ref can be determined. If HEAD is detached, the ref is named by
+// the SHA of the commit to which it refers.
+func newBatchRef(ref *git.Ref) *batchRef {
+	if ref == nil || len(ref.Name) == 0 {
+		return nil
+	}
+
+	if ref.Type == git.RefTypeHEAD {
+		if len(ref.Sha) == 0 {
+			return nil
+		}
+		return &batchRef{Name: ref.Sha}
+	}
+	return &batchRef{Name: ref.Refspec()}
+}
+
 type batchRequest struct {
 	Operation            string      `json:"operation"`
 	Objects              []*Transfer `json:"objects"`
 	TransferAdapterNames []string    `json:"transfers,omitempty"`
-	Ref                  *batchRef   `json:"ref"`
+	Ref                  *batchRef   `json:"ref,omitempty"`
 	HashAlgorithm        string      `json:"hash_algo"`
+	// ChunkSize is the preferred size of each part of a multipart
+	// upload, which servers may use to split large uploads into parts.
+	ChunkSize int64 `json:"chunk_size,omitempty"`
+	// Compression lists the algorithms with which the client is able to
+	// compress and decompress object bodies, if the server asks for it.
+	Compression []string `json:"compression,omitempty"`
+
+	// ctx is the context.Context of the request, if any, whose
+	// cancellation aborts it.
+	ctx context.Context
 }
 
 type BatchResponse struct {
@@ -33,22 +81,127 @@ type BatchResponse struct {
 	TransferAdapterName string      `json:"transfer"`
 	HashAlgorithm       string      `json:"hash_algo"`
 	endpoint            lfshttp.Endpoint
+	date                time.Time
+}
+
+// Date returns the time given by the Date header of the server's response, or
+// the zero time if it sent none.
+func (r *BatchResponse) Date() time.Time {
+	return r.date
 }
 
 func Batch(m Manifest, dir Direction, remote string, remoteRef *git.Ref, objects []*Transfer) (*BatchResponse, error) {
+	return BatchContext(context.Background(), m, dir, remote, remoteRef, objects)
+}
+
+// BatchContext is like Batch, but aborts the request if the given context is
+// cancelled before it completes.
+func BatchContext(ctx context.Context, m Manifest, dir Direction, remote string, remoteRef *git.Ref, objects []*Transfer) (*BatchResponse, error) {
 	if len(objects) == 0 {
 		return &BatchResponse{}, nil
 	}
 
 	cm := m.Upgrade()
 
-	return cm.batchClient().Batch(remote, &batchRequest{
+	bReq := &batchRequest{
 		Operation:            dir.String(),
 		Objects:              objects,
 		TransferAdapterNames: m.GetAdapterNames(dir),
-		Ref:                  &batchRef{Name: remoteRef.Refspec()},
-		HashAlgorithm:        "sha256",
-	})
+		HashAlgorithm:        cm.hashAlgorithm(),
+		ctx:                  ctx,
+	}
+	if cm.sendRef() {
+		bReq.Ref = newBatchRef(remoteRef)
+	}
+	if dir == Upload {
+		bReq.ChunkSize = cm.uploadChunkSize()
+	}
+	if c := cm.transferCompression(); len(c) > 0 {
+		bReq.Compression = []string{c}
+	}
+
+	return cm.batchClient().Batch(remote, bReq)
+}
+
+// batchCompressionEnabled returns whether batch requests to the given endpoint
+// may be compressed, and their responses may be encoded, as given by the
+// "lfs.<url>.batchcompression" setting.
+func (c *tqClient) batchCompressionEnabled(e lfshttp.Endpoint) bool {
+	uc := config.NewURLConfig(c.GitEnv())
+	return uc.Bool("lfs", e.Url, "batchcompression", false)
+}
+
+// batchTimeout returns the longest time which a batch request and its response
+// may take, as given by the "lfs.transfer.batchtimeout" setting, or zero if
+// there is no limit.
+func (c *tqClient) batchTimeout() time.Duration {
+	return time.Duration(c.GitEnv().Int("lfs.transfer.batchtimeout", 0)) * time.Second
+}
+
+// batchTimeoutError returns a retriable error in place of err if the given
+// batch request failed because it took longer than the timeout.
+func batchTimeoutError(req *http.Request, timeout time.Duration, err error) error {
+	if timeout > 0 && req.Context().Err() == context.DeadlineExceeded {
+		return errors.NewRetriableError(errors.New(tr.Tr.Get("batch request timed out after %s", timeout)))
+	}
+	return err
+}
+
+// acceptsGzip returns whether the given endpoint has advertised that it
+// accepts gzip-compressed requests.
+func (c *tqClient) acceptsGzip(e lfshttp.Endpoint) bool {
+	c.gzipMu.Lock()
+	defer c.gzipMu.Unlock()
+	return c.gzipEndpoints[e.Url]
+}
+
+// recordAcceptEncoding records whether the given endpoint accepts
+// gzip-compressed requests, as advertised by the "Accept-Encoding" header of
+// its response.
+func (c *tqClient) recordAcceptEncoding(e lfshttp.Endpoint, res *http.Response) {
+	accepts := lfshttp.AcceptsContentEncoding(res.Header.Get("Accept-Encoding"), "gzip")
+
+	c.gzipMu.Lock()
+	defer c.gzipMu.Unlock()
+	if c.gzipEndpoints == nil {
+		c.gzipEndpoints = make(map[string]bool)
+	}
+	c.gzipEndpoints[e.Url] = accepts
+}
+
+// batchHashAlgorithmMatches returns whether the hash algorithm of a batch
+// response is the one which was requested. Servers which omit the hash
+// algorithm from their response are assumed to use SHA-256.
+func batchHashAlgorithmMatches(requested, received string) bool {
+	if len(requested) == 0 {
+		requested = tools.HashAlgorithmSHA256
+	}
+	if len(received) == 0 {
+		received = tools.HashAlgorithmSHA256
+	}
+	return requested == received
+}
+
+// readCacheableBody reads the body of the given batch response, and leaves it
+// to be read again, if it may be cached under the given key because the server
+// gave it an ETag.  Otherwise, it removes any response cached under the key and
+// returns nil.
+func (c *tqClient) readCacheableBody(res *http.Response, key string) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	if res.StatusCode != 200 || len(res.Header.Get("ETag")) == 0 {
+		c.cache.Remove(key)
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
 }
 
 type BatchClient interface {
@@ -90,22 +243,102 @@ func (c *tqClient) Batch(remote string, bReq *batchRequest) (*BatchResponse, err
 
 	tracerx.Printf("api: batch %d files", len(bReq.Objects))
 
+	var cacheKey string
+	var cached *batchCacheEntry
+	if c.cache != nil {
+		if key, err := batchCacheKey(bRes.endpoint.Url, bReq); err != nil {
+			tracerx.Printf("api: unable to cache batch response: %s", err)
+		} else {
+			cacheKey = key
+			if e, ok := c.cache.Get(key); ok {
+				cached = e
+				req.Header.Set("If-None-Match", e.ETag)
+			}
+		}
+	}
+
+	if bReq.ctx != nil {
+		req = req.WithContext(bReq.ctx)
+	}
+	timeout := c.batchTimeout()
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	compression := c.batchCompressionEnabled(bRes.endpoint)
+	if compression {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		if req.ContentLength > batchCompressionThreshold && c.acceptsGzip(bRes.endpoint) {
+			size := req.ContentLength
+			if err := lfshttp.CompressRequestBody(req); err != nil {
+				return nil, errors.Wrap(err, tr.Tr.Get("batch request"))
+			}
+			tracerx.Printf("api: compressed batch request from %d to %d bytes", size, req.ContentLength)
+		}
+	}
+
 	req = c.Client.LogRequest(req, "lfs.batch")
 	res, err := c.DoAPIRequestWithAuth(remote, lfshttp.WithRetries(req, c.MaxRetries()))
 	if err != nil {
 		tracerx.Printf("api error: %s", err)
-		return nil, errors.Wrap(err, tr.Tr.Get("batch response"))
+		return nil, errors.Wrap(batchTimeoutError(req, timeout, err), tr.Tr.Get("batch response"))
+	}
+
+	if compression {
+		c.recordAcceptEncoding(bRes.endpoint, res)
+	}
+
+	notModified := cached != nil && res.StatusCode == http.StatusNotModified
+	if notModified {
+		res.Body.Close()
+		cachedRes, err := cached.response()
+		if err != nil {
+			c.cache.Remove(cacheKey)
+			return nil, errors.Wrap(err, tr.Tr.Get("batch response"))
+		}
+
+		tracerx.Printf("api: reusing cached batch response for %d files", len(bReq.Objects))
+		cachedRes.endpoint = bRes.endpoint
+		bRes = cachedRes
+		requestedAt = cached.RequestedAt
+	} else {
+		if compression {
+			if err := lfshttp.DecodeResponseBody(res); err != nil {
+				res.Body.Close()
+				return nil, errors.Wrap(err, tr.Tr.Get("batch response"))
+			}
+		}
+
+		body, err := c.readCacheableBody(res, cacheKey)
+		if err != nil {
+			return nil, errors.Wrap(batchTimeoutError(req, timeout, err), tr.Tr.Get("batch response"))
+		}
+
+		if err := lfshttp.DecodeJSON(res, bRes); err != nil {
+			return bRes, errors.Wrap(batchTimeoutError(req, timeout, err), tr.Tr.Get("batch response"))
+		}
+
+		if body != nil && batchHashAlgorithmMatches(bReq.HashAlgorithm, bRes.HashAlgorithm) {
+			if batchResponseHasCredentials(bRes) {
+				tracerx.Printf("api: not caching batch response with credentials")
+				c.cache.Remove(cacheKey)
+			} else if err := c.cache.Set(cacheKey, res.Header.Get("ETag"), requestedAt, body); err != nil {
+				tracerx.Printf("api: unable to cache batch response: %s", err)
+			}
+		}
 	}
 
-	if err := lfshttp.DecodeJSON(res, bRes); err != nil {
-		return bRes, errors.Wrap(err, tr.Tr.Get("batch response"))
+	if date, err := http.ParseTime(res.Header.Get("Date")); err == nil {
+		bRes.date = date
 	}
 
-	if bRes.HashAlgorithm != "" && bRes.HashAlgorithm != "sha256" {
+	if !batchHashAlgorithmMatches(bReq.HashAlgorithm, bRes.HashAlgorithm) {
 		return bRes, errors.Wrap(errors.New(tr.Tr.Get("unsupported hash algorithm")), tr.Tr.Get("batch response"))
 	}
 
-	if res.StatusCode != 200 {
+	if res.StatusCode != 200 && !notModified {
 		return nil, lfshttp.NewStatusCodeError(res)
 	}
 
@@ -1,15 +1,20 @@
 package tq
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/git"
 	"github.com/git-lfs/git-lfs/v3/lfsapi"
 	"github.com/git-lfs/git-lfs/v3/lfshttp"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +33,7 @@ func TestAPIBatch(t *testing.T) {
 		}
 
 		assert.Equal(t, "POST", r.Method)
-		assert.Equal(t, "106", r.Header.Get("Content-Length"))
+		assert.Equal(t, "95", r.Header.Get("Content-Length"))
 
 		bodyLoader, body := gojsonschema.NewReaderLoader(r.Body)
 		bReq := &batchRequest{}
@@ -128,6 +133,96 @@ func TestAPIBatchOnlyBasic(t *testing.T) {
 	assert.Equal(t, "basic", bRes.TransferAdapterName)
 }
 
+func TestAPIBatchCompression(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "gzip, deflate", r.Header.Get("Accept-Encoding"))
+
+		var body io.Reader = r.Body
+		if requests == 1 {
+			assert.Empty(t, r.Header.Get("Content-Encoding"))
+		} else {
+			assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+			gz, err := gzip.NewReader(r.Body)
+			require.Nil(t, err)
+			body = gz
+		}
+
+		bReq := &batchRequest{}
+		assert.Nil(t, json.NewDecoder(body).Decode(bReq))
+		r.Body.Close()
+
+		w.Header().Set("Accept-Encoding", "gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		gz := gzip.NewWriter(w)
+		assert.Nil(t, json.NewEncoder(gz).Encode(&BatchResponse{
+			TransferAdapterName: "basic",
+			Objects:             bReq.Objects,
+		}))
+		gz.Close()
+	}))
+	defer srv.Close()
+
+	c, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":              srv.URL + "/api",
+		"lfs.batchcompression": "true",
+	}))
+	require.Nil(t, err)
+
+	objects := make([]*Transfer, 0, 100)
+	for i := 0; i < cap(objects); i++ {
+		objects = append(objects, &Transfer{Oid: fmt.Sprintf("%064d", i), Size: 1})
+	}
+
+	tqc := &tqClient{Client: c}
+	for i := 0; i < 2; i++ {
+		bRes, err := tqc.Batch("remote", &batchRequest{Objects: objects})
+		require.Nil(t, err)
+		assert.Equal(t, "basic", bRes.TransferAdapterName)
+		assert.Equal(t, len(objects), len(bRes.Objects))
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestAPIBatchCompressionDisabled(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+
+		bReq := &batchRequest{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+		r.Body.Close()
+
+		w.Header().Set("Accept-Encoding", "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		assert.Nil(t, json.NewEncoder(w).Encode(&BatchResponse{
+			TransferAdapterName: "basic",
+			Objects:             bReq.Objects,
+		}))
+	}))
+	defer srv.Close()
+
+	c, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url": srv.URL + "/api",
+	}))
+	require.Nil(t, err)
+
+	objects := make([]*Transfer, 0, 100)
+	for i := 0; i < cap(objects); i++ {
+		objects = append(objects, &Transfer{Oid: fmt.Sprintf("%064d", i), Size: 1})
+	}
+
+	tqc := &tqClient{Client: c}
+	for i := 0; i < 2; i++ {
+		_, err := tqc.Batch("remote", &batchRequest{Objects: objects})
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 2, requests)
+}
+
 func TestAPIBatchEmptyObjects(t *testing.T) {
 	c, err := lfsapi.NewClient(nil)
 	require.Nil(t, err)
@@ -142,6 +237,127 @@ func TestAPIBatchEmptyObjects(t *testing.T) {
 	assert.Equal(t, 0, len(bRes.Objects))
 }
 
+func TestAPIBatchRef(t *testing.T) {
+	for desc, c := range map[string]struct {
+		Ref      *git.Ref
+		SendRef  string
+		Expected *batchRef
+	}{
+		"branch": {
+			&git.Ref{Name: "main", Type: git.RefTypeLocalBranch, Sha: "abc123"}, "",
+			&batchRef{Name: "refs/heads/main"},
+		},
+		"detached HEAD": {
+			&git.Ref{Name: "HEAD", Type: git.RefTypeHEAD, Sha: "abc123"}, "",
+			&batchRef{Name: "abc123"},
+		},
+		"no ref": {
+			nil, "", nil,
+		},
+		"unknown ref": {
+			&git.Ref{}, "", nil,
+		},
+		"sendref disabled": {
+			&git.Ref{Name: "main", Type: git.RefTypeLocalBranch, Sha: "abc123"}, "false",
+			nil,
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			var bReq *batchRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				r.Body.Close()
+				require.Nil(t, err)
+				require.Nil(t, json.Unmarshal(body, &bReq))
+				assert.Equal(t, c.Expected != nil, strings.Contains(string(body), `"ref"`))
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+			}))
+			defer srv.Close()
+
+			gitConf := map[string]string{"lfs.url": srv.URL + "/api"}
+			if len(c.SendRef) > 0 {
+				gitConf["lfs.transfer.sendref"] = c.SendRef
+			}
+			cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, gitConf))
+			require.Nil(t, err)
+
+			m := NewManifest(nil, cli, "", "")
+			_, err = Batch(m, Download, "origin", c.Ref, []*Transfer{{Oid: "a", Size: 1}})
+			require.Nil(t, err)
+			require.NotNil(t, bReq)
+			assert.Equal(t, c.Expected, bReq.Ref)
+		})
+	}
+}
+
+func TestAPIBatchTransferCompression(t *testing.T) {
+	for desc, c := range map[string]struct {
+		Setting  string
+		Expected []string
+	}{
+		"zstd":    {"zstd", []string{"zstd"}},
+		"none":    {"none", nil},
+		"unset":   {"", nil},
+		"invalid": {"lz4", nil},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			var bReq *batchRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bodyLoader, body := gojsonschema.NewReaderLoader(r.Body)
+				require.Nil(t, json.NewDecoder(body).Decode(&bReq))
+				r.Body.Close()
+				assertSchema(t, batchReqSchema, bodyLoader)
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+			}))
+			defer srv.Close()
+
+			gitConf := map[string]string{"lfs.url": srv.URL + "/api"}
+			if len(c.Setting) > 0 {
+				gitConf["lfs.transfer.compression"] = c.Setting
+			}
+			cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, gitConf))
+			require.Nil(t, err)
+
+			m := NewManifest(nil, cli, "", "")
+			_, err = Batch(m, Upload, "origin", nil, []*Transfer{{Oid: "a", Size: 1}})
+			require.Nil(t, err)
+			require.NotNil(t, bReq)
+			assert.Equal(t, c.Expected, bReq.Compression)
+		})
+	}
+}
+
+func TestAPIBatchTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Never respond, until the test is over.
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":                   srv.URL + "/api",
+		"lfs.transfer.batchtimeout": "1",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	start := time.Now()
+	_, err = Batch(m, Download, "origin", nil, []*Transfer{{Oid: "a", Size: 1}})
+	require.NotNil(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second)
+	assert.True(t, errors.IsRetriableError(err))
+	assert.Contains(t, err.Error(), "batch request timed out after 1s")
+}
+
 var (
 	batchReqSchema *sourcedSchema
 	batchResSchema *sourcedSchema
@@ -0,0 +1,139 @@
+package tq
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/tools"
+)
+
+// bandwidthLimiter is a token bucket which limits the aggregate rate at which
+// bytes are transferred. A single limiter is shared by every adapter created
+// from the same manifest, so that concurrent transfers split the available
+// bandwidth between them rather than each being allowed the full rate.
+type bandwidthLimiter struct {
+	// rate is the number of bytes per second which may be transferred.
+	rate float64
+	// burst is the maximum number of tokens which may accumulate while
+	// the limiter is idle, and the largest single read which is allowed.
+	burst int
+
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// newBandwidthLimiter returns a limiter allowing bytesPerSecond bytes to be
+// transferred each second, or nil if bytesPerSecond is zero, in which case
+// transfers are unlimited.
+func newBandwidthLimiter(bytesPerSecond uint64) *bandwidthLimiter {
+	if bytesPerSecond == 0 {
+		return nil
+	}
+
+	burst := int(bytesPerSecond)
+	if uint64(burst) != bytesPerSecond || burst < 0 {
+		burst = int(^uint(0) >> 1)
+	}
+
+	return &bandwidthLimiter{
+		rate:  float64(bytesPerSecond),
+		burst: burst,
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+}
+
+// waitN takes n tokens from the bucket, blocking until they have been
+// replenished if the bucket does not hold enough. Tokens may be borrowed
+// ahead of time, so that callers waiting concurrently are each delayed in
+// turn rather than all being woken at once.
+func (l *bandwidthLimiter) waitN(n int) {
+	l.mu.Lock()
+	l.refill()
+	l.tokens -= float64(n)
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait > 0 {
+		l.sleep(wait)
+	}
+}
+
+// refill adds the tokens accumulated since the last call. It must be called
+// with l.mu held.
+func (l *bandwidthLimiter) refill() {
+	now := l.now()
+	if l.last.IsZero() {
+		l.tokens = float64(l.burst)
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+}
+
+// read performs a single rate limited read of p from r. Tokens for the bytes
+// read are taken before returning, so that callers observing the result (such
+// as progress callbacks) see bytes arrive at the limited rate.
+func (l *bandwidthLimiter) read(r io.Reader, p []byte) (int, error) {
+	if len(p) > l.burst {
+		p = p[:l.burst]
+	}
+
+	n, err := r.Read(p)
+	if n > 0 {
+		l.waitN(n)
+	}
+	return n, err
+}
+
+// limitedReader is an io.Reader whose reads are limited by a shared
+// bandwidthLimiter.
+type limitedReader struct {
+	r io.Reader
+	l *bandwidthLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	return r.l.read(r.r, p)
+}
+
+// limitedBody is a tools.ReadSeekCloser whose reads are limited by a shared
+// bandwidthLimiter.
+type limitedBody struct {
+	tools.ReadSeekCloser
+	l *bandwidthLimiter
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	return b.l.read(b.ReadSeekCloser, p)
+}
+
+// newLimitedReader wraps r so that its reads are limited by l. If l is nil,
+// r is returned unmodified.
+func newLimitedReader(r io.Reader, l *bandwidthLimiter) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{r: r, l: l}
+}
+
+// newLimitedBody wraps body so that its reads are limited by l. If l is nil,
+// body is returned unmodified.
+func newLimitedBody(body tools.ReadSeekCloser, l *bandwidthLimiter) tools.ReadSeekCloser {
+	if l == nil {
+		return body
+	}
+	return &limitedBody{ReadSeekCloser: body, l: l}
+}
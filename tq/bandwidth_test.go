@@ -0,0 +1,72 @@
+package tq
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBandwidthLimiter(bytesPerSecond uint64) (*bandwidthLimiter, *time.Duration) {
+	var clock time.Time = time.Unix(0, 0)
+	var slept time.Duration
+
+	l := newBandwidthLimiter(bytesPerSecond)
+	l.now = func() time.Time { return clock }
+	l.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+	return l, &slept
+}
+
+func TestBandwidthLimiterZeroIsUnlimited(t *testing.T) {
+	assert.Nil(t, newBandwidthLimiter(0))
+
+	r := bytes.NewReader([]byte("abc"))
+	assert.Equal(t, io.Reader(r), newLimitedReader(r, nil))
+}
+
+func TestBandwidthLimiterAllowsInitialBurst(t *testing.T) {
+	l, slept := newTestBandwidthLimiter(100)
+
+	l.waitN(100)
+	assert.Equal(t, time.Duration(0), *slept)
+}
+
+func TestBandwidthLimiterDelaysExcessReads(t *testing.T) {
+	l, slept := newTestBandwidthLimiter(100)
+
+	r := newLimitedReader(bytes.NewReader(make([]byte, 350)), l)
+	n, err := io.Copy(io.Discard, r)
+	require.Nil(t, err)
+
+	assert.EqualValues(t, 350, n)
+	assert.Equal(t, 2500*time.Millisecond, *slept)
+}
+
+func TestBandwidthLimiterSharesRateAcrossReaders(t *testing.T) {
+	l, slept := newTestBandwidthLimiter(100)
+
+	r1 := newLimitedReader(bytes.NewReader(make([]byte, 100)), l)
+	r2 := newLimitedReader(bytes.NewReader(make([]byte, 100)), l)
+
+	_, err := io.Copy(io.Discard, r1)
+	require.Nil(t, err)
+	_, err = io.Copy(io.Discard, r2)
+	require.Nil(t, err)
+
+	assert.Equal(t, time.Second, *slept)
+}
+
+func TestBandwidthLimiterCapsReadsAtBurst(t *testing.T) {
+	l, _ := newTestBandwidthLimiter(10)
+
+	r := newLimitedReader(bytes.NewReader(make([]byte, 100)), l)
+	n, err := r.Read(make([]byte, 100))
+	require.Nil(t, err)
+	assert.Equal(t, 10, n)
+}
@@ -9,18 +9,49 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfshttp"
 	"github.com/git-lfs/git-lfs/v3/tools"
 	"github.com/git-lfs/git-lfs/v3/tr"
 	"github.com/rubyist/tracerx"
 )
 
+// acceptEncodingKey is the "Accept-Encoding" header sent with each download
+// of a batch, which lets a server or CDN send objects with a content coding
+// which it prefers. Only "gzip" and "deflate" may be accepted, since those are
+// the codings which can be decoded.
+const acceptEncodingKey = "lfs.transfer.acceptencoding"
+
 // Adapter for basic HTTP downloads, includes resuming via HTTP Range
 type basicDownloadAdapter struct {
 	*adapterBase
 }
 
+// acceptEncoding returns the "Accept-Encoding" header to send with downloads,
+// as given by the "lfs.transfer.acceptencoding" setting, or an empty string if
+// none should be sent.
+func (a *basicDownloadAdapter) acceptEncoding() string {
+	v, ok := a.apiClient.GitEnv().Get(acceptEncodingKey)
+	if !ok || len(v) == 0 {
+		return ""
+	}
+
+	var codings []string
+	for _, coding := range strings.Split(v, ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		switch coding {
+		case "gzip", "deflate":
+			codings = append(codings, coding)
+		case "":
+		default:
+			tracerx.Printf("xfer: ignoring unsupported coding %q in %s", coding, acceptEncodingKey)
+		}
+	}
+	return strings.Join(codings, ", ")
+}
+
 func (a *basicDownloadAdapter) tempDir() string {
 	// Shared with the SSH adapter.
 	d := filepath.Join(a.fs.LFSStorageDir, "incomplete")
@@ -62,7 +93,11 @@ func (a *basicDownloadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progr
 	}
 
 	// Attempt to resume download. No error checking here. If we fail, we'll simply download from the start
-	tools.RobustRename(a.downloadFilename(t), f.Name())
+	// A compressed download can't be resumed, since the Range header would
+	// refer to the compressed body.
+	if rel, _ := t.Rel("download"); rel == nil || len(rel.Compression) == 0 {
+		tools.RobustRename(a.downloadFilename(t), f.Name())
+	}
 
 	// Open temp file. It is either empty or partially downloaded
 	f, err = os.OpenFile(f.Name(), os.O_RDWR, 0644)
@@ -71,7 +106,10 @@ func (a *basicDownloadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progr
 	}
 
 	// Read any existing data into hash
-	hash := tools.NewLfsContentHash()
+	hash, err := tools.NewLfsContentHashFor(a.hashAlgo)
+	if err != nil {
+		return err
+	}
 	fromByte, err := io.Copy(hash, f)
 	if err != nil {
 		return err
@@ -96,7 +134,11 @@ func (a *basicDownloadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progr
 
 	err = a.download(t, cb, authOkFunc, f, fromByte, hash)
 
-	if err != nil {
+	if err != nil && a.cancelled() {
+		// The queue was cancelled, so discard the partial file
+		// rather than keeping it to resume from.
+		tracerx.Printf("xfer: download of %q cancelled, removing partial file", t.Oid)
+	} else if err != nil {
 		f.Close()
 		// Rename file so next download can resume from where we stopped.
 		// No error checking here, if rename fails then file will be deleted and there just will be no download resuming
@@ -120,6 +162,9 @@ func (a *basicDownloadAdapter) download(t *Transfer, cb ProgressCallback, authOk
 	if rel == nil {
 		return errors.Errorf(tr.Tr.Get("Object %s not found on the server.", t.Oid))
 	}
+	if err := checkCompression(rel.Compression); err != nil {
+		return err
+	}
 
 	req, err := a.newHTTPRequest("GET", rel)
 	if err != nil {
@@ -131,6 +176,18 @@ func (a *basicDownloadAdapter) download(t *Transfer, cb ProgressCallback, authOk
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", fromByte, t.Size-1))
 	}
 
+	// Only ask for an encoded response when downloading from the start,
+	// so that the Range header of a resumed download refers to the
+	// object's own content, and not to objects which are already
+	// compressed with zstd.
+	var encoded bool
+	if fromByte == 0 && len(rel.Compression) == 0 {
+		if accept := a.acceptEncoding(); len(accept) > 0 {
+			req.Header.Set("Accept-Encoding", accept)
+			encoded = true
+		}
+	}
+
 	req = a.apiClient.LogRequest(req, "lfs.data.download")
 	res, err := a.makeRequest(t, req)
 	if err != nil {
@@ -152,6 +209,15 @@ func (a *basicDownloadAdapter) download(t *Transfer, cb ProgressCallback, authOk
 			return a.download(t, cb, authOkFunc, dlFile, 0, nil)
 		}
 
+		// A status code of 403 from an action which has since expired
+		// likely means that the href is no longer valid, so request a
+		// fresh action for the object.
+		if res.StatusCode == 403 {
+			if at, expired := rel.IsExpiredWithin(objectExpirationToTransfer); expired {
+				return errors.NewRetriableError(&ActionExpiredErr{Rel: "download", At: at})
+			}
+		}
+
 		// Special-cae status code 429 - retry after certain time
 		if res.StatusCode == 429 {
 			retLaterErr := errors.NewRetriableLaterError(err, res.Header.Get("Retry-After"))
@@ -223,15 +289,43 @@ func (a *basicDownloadAdapter) download(t *Transfer, cb ProgressCallback, authOk
 		authOkFunc()
 	}
 
-	var hasher *tools.HashingReader
-	httpReader := tools.NewRetriableReader(res.Body)
+	if encoded {
+		if err := lfshttp.DecodeResponseBody(res); err != nil {
+			return err
+		}
+		defer res.Body.Close()
+	}
 
-	if fromByte > 0 && hash != nil {
-		// pre-load hashing reader with previous content
-		hasher = tools.NewHashingReaderPreloadHash(httpReader, hash)
-	} else {
-		hasher = tools.NewHashingReader(httpReader)
+	var httpReader io.Reader = newLimitedReader(tools.NewRetriableReader(res.Body), a.bandwidth)
+	size := res.ContentLength
+	if res.Uncompressed {
+		// The length of an encoded response is not that of the
+		// object, which is what progress is reported in terms of.
+		size = t.Size
+	}
+	if rel.Compression == CompressionZstd {
+		// The object is verified, and progress reported, in terms of
+		// its uncompressed content.
+		dec, err := newDecompressingReader(httpReader, t.Size)
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		httpReader = dec
+		size = t.Size
+	}
+
+	if fromByte == 0 || hash == nil {
+		if hash, err = tools.NewLfsContentHashFor(a.hashAlgo); err != nil {
+			return err
+		}
 	}
+	// pre-load hashing reader with any previous content
+	hasher := tools.NewHashingReaderPreloadHash(httpReader, hash)
+	// Stop reading one byte past the expected size, since the content
+	// can then no longer match, rather than downloading all of an
+	// oversized response.
+	limited := io.LimitReader(hasher, t.Size-fromByte+1)
 
 	dlfilename := dlFile.Name()
 	// Wrap callback to give name context
@@ -241,13 +335,36 @@ func (a *basicDownloadAdapter) download(t *Transfer, cb ProgressCallback, authOk
 		}
 		return nil
 	}
-	written, err := tools.CopyWithCallback(dlFile, hasher, res.ContentLength, ccb)
+	written, err := tools.CopyWithCallback(dlFile, limited, size, ccb)
 	if err != nil {
 		return errors.Wrapf(err, tr.Tr.Get("cannot write data to temporary file %q", dlfilename))
 	}
 
 	if actual := hasher.Hash(); actual != t.Oid {
-		return errors.New(tr.Tr.Get("expected OID %s, got %s after %d bytes written", t.Oid, actual, written))
+		// A short read leaves a partial file which may be resumed
+		// later, but if we received the full content and it is still
+		// incorrect, discard it so that it isn't resumed from again.
+		// If it was resumed, the partial data we started with may be
+		// what is corrupt, so try again from the beginning.
+		if fromByte+written >= t.Size {
+			if _, err := dlFile.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			if err := dlFile.Truncate(0); err != nil {
+				return err
+			}
+			if fromByte > 0 {
+				tracerx.Printf("xfer: resumed download of %q from byte %d failed verification; re-downloading from start", t.Oid, fromByte)
+				// Take back the progress reported so far, so that
+				// the content isn't counted twice.
+				if cb != nil {
+					cb(t.Name, t.Size, 0, -int(fromByte+written))
+				}
+				// Authentication has already been signalled above.
+				return a.download(t, cb, nil, dlFile, 0, nil)
+			}
+		}
+		return errors.NewIntegrityError(errors.New(tr.Tr.Get("expected OID %s, got %s after %d bytes written", t.Oid, actual, written)))
 	}
 
 	if err := dlFile.Close(); err != nil {
@@ -279,7 +396,7 @@ func configureBasicDownloadAdapter(m *concreteManifest) {
 
 func (a *basicDownloadAdapter) makeRequest(t *Transfer, req *http.Request) (*http.Response, error) {
 	res, err := a.doHTTP(t, req)
-	if errors.IsAuthError(err) && len(req.Header.Get("Authorization")) == 0 {
+	if errors.IsAuthError(err) && len(req.Header.Get("Authorization")) == 0 && a.sendsCredentials(t, req) {
 		return a.makeRequest(t, req)
 	}
 
@@ -1,6 +1,7 @@
 package tq
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -50,18 +51,44 @@ func (a *basicUploadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progres
 		return errors.Errorf(tr.Tr.Get("No upload action for object: %s", t.Oid))
 	}
 
+	if len(rel.Parts) > 0 {
+		return a.uploadParts(t, rel, cb, authOkFunc)
+	}
+
+	if err := checkCompression(rel.Compression); err != nil {
+		return err
+	}
+	if rel.Compression == CompressionZstd {
+		return a.uploadCompressed(t, rel, cb, authOkFunc)
+	}
+
+	// If the server gave us an upload session, ask it how much of the
+	// object it already has from an earlier attempt, so that only the
+	// rest need be sent.
+	var offset int64
+	if len(rel.UploadSession) > 0 {
+		offset = a.committedOffset(t, rel)
+	}
+
 	req, err := a.newHTTPRequest("PUT", rel)
 	if err != nil {
 		return err
 	}
 
+	if len(rel.UploadSession) > 0 {
+		req.Header.Set("X-Upload-Session", rel.UploadSession)
+	}
+	if offset > 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, t.Size-1, t.Size))
+	}
+
 	if req.Header.Get("Transfer-Encoding") == "chunked" {
 		req.TransferEncoding = []string{"chunked"}
 	} else {
-		req.Header.Set("Content-Length", strconv.FormatInt(t.Size, 10))
+		req.Header.Set("Content-Length", strconv.FormatInt(t.Size-offset, 10))
 	}
 
-	req.ContentLength = t.Size
+	req.ContentLength = t.Size - offset
 
 	f, err := os.OpenFile(t.Path, os.O_RDONLY, 0644)
 	if err != nil {
@@ -69,20 +96,28 @@ func (a *basicUploadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progres
 	}
 	defer f.Close()
 
-	if err := a.setContentTypeFor(req, f); err != nil {
+	if err := a.setContentTypeFor(req, t, f); err != nil {
 		return err
 	}
 
+	if offset > 0 {
+		a.Trace("xfer: resuming upload of %q from %d", t.Oid, offset)
+		advanceCallbackProgress(cb, t, offset)
+	}
+
 	// Ensure progress callbacks made while uploading
 	// Wrap callback to give name context
 	ccb := func(totalSize int64, readSoFar int64, readSinceLast int) error {
 		if cb != nil {
-			return cb(t.Name, totalSize, readSoFar, readSinceLast)
+			return cb(t.Name, totalSize, offset+readSoFar, readSinceLast)
 		}
 		return nil
 	}
 
-	cbr := tools.NewFileBodyWithCallback(f, t.Size, ccb)
+	// Limit reads beneath the callback so that progress reflects
+	// the throttled rate rather than how quickly the file is read.
+	body := newLimitedBody(a.uploadBody(f, t, offset), a.bandwidth)
+	cbr := tools.NewBodyWithCallback(body, t.Size, ccb)
 	var reader lfsapi.ReadSeekCloser = cbr
 
 	// Signal auth was ok on first read; this frees up other workers to start
@@ -96,7 +131,7 @@ func (a *basicUploadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progres
 	req.Body = reader
 
 	req = a.apiClient.LogRequest(req, "lfs.data.upload")
-	res, err := a.makeRequest(t, req)
+	res, err := a.makeRequest(t, req, offset)
 	if err != nil {
 		if errors.IsUnprocessableEntityError(err) {
 			// If we got an HTTP 422, we do _not_ want to retry the
@@ -118,6 +153,9 @@ func (a *basicUploadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progres
 		if perr := cbr.ResetProgress(); perr != nil {
 			err = errors.Wrap(err, perr.Error())
 		}
+		if offset > 0 && cb != nil {
+			cb(t.Name, t.Size, 0, -int(offset))
+		}
 
 		if res == nil {
 			// We encountered a network or similar error which caused us
@@ -155,16 +193,50 @@ func (a *basicUploadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb Progres
 	return verifyUpload(a.apiClient, a.remote, t)
 }
 
-func (a *adapterBase) setContentTypeFor(req *http.Request, r io.ReadSeeker) error {
+// committedOffset asks the server how many bytes of the object it has already
+// received in the upload session of the given action, returning zero if the
+// server does not say, in which case the whole object is uploaded.
+func (a *basicUploadAdapter) committedOffset(t *Transfer, rel *Action) int64 {
+	req, err := a.newHTTPRequest("HEAD", rel)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("X-Upload-Session", rel.UploadSession)
+
+	res, err := a.doHTTP(t, req)
+	if err != nil {
+		a.Trace("xfer: unable to query upload session for %q: %s", t.Oid, err)
+		return 0
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	offHdr := res.Header.Get("X-Upload-Offset")
+	offset, err := strconv.ParseInt(offHdr, 10, 64)
+	if err != nil || offset < 0 || offset >= t.Size {
+		return 0
+	}
+	return offset
+}
+
+// uploadBody returns a body which reads the given file from offset onwards.
+func (a *basicUploadAdapter) uploadBody(f *os.File, t *Transfer, offset int64) tools.ReadSeekCloser {
+	if offset == 0 {
+		return tools.NewFileBody(f)
+	}
+	return &sectionBody{SectionReader: io.NewSectionReader(f, offset, t.Size-offset)}
+}
+
+func (a *adapterBase) setContentTypeFor(req *http.Request, t *Transfer, r io.ReadSeeker) error {
 	uc := config.NewURLConfig(a.apiClient.GitEnv())
 	disabled := !uc.Bool("lfs", req.URL.String(), "contenttype", true)
 	if len(req.Header.Get("Content-Type")) != 0 {
 		return nil
 	}
 
-	var contentType string
+	contentType := a.contentTypeForName(t.Name)
 
-	if !disabled {
+	if !disabled && contentType == "" {
 		buffer := make([]byte, 512)
 		n, err := r.Read(buffer)
 		if err != nil && err != io.EOF {
@@ -185,6 +257,18 @@ func (a *adapterBase) setContentTypeFor(req *http.Request, r io.ReadSeeker) erro
 	return nil
 }
 
+// contentTypeForName returns the Content-Type given by the
+// "lfs.contenttype.<ext>" setting for the extension of the given file name,
+// or the empty string if there is none.
+func (a *adapterBase) contentTypeForName(name string) string {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if len(ext) == 0 {
+		return ""
+	}
+	contentType, _ := a.apiClient.GitEnv().Get("lfs.contenttype." + strings.ToLower(ext))
+	return contentType
+}
+
 // startCallbackReader is a reader wrapper which calls a function as soon as the
 // first Read() call is made. This callback is only made once
 type startCallbackReader struct {
@@ -225,9 +309,9 @@ func configureBasicUploadAdapter(m *concreteManifest) {
 	})
 }
 
-func (a *basicUploadAdapter) makeRequest(t *Transfer, req *http.Request) (*http.Response, error) {
+func (a *basicUploadAdapter) makeRequest(t *Transfer, req *http.Request, offset int64) (*http.Response, error) {
 	res, err := a.doHTTP(t, req)
-	if errors.IsAuthError(err) && len(req.Header.Get("Authorization")) == 0 {
+	if errors.IsAuthError(err) && len(req.Header.Get("Authorization")) == 0 && a.sendsCredentials(t, req) {
 		// Construct a new body with just the raw file and no callbacks. Since
 		// all progress tracking happens when the net.http code copies our
 		// request body into a new request, we can safely make this request
@@ -236,8 +320,8 @@ func (a *basicUploadAdapter) makeRequest(t *Transfer, req *http.Request) (*http.
 		f, _ := os.OpenFile(t.Path, os.O_RDONLY, 0644)
 		defer f.Close()
 
-		req.Body = tools.NewBodyWithCallback(f, t.Size, nil)
-		return a.makeRequest(t, req)
+		req.Body = tools.NewBodyWithCallback(a.uploadBody(f, t, offset), t.Size, nil)
+		return a.makeRequest(t, req, offset)
 	}
 
 	return res, err
@@ -0,0 +1,124 @@
+package tq
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+// uploadCompressed uploads t to rel.Href with its body compressed by zstd, as
+// the server asked. The object is compressed to a temporary file first, so
+// that the compressed size can be sent and the body rewound for retries.
+// Progress is reported in terms of the uncompressed object.
+func (a *basicUploadAdapter) uploadCompressed(t *Transfer, rel *Action, cb ProgressCallback, authOkFunc func()) error {
+	f, err := compressToTemp(t.Path, a.tempDir())
+	if err != nil {
+		return errors.Wrap(err, tr.Tr.Get("basic upload"))
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := stat.Size()
+	a.Trace("xfer: uploading %q compressed from %d to %d bytes", t.Oid, t.Size, size)
+
+	req, err := a.newHTTPRequest("PUT", rel)
+	if err != nil {
+		return err
+	}
+
+	// The content type describes the object, not its encoding.
+	orig, err := os.Open(t.Path)
+	if err != nil {
+		return errors.Wrap(err, tr.Tr.Get("basic upload"))
+	}
+	err = a.setContentTypeFor(req, t, orig)
+	orig.Close()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Encoding", CompressionZstd)
+	req.Header.Set("Content-Length", strconv.FormatInt(size, 10))
+	req.ContentLength = size
+
+	var reported int64
+	ccb := func(totalSize int64, readSoFar int64, readSinceLast int) error {
+		if cb == nil || size == 0 {
+			return nil
+		}
+		uncompressed := int64(float64(readSoFar) / float64(size) * float64(t.Size))
+		delta := uncompressed - reported
+		reported = uncompressed
+		return cb(t.Name, t.Size, uncompressed, int(delta))
+	}
+
+	body := newLimitedBody(tools.NewFileBody(f), a.bandwidth)
+	var reader lfsapi.ReadSeekCloser = tools.NewBodyWithCallback(body, size, ccb)
+	if authOkFunc != nil {
+		reader = newStartCallbackReader(reader, func() error {
+			authOkFunc()
+			return nil
+		})
+	}
+	req.Body = reader
+
+	req = a.apiClient.LogRequest(req, "lfs.data.upload")
+	res, err := a.doHTTP(t, req)
+	if err != nil {
+		if errors.IsUnprocessableEntityError(err) {
+			return err
+		}
+
+		// Rewind the progress made so far, since the retry will
+		// upload the whole object again.
+		if reported > 0 && cb != nil {
+			cb(t.Name, t.Size, 0, -int(reported))
+		}
+
+		if res == nil {
+			return errors.NewRetriableError(err)
+		}
+		if res.StatusCode == 429 {
+			retLaterErr := errors.NewRetriableLaterError(err, res.Header.Get("Retry-After"))
+			if retLaterErr != nil {
+				return retLaterErr
+			}
+		}
+		return errors.NewRetriableError(err)
+	}
+
+	// A status code of 403 likely means that an authentication token for the
+	// upload has expired. This can be safely retried.
+	if res.StatusCode == 403 {
+		err = errors.New(tr.Tr.Get("Received status %d", res.StatusCode))
+		return errors.NewRetriableError(err)
+	}
+
+	if res.StatusCode > 299 {
+		return errors.Wrapf(nil, tr.Tr.Get("Invalid status for %s %s: %d",
+			req.Method,
+			strings.SplitN(req.URL.String(), "?", 2)[0],
+			res.StatusCode,
+		))
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	// Account for any rounding in the progress reported above.
+	advanceCallbackProgress(cb, t, t.Size-reported)
+
+	return verifyUpload(a.apiClient, a.remote, t)
+}
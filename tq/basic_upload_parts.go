@@ -0,0 +1,255 @@
+package tq
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+)
+
+const defaultMaxPartRetries = 8
+
+// uploadParts performs a multipart upload of t, in which each of the parts
+// given in rel is uploaded separately, and concurrently, before a request is
+// made to rel.Href to ask the server to reassemble the object. A part which
+// fails to upload is retried on its own, without restarting the parts which
+// have already been uploaded.
+func (a *basicUploadAdapter) uploadParts(t *Transfer, rel *Action, cb ProgressCallback, authOkFunc func()) error {
+	if err := validateActionParts(t, rel.Parts); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(t.Path, os.O_RDONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, tr.Tr.Get("basic upload"))
+	}
+	defer f.Close()
+
+	progress := &partProgress{t: t, cb: cb}
+
+	// Upload the first part on its own, so that other workers are only
+	// freed to start once we know that authentication was successful.
+	if err := a.uploadPartWithRetries(t, f, rel.Parts, 0, progress); err != nil {
+		return err
+	}
+	if authOkFunc != nil {
+		authOkFunc()
+	}
+
+	if rest := rel.Parts[1:]; len(rest) > 0 {
+		workers := tools.MinInt(tools.MaxInt(a.concurrency, 1), len(rest))
+		indexes := make(chan int, len(rest))
+		for i := range rest {
+			indexes <- i + 1
+		}
+		close(indexes)
+
+		errs := make(chan error, len(rest))
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					if err := a.uploadPartWithRetries(t, f, rel.Parts, i, progress); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+
+	if err := a.completeParts(t, rel); err != nil {
+		return err
+	}
+
+	return verifyUpload(a.apiClient, a.remote, t)
+}
+
+// validateActionParts returns an error if any of the given parts lie outside
+// of the object being transferred.
+func validateActionParts(t *Transfer, parts []*ActionPart) error {
+	for i, part := range parts {
+		if part == nil || part.Pos < 0 || part.Size < 0 || part.Pos+part.Size > t.Size {
+			return errors.New(tr.Tr.Get("Invalid part %d of upload for object: %s", i+1, t.Oid))
+		}
+	}
+	return nil
+}
+
+// uploadPartWithRetries uploads the part at index i of parts, retrying it up
+// to the configured number of times if it fails with a retriable error.
+func (a *basicUploadAdapter) uploadPartWithRetries(t *Transfer, f *os.File, parts []*ActionPart, i int, progress *partProgress) error {
+	maxRetries := a.maxRetries
+	if maxRetries < 1 {
+		maxRetries = defaultMaxPartRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := a.uploadPart(t, f, parts[i], progress)
+		if err == nil {
+			tracerx.Printf("xfer: uploaded part %d of %d for %q", i+1, len(parts), t.Oid)
+			return nil
+		}
+
+		if attempt >= maxRetries || !errors.IsRetriableError(err) {
+			return err
+		}
+		tracerx.Printf("xfer: retrying part %d of %d for %q: %s", i+1, len(parts), t.Oid, err)
+	}
+}
+
+// uploadPart uploads a single part of a multipart upload. Errors which may be
+// resolved by uploading the part again are returned as retriable errors.
+func (a *basicUploadAdapter) uploadPart(t *Transfer, f *os.File, part *ActionPart, progress *partProgress) error {
+	req, err := a.newHTTPRequest("PUT", &Action{Href: part.Href, Header: part.Header})
+	if err != nil {
+		return err
+	}
+
+	if req.Header.Get("Transfer-Encoding") == "chunked" {
+		req.TransferEncoding = []string{"chunked"}
+	} else {
+		req.Header.Set("Content-Length", strconv.FormatInt(part.Size, 10))
+	}
+	req.ContentLength = part.Size
+
+	if len(req.Header.Get("Content-Type")) == 0 {
+		contentType := a.contentTypeForName(t.Name)
+		if len(contentType) == 0 {
+			contentType = defaultContentType
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	section := &sectionBody{SectionReader: io.NewSectionReader(f, part.Pos, part.Size)}
+	cbr := tools.NewBodyWithCallback(newLimitedBody(section, a.bandwidth), part.Size, progress.callback)
+	req.Body = cbr
+
+	req = a.apiClient.LogRequest(req, "lfs.data.upload")
+	res, err := a.doHTTP(t, req)
+	if errors.IsAuthError(err) && len(req.Header.Get("Authorization")) == 0 {
+		if perr := cbr.ResetProgress(); perr != nil {
+			return perr
+		}
+		if _, serr := cbr.Seek(0, io.SeekStart); serr != nil {
+			return serr
+		}
+		res, err = a.doHTTP(t, req)
+	}
+
+	if err != nil {
+		if errors.IsUnprocessableEntityError(err) {
+			return err
+		}
+
+		if perr := cbr.ResetProgress(); perr != nil {
+			err = errors.Wrap(err, perr.Error())
+		}
+		return errors.NewRetriableError(err)
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	if res.StatusCode > 299 {
+		if perr := cbr.ResetProgress(); perr != nil {
+			return perr
+		}
+
+		err := errors.New(tr.Tr.Get("Invalid status for %s %s: %d",
+			req.Method,
+			strings.SplitN(req.URL.String(), "?", 2)[0],
+			res.StatusCode,
+		))
+		return errors.NewRetriableError(err)
+	}
+
+	return nil
+}
+
+// completeParts asks the server to reassemble the object from the parts which
+// have been uploaded.
+func (a *basicUploadAdapter) completeParts(t *Transfer, rel *Action) error {
+	req, err := a.newHTTPRequest("POST", &Action{Href: rel.Href, Header: rel.Header})
+	if err != nil {
+		return err
+	}
+
+	err = lfsapi.MarshalToRequest(req, struct {
+		Oid  string `json:"oid"`
+		Size int64  `json:"size"`
+	}{Oid: t.Oid, Size: t.Size})
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+	req.Header.Set("Accept", "application/vnd.git-lfs+json")
+
+	req = a.apiClient.LogRequest(req, "lfs.data.upload")
+	res, err := a.doHTTP(t, req)
+	if err != nil {
+		if errors.IsUnprocessableEntityError(err) {
+			return err
+		}
+		return errors.NewRetriableError(err)
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	if res.StatusCode > 299 {
+		return errors.Wrapf(nil, tr.Tr.Get("Invalid status for %s %s: %d",
+			req.Method,
+			strings.SplitN(req.URL.String(), "?", 2)[0],
+			res.StatusCode,
+		))
+	}
+	return nil
+}
+
+// partProgress combines the progress of each of the parts of a multipart
+// upload, which are uploaded concurrently, into the progress of the object.
+type partProgress struct {
+	t  *Transfer
+	cb ProgressCallback
+
+	read int64
+	mu   sync.Mutex
+}
+
+func (p *partProgress) callback(totalSize int64, readSoFar int64, readSinceLast int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.read += int64(readSinceLast)
+	if p.cb != nil {
+		return p.cb(p.t.Name, p.t.Size, p.read, readSinceLast)
+	}
+	return nil
+}
+
+// sectionBody is a tools.ReadSeekCloser for a single part of a file, which
+// may be read concurrently with other parts of the same file.
+type sectionBody struct {
+	*io.SectionReader
+}
+
+func (b *sectionBody) Close() error {
+	return nil
+}
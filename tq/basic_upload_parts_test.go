@@ -0,0 +1,26 @@
+package tq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateActionParts(t *testing.T) {
+	tr := &Transfer{Oid: "oid", Size: 25}
+
+	assert.NoError(t, validateActionParts(tr, []*ActionPart{
+		{Href: "https://example.com/1", Pos: 0, Size: 10},
+		{Href: "https://example.com/2", Pos: 10, Size: 10},
+		{Href: "https://example.com/3", Pos: 20, Size: 5},
+	}))
+
+	for desc, part := range map[string]*ActionPart{
+		"nil part":        nil,
+		"negative pos":    {Pos: -1, Size: 10},
+		"negative size":   {Pos: 0, Size: -1},
+		"past end of obj": {Pos: 20, Size: 10},
+	} {
+		assert.Error(t, validateActionParts(tr, []*ActionPart{part}), desc)
+	}
+}
@@ -0,0 +1,224 @@
+package tq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/git-lfs/git-lfs/v3/fs"
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// batchCacheTTL is the length of time after which a cached batch
+	// response is discarded, even if none of its actions have expired.
+	batchCacheTTL = 24 * time.Hour
+)
+
+// batchCacheEntry records a batch response which the server gave an ETag.
+type batchCacheEntry struct {
+	ETag string `json:"etag"`
+	// RequestedAt is when the request for the response was made, from
+	// which the "expires_in" property of its actions is measured.
+	RequestedAt time.Time       `json:"requested_at"`
+	Response    json.RawMessage `json:"response"`
+}
+
+// batchCache records the responses to batch requests which the server gave an
+// ETag, one per file in the LFS storage directory, so that when an identical
+// request is made again the server may be asked with an "If-None-Match"
+// header whether the response has changed, and the cached response reused if
+// it has not.
+//
+// A response whose actions carry credentials, in a header or in their hrefs, is
+// never recorded.  The files are nonetheless only readable by their owner, and
+// a response is discarded once any of its actions are about to expire.
+type batchCache struct {
+	dir string
+	now func() time.Time
+}
+
+// newBatchCache returns a batch cache stored in the LFS storage directory of
+// the given filesystem, or nil if there is none.
+func newBatchCache(f *fs.Filesystem) *batchCache {
+	if f == nil || len(f.LFSStorageDir) == 0 {
+		return nil
+	}
+	return &batchCache{
+		dir: filepath.Join(f.LFSStorageDir, "batchcache"),
+		now: time.Now,
+	}
+}
+
+// batchCacheKey returns the key under which the response to the given batch
+// request to the given endpoint URL is cached.
+func batchCacheKey(url string, bReq *batchRequest) (string, error) {
+	by, err := json.Marshal(bReq)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(url))
+	h.Write([]byte{0})
+	h.Write(by)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get returns the cached response for the given key, if there is one which
+// has not expired.  An expired response is removed from the cache.
+func (c *batchCache) Get(key string) (*batchCacheEntry, bool) {
+	path := c.path(key)
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	e := &batchCacheEntry{}
+	if err := json.Unmarshal(by, e); err != nil {
+		tracerx.Printf("tq: ignoring invalid cached batch response %s: %s", path, err)
+		os.Remove(path)
+		return nil, false
+	}
+	if c.expired(e) {
+		os.Remove(path)
+		return nil, false
+	}
+	return e, true
+}
+
+// Set records the given batch response body, which the server gave the ETag
+// "etag" in response to a request made at "requestedAt", and removes any
+// responses which were cached more than batchCacheTTL ago.
+func (c *batchCache) Set(key, etag string, requestedAt time.Time, body []byte) error {
+	by, err := json.Marshal(&batchCacheEntry{
+		ETag:        etag,
+		RequestedAt: requestedAt,
+		Response:    json.RawMessage(body),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	c.removeStale()
+
+	tmp, err := os.CreateTemp(c.dir, "batchcache")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(by); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Remove removes any cached response for the given key.
+func (c *batchCache) Remove(key string) {
+	os.Remove(c.path(key))
+}
+
+// removeStale removes the files of responses which were cached more than
+// batchCacheTTL ago.
+func (c *batchCache) removeStale() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && c.now().Sub(info.ModTime()) > batchCacheTTL {
+			os.Remove(filepath.Join(c.dir, entry.Name()))
+		}
+	}
+}
+
+// expired returns whether the given cached response is too old to be used, or
+// any of its actions expire before they could be used to transfer an object.
+func (c *batchCache) expired(e *batchCacheEntry) bool {
+	if len(e.ETag) == 0 || c.now().Sub(e.RequestedAt) > batchCacheTTL {
+		return true
+	}
+
+	bRes, err := e.response()
+	if err != nil {
+		return true
+	}
+	for _, obj := range bRes.Objects {
+		for _, set := range []ActionSet{obj.Actions, obj.Links} {
+			for _, a := range set {
+				if _, expired := a.IsExpiredWithin(objectExpirationToTransfer); expired {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// batchResponseHasCredentials returns whether any action of the given batch
+// response, or any part of one, has headers, which may include an
+// "Authorization" header, or an href with a query string or user information,
+// as a pre-signed URL does.  Such a response must not be recorded on disk.
+func batchResponseHasCredentials(bRes *BatchResponse) bool {
+	for _, obj := range bRes.Objects {
+		for _, set := range []ActionSet{obj.Actions, obj.Links} {
+			for _, a := range set {
+				if hasCredentials(a.Href, a.Header) {
+					return true
+				}
+				for _, part := range a.Parts {
+					if hasCredentials(part.Href, part.Header) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasCredentials(href string, header map[string]string) bool {
+	if len(header) > 0 {
+		return true
+	}
+	u, err := url.Parse(href)
+	return err != nil || u.User != nil || len(u.RawQuery) > 0
+}
+
+func (c *batchCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// response returns the cached batch response, whose actions are treated as
+// having been created when the original request was made.
+func (e *batchCacheEntry) response() (*BatchResponse, error) {
+	bRes := &BatchResponse{}
+	if err := json.Unmarshal(e.Response, bRes); err != nil {
+		return nil, err
+	}
+	for _, obj := range bRes.Objects {
+		for _, set := range []ActionSet{obj.Actions, obj.Links} {
+			for _, a := range set {
+				a.createdAt = e.RequestedAt
+			}
+		}
+	}
+	return bRes, nil
+}
@@ -0,0 +1,83 @@
+package tq
+
+import (
+	"io"
+	"os"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionZstd is the name of the compression algorithm with which
+	// object bodies are compressed when the server's actions ask for it.
+	CompressionZstd = "zstd"
+)
+
+// checkCompression returns an error if the given compression algorithm, as
+// named by an action, is not one which is supported.
+func checkCompression(compression string) error {
+	if len(compression) == 0 || compression == CompressionZstd {
+		return nil
+	}
+	return errors.New(tr.Tr.Get("unsupported compression: %q", compression))
+}
+
+// compressToTemp writes the zstd-compressed contents of the file at the given
+// path to a new temporary file in dir, and returns the file positioned at its
+// start.
+func compressToTemp(path, dir string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tmp, err := os.CreateTemp(dir, "zstd")
+	if err != nil {
+		return nil, err
+	}
+
+	err = func() error {
+		enc, err := zstd.NewWriter(tmp, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(enc, f); err != nil {
+			enc.Close()
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		_, err = tmp.Seek(0, io.SeekStart)
+		return err
+	}()
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return tmp, nil
+}
+
+// decompressingReader decompresses a zstd-compressed body, returning no more
+// than limit bytes so that a corrupt or malicious body can not produce more
+// data than the object should contain.
+type decompressingReader struct {
+	dec *zstd.Decoder
+	io.Reader
+}
+
+func newDecompressingReader(r io.Reader, limit int64) (*decompressingReader, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &decompressingReader{dec: dec, Reader: io.LimitReader(dec, limit)}, nil
+}
+
+func (r *decompressingReader) Close() {
+	r.dec.Close()
+}
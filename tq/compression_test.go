@@ -0,0 +1,60 @@
+package tq
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompression(t *testing.T) {
+	assert.Nil(t, checkCompression(""))
+	assert.Nil(t, checkCompression(CompressionZstd))
+	assert.NotNil(t, checkCompression("gzip"))
+}
+
+func TestCompressToTempRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	contents := []byte(strings.Repeat("2024-01-01 INFO compressible log line\n", 1000))
+	path := filepath.Join(dir, "object")
+	require.Nil(t, os.WriteFile(path, contents, 0644))
+
+	f, err := compressToTemp(path, dir)
+	require.Nil(t, err)
+	defer f.Close()
+
+	stat, err := f.Stat()
+	require.Nil(t, err)
+	assert.Less(t, stat.Size(), int64(len(contents)))
+
+	dec, err := newDecompressingReader(f, int64(len(contents)))
+	require.Nil(t, err)
+	defer dec.Close()
+
+	actual, err := io.ReadAll(dec)
+	require.Nil(t, err)
+	assert.Equal(t, contents, actual)
+}
+
+func TestDecompressingReaderLimitsOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "object")
+	require.Nil(t, os.WriteFile(path, bytes.Repeat([]byte("a"), 4096), 0644))
+
+	f, err := compressToTemp(path, dir)
+	require.Nil(t, err)
+	defer f.Close()
+
+	dec, err := newDecompressingReader(f, 16)
+	require.Nil(t, err)
+	defer dec.Close()
+
+	actual, err := io.ReadAll(dec)
+	require.Nil(t, err)
+	assert.Len(t, actual, 16)
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/git-lfs/git-lfs/v3/errors"
 	"github.com/git-lfs/git-lfs/v3/fs"
@@ -28,6 +31,12 @@ type customAdapter struct {
 	concurrent          bool
 	originalConcurrency int
 	standalone          bool
+
+	// env is the names of the environment variables which the process
+	// requires, and envValues their "NAME=value" pairs, which are passed
+	// to each process explicitly.
+	env       []string
+	envValues []string
 }
 
 // Struct to capture stderr and write to trace
@@ -111,6 +120,14 @@ type customAdapterResponseMessage struct {
 
 func (a *customAdapter) Begin(cfg AdapterConfig, cb ProgressCallback) error {
 	a.originalConcurrency = cfg.ConcurrentTransfers()
+
+	if len(a.env) > 0 {
+		envValues, err := a.requiredEnv(cfg.APIClient().OSEnv())
+		if err != nil {
+			return err
+		}
+		a.envValues = envValues
+	}
 	if a.concurrent {
 		// Use common workers impl, but downgrade workers to number of processes
 		return a.adapterBase.Begin(cfg, cb)
@@ -120,6 +137,29 @@ func (a *customAdapter) Begin(cfg AdapterConfig, cb ProgressCallback) error {
 	return a.adapterBase.Begin(&customAdapterConfig{AdapterConfig: cfg}, cb)
 }
 
+// requiredEnv returns the "NAME=value" pairs of the environment variables
+// which the process requires, or an error naming those which are not set, so
+// that the transfer fails before any process is started.
+func (a *customAdapter) requiredEnv(osEnv Env) ([]string, error) {
+	var values, missing []string
+	for _, name := range a.env {
+		value, ok := osEnv.Get(name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		values = append(values, name+"="+value)
+	}
+
+	if len(missing) > 0 {
+		return nil, errors.New(tr.Tr.GetN(
+			"custom transfer adapter %q requires the environment variable %s, which is not set",
+			"custom transfer adapter %q requires the environment variables %s, which are not set",
+			len(missing), a.name, strings.Join(missing, ", ")))
+	}
+	return values, nil
+}
+
 func (a *customAdapter) WorkerStarting(workerNum int) (interface{}, error) {
 	// Start a process per worker
 	// If concurrent = false we have already dialled back workers to 1
@@ -129,6 +169,9 @@ func (a *customAdapter) WorkerStarting(workerNum int) (interface{}, error) {
 	if err != nil {
 		return nil, errors.New(tr.Tr.Get("failed to find custom transfer command %q remote: %v", a.path, err))
 	}
+	// The environment is shared by every command, so copy it rather
+	// than appending to it in place.
+	cmd.Env = append(append([]string(nil), cmd.Env...), a.envValues...)
 	outp, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, errors.New(tr.Tr.Get("failed to get stdout for custom transfer command %q remote: %v", a.path, err))
@@ -312,7 +355,7 @@ func (a *customAdapter) DoTransfer(ctx interface{}, t *Transfer, cb ProgressCall
 			}
 			if a.direction == Download {
 				// So we don't have to blindly trust external providers, check SHA
-				if err = tools.VerifyFileHash(t.Oid, resp.Path); err != nil {
+				if err = tools.VerifyFileHash(a.hashAlgo, t.Oid, resp.Path); err != nil {
 					return errors.New(tr.Tr.Get("downloaded file failed checks: %v", err))
 				}
 				// Move file to final location
@@ -340,8 +383,16 @@ func (a *customAdapter) DoTransfer(ctx interface{}, t *Transfer, cb ProgressCall
 	return nil
 }
 
-func newCustomAdapter(f *fs.Filesystem, name string, dir Direction, path, args string, concurrent, standalone bool) *customAdapter {
-	c := &customAdapter{newAdapterBase(f, name, dir, nil), path, args, concurrent, 3, standalone}
+func newCustomAdapter(f *fs.Filesystem, name string, dir Direction, path, args string, concurrent, standalone bool, env []string) *customAdapter {
+	c := &customAdapter{
+		adapterBase:         newAdapterBase(f, name, dir, nil),
+		path:                path,
+		args:                args,
+		concurrent:          concurrent,
+		originalConcurrency: 3,
+		standalone:          standalone,
+		env:                 env,
+	}
 	// self implements impl
 	c.transferImpl = c
 	return c
@@ -354,7 +405,7 @@ const (
 func configureDefaultCustomAdapters(git Env, m *concreteManifest) {
 	newfunc := func(name string, dir Direction) Adapter {
 		standalone := m.standaloneTransferAgent != ""
-		return newCustomAdapter(m.fs, standaloneFileName, dir, "git-lfs", "standalone-file", false, standalone)
+		return newCustomAdapter(m.fs, standaloneFileName, dir, "git-lfs", "standalone-file", false, standalone, nil)
 	}
 	m.RegisterNewAdapterFunc(standaloneFileName, Download, newfunc)
 	m.RegisterNewAdapterFunc(standaloneFileName, Upload, newfunc)
@@ -364,6 +415,13 @@ func configureDefaultCustomAdapters(git Env, m *concreteManifest) {
 func configureCustomAdapters(git Env, m *concreteManifest) {
 	configureDefaultCustomAdapters(git, m)
 
+	// Adapters found in lfs.customtransfer.dir are registered first, so
+	// that an adapter configured explicitly under the same name takes
+	// precedence.
+	if dir, ok := git.Get("lfs.customtransfer.dir"); ok && len(dir) > 0 {
+		configureCustomAdapterDir(dir, m)
+	}
+
 	pathRegex := regexp.MustCompile(`lfs.customtransfer.([^.]+).path`)
 	for k, _ := range git.All() {
 		match := pathRegex.FindStringSubmatch(k)
@@ -377,24 +435,107 @@ func configureCustomAdapters(git Env, m *concreteManifest) {
 		args, _ := git.Get(fmt.Sprintf("lfs.customtransfer.%s.args", name))
 		concurrent := git.Bool(fmt.Sprintf("lfs.customtransfer.%s.concurrent", name), true)
 		direction, _ := git.Get(fmt.Sprintf("lfs.customtransfer.%s.direction", name))
-		if len(direction) == 0 {
-			direction = "both"
-		} else {
-			direction = strings.ToLower(direction)
+		env := parseCustomAdapterEnv(git.GetAll(fmt.Sprintf("lfs.customtransfer.%s.env", name)))
+
+		registerCustomAdapter(m, name, path, args, concurrent, direction, env)
+	}
+}
+
+// customAdapterManifest describes a custom adapter found in the directory
+// given by lfs.customtransfer.dir. Its fields correspond to the
+// lfs.customtransfer.<name> settings of the same names.
+type customAdapterManifest struct {
+	Args       string   `json:"args"`
+	Concurrent *bool    `json:"concurrent"`
+	Direction  string   `json:"direction"`
+	Env        []string `json:"env"`
+}
+
+// configureCustomAdapterDir registers each executable file in dir as a custom
+// adapter named after the file. If a file named after the adapter with a
+// ".json" extension also exists, it is read as a customAdapterManifest.
+func configureCustomAdapterDir(dir string, m *concreteManifest) {
+	dir, err := tools.ExpandPath(dir, false)
+	if err != nil {
+		tracerx.Printf("custom transfer: cannot expand %q: %v", dir, err)
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		tracerx.Printf("custom transfer: cannot read directory %q: %v", dir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		filename := entry.Name()
+		if strings.HasPrefix(filename, ".") || filepath.Ext(filename) == ".json" {
+			continue
 		}
 
-		// Separate closure for each since we need to capture vars above
-		newfunc := func(name string, dir Direction) Adapter {
-			standalone := m.standaloneTransferAgent != ""
-			return newCustomAdapter(m.fs, name, dir, path, args, concurrent, standalone)
+		path := filepath.Join(dir, filename)
+		stat, err := os.Stat(path)
+		if err != nil || !stat.Mode().IsRegular() {
+			continue
 		}
 
-		if direction == "download" || direction == "both" {
-			m.RegisterNewAdapterFunc(name, Download, newfunc)
+		name := filename
+		if runtime.GOOS == "windows" {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		} else if stat.Mode()&0111 == 0 {
+			continue
 		}
-		if direction == "upload" || direction == "both" {
-			m.RegisterNewAdapterFunc(name, Upload, newfunc)
+
+		manifest := &customAdapterManifest{}
+		if data, err := os.ReadFile(filepath.Join(dir, name+".json")); err == nil {
+			if err := json.Unmarshal(data, manifest); err != nil {
+				tracerx.Printf("custom transfer: ignoring %q, invalid manifest: %v", path, err)
+				continue
+			}
+		}
+
+		concurrent := true
+		if manifest.Concurrent != nil {
+			concurrent = *manifest.Concurrent
 		}
+
+		tracerx.Printf("custom transfer: found adapter %q at %q", name, path)
+		registerCustomAdapter(m, name, path, manifest.Args, concurrent, manifest.Direction, parseCustomAdapterEnv(manifest.Env))
+	}
+}
+
+// parseCustomAdapterEnv returns the names of the environment variables given
+// by the values of lfs.customtransfer.<name>.env, each of which may list
+// several names separated by commas or whitespace.
+func parseCustomAdapterEnv(values []string) []string {
+	var env []string
+	for _, value := range values {
+		env = append(env, strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})...)
+	}
+	return env
+}
+
+// registerCustomAdapter registers a custom adapter with the given name for
+// the given direction, which is "download", "upload", or "both" if empty.
+func registerCustomAdapter(m *concreteManifest, name, path, args string, concurrent bool, direction string, env []string) {
+	if len(direction) == 0 {
+		direction = "both"
+	} else {
+		direction = strings.ToLower(direction)
+	}
+
+	newfunc := func(name string, dir Direction) Adapter {
+		standalone := m.standaloneTransferAgent != ""
+		return newCustomAdapter(m.fs, name, dir, path, args, concurrent, standalone, env)
+	}
+
+	if direction == "download" || direction == "both" {
+		m.RegisterNewAdapterFunc(name, Download, newfunc)
+	}
+	if direction == "upload" || direction == "both" {
+		m.RegisterNewAdapterFunc(name, Upload, newfunc)
 	}
 }
 
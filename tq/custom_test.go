@@ -1,6 +1,9 @@
 package tq
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/git-lfs/git-lfs/v3/lfsapi"
@@ -114,3 +117,115 @@ func TestCustomTransferBothConfig(t *testing.T) {
 	assert.Equal(t, cu.args, args, "args should be correct")
 	assert.Equal(t, cu.concurrent, true, "concurrent should be set")
 }
+
+func TestCustomTransferDirConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "testdir"), []byte("#!/bin/sh\n"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "testdir.json"),
+		[]byte(`{"args": "--whatever", "concurrent": false, "direction": "download"}`), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "testdefault"), []byte("#!/bin/sh\n"), 0755))
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.customtransfer.dir": dir,
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	d := m.NewDownloadAdapter("testdir")
+	cd, _ := d.(*customAdapter)
+	require.NotNil(t, cd, "Download adapter should be customAdapter")
+	assert.Equal(t, filepath.Join(dir, "testdir"), cd.path, "Path should be correct")
+	assert.Equal(t, "--whatever", cd.args, "args should be read from the manifest")
+	assert.Equal(t, false, cd.concurrent, "concurrent should be read from the manifest")
+
+	u := m.NewUploadAdapter("testdir")
+	cu, _ := u.(*customAdapter)
+	assert.Nil(t, cu, "Upload adapter should NOT be custom (default to basic)")
+
+	u = m.NewUploadAdapter("testdefault")
+	cu, _ = u.(*customAdapter)
+	require.NotNil(t, cu, "Upload adapter should be customAdapter")
+	assert.Equal(t, filepath.Join(dir, "testdefault"), cu.path, "Path should be correct")
+	assert.Equal(t, "", cu.args, "args should be blank")
+	assert.Equal(t, true, cu.concurrent, "concurrent should be defaulted")
+
+	assert.NotContains(t, m.GetDownloadAdapterNames(), "testdir.json")
+}
+
+func TestCustomTransferDirSkipsNonExecutables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files are not marked executable on Windows")
+	}
+
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "testplain"), []byte("data\n"), 0644))
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.customtransfer.dir": dir,
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	assert.NotContains(t, m.GetDownloadAdapterNames(), "testplain")
+	assert.NotContains(t, m.GetUploadAdapterNames(), "testplain")
+}
+
+func TestCustomTransferDirConfigIsOverridden(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "testoverride"), []byte("#!/bin/sh\n"), 0755))
+
+	path := "/path/to/binary"
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.customtransfer.dir":               dir,
+		"lfs.customtransfer.testoverride.path": path,
+		"lfs.customtransfer.testoverride.args": "--explicit",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	d := m.NewDownloadAdapter("testoverride")
+	cd, _ := d.(*customAdapter)
+	require.NotNil(t, cd, "Download adapter should be customAdapter")
+	assert.Equal(t, path, cd.path, "Path should be taken from the configuration")
+	assert.Equal(t, "--explicit", cd.args, "args should be taken from the configuration")
+}
+
+func TestCustomTransferEnvConfig(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, map[string]string{
+		"TEST_TOKEN": "secret",
+		"TEST_USER":  "user",
+	}, map[string]string{
+		"lfs.customtransfer.testenv.path": "/path/to/binary",
+		"lfs.customtransfer.testenv.env":  "TEST_TOKEN, TEST_USER",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	d := m.NewDownloadAdapter("testenv")
+	cd, _ := d.(*customAdapter)
+	require.NotNil(t, cd, "Download adapter should be customAdapter")
+	assert.Equal(t, []string{"TEST_TOKEN", "TEST_USER"}, cd.env)
+
+	values, err := cd.requiredEnv(cli.OSEnv())
+	require.Nil(t, err)
+	assert.Equal(t, []string{"TEST_TOKEN=secret", "TEST_USER=user"}, values)
+}
+
+func TestCustomTransferEnvMissing(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, map[string]string{
+		"TEST_USER": "user",
+	}, map[string]string{
+		"lfs.customtransfer.testenv.path": "/path/to/binary",
+		"lfs.customtransfer.testenv.env":  "TEST_TOKEN TEST_USER TEST_HOST",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	u := m.NewUploadAdapter("testenv")
+	cu, _ := u.(*customAdapter)
+	require.NotNil(t, cu, "Upload adapter should be customAdapter")
+
+	err = cu.Begin(&adapterConfig{apiClient: cli, concurrentTransfers: 1}, nil)
+	require.NotNil(t, err)
+	assert.Equal(t, `custom transfer adapter "testenv" requires the environment variables TEST_TOKEN, TEST_HOST, which are not set`, err.Error())
+}
@@ -0,0 +1,179 @@
+package tq
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tools/delta"
+	"github.com/git-lfs/git-lfs/v3/tr"
+)
+
+const (
+	DeltaAdapterName = "delta"
+)
+
+var deltaBaseRE = regexp.MustCompile(`\A[0-9a-f]{64}\z`)
+
+// Adapter for uploads of deltas against objects which the server already has,
+// which falls back to basic uploads when no delta can be sent.
+type deltaUploadAdapter struct {
+	*basicUploadAdapter
+}
+
+func (a *deltaUploadAdapter) DoTransfer(ctx interface{}, t *Transfer, cb ProgressCallback, authOkFunc func()) error {
+	rel, err := t.Rel("delta")
+	if err != nil {
+		return err
+	}
+	if rel == nil || len(rel.Base) == 0 {
+		a.Trace("xfer: no delta base for %q, uploading in full", t.Oid)
+		return a.basicUploadAdapter.DoTransfer(ctx, t, cb, authOkFunc)
+	}
+
+	deltaFile, err := a.deltaFor(t, rel.Base)
+	if err != nil {
+		a.Trace("xfer: unable to compute delta of %q against %q, uploading in full: %s", t.Oid, rel.Base, err)
+		return a.basicUploadAdapter.DoTransfer(ctx, t, cb, authOkFunc)
+	}
+	defer func() {
+		deltaFile.Close()
+		os.Remove(deltaFile.Name())
+	}()
+
+	stat, err := deltaFile.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() >= t.Size {
+		a.Trace("xfer: delta of %q against %q is no smaller, uploading in full", t.Oid, rel.Base)
+		return a.basicUploadAdapter.DoTransfer(ctx, t, cb, authOkFunc)
+	}
+
+	a.Trace("xfer: uploading delta of %q against %q (%d of %d bytes)", t.Oid, rel.Base, stat.Size(), t.Size)
+	req, err := a.newHTTPRequest("PUT", rel)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", delta.MediaType)
+	req.Header.Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
+	req.ContentLength = stat.Size()
+
+	var reader lfsapi.ReadSeekCloser = newLimitedBody(tools.NewFileBody(deltaFile), a.bandwidth)
+	if authOkFunc != nil {
+		reader = newStartCallbackReader(reader, func() error {
+			authOkFunc()
+			return nil
+		})
+	}
+	req.Body = reader
+
+	req = a.apiClient.LogRequest(req, "lfs.data.upload")
+	res, err := a.doHTTP(t, req)
+	if err != nil {
+		if errors.IsUnprocessableEntityError(err) {
+			// The server could not apply the delta, perhaps
+			// because it no longer has the base, so send the
+			// whole object instead. Authentication has already
+			// been signalled by reading the delta.
+			a.Trace("xfer: delta of %q rejected, uploading in full: %s", t.Oid, err)
+			return a.basicUploadAdapter.DoTransfer(ctx, t, cb, nil)
+		}
+		if res == nil {
+			return errors.NewRetriableError(err)
+		}
+		if res.StatusCode == 429 {
+			retLaterErr := errors.NewRetriableLaterError(err, res.Header.Get("Retry-After"))
+			if retLaterErr != nil {
+				return retLaterErr
+			}
+		}
+		return errors.NewRetriableError(err)
+	}
+
+	// A status code of 403 likely means that an authentication token for the
+	// upload has expired. This can be safely retried.
+	if res.StatusCode == 403 {
+		err = errors.New(tr.Tr.Get("Received status %d", res.StatusCode))
+		return errors.NewRetriableError(err)
+	}
+
+	if res.StatusCode > 299 {
+		return errors.Wrapf(nil, tr.Tr.Get("Invalid status for %s %s: %d",
+			req.Method,
+			strings.SplitN(req.URL.String(), "?", 2)[0],
+			res.StatusCode,
+		))
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	// The whole object has been transferred, even though only the delta
+	// was sent.
+	advanceCallbackProgress(cb, t, t.Size)
+
+	return verifyUpload(a.apiClient, a.remote, t)
+}
+
+// deltaFor writes a delta of the object being transferred against the local
+// copy of the given base object to a temporary file, and returns the file
+// positioned at its start.
+func (a *deltaUploadAdapter) deltaFor(t *Transfer, base string) (*os.File, error) {
+	if !deltaBaseRE.MatchString(base) {
+		return nil, errors.New(tr.Tr.Get("invalid delta base: %q", base))
+	}
+
+	baseFile, err := os.Open(a.fs.ObjectPathname(base))
+	if err != nil {
+		return nil, err
+	}
+	defer baseFile.Close()
+
+	stat, err := baseFile.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(t.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tmp, err := os.CreateTemp(a.fs.TempDir(), "delta")
+	if err != nil {
+		return nil, err
+	}
+	if err := delta.Diff(baseFile, stat.Size(), f, tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return tmp, nil
+}
+
+func configureDeltaAdapter(m *concreteManifest) {
+	m.RegisterNewAdapterFunc(DeltaAdapterName, Upload, func(name string, dir Direction) Adapter {
+		switch dir {
+		case Upload:
+			du := &deltaUploadAdapter{&basicUploadAdapter{newAdapterBase(m.fs, name, dir, nil)}}
+			// self implements impl
+			du.transferImpl = du
+			return du
+		case Download:
+			panic(tr.Tr.Get("Should never ask this function to download"))
+		}
+		return nil
+	})
+}
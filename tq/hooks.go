@@ -0,0 +1,52 @@
+package tq
+
+// TransferHooks holds functions which a TransferQueue calls as its transfer
+// adapter processes each object, so that a program embedding the queue can
+// observe transfers without parsing its progress output. Any of the functions
+// may be nil.
+//
+// The functions are called from the adapter's worker goroutines, so they may
+// be called concurrently for different objects and must be safe for such use.
+// For any one object, they are called in order from a single goroutine. An
+// object whose transfer fails may be retried, in which case its hooks are
+// called again for each further attempt.
+type TransferHooks struct {
+	// OnTransferStart is called when a worker begins to transfer the
+	// object with the given OID, name and size.
+	OnTransferStart func(oid, name string, size int64)
+	// OnTransferProgress is called with the total number of bytes of the
+	// object with the given OID which have been transferred so far.
+	OnTransferProgress func(oid string, bytes int64)
+	// OnTransferComplete is called when a worker has finished an attempt
+	// to transfer the object with the given OID, with the error which
+	// caused the attempt to fail, if any.
+	OnTransferComplete func(oid string, err error)
+}
+
+func (h *TransferHooks) start(t *Transfer) {
+	if h != nil && h.OnTransferStart != nil {
+		h.OnTransferStart(t.Oid, t.Name, t.Size)
+	}
+}
+
+func (h *TransferHooks) complete(t *Transfer, err error) {
+	if h != nil && h.OnTransferComplete != nil {
+		h.OnTransferComplete(t.Oid, err)
+	}
+}
+
+// progressCallback returns a ProgressCallback for the given transfer which
+// calls OnTransferProgress before passing each update on to cb.
+func (h *TransferHooks) progressCallback(t *Transfer, cb ProgressCallback) ProgressCallback {
+	if h == nil || h.OnTransferProgress == nil {
+		return cb
+	}
+
+	return func(name string, totalSize, readSoFar int64, readSinceLast int) error {
+		h.OnTransferProgress(t.Oid, readSoFar)
+		if cb == nil {
+			return nil
+		}
+		return cb(name, totalSize, readSoFar, readSinceLast)
+	}
+}
@@ -0,0 +1,102 @@
+package tq
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hooksTestImpl struct{}
+
+func (i *hooksTestImpl) WorkerStarting(workerNum int) (interface{}, error) {
+	return nil, nil
+}
+
+func (i *hooksTestImpl) WorkerEnding(workerNum int, ctx interface{}) {}
+
+func (i *hooksTestImpl) DoTransfer(ctx interface{}, t *Transfer, cb ProgressCallback, authOkFunc func()) error {
+	if authOkFunc != nil {
+		authOkFunc()
+	}
+	if t.Oid == "bad" {
+		return errors.New("transfer failed")
+	}
+	cb(t.Name, t.Size, t.Size/2, int(t.Size/2))
+	cb(t.Name, t.Size, t.Size, int(t.Size-t.Size/2))
+	return nil
+}
+
+func TestAdapterCallsTransferHooks(t *testing.T) {
+	c, err := lfsapi.NewClient(nil)
+	require.Nil(t, err)
+
+	var (
+		mu       sync.Mutex
+		events   = make(map[string][]string)
+		progress = make(map[string][]int64)
+		failures = make(map[string]error)
+		reported int
+	)
+	hooks := &TransferHooks{
+		OnTransferStart: func(oid, name string, size int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			events[oid] = append(events[oid], "start "+name)
+		},
+		OnTransferProgress: func(oid string, bytes int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			events[oid] = append(events[oid], "progress")
+			progress[oid] = append(progress[oid], bytes)
+		},
+		OnTransferComplete: func(oid string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			events[oid] = append(events[oid], "complete")
+			failures[oid] = err
+		},
+	}
+
+	a := newAdapterBase(nil, "test", Download, &hooksTestImpl{})
+	require.Nil(t, a.Begin(&adapterConfig{
+		apiClient:           c,
+		concurrentTransfers: 2,
+		hooks:               hooks,
+	}, func(name string, totalSize, readSoFar int64, readSinceLast int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		reported++
+		return nil
+	}))
+
+	results := a.Add(
+		&Transfer{Oid: "good", Name: "good.dat", Size: 10},
+		&Transfer{Oid: "bad", Name: "bad.dat", Size: 10},
+	)
+	for range results {
+	}
+	a.End()
+
+	assert.Equal(t, []string{"start good.dat", "progress", "progress", "complete"}, events["good"])
+	assert.Equal(t, []int64{5, 10}, progress["good"])
+	assert.Nil(t, failures["good"])
+
+	assert.Equal(t, []string{"start bad.dat", "complete"}, events["bad"])
+	assert.EqualError(t, failures["bad"], "transfer failed")
+
+	// The progress callback given to the adapter is still called.
+	assert.Equal(t, 2, reported)
+}
+
+func TestNilTransferHooks(t *testing.T) {
+	var hooks *TransferHooks
+	tr := &Transfer{Oid: "a"}
+
+	hooks.start(tr)
+	hooks.complete(tr, nil)
+	assert.Nil(t, hooks.progressCallback(tr, nil))
+}
@@ -1,6 +1,7 @@
 package tq
 
 import (
+	"strconv"
 	"strings"
 	"sync"
 
@@ -8,6 +9,8 @@ import (
 	"github.com/git-lfs/git-lfs/v3/fs"
 	"github.com/git-lfs/git-lfs/v3/lfsapi"
 	"github.com/git-lfs/git-lfs/v3/ssh"
+	"github.com/git-lfs/git-lfs/v3/tools"
+	"github.com/git-lfs/git-lfs/v3/tools/humanize"
 	"github.com/rubyist/tracerx"
 )
 
@@ -130,19 +133,41 @@ type concreteManifest struct {
 	// maxRetries is the maximum number of retries a single object can
 	// attempt to make before it will be dropped. maxRetryDelay is the maximum
 	// time in seconds to wait between retry attempts when using backoff.
-	maxRetries              int
+	maxRetries int
+	// maxRetriesPerObject is the maximum number of times the transfer of
+	// a single object is retried after it fails, as opposed to the
+	// retries of the Batch API requests which include it.
+	maxRetriesPerObject     int
 	maxRetryDelay           int
 	concurrentTransfers     int
 	basicTransfersOnly      bool
 	standaloneTransferAgent string
 	tusTransfersAllowed     bool
-	downloadAdapterFuncs    map[string]NewAdapterFunc
-	uploadAdapterFuncs      map[string]NewAdapterFunc
-	fs                      *fs.Filesystem
-	apiClient               *lfsapi.Client
-	sshTransfer             *ssh.SSHTransfer
-	batchClientAdapter      BatchClient
-	mu                      sync.Mutex
+	// bandwidth limits the aggregate throughput of all adapters created
+	// from this manifest, or is nil if throughput is unlimited.
+	bandwidth *bandwidthLimiter
+	// hashAlgo is the hash algorithm used to compute the OIDs of the
+	// objects being transferred.
+	hashAlgo string
+	// chunkSize is the preferred size of each part of a multipart
+	// upload, or zero if multipart uploads are not requested.
+	chunkSize int64
+	// compression is the algorithm with which object bodies may be
+	// compressed, or empty if they are never compressed.
+	compression string
+	// omitRef is whether to leave the ref out of batch requests, for
+	// servers which reject it.
+	omitRef bool
+	// adapters caches the transfer adapters chosen by the server, or is
+	// nil if the cache is disabled.
+	adapters             *adapterCache
+	downloadAdapterFuncs map[string]NewAdapterFunc
+	uploadAdapterFuncs   map[string]NewAdapterFunc
+	fs                   *fs.Filesystem
+	apiClient            *lfsapi.Client
+	sshTransfer          *ssh.SSHTransfer
+	batchClientAdapter   BatchClient
+	mu                   sync.Mutex
 }
 
 func (m *concreteManifest) APIClient() *lfsapi.Client {
@@ -161,6 +186,24 @@ func (m *concreteManifest) ConcurrentTransfers() int {
 	return m.concurrentTransfers
 }
 
+// concurrentTransfersFor returns the number of concurrent transfers to make
+// to the given endpoint URL, which is given by the most specific
+// "lfs.<url>.concurrenttransfers" setting matching the URL, if any, and
+// otherwise by ConcurrentTransfers().
+func (m *concreteManifest) concurrentTransfersFor(rawurl string) int {
+	if m.sshTransfer != nil && !m.sshTransfer.IsMultiplexingEnabled() {
+		return m.concurrentTransfers
+	}
+
+	uc := config.NewURLConfig(m.apiClient.GitEnv())
+	if v, ok := uc.Get("lfs", rawurl, "concurrenttransfers"); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return m.concurrentTransfers
+}
+
 func (m *concreteManifest) IsStandaloneTransfer() bool {
 	return m.standaloneTransferAgent != ""
 }
@@ -172,6 +215,30 @@ func (m *concreteManifest) batchClient() BatchClient {
 	return m.batchClientAdapter
 }
 
+func (m *concreteManifest) bandwidthLimiter() *bandwidthLimiter {
+	return m.bandwidth
+}
+
+func (m *concreteManifest) hashAlgorithm() string {
+	return m.hashAlgo
+}
+
+func (m *concreteManifest) uploadChunkSize() int64 {
+	return m.chunkSize
+}
+
+func (m *concreteManifest) transferCompression() string {
+	return m.compression
+}
+
+func (m *concreteManifest) sendRef() bool {
+	return !m.omitRef
+}
+
+func (m *concreteManifest) adapterCache() *adapterCache {
+	return m.adapters
+}
+
 func (m *concreteManifest) Upgrade() *concreteManifest {
 	return m
 }
@@ -200,37 +267,79 @@ func newConcreteManifest(f *fs.Filesystem, apiClient *lfsapi.Client, operation,
 		useSSHMultiplexing = sshTransfer.IsMultiplexingEnabled()
 	}
 
+	client := &tqClient{Client: apiClient}
 	m := &concreteManifest{
 		fs:                   f,
 		apiClient:            apiClient,
-		batchClientAdapter:   &tqClient{Client: apiClient},
+		batchClientAdapter:   client,
 		downloadAdapterFuncs: make(map[string]NewAdapterFunc),
 		uploadAdapterFuncs:   make(map[string]NewAdapterFunc),
 		sshTransfer:          sshTransfer,
+		hashAlgo:             tools.HashAlgorithmSHA256,
 	}
 
-	var tusAllowed bool
+	var tusAllowed, deltaAllowed bool
 	if git := apiClient.GitEnv(); git != nil {
 		if v := git.Int("lfs.transfer.maxretries", 0); v > 0 {
 			m.maxRetries = v
 		}
+		if v := git.Int("lfs.transfer.maxretriesperobject", 0); v > 0 {
+			m.maxRetriesPerObject = v
+		}
 		if v := git.Int("lfs.transfer.maxretrydelay", -1); v > -1 {
 			m.maxRetryDelay = v
 		}
 		if v := git.Int("lfs.concurrenttransfers", 0); v > 0 {
 			m.concurrentTransfers = v
 		}
+		if v, ok := git.Get("lfs.transfer.maxbytespersecond"); ok && len(v) > 0 {
+			if n, err := humanize.ParseBytes(v); err != nil {
+				tracerx.Printf("tq: invalid lfs.transfer.maxbytespersecond %q: %s", v, err)
+			} else {
+				m.bandwidth = newBandwidthLimiter(n)
+			}
+		}
+		if v, ok := git.Get("lfs.transfer.chunksize"); ok && len(v) > 0 {
+			if n, err := humanize.ParseBytes(v); err != nil || int64(n) < 0 {
+				tracerx.Printf("tq: invalid lfs.transfer.chunksize %q", v)
+			} else {
+				m.chunkSize = int64(n)
+			}
+		}
+		if v, ok := git.Get("lfs.hashalgo"); ok && len(v) > 0 {
+			m.hashAlgo = v
+		}
+		if v, ok := git.Get("lfs.transfer.compression"); ok && len(v) > 0 {
+			switch v {
+			case CompressionZstd:
+				m.compression = v
+			case "none":
+			default:
+				tracerx.Printf("tq: invalid lfs.transfer.compression %q", v)
+			}
+		}
+		m.omitRef = !git.Bool("lfs.transfer.sendref", true)
+		if git.Bool("lfs.transfer.adaptercache", false) {
+			m.adapters = newAdapterCache(f)
+		}
+		if git.Bool("lfs.transfer.batchcache", false) {
+			client.cache = newBatchCache(f)
+		}
 		m.basicTransfersOnly = git.Bool("lfs.basictransfersonly", false)
 		m.standaloneTransferAgent = findStandaloneTransfer(
 			apiClient, operation, remote,
 		)
 		tusAllowed = git.Bool("lfs.tustransfers", false)
+		deltaAllowed = git.Bool("lfs.deltatransfers", false)
 		configureCustomAdapters(git, m)
 	}
 
 	if m.maxRetries < 1 {
 		m.maxRetries = defaultMaxRetries
 	}
+	if m.maxRetriesPerObject < 1 {
+		m.maxRetriesPerObject = m.maxRetries
+	}
 	if m.maxRetryDelay < 1 {
 		m.maxRetryDelay = defaultMaxRetryDelay
 	}
@@ -256,6 +365,9 @@ func newConcreteManifest(f *fs.Filesystem, apiClient *lfsapi.Client, operation,
 	if tusAllowed {
 		configureTusAdapter(m)
 	}
+	if deltaAllowed {
+		configureDeltaAdapter(m)
+	}
 	configureSSHAdapter(m)
 	return m
 }
@@ -17,6 +17,7 @@ func TestManifestIsConfigurable(t *testing.T) {
 
 	m := NewManifest(nil, cli, "", "")
 	assert.Equal(t, 3, m.MaxRetries())
+	assert.Equal(t, 3, m.Upgrade().maxRetriesPerObject)
 }
 
 func TestManifestClampsValidValues(t *testing.T) {
@@ -38,3 +39,77 @@ func TestManifestIgnoresNonInts(t *testing.T) {
 	m := NewManifest(nil, cli, "", "")
 	assert.Equal(t, 8, m.MaxRetries())
 }
+
+func TestManifestParsesMaxBytesPerSecond(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.transfer.maxbytespersecond": "5MB",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	require.NotNil(t, m.Upgrade().bandwidthLimiter())
+	assert.Equal(t, float64(5000000), m.Upgrade().bandwidthLimiter().rate)
+}
+
+func TestManifestMaxBytesPerSecondZeroIsUnlimited(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.transfer.maxbytespersecond": "0",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	assert.Nil(t, m.Upgrade().bandwidthLimiter())
+}
+
+func TestManifestParsesChunkSize(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.transfer.chunksize": "64MB",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	assert.Equal(t, int64(64000000), m.Upgrade().uploadChunkSize())
+}
+
+func TestManifestConcurrentTransfersForURL(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.concurrenttransfers":                             "16",
+		"lfs.https://slow.example.com.concurrenttransfers":    "4",
+		"lfs.https://invalid.example.com.concurrenttransfers": "not_an_int",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	assert.Equal(t, 16, m.ConcurrentTransfers())
+	assert.Equal(t, 4, m.Upgrade().concurrentTransfersFor("https://slow.example.com/repo.git/info/lfs"))
+	assert.Equal(t, 16, m.Upgrade().concurrentTransfersFor("https://fast.example.com/repo.git/info/lfs"))
+	assert.Equal(t, 16, m.Upgrade().concurrentTransfersFor("https://invalid.example.com/repo.git/info/lfs"))
+}
+
+func TestManifestConcurrentTransfersForURLDefault(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.https://fast.example.com.concurrenttransfers": "32",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	assert.Equal(t, 32, m.Upgrade().concurrentTransfersFor("https://fast.example.com/repo.git/info/lfs"))
+	assert.Equal(t, 8, m.Upgrade().concurrentTransfersFor("https://other.example.com/repo.git/info/lfs"))
+}
+
+func TestManifestDeltaTransfers(t *testing.T) {
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, cli, "", "")
+	assert.NotContains(t, m.GetUploadAdapterNames(), DeltaAdapterName)
+
+	cli, err = lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.deltatransfers": "true",
+	}))
+	require.Nil(t, err)
+
+	m = NewManifest(nil, cli, "", "")
+	assert.Contains(t, m.GetUploadAdapterNames(), DeltaAdapterName)
+	assert.NotContains(t, m.GetDownloadAdapterNames(), DeltaAdapterName)
+}
@@ -1,10 +1,12 @@
 package tq
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,16 +33,42 @@ type Meter struct {
 	lastAvg           time.Time
 	estimatedFiles    int32
 	paused            uint32
-	fileIndex         map[string]int64 // Maps a file name to its transfer number
+	fileIndex         map[string]int64  // Maps a file name to its transfer number
+	fileOid           map[string]string // Maps a file name to its OID
 	fileIndexMutex    *sync.Mutex
 	updates           chan *tasklog.Update
 	cfg               *config.Configuration
 
-	DryRun    bool
-	Logger    *tools.SyncWriter
-	Direction Direction
+	DryRun bool
+	Logger *tools.SyncWriter
+	// EventLogger, if non-nil, is written a JSON progress event, one per
+	// line, each time the number of bytes transferred changes.
+	EventLogger *tools.SyncWriter
+	Direction   Direction
 }
 
+// rateSmoothing is the weight given to the most recent sample of the transfer
+// rate when updating its exponential moving average, so that the displayed
+// rate follows changes in speed without jumping about with every sample.
+const rateSmoothing = 0.3
+
+// progressEvent is the JSON progress event written to a Meter's EventLogger.
+type progressEvent struct {
+	Oid        string `json:"oid"`
+	Name       string `json:"name"`
+	Direction  string `json:"direction"`
+	BytesSoFar int64  `json:"bytes_so_far"`
+	BytesTotal int64  `json:"bytes_total"`
+}
+
+var (
+	// eventLoggers holds the writers opened for each file descriptor by
+	// EventLoggerToFd, since closing any *os.File for a descriptor,
+	// including when one is garbage collected, closes the descriptor.
+	eventLoggers   = make(map[int]*tools.SyncWriter)
+	eventLoggersMu sync.Mutex
+)
+
 type env interface {
 	Get(key string) (val string, ok bool)
 }
@@ -54,33 +82,76 @@ func (m *Meter) LoggerFromEnv(os env) *tools.SyncWriter {
 }
 
 func (m *Meter) LoggerToFile(name string) *tools.SyncWriter {
-	printErr := func(err string) {
-		fmt.Fprintln(os.Stderr, tr.Tr.Get("Error creating progress logger: %s", err))
-	}
-
 	if !filepath.IsAbs(name) {
-		printErr(tr.Tr.Get("GIT_LFS_PROGRESS must be an absolute path"))
+		printLoggerErr(tr.Tr.Get("GIT_LFS_PROGRESS must be an absolute path"))
 		return nil
 	}
 
 	if err := tools.MkdirAll(filepath.Dir(name), m.cfg); err != nil {
-		printErr(err.Error())
+		printLoggerErr(err.Error())
 		return nil
 	}
 
 	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
-		printErr(err.Error())
+		printLoggerErr(err.Error())
 		return nil
 	}
 
 	return tools.NewSyncWriter(file)
 }
 
+// EventLoggerFromEnv returns a writer for the file descriptor given by
+// GIT_LFS_PROGRESS_FD, or nil if it is not set.
+func (m *Meter) EventLoggerFromEnv(os env) *tools.SyncWriter {
+	v, _ := os.Get("GIT_LFS_PROGRESS_FD")
+	if len(v) < 1 {
+		return nil
+	}
+
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		printLoggerErr(tr.Tr.Get("GIT_LFS_PROGRESS_FD must be a file descriptor number"))
+		return nil
+	}
+	return m.EventLoggerToFd(fd)
+}
+
+// EventLoggerToFd returns a writer for the given open file descriptor, or nil
+// if it is not valid.
+func (m *Meter) EventLoggerToFd(fd int) *tools.SyncWriter {
+	eventLoggersMu.Lock()
+	defer eventLoggersMu.Unlock()
+
+	if w, ok := eventLoggers[fd]; ok {
+		return w
+	}
+
+	if fd < 0 {
+		printLoggerErr(tr.Tr.Get("invalid file descriptor: %d", fd))
+		return nil
+	}
+
+	file := os.NewFile(uintptr(fd), "progress-fd")
+	if _, err := file.Stat(); err != nil {
+		printLoggerErr(err.Error())
+		return nil
+	}
+
+	w := tools.NewSyncWriter(file)
+	eventLoggers[fd] = w
+	return w
+}
+
+func printLoggerErr(err string) {
+	fmt.Fprintln(os.Stderr, tr.Tr.Get("Error creating progress logger: %s", err))
+}
+
 // NewMeter creates a new Meter.
 func NewMeter(cfg *config.Configuration) *Meter {
 	m := &Meter{
 		fileIndex:      make(map[string]int64),
+		fileOid:        make(map[string]string),
 		fileIndexMutex: &sync.Mutex{},
 		updates:        make(chan *tasklog.Update),
 		cfg:            cfg,
@@ -130,9 +201,9 @@ func (m *Meter) Skip(size int64) {
 	atomic.AddInt64(&m.currentBytes, size)
 }
 
-// StartTransfer tells the progress meter that a transferring file is being
-// added to the TransferQueue.
-func (m *Meter) StartTransfer(name string) {
+// StartTransfer tells the progress meter that a transferring file with the
+// given OID is being added to the TransferQueue.
+func (m *Meter) StartTransfer(name, oid string) {
 	if m == nil {
 		return
 	}
@@ -141,6 +212,7 @@ func (m *Meter) StartTransfer(name string) {
 	idx := atomic.AddInt64(&m.transferringFiles, 1)
 	m.fileIndexMutex.Lock()
 	m.fileIndex[name] = idx
+	m.fileOid[name] = oid
 	m.fileIndexMutex.Unlock()
 }
 
@@ -162,7 +234,11 @@ func (m *Meter) TransferBytes(direction, name string, read, total int64, current
 
 		bps := float64(m.lastBytes) / since.Seconds()
 
-		m.avgBytes = (m.avgBytes*float64(m.sampleCount) + bps) / (float64(m.sampleCount) + 1.0)
+		if m.sampleCount == 0 {
+			m.avgBytes = bps
+		} else {
+			m.avgBytes = rateSmoothing*bps + (1-rateSmoothing)*m.avgBytes
+		}
 
 		atomic.StoreInt64(&m.lastBytes, 0)
 		atomic.AddUint64(&m.sampleCount, 1)
@@ -181,6 +257,7 @@ func (m *Meter) FinishTransfer(name string) {
 	atomic.AddInt64(&m.finishedFiles, 1)
 	m.fileIndexMutex.Lock()
 	delete(m.fileIndex, name)
+	delete(m.fileOid, name)
 	m.fileIndexMutex.Unlock()
 }
 
@@ -233,15 +310,37 @@ func (m *Meter) skipUpdate() bool {
 }
 
 func (m *Meter) str() string {
-	// (Uploading|Downloading) LFS objects: 100% (10/10) 100 MiB | 10 MiB/s
+	// (Uploading|Downloading) LFS objects: 50% (5/10), 50 MB | 10 MB/s, ETA 5s
 	percentage := 100 * float64(m.finishedFiles) / float64(m.estimatedFiles)
 
-	return fmt.Sprintf("%s: %3.f%% (%d/%d), %s | %s",
+	s := fmt.Sprintf("%s: %3.f%% (%d/%d), %s | %s",
 		m.Direction.Progress(),
 		percentage,
 		m.finishedFiles, m.estimatedFiles,
 		humanize.FormatBytes(clamp(m.currentBytes)),
 		humanize.FormatByteRate(clampf(m.avgBytes), time.Second))
+
+	if eta, ok := m.eta(); ok {
+		s += fmt.Sprintf(", %s", tr.Tr.Get("ETA %s", humanize.FormatDuration(eta)))
+	}
+	return s
+}
+
+// eta returns the estimated time remaining until all of the expected bytes
+// have been transferred at the current rate. It returns false if that cannot
+// be known, such as when no sizes were given up front because the objects are
+// being streamed, or when nothing has been measured yet.
+func (m *Meter) eta() (time.Duration, bool) {
+	remaining := atomic.LoadInt64(&m.estimatedBytes) - atomic.LoadInt64(&m.currentBytes)
+	if remaining <= 0 || m.avgBytes <= 0 {
+		return 0, false
+	}
+
+	secs := float64(remaining) / m.avgBytes
+	if secs > math.MaxInt64/float64(time.Second) {
+		return 0, false
+	}
+	return time.Duration(secs * float64(time.Second)), true
 }
 
 // clamp clamps the given "x" within the acceptable domain of the uint64 integer
@@ -269,8 +368,21 @@ func clampf(x float64) uint64 {
 func (m *Meter) logBytes(direction, name string, read, total int64) {
 	m.fileIndexMutex.Lock()
 	idx := m.fileIndex[name]
+	oid := m.fileOid[name]
 	logger := m.Logger
+	eventLogger := m.EventLogger
 	m.fileIndexMutex.Unlock()
+
+	if eventLogger != nil {
+		m.logEvent(eventLogger, &progressEvent{
+			Oid:        oid,
+			Name:       name,
+			Direction:  direction,
+			BytesSoFar: read,
+			BytesTotal: total,
+		})
+	}
+
 	if logger == nil {
 		return
 	}
@@ -282,3 +394,15 @@ func (m *Meter) logBytes(direction, name string, read, total int64) {
 		m.fileIndexMutex.Unlock()
 	}
 }
+
+func (m *Meter) logEvent(logger *tools.SyncWriter, event *progressEvent) {
+	line, err := json.Marshal(event)
+	if err == nil {
+		err = logger.Write(append(line, '\n'))
+	}
+	if err != nil {
+		m.fileIndexMutex.Lock()
+		m.EventLogger = nil
+		m.fileIndexMutex.Unlock()
+	}
+}
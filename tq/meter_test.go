@@ -0,0 +1,57 @@
+package tq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeterStrIncludesETA(t *testing.T) {
+	m := &Meter{
+		Direction:      Download,
+		estimatedFiles: 10,
+		finishedFiles:  5,
+		estimatedBytes: 100 * 1000 * 1000,
+		currentBytes:   50 * 1000 * 1000,
+		avgBytes:       10 * 1000 * 1000,
+	}
+
+	assert.Equal(t, "Downloading LFS objects:  50% (5/10), 50 MB | 10 MB/s, ETA 5s", m.str())
+}
+
+func TestMeterStrOmitsETAWithoutRate(t *testing.T) {
+	m := &Meter{
+		Direction:      Upload,
+		estimatedFiles: 2,
+		estimatedBytes: 2000,
+	}
+
+	assert.Equal(t, "Uploading LFS objects:   0% (0/2), 0 B | 0 B/s", m.str())
+}
+
+func TestMeterStrOmitsETAWithUnknownSize(t *testing.T) {
+	// Objects streamed without a size up front leave nothing to estimate
+	// the time remaining from.
+	m := &Meter{
+		Direction:      Checkout,
+		estimatedFiles: 1,
+		currentBytes:   3000,
+		avgBytes:       1000,
+	}
+
+	assert.Equal(t, "Checking out LFS objects:   0% (0/1), 3.0 KB | 1.0 KB/s", m.str())
+}
+
+func TestMeterTransferBytesSmoothsRate(t *testing.T) {
+	m := NewMeter(nil)
+	m.DryRun = true
+
+	m.lastAvg = time.Now().Add(-2 * time.Second)
+	m.TransferBytes("download", "a.dat", 4000, 4000, 4000)
+	assert.InDelta(t, 2000, m.avgBytes, 10)
+
+	m.lastAvg = time.Now().Add(-2 * time.Second)
+	m.TransferBytes("download", "a.dat", 4000, 4000, 0)
+	assert.InDelta(t, 2000*(1-rateSmoothing), m.avgBytes, 10)
+}
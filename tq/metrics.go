@@ -0,0 +1,81 @@
+package tq
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// transferMetrics counts the transfers made by every TransferQueue in this
+// process, so that they may be reported once the command has finished.
+type transferMetrics struct {
+	queues          int64
+	uploadedBytes   int64
+	downloadedBytes int64
+	uploadedFiles   int64
+	downloadedFiles int64
+	retries         int64
+}
+
+var metrics transferMetrics
+
+func (m *transferMetrics) addQueue() {
+	atomic.AddInt64(&m.queues, 1)
+}
+
+func (m *transferMetrics) addBytes(dir Direction, n int) {
+	if dir == Upload {
+		atomic.AddInt64(&m.uploadedBytes, int64(n))
+	} else {
+		atomic.AddInt64(&m.downloadedBytes, int64(n))
+	}
+}
+
+func (m *transferMetrics) addFile(dir Direction) {
+	if dir == Upload {
+		atomic.AddInt64(&m.uploadedFiles, 1)
+	} else {
+		atomic.AddInt64(&m.downloadedFiles, 1)
+	}
+}
+
+func (m *transferMetrics) addRetry() {
+	atomic.AddInt64(&m.retries, 1)
+}
+
+// HasMetrics returns whether any TransferQueue has been created in this
+// process, and so whether WriteMetrics has anything to report.
+func HasMetrics() bool {
+	return atomic.LoadInt64(&metrics.queues) > 0
+}
+
+// WriteMetrics writes the number of bytes and objects transferred by every
+// TransferQueue in this process, the number of retried transfers, and the
+// given duration of the command, to w in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer, command string, duration time.Duration) error {
+	_, err := fmt.Fprintf(w, `# HELP git_lfs_transfer_bytes_total Bytes transferred by Git LFS.
+# TYPE git_lfs_transfer_bytes_total counter
+git_lfs_transfer_bytes_total{command=%[1]q,direction="upload"} %[2]d
+git_lfs_transfer_bytes_total{command=%[1]q,direction="download"} %[3]d
+# HELP git_lfs_transfer_objects_total Objects transferred by Git LFS.
+# TYPE git_lfs_transfer_objects_total counter
+git_lfs_transfer_objects_total{command=%[1]q,direction="upload"} %[4]d
+git_lfs_transfer_objects_total{command=%[1]q,direction="download"} %[5]d
+# HELP git_lfs_transfer_retries_total Object transfers retried by Git LFS.
+# TYPE git_lfs_transfer_retries_total counter
+git_lfs_transfer_retries_total{command=%[1]q} %[6]d
+# HELP git_lfs_command_duration_seconds Time taken by the Git LFS command.
+# TYPE git_lfs_command_duration_seconds gauge
+git_lfs_command_duration_seconds{command=%[1]q} %.3[7]f
+`,
+		command,
+		atomic.LoadInt64(&metrics.uploadedBytes),
+		atomic.LoadInt64(&metrics.downloadedBytes),
+		atomic.LoadInt64(&metrics.uploadedFiles),
+		atomic.LoadInt64(&metrics.downloadedFiles),
+		atomic.LoadInt64(&metrics.retries),
+		duration.Seconds(),
+	)
+	return err
+}
@@ -0,0 +1,52 @@
+package tq
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	old := metrics
+	defer func() { metrics = old }()
+
+	metrics = transferMetrics{}
+	metrics.addQueue()
+	metrics.addBytes(Upload, 100)
+	metrics.addBytes(Download, 20)
+	metrics.addBytes(Checkout, 3)
+	metrics.addFile(Upload)
+	metrics.addFile(Download)
+	metrics.addFile(Checkout)
+	metrics.addRetry()
+
+	assert.True(t, HasMetrics())
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteMetrics(&buf, "push", 1500*time.Millisecond))
+	assert.Equal(t, `# HELP git_lfs_transfer_bytes_total Bytes transferred by Git LFS.
+# TYPE git_lfs_transfer_bytes_total counter
+git_lfs_transfer_bytes_total{command="push",direction="upload"} 100
+git_lfs_transfer_bytes_total{command="push",direction="download"} 23
+# HELP git_lfs_transfer_objects_total Objects transferred by Git LFS.
+# TYPE git_lfs_transfer_objects_total counter
+git_lfs_transfer_objects_total{command="push",direction="upload"} 1
+git_lfs_transfer_objects_total{command="push",direction="download"} 2
+# HELP git_lfs_transfer_retries_total Object transfers retried by Git LFS.
+# TYPE git_lfs_transfer_retries_total counter
+git_lfs_transfer_retries_total{command="push"} 1
+# HELP git_lfs_command_duration_seconds Time taken by the Git LFS command.
+# TYPE git_lfs_command_duration_seconds gauge
+git_lfs_command_duration_seconds{command="push"} 1.500
+`, buf.String())
+}
+
+func TestHasMetricsWithoutQueues(t *testing.T) {
+	old := metrics
+	defer func() { metrics = old }()
+
+	metrics = transferMetrics{}
+	assert.False(t, HasMetrics())
+}
@@ -0,0 +1,113 @@
+package tq
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/git"
+	"github.com/git-lfs/git-lfs/v3/tr"
+	"github.com/rubyist/tracerx"
+)
+
+var contentRangeRE = regexp.MustCompile(`\Abytes (\d+)-`)
+
+// DownloadRange returns a reader for the bytes of the object t which start at
+// offset, up to length bytes, or to the end of the object if length is
+// negative.  The object's download URL is requested from the given remote with
+// a batch request, and only the wanted bytes are then requested from that URL
+// with an HTTP Range header.  If the server ignores the Range header and sends
+// the whole object, the bytes before offset are discarded.
+//
+// The bytes read are not verified against the object's OID.  An error for
+// which errors.IsNotImplementedError is true is returned if the server does
+// not offer a plain HTTP download of the object, in which case the caller
+// should download the whole object instead.
+func DownloadRange(m Manifest, remote string, remoteRef *git.Ref, t *Transfer, offset, length int64) (io.ReadCloser, error) {
+	bRes, err := Batch(m, Download, remote, remoteRef, []*Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+
+	if name := bRes.TransferAdapterName; len(name) > 0 && name != BasicAdapterName {
+		return nil, errors.NewNotImplementedError(errors.New(tr.Tr.Get("range requests are not supported by the %q transfer adapter", name)))
+	}
+
+	var obj *Transfer
+	for _, o := range bRes.Objects {
+		if o.Oid == t.Oid {
+			obj = o
+			break
+		}
+	}
+	if obj == nil || obj.Error != nil {
+		return nil, errors.Errorf(tr.Tr.Get("Object %s not found on the server.", t.Oid))
+	}
+
+	rel, err := obj.Rel("download")
+	if err != nil {
+		return nil, err
+	}
+	if rel == nil {
+		return nil, errors.Errorf(tr.Tr.Get("Object %s not found on the server.", t.Oid))
+	}
+	if len(rel.Compression) > 0 {
+		return nil, errors.NewNotImplementedError(errors.New(tr.Tr.Get("range requests are not supported for compressed downloads")))
+	}
+
+	apiClient := m.APIClient()
+	a := &adapterBase{apiClient: apiClient, remote: remote, direction: Download}
+	req, err := a.newHTTPRequest("GET", rel)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+
+	req = apiClient.LogRequest(req, "lfs.data.download")
+	res, err := a.doHTTP(obj, req)
+	if errors.IsAuthError(err) && len(req.Header.Get("Authorization")) == 0 {
+		res, err = a.doHTTP(obj, req)
+	}
+	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case 206:
+		match := contentRangeRE.FindStringSubmatch(res.Header.Get("Content-Range"))
+		if match == nil {
+			res.Body.Close()
+			return nil, errors.New(tr.Tr.Get("badly formatted Content-Range header: %q", res.Header.Get("Content-Range")))
+		}
+		if start, _ := strconv.ParseInt(match[1], 10, 64); start != offset {
+			res.Body.Close()
+			return nil, errors.New(tr.Tr.Get("Content-Range start byte incorrect: %s expected %d", match[1], offset))
+		}
+	case 200:
+		tracerx.Printf("xfer: server ignored range request for %q; skipping %d bytes", t.Oid, offset)
+		if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+	default:
+		res.Body.Close()
+		return nil, errors.New(tr.Tr.Get("expected status code 206, received %d", res.StatusCode))
+	}
+
+	if length < 0 {
+		return res.Body, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(res.Body, length), res.Body}, nil
+}
@@ -0,0 +1,92 @@
+package tq
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/lfshttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRangeServer(t *testing.T, adapter string, honorRange bool) *httptest.Server {
+	content := "0123456789"
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				o.Actions = ActionSet{"download": &Action{Href: srv.URL + "/objects/" + o.Oid}}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{
+				TransferAdapterName: adapter,
+				Objects:             bReq.Objects,
+			})
+		case "/objects/a":
+			if !honorRange {
+				io.WriteString(w, content)
+				return
+			}
+			assert.Equal(t, "bytes=3-6", r.Header.Get("Range"))
+			w.Header().Set("Content-Range", "bytes 3-6/10")
+			w.WriteHeader(206)
+			io.WriteString(w, content[3:7])
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	return srv
+}
+
+func downloadRange(t *testing.T, srv *httptest.Server) (string, error) {
+	c, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url": srv.URL + "/api",
+	}))
+	require.Nil(t, err)
+
+	m := NewManifest(nil, c, "", "")
+	r, err := DownloadRange(m, "origin", nil, &Transfer{Oid: "a", Size: 10}, 3, 4)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	by, err := io.ReadAll(r)
+	require.Nil(t, err)
+	return string(by), nil
+}
+
+func TestDownloadRange(t *testing.T) {
+	srv := newRangeServer(t, "basic", true)
+	defer srv.Close()
+
+	content, err := downloadRange(t, srv)
+	require.Nil(t, err)
+	assert.Equal(t, "3456", content)
+}
+
+func TestDownloadRangeIgnored(t *testing.T) {
+	srv := newRangeServer(t, "basic", false)
+	defer srv.Close()
+
+	content, err := downloadRange(t, srv)
+	require.Nil(t, err)
+	assert.Equal(t, "3456", content)
+}
+
+func TestDownloadRangeUnsupportedAdapter(t *testing.T) {
+	srv := newRangeServer(t, "tus", true)
+	defer srv.Close()
+
+	_, err := downloadRange(t, srv)
+	assert.True(t, errors.IsNotImplementedError(err))
+}
@@ -59,7 +59,7 @@ func (a *SSHBatchClient) Batch(remote string, bReq *batchRequest) (*BatchRespons
 	tracerx.Printf("api: batch %d files", len(bReq.Objects))
 
 	requestedAt := time.Now()
-	args := []string{"transfer=ssh", "hash-algo=sha256"}
+	args := []string{"transfer=ssh", fmt.Sprintf("hash-algo=%s", bReq.HashAlgorithm)}
 	if bReq.Ref != nil {
 		args = append(args, fmt.Sprintf("refname=%s", bReq.Ref.Name))
 	}
@@ -83,7 +83,7 @@ func (a *SSHBatchClient) Batch(remote string, bReq *batchRequest) (*BatchRespons
 		}
 		if entries[0] == "hash-algo" {
 			bRes.HashAlgorithm = entries[1]
-			if bRes.HashAlgorithm != "sha256" {
+			if !batchHashAlgorithmMatches(bReq.HashAlgorithm, bRes.HashAlgorithm) {
 				return nil, errors.New(tr.Tr.Get("batch response: unsupported hash algorithm: %q", entries[1]))
 			}
 		}
@@ -264,14 +264,18 @@ func (a *SSHAdapter) doDownload(t *Transfer, workerNum int, f *os.File, cb Progr
 		}
 		return nil
 	}
-	hasher := tools.NewHashingReader(data)
+	hash, err := tools.NewLfsContentHashFor(a.hashAlgo)
+	if err != nil {
+		return err
+	}
+	hasher := tools.NewHashingReaderPreloadHash(data, hash)
 	written, err := tools.CopyWithCallback(f, hasher, t.Size, ccb)
 	if err != nil {
 		return errors.Wrapf(err, tr.Tr.Get("cannot write data to temporary file %q", dlfilename))
 	}
 
 	if actual := hasher.Hash(); actual != t.Oid {
-		return errors.New(tr.Tr.Get("expected OID %s, got %s after %d bytes written", t.Oid, actual, written))
+		return errors.NewIntegrityError(errors.New(tr.Tr.Get("expected OID %s, got %s after %d bytes written", t.Oid, actual, written)))
 	}
 
 	if err := f.Close(); err != nil {
@@ -0,0 +1,73 @@
+package tq
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// stallWatchdog cancels the context of a transfer which makes no progress for
+// longer than its timeout. Unlike a deadline, the timeout starts again each
+// time some bytes of the object are transferred, so a large object which is
+// transferred slowly but steadily is never cancelled.
+type stallWatchdog struct {
+	timeout time.Duration
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stalled bool
+}
+
+// newStallWatchdog returns a new stallWatchdog with the given timeout, which
+// has already started, and the context derived from parent which it cancels if
+// the timeout passes.
+func newStallWatchdog(parent context.Context, timeout time.Duration) (context.Context, *stallWatchdog) {
+	ctx, cancel := context.WithCancel(parent)
+	w := &stallWatchdog{timeout: timeout, cancel: cancel}
+
+	w.mu.Lock()
+	w.timer = time.AfterFunc(timeout, w.fire)
+	w.mu.Unlock()
+
+	return ctx, w
+}
+
+func (w *stallWatchdog) fire() {
+	w.mu.Lock()
+	w.stalled = true
+	w.mu.Unlock()
+
+	w.cancel()
+}
+
+// progressCallback returns a ProgressCallback which restarts the timeout each
+// time progress is made, and then calls cb, if it is not nil.
+func (w *stallWatchdog) progressCallback(cb ProgressCallback) ProgressCallback {
+	return func(name string, totalSize, readSoFar int64, readSinceLast int) error {
+		if readSinceLast > 0 {
+			w.mu.Lock()
+			if !w.stalled {
+				w.timer.Reset(w.timeout)
+			}
+			w.mu.Unlock()
+		}
+
+		if cb == nil {
+			return nil
+		}
+		return cb(name, totalSize, readSoFar, readSinceLast)
+	}
+}
+
+// Stop stops the watchdog, and returns whether it had cancelled the transfer
+// for having stalled.
+func (w *stallWatchdog) Stop() bool {
+	w.mu.Lock()
+	w.timer.Stop()
+	stalled := w.stalled
+	w.mu.Unlock()
+
+	w.cancel()
+	return stalled
+}
@@ -0,0 +1,56 @@
+package tq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStallWatchdogCancelsWithoutProgress(t *testing.T) {
+	ctx, w := newStallWatchdog(context.Background(), 10*time.Millisecond)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchdog did not cancel the stalled transfer")
+	}
+	assert.True(t, w.Stop())
+}
+
+func TestStallWatchdogResetsOnProgress(t *testing.T) {
+	ctx, w := newStallWatchdog(context.Background(), 100*time.Millisecond)
+
+	var called int
+	cb := w.progressCallback(func(name string, totalSize, readSoFar int64, readSinceLast int) error {
+		called++
+		return nil
+	})
+
+	// Report progress for several times the timeout in total.
+	for i := 0; i < 10; i++ {
+		time.Sleep(20 * time.Millisecond)
+		assert.Nil(t, cb("a", 10, int64(i+1), 1))
+	}
+
+	assert.Nil(t, ctx.Err())
+	assert.False(t, w.Stop())
+	assert.Equal(t, 10, called)
+}
+
+func TestStallWatchdogIgnoresRewoundProgress(t *testing.T) {
+	ctx, w := newStallWatchdog(context.Background(), 10*time.Millisecond)
+	cb := w.progressCallback(nil)
+
+	deadline := time.After(5 * time.Second)
+	for ctx.Err() == nil {
+		assert.Nil(t, cb("a", 10, 0, -1))
+		select {
+		case <-deadline:
+			t.Fatal("watchdog did not cancel the stalled transfer")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	assert.True(t, w.Stop())
+}
@@ -3,6 +3,7 @@
 package tq
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -105,13 +106,17 @@ func newTransfer(tr *Transfer, name string, path string) *Transfer {
 
 	for rel, action := range tr.Actions {
 		t.Actions[rel] = &Action{
-			Href:      action.Href,
-			Header:    action.Header,
-			ExpiresAt: action.ExpiresAt,
-			ExpiresIn: action.ExpiresIn,
-			Id:        action.Id,
-			Token:     action.Token,
-			createdAt: action.createdAt,
+			Href:          action.Href,
+			Header:        action.Header,
+			ExpiresAt:     action.ExpiresAt,
+			ExpiresIn:     action.ExpiresIn,
+			Parts:         action.Parts,
+			Base:          action.Base,
+			UploadSession: action.UploadSession,
+			Compression:   action.Compression,
+			Id:            action.Id,
+			Token:         action.Token,
+			createdAt:     action.createdAt,
 		}
 	}
 
@@ -120,13 +125,17 @@ func newTransfer(tr *Transfer, name string, path string) *Transfer {
 
 		for rel, link := range tr.Links {
 			t.Links[rel] = &Action{
-				Href:      link.Href,
-				Header:    link.Header,
-				ExpiresAt: link.ExpiresAt,
-				ExpiresIn: link.ExpiresIn,
-				Id:        link.Id,
-				Token:     link.Token,
-				createdAt: link.createdAt,
+				Href:          link.Href,
+				Header:        link.Header,
+				ExpiresAt:     link.ExpiresAt,
+				ExpiresIn:     link.ExpiresIn,
+				Parts:         link.Parts,
+				Base:          link.Base,
+				UploadSession: link.UploadSession,
+				Compression:   link.Compression,
+				Id:            link.Id,
+				Token:         link.Token,
+				createdAt:     link.createdAt,
 			}
 		}
 	}
@@ -139,12 +148,37 @@ type Action struct {
 	Header    map[string]string `json:"header,omitempty"`
 	ExpiresAt time.Time         `json:"expires_at,omitempty"`
 	ExpiresIn int               `json:"expires_in,omitempty"`
-	Id        string            `json:"-"`
-	Token     string            `json:"-"`
+	// Parts, if given for an upload action, splits the upload into
+	// several parts, after which a request is made to Href to complete
+	// it.
+	Parts []*ActionPart `json:"parts,omitempty"`
+	// Base, if given for a delta action, is the OID of an object which
+	// the server already has, and against which a delta of the object
+	// may be uploaded to Href.
+	Base string `json:"base,omitempty"`
+	// UploadSession, if given for an upload action, identifies a session
+	// in which the server keeps the bytes of an interrupted upload, so
+	// that a later upload may resume from where it stopped.
+	UploadSession string `json:"upload_session,omitempty"`
+	// Compression, if given, is the algorithm with which the body of an
+	// upload is to be compressed, or with which the body of a download
+	// has been compressed.
+	Compression string `json:"compression,omitempty"`
+	Id          string `json:"-"`
+	Token       string `json:"-"`
 
 	createdAt time.Time
 }
 
+// ActionPart is a single part of a multipart upload, to which the Size bytes
+// of the object starting at Pos are uploaded.
+type ActionPart struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+	Pos    int64             `json:"pos"`
+	Size   int64             `json:"size"`
+}
+
 func (a *Action) IsExpiredWithin(d time.Duration) (time.Time, bool) {
 	return tools.IsExpiredAtOrIn(a.createdAt, d, a.ExpiresAt, time.Duration(a.ExpiresIn)*time.Second)
 }
@@ -206,6 +240,11 @@ type adapterConfig struct {
 	apiClient           *lfsapi.Client
 	concurrentTransfers int
 	remote              string
+	bandwidth           *bandwidthLimiter
+	hashAlgo            string
+	retries             int
+	hooks               *TransferHooks
+	ctx                 context.Context
 }
 
 func (c *adapterConfig) ConcurrentTransfers() int {
@@ -220,6 +259,31 @@ func (c *adapterConfig) Remote() string {
 	return c.remote
 }
 
+func (c *adapterConfig) bandwidthLimiter() *bandwidthLimiter {
+	return c.bandwidth
+}
+
+func (c *adapterConfig) hashAlgorithm() string {
+	return c.hashAlgo
+}
+
+func (c *adapterConfig) maxRetries() int {
+	return c.retries
+}
+
+func (c *adapterConfig) transferHooks() *TransferHooks {
+	return c.hooks
+}
+
+// transferContext returns the context.Context whose cancellation stops the
+// transfers of the adapter.
+func (c *adapterConfig) transferContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 // Adapter is implemented by types which can upload and/or download LFS
 // file content to a remote store. Each Adapter accepts one or more requests
 // which it may schedule and parallelise in whatever way it chooses, clients of
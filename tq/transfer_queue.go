@@ -1,6 +1,7 @@
 package tq
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -192,30 +193,60 @@ type TransferQueue struct {
 	adapter           Adapter
 	adapterInProgress bool
 	adapterInitMutex  sync.Mutex
-	dryRun            bool
-	cb                tools.CopyCallback
-	meter             *Meter
-	errors            []error
-	transfers         map[string]*objects
-	batchSize         int
-	bufferDepth       int
-	incoming          chan *objectTuple // Channel for processing incoming items
-	errorc            chan error        // Channel for processing errors
-	watchers          []chan *Transfer
-	trMutex           *sync.Mutex
-	collectorWait     sync.WaitGroup
-	errorwait         sync.WaitGroup
+	// noAdapterCache is whether to ignore the cache of the transfer
+	// adapters chosen by the server.
+	noAdapterCache bool
+	// sortAscending is whether each batch is sorted by ascending rather
+	// than descending object size.
+	sortAscending bool
+	// adapterCacheChecked is whether the cache of transfer adapters has
+	// been consulted, which is done once before the first batch request.
+	adapterCacheChecked bool
+	// adapterCacheWait waits for any adapter which was begun from the
+	// cache of transfer adapters to finish beginning.
+	adapterCacheWait sync.WaitGroup
+	dryRun           bool
+	cb               tools.CopyCallback
+	hooks            *TransferHooks
+	meter            *Meter
+	errors           []error
+	transfers        map[string]*objects
+	batchSize        int
+	bufferDepth      int
+	incoming         chan *objectTuple // Channel for processing incoming items
+	errorc           chan error        // Channel for processing errors
+	watchers         []chan *Transfer
+	trMutex          *sync.Mutex
+	collectorWait    sync.WaitGroup
+	errorwait        sync.WaitGroup
 	// wait is used to keep track of pending transfers. It is incremented
 	// once per unique OID on Add(), and is decremented when that transfer
 	// is marked as completed or failed, but not retried.
 	wait     *abortableWaitGroup
 	manifest Manifest
-	rc       *retryCounter
+	// rc counts the retries of the transfer of each object, and brc
+	// those of each object because the Batch API request including it
+	// failed. erc counts the fresh actions requested for each object
+	// because its actions expired before its transfer could start.
+	rc  *retryCounter
+	brc *retryCounter
+	erc *retryCounter
+	// maxRetriesPerObject overrides the maximum number of retries of
+	// the transfer of each object given by the manifest, if positive.
+	maxRetriesPerObject int
+	// exhausted holds the OIDs of the objects which were not retried
+	// again because they had run out of retries.
+	exhausted map[string]struct{}
 
 	// unsupportedContentType indicates whether the transfer queue ever saw
 	// an HTTP 422 response indicating that their upload destination does
 	// not support Content-Type detection.
 	unsupportedContentType bool
+
+	// ctx is the context.Context whose cancellation stops the queue.
+	ctx context.Context
+	// cancelOnce reports the error of ctx once it is cancelled.
+	cancelOnce sync.Once
 }
 
 // objects holds a set of objects.
@@ -253,6 +284,13 @@ type objectTuple struct {
 	ReadyTime       time.Time
 }
 
+// objectRetry is an object which is to be retried, along with the retry counter
+// its retry is counted on.
+type objectRetry struct {
+	t  *objectTuple
+	rc *retryCounter
+}
+
 func (o *objectTuple) ToTransfer() *Transfer {
 	return &Transfer{
 		Name:    o.Name,
@@ -271,6 +309,23 @@ func DryRun(dryRun bool) Option {
 	}
 }
 
+// WithAdapterCache sets whether the queue consults and updates the cache of
+// the transfer adapters chosen by the server, if the cache is enabled by the
+// "lfs.transfer.adaptercache" setting.
+func WithAdapterCache(enabled bool) Option {
+	return func(tq *TransferQueue) {
+		tq.noAdapterCache = !enabled
+	}
+}
+
+// WithSortAscending sets whether the objects in each batch are transferred in
+// order of ascending, rather than descending, size.
+func WithSortAscending(ascending bool) Option {
+	return func(tq *TransferQueue) {
+		tq.sortAscending = ascending
+	}
+}
+
 func WithProgress(m *Meter) Option {
 	return func(tq *TransferQueue) {
 		tq.meter = m
@@ -289,6 +344,34 @@ func WithProgressCallback(cb tools.CopyCallback) Option {
 	}
 }
 
+// WithTransferHooks sets the functions to be called by the transfer adapter of
+// the queue as it processes each object.
+func WithTransferHooks(hooks *TransferHooks) Option {
+	return func(tq *TransferQueue) {
+		tq.hooks = hooks
+	}
+}
+
+// WithContext sets the context.Context of the queue. Once it is cancelled, no
+// more objects are sent to the server or the transfer adapter, the HTTP
+// requests of any transfers in progress are aborted, and their partially
+// downloaded files are removed. The objects which were not transferred are
+// reported as failed, and the error of the context is returned by Errors().
+func WithContext(ctx context.Context) Option {
+	return func(tq *TransferQueue) {
+		tq.ctx = ctx
+	}
+}
+
+// WithMaxRetriesPerObject sets the maximum number of times the transfer of a
+// single object is retried, overriding "lfs.transfer.maxretriesperobject".
+// It does not affect the retries of Batch API requests.
+func WithMaxRetriesPerObject(n int) Option {
+	return func(tq *TransferQueue) {
+		tq.maxRetriesPerObject = n
+	}
+}
+
 func WithBatchSize(size int) Option {
 	return func(tq *TransferQueue) { tq.batchSize = size }
 }
@@ -307,7 +390,11 @@ func NewTransferQueue(dir Direction, manifest Manifest, remote string, options .
 		trMutex:   &sync.Mutex{},
 		manifest:  manifest,
 		rc:        newRetryCounter(),
+		brc:       newRetryCounter(),
+		erc:       newRetryCounter(),
+		exhausted: make(map[string]struct{}),
 		wait:      newAbortableWaitGroup(),
+		ctx:       context.Background(),
 	}
 
 	for _, opt := range options {
@@ -324,6 +411,8 @@ func NewTransferQueue(dir Direction, manifest Manifest, remote string, options .
 		q.meter.Direction = q.direction
 	}
 
+	metrics.addQueue()
+
 	q.incoming = make(chan *objectTuple, q.bufferDepth)
 	q.collectorWait.Add(1)
 	q.errorwait.Add(1)
@@ -338,8 +427,15 @@ func (q *TransferQueue) Upgrade() {
 	if q.client == nil {
 		manifest := q.manifest.Upgrade()
 		q.client = &tqClient{Client: manifest.APIClient()}
-		q.rc.MaxRetries = manifest.maxRetries
+		q.rc.MaxRetries = manifest.maxRetriesPerObject
+		if q.maxRetriesPerObject > 0 {
+			q.rc.MaxRetries = q.maxRetriesPerObject
+		}
 		q.rc.MaxRetryDelay = manifest.maxRetryDelay
+		q.brc.MaxRetries = manifest.maxRetries
+		q.brc.MaxRetryDelay = manifest.maxRetryDelay
+		q.erc.MaxRetries = manifest.maxRetries
+		q.erc.MaxRetryDelay = manifest.maxRetryDelay
 		q.client.SetMaxRetries(manifest.maxRetries)
 	}
 }
@@ -452,8 +548,12 @@ func (q *TransferQueue) collectBatches() {
 		}
 
 		// Before enqueuing the next batch, sort by descending object
-		// size.
-		sort.Sort(sort.Reverse(next))
+		// size, unless ascending order was requested.
+		if q.sortAscending {
+			sort.Sort(next)
+		} else {
+			sort.Sort(sort.Reverse(next))
+		}
 
 		done := make(chan struct{})
 
@@ -492,6 +592,12 @@ func (q *TransferQueue) collectBatches() {
 		// - items collected while the batch was processing.
 		var minWaitTime time.Duration
 		next, pending, minWaitTime = retries.Concat(append(pending, collected...), q.batchSize)
+		if q.ctx.Err() != nil {
+			// Don't wait to retry any objects once the queue is
+			// cancelled.
+			q.cancelBatch(append(next, pending...))
+			next, pending = q.makeBatch(), q.makeBatch()
+		}
 		if len(next) == 0 && len(pending) != 0 {
 			// There are some pending that could not be queued.
 			// Wait the requested time before resuming loop.
@@ -541,13 +647,18 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 	q.Upgrade()
 
 	next := q.makeBatch()
+	if q.ctx.Err() != nil {
+		q.cancelBatch(batch)
+		return next, nil
+	}
 	tracerx.Printf("tq: sending batch of size %d", len(batch))
 
-	enqueueRetry := func(t *objectTuple, err error, readyTime *time.Time) {
-		count := q.rc.Increment(t.Oid)
+	enqueueRetry := func(rc *retryCounter, t *objectTuple, err error, readyTime *time.Time) {
+		count := rc.Increment(t.Oid)
+		metrics.addRetry()
 
 		if readyTime == nil {
-			t.ReadyTime = q.rc.ReadyTime(t.Oid)
+			t.ReadyTime = rc.ReadyTime(t.Oid)
 		} else {
 			t.ReadyTime = *readyTime
 		}
@@ -575,20 +686,25 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 			TransferAdapterName: manifest.standaloneTransferAgent,
 		}
 	} else {
+		q.beginCachedAdapter()
+
 		// Query the Git LFS server for what transfer method to use and
 		// details such as URLs, authentication, etc.
 		var err error
-		bRes, err = Batch(q.manifest, q.direction, q.remote, q.ref, batch.ToTransfers())
-		if err != nil {
+		bRes, err = BatchContext(q.ctx, q.manifest, q.direction, q.remote, q.ref, batch.ToTransfers())
+		if err != nil && q.ctx.Err() != nil {
+			q.cancelBatch(batch)
+			return next, nil
+		} else if err != nil {
 			var hasNonRetriableObjects = false
 			// If there was an error making the batch API call, mark all of
 			// the objects for retry if possible.  If any should not be retried,
 			// they will be marked as failed.
 			for _, t := range batch {
-				if q.canRetryObject(t.Oid, err) {
-					enqueueRetry(t, err, nil)
-				} else if readyTime, canRetry := q.canRetryObjectLater(t.Oid, err); canRetry {
-					enqueueRetry(t, err, &readyTime)
+				if q.canRetryObject(q.brc, t.Oid, err) {
+					enqueueRetry(q.brc, t, err, nil)
+				} else if readyTime, canRetry := q.canRetryObjectLater(q.brc, t.Oid, err); canRetry {
+					enqueueRetry(q.brc, t, err, &readyTime)
 				} else {
 					hasNonRetriableObjects = true
 					q.wait.Done()
@@ -626,6 +742,9 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 		}
 	}
 
+	if manifest.standaloneTransferAgent == "" {
+		q.cacheAdapter(bRes)
+	}
 	q.useAdapter(bRes.TransferAdapterName)
 	q.meter.Start()
 
@@ -658,8 +777,9 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 			tr := newTransfer(o, objects.First().Name, objects.First().Path)
 
 			if a, err := tr.Rel(q.direction.String()); err != nil {
-				if q.canRetryObject(tr.Oid, err) {
-					enqueueRetry(objects.First(), err, nil)
+				rc := q.retryCounterFor(err)
+				if q.canRetryObject(rc, tr.Oid, err) {
+					enqueueRetry(rc, objects.First(), err, nil)
 				} else {
 					q.errorc <- errors.Errorf("[%v] %v", tr.Name, err)
 
@@ -670,20 +790,43 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 				q.Skip(o.Size)
 				q.wait.Done()
 			} else {
-				q.meter.StartTransfer(objects.First().Name)
+				q.meter.StartTransfer(objects.First().Name, o.Oid)
 				toTransfer = append(toTransfer, tr)
 			}
 		}
 	}
 
 	retries := q.addToAdapter(bRes.endpoint, toTransfer)
-	for t := range retries {
-		enqueueRetry(t, nil, nil)
+	for r := range retries {
+		enqueueRetry(r.rc, r.t, nil, nil)
 	}
 
 	return next, nil
 }
 
+// cancelBatch marks the objects in the given batch as failed because the queue
+// was cancelled, without transferring them.
+func (q *TransferQueue) cancelBatch(batch batch) {
+	if len(batch) == 0 {
+		return
+	}
+
+	tracerx.Printf("tq: cancelled, skipping %d object(s)", len(batch))
+	q.reportCancelled()
+	for _, t := range batch {
+		q.Skip(t.Size)
+		q.wait.Done()
+	}
+}
+
+// reportCancelled records the error of the context of the queue, only once
+// however many objects were not transferred because of it.
+func (q *TransferQueue) reportCancelled() {
+	q.cancelOnce.Do(func() {
+		q.errorc <- q.ctx.Err()
+	})
+}
+
 // makeBatch returns a new, empty batch, with a capacity equal to the maximum
 // batch size designated by the `*TransferQueue`.
 func (q *TransferQueue) makeBatch() batch { return make(batch, 0, q.batchSize) }
@@ -694,10 +837,10 @@ func (q *TransferQueue) makeBatch() batch { return make(batch, 0, q.batchSize) }
 // closed.
 //
 // addToAdapter returns immediately, and does not block.
-func (q *TransferQueue) addToAdapter(e lfshttp.Endpoint, pending []*Transfer) <-chan *objectTuple {
+func (q *TransferQueue) addToAdapter(e lfshttp.Endpoint, pending []*Transfer) <-chan *objectRetry {
 	q.Upgrade()
 
-	retries := make(chan *objectTuple, len(pending))
+	retries := make(chan *objectRetry, len(pending))
 
 	if err := q.ensureAdapterBegun(e); err != nil {
 		close(retries)
@@ -791,14 +934,22 @@ func (q *TransferQueue) makeDryRunResults(ts []*Transfer) <-chan TransferResult
 // handleTransferResult observes the transfer result, sending it on the retries
 // channel if it was able to be retried.
 func (q *TransferQueue) handleTransferResult(
-	res TransferResult, retries chan<- *objectTuple,
+	res TransferResult, retries chan<- *objectRetry,
 ) {
 	oid := res.Transfer.Oid
 
 	if res.Error != nil {
+		rc := q.retryCounterFor(res.Error)
+
 		// If there was an error encountered when processing the
 		// transfer (res.Transfer), handle the error as is appropriate:
-		if readyTime, canRetry := q.canRetryObjectLater(oid, res.Error); canRetry {
+		if q.ctx.Err() != nil && errors.Cause(res.Error) == q.ctx.Err() {
+			// If the queue was cancelled, the transfer was stopped
+			// and is not retried. The error of the context is
+			// reported only once.
+			q.reportCancelled()
+			q.wait.Done()
+		} else if readyTime, canRetry := q.canRetryObjectLater(rc, oid, res.Error); canRetry {
 			// If the object can't be retried now, but can be
 			// after a certain period of time, send it to
 			// the retry channel with a time when it's ready.
@@ -810,11 +961,11 @@ func (q *TransferQueue) handleTransferResult(
 			if ok {
 				t := objects.First()
 				t.ReadyTime = readyTime
-				retries <- t
+				retries <- &objectRetry{t: t, rc: rc}
 			} else {
 				q.errorc <- res.Error
 			}
-		} else if q.canRetryObject(oid, res.Error) {
+		} else if q.canRetryObject(rc, oid, res.Error) {
 			// If the object can be retried, send it on the retries
 			// channel, where it will be read at the call-site and
 			// its retry count will be incremented.
@@ -825,7 +976,7 @@ func (q *TransferQueue) handleTransferResult(
 			q.trMutex.Unlock()
 
 			if ok {
-				retries <- objects.First()
+				retries <- &objectRetry{t: objects.First(), rc: rc}
 			} else {
 				q.errorc <- res.Error
 			}
@@ -864,6 +1015,7 @@ func (q *TransferQueue) handleTransferResult(
 
 		q.trMutex.Unlock()
 
+		metrics.addFile(q.direction)
 		q.meter.FinishTransfer(res.Transfer.Name)
 		q.wait.Done()
 	}
@@ -887,6 +1039,65 @@ func (q *TransferQueue) useAdapter(name string) {
 	q.adapter = q.manifest.NewAdapterOrDefault(name, q.direction)
 }
 
+func (q *TransferQueue) adapterCache() *adapterCache {
+	if q.noAdapterCache || q.dryRun {
+		return nil
+	}
+	return q.manifest.Upgrade().adapterCache()
+}
+
+// beginCachedAdapter begins the transfer adapter which the server chose the
+// last time a batch request was made to the endpoint of the queue, if it is
+// cached, so that the adapter can start while the first batch request is made.
+// If the server chooses a different adapter, the queue switches to it.
+func (q *TransferQueue) beginCachedAdapter() {
+	if q.adapterCacheChecked {
+		return
+	}
+	q.adapterCacheChecked = true
+
+	cache := q.adapterCache()
+	if cache == nil {
+		return
+	}
+
+	e := q.manifest.APIClient().Endpoints.Endpoint(q.direction.String(), q.remote)
+	name, ok := cache.Get(q.direction, e.Url)
+	if !ok {
+		return
+	}
+
+	tracerx.Printf("tq: using cached transfer adapter %q for %s", name, e.Url)
+	q.useAdapter(name)
+
+	q.adapterCacheWait.Add(1)
+	go func() {
+		defer q.adapterCacheWait.Done()
+		if err := q.ensureAdapterBegun(e); err != nil {
+			tracerx.Printf("tq: unable to begin cached transfer adapter %q: %s", name, err)
+		}
+	}()
+}
+
+// cacheAdapter records the transfer adapter chosen by the server in the given
+// batch response, if it differs from the one which is cached.  An empty name,
+// which the server gives when it chooses the default adapter, is recorded as
+// it is.
+func (q *TransferQueue) cacheAdapter(bRes *BatchResponse) {
+	cache := q.adapterCache()
+	if cache == nil || len(bRes.endpoint.Url) == 0 {
+		return
+	}
+
+	name := bRes.TransferAdapterName
+	if cached, ok := cache.Get(q.direction, bRes.endpoint.Url); ok && cached == name {
+		return
+	}
+	if err := cache.Set(q.direction, bRes.endpoint.Url, name); err != nil {
+		tracerx.Printf("tq: unable to cache transfer adapter %q: %s", name, err)
+	}
+}
+
 func (q *TransferQueue) finishAdapter() {
 	if q.adapterInProgress {
 		q.adapter.End()
@@ -918,6 +1129,7 @@ func (q *TransferQueue) ensureAdapterBegun(e lfshttp.Endpoint) error {
 	// Progress callback - receives byte updates
 	cb := func(name string, total, read int64, current int) error {
 		q.meter.TransferBytes(q.direction.String(), name, read, total, current)
+		metrics.addBytes(q.direction, current)
 		if q.cb != nil {
 			// NOTE: this is the mechanism by which the logpath
 			// specified by GIT_LFS_PROGRESS is written to.
@@ -939,13 +1151,19 @@ func (q *TransferQueue) ensureAdapterBegun(e lfshttp.Endpoint) error {
 }
 
 func (q *TransferQueue) toAdapterCfg(e lfshttp.Endpoint) AdapterConfig {
-	apiClient := q.manifest.APIClient()
-	concurrency := q.manifest.ConcurrentTransfers()
+	manifest := q.manifest.Upgrade()
+	apiClient := manifest.APIClient()
+	concurrency := manifest.concurrentTransfersFor(e.Url)
 
 	return &adapterConfig{
 		concurrentTransfers: concurrency,
 		apiClient:           apiClient,
 		remote:              q.remote,
+		bandwidth:           manifest.bandwidthLimiter(),
+		hashAlgo:            manifest.hashAlgorithm(),
+		retries:             manifest.MaxRetries(),
+		hooks:               q.hooks,
+		ctx:                 q.ctx,
 	}
 }
 
@@ -957,6 +1175,7 @@ func (q *TransferQueue) Wait() {
 
 	q.wait.Wait()
 	q.collectorWait.Wait()
+	q.adapterCacheWait.Wait()
 
 	q.finishAdapter()
 	close(q.errorc)
@@ -1022,28 +1241,67 @@ func (q *TransferQueue) canRetryLater(err error) (time.Time, bool) {
 	return errors.IsRetriableLaterError(err)
 }
 
+// retryCounterFor returns the retry counter on which a retry of an object after
+// the given error is counted. An object whose actions expired before its
+// transfer started is sent again in a batch to request fresh actions, which
+// doesn't count against the retries of its transfer.
+func (q *TransferQueue) retryCounterFor(err error) *retryCounter {
+	if IsActionExpiredError(errors.Cause(err)) {
+		return q.erc
+	}
+	return q.rc
+}
+
 // canRetryObject returns whether the given error is retriable for the object
-// given by "oid". If the an OID has met its retry limit, then it will not be
-// able to be retried again. If so, canRetryObject returns whether or not that
-// given error "err" is retriable.
-func (q *TransferQueue) canRetryObject(oid string, err error) bool {
-	if count, ok := q.rc.CanRetry(oid); !ok {
-		tracerx.Printf("tq: refusing to retry %q, too many retries (%d)", oid, count)
+// given by "oid". If the an OID has met its retry limit in the given retry
+// counter, then it will not be able to be retried again. If so,
+// canRetryObject returns whether or not that given error "err" is retriable.
+func (q *TransferQueue) canRetryObject(rc *retryCounter, oid string, err error) bool {
+	if !q.hasRetriesLeft(rc, oid) {
 		return false
 	}
 
 	return q.canRetry(err)
 }
 
-func (q *TransferQueue) canRetryObjectLater(oid string, err error) (time.Time, bool) {
-	if count, ok := q.rc.CanRetry(oid); !ok {
-		tracerx.Printf("tq: refusing to retry %q, too many retries (%d)", oid, count)
+func (q *TransferQueue) canRetryObjectLater(rc *retryCounter, oid string, err error) (time.Time, bool) {
+	if !q.hasRetriesLeft(rc, oid) {
 		return time.Time{}, false
 	}
 
 	return q.canRetryLater(err)
 }
 
+// hasRetriesLeft returns whether the object given by "oid" has not yet met its
+// retry limit in the given retry counter, recording it as having exhausted its
+// retries if it has.
+func (q *TransferQueue) hasRetriesLeft(rc *retryCounter, oid string) bool {
+	count, ok := rc.CanRetry(oid)
+	if !ok {
+		tracerx.Printf("tq: refusing to retry %q, too many retries (%d)", oid, count)
+
+		q.trMutex.Lock()
+		q.exhausted[oid] = struct{}{}
+		q.trMutex.Unlock()
+	}
+	return ok
+}
+
+// ExhaustedRetries returns the sorted OIDs of the objects which failed to be
+// transferred after running out of retries. It should only be called after
+// Wait().
+func (q *TransferQueue) ExhaustedRetries() []string {
+	q.trMutex.Lock()
+	defer q.trMutex.Unlock()
+
+	oids := make([]string, 0, len(q.exhausted))
+	for oid := range q.exhausted {
+		oids = append(oids, oid)
+	}
+	sort.Strings(oids)
+	return oids
+}
+
 // Errors returns any errors encountered during transfer.
 func (q *TransferQueue) Errors() []error {
 	return q.errors
@@ -1,10 +1,23 @@
 package tq
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/git-lfs/git-lfs/v3/errors"
+	"github.com/git-lfs/git-lfs/v3/fs"
+	"github.com/git-lfs/git-lfs/v3/lfsapi"
+	"github.com/git-lfs/git-lfs/v3/lfshttp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestManifestDefaultsToFixedRetries(t *testing.T) {
@@ -80,3 +93,375 @@ func TestBatchSizeReturnsBatchSize(t *testing.T) {
 
 	assert.Equal(t, 3, q.BatchSize())
 }
+
+func TestTransferQueueCancel(t *testing.T) {
+	oid := strings.Repeat("a", 64)
+	started := make(chan struct{})
+	var batches int32
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			atomic.AddInt32(&batches, 1)
+
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				o.Actions = ActionSet{"download": &Action{Href: srv.URL + "/objects/" + o.Oid}}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+		case "/objects/" + oid:
+			// Send part of the object, and then stall until the
+			// request is aborted.
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte(strings.Repeat("x", 50)))
+			w.(http.Flusher).Flush()
+			close(started)
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url": srv.URL + "/api",
+	}))
+	require.Nil(t, err)
+
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+	path := filepath.Join(t.TempDir(), oid)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := NewTransferQueue(Download, NewManifest(f, cli, "", ""), "origin", WithContext(ctx), WithBatchSize(1))
+	q.Add("a.dat", path, oid, 100, false, nil)
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for download to start")
+	}
+	cancel()
+
+	// Objects added once the queue is cancelled are not requested.
+	q.Add("b.dat", path+"-b", strings.Repeat("b", 64), 100, false, nil)
+	q.Wait()
+
+	errs := q.Errors()
+	if assert.Equal(t, 1, len(errs)) {
+		assert.Equal(t, context.Canceled, errors.Cause(errs[0]))
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&batches))
+
+	assert.NoFileExists(t, path)
+	incomplete, err := os.ReadDir(filepath.Join(f.LFSStorageDir, "incomplete"))
+	require.Nil(t, err)
+	assert.Empty(t, incomplete)
+}
+
+func TestTransferQueueRetriesPerObject(t *testing.T) {
+	oid := strings.Repeat("a", 64)
+	var batches, downloads int32
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			atomic.AddInt32(&batches, 1)
+
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				o.Actions = ActionSet{"download": &Action{Href: srv.URL + "/objects/" + o.Oid}}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+		case "/objects/" + oid:
+			atomic.AddInt32(&downloads, 1)
+			w.WriteHeader(500)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":                          srv.URL + "/api",
+		"lfs.transfer.maxretries":          "5",
+		"lfs.transfer.maxretriesperobject": "3",
+		"lfs.transfer.maxretrydelay":       "1",
+	}))
+	require.Nil(t, err)
+
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+	path := filepath.Join(t.TempDir(), oid)
+
+	m := NewManifest(f, cli, "", "")
+	assert.Equal(t, 5, m.MaxRetries())
+	assert.Equal(t, 3, m.Upgrade().maxRetriesPerObject)
+
+	// The option overrides the configured number of retries.
+	q := NewTransferQueue(Download, m, "origin", WithMaxRetriesPerObject(1), WithBatchSize(1))
+	q.Add("a.dat", path, oid, 100, false, nil)
+	q.Wait()
+
+	assert.Equal(t, 1, len(q.Errors()))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&downloads))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&batches))
+	assert.Equal(t, []string{oid}, q.ExhaustedRetries())
+}
+
+func TestTransferQueueRefreshesExpiredActions(t *testing.T) {
+	contents := "contents"
+	oid := "d1b2a59fbea7e20077af9f91b27e95e865061b270be03ff539ab3b73587882e8"
+	var batches, downloads int32
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			// The actions of the first two responses have expired
+			// before they're received.
+			n := atomic.AddInt32(&batches, 1)
+
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				a := &Action{Href: srv.URL + "/objects/" + o.Oid}
+				if n <= 2 {
+					a.ExpiresAt = time.Now().Add(-time.Minute)
+				}
+				o.Actions = ActionSet{"download": a}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+		case "/objects/" + oid:
+			atomic.AddInt32(&downloads, 1)
+			w.Write([]byte(contents))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":                    srv.URL + "/api",
+		"lfs.transfer.maxretries":    "3",
+		"lfs.transfer.maxretrydelay": "1",
+	}))
+	require.Nil(t, err)
+
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+	path := filepath.Join(t.TempDir(), oid)
+
+	// Requesting fresh actions doesn't count against the retries of the
+	// transfer of the object.
+	m := NewManifest(f, cli, "", "")
+	q := NewTransferQueue(Download, m, "origin", WithMaxRetriesPerObject(1), WithBatchSize(1))
+	q.Add("a.dat", path, oid, int64(len(contents)), false, nil)
+	q.Wait()
+
+	assert.Empty(t, q.Errors())
+	assert.EqualValues(t, 3, atomic.LoadInt32(&batches))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&downloads))
+
+	by, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, contents, string(by))
+}
+
+func TestTransferQueueRefreshesActionsRejectedAfterExpiry(t *testing.T) {
+	contents := "contents"
+	oid := "d1b2a59fbea7e20077af9f91b27e95e865061b270be03ff539ab3b73587882e8"
+	var batches int32
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			n := atomic.AddInt32(&batches, 1)
+
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				href := srv.URL + "/objects/" + o.Oid
+				if n <= 2 {
+					href += "/expiring"
+				}
+				o.Actions = ActionSet{"download": &Action{
+					Href:      href,
+					ExpiresAt: time.Now().Add(objectExpirationToTransfer + 200*time.Millisecond),
+				}}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+		case "/objects/" + oid + "/expiring":
+			// Reject the request once the action has expired.
+			time.Sleep(300 * time.Millisecond)
+			w.WriteHeader(403)
+		case "/objects/" + oid:
+			w.Write([]byte(contents))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":                    srv.URL + "/api",
+		"lfs.transfer.maxretrydelay": "1",
+	}))
+	require.Nil(t, err)
+
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+	path := filepath.Join(t.TempDir(), oid)
+
+	m := NewManifest(f, cli, "", "")
+	q := NewTransferQueue(Download, m, "origin", WithMaxRetriesPerObject(1), WithBatchSize(1))
+	q.Add("a.dat", path, oid, int64(len(contents)), false, nil)
+	q.Wait()
+
+	assert.Empty(t, q.Errors())
+	assert.EqualValues(t, 3, atomic.LoadInt32(&batches))
+}
+
+// beginCountingAdapter is a test adapter which counts the number of times it
+// is begun.
+type beginCountingAdapter struct {
+	testAdapter
+	begun *int32
+}
+
+func (a *beginCountingAdapter) Begin(cfg AdapterConfig, cb ProgressCallback) error {
+	atomic.AddInt32(a.begun, 1)
+	return nil
+}
+
+func TestTransferQueueAdapterCache(t *testing.T) {
+	contents := "contents"
+	oid := "d1b2a59fbea7e20077af9f91b27e95e865061b270be03ff539ab3b73587882e8"
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				o.Actions = ActionSet{"download": &Action{Href: srv.URL + "/objects/" + o.Oid}}
+			}
+
+			// Choose the default adapter by leaving the
+			// transfer adapter name empty.
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+		case "/objects/" + oid:
+			w.Write([]byte(contents))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":                   srv.URL + "/api",
+		"lfs.transfer.adaptercache": "true",
+	}))
+	require.Nil(t, err)
+
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+	cache := newAdapterCache(f)
+	url := srv.URL + "/api"
+
+	var begun int32
+	download := func() {
+		m := NewManifest(f, cli, "", "")
+		m.RegisterNewAdapterFunc("counting", Download, func(name string, dir Direction) Adapter {
+			return &beginCountingAdapter{testAdapter{name, dir}, &begun}
+		})
+
+		q := NewTransferQueue(Download, m, "origin", WithBatchSize(1))
+		q.Add("a.dat", filepath.Join(t.TempDir(), oid), oid, int64(len(contents)), false, nil)
+		q.Wait()
+		assert.Empty(t, q.Errors())
+	}
+
+	// On a miss, no adapter is begun early, and the adapter chosen by
+	// the server is recorded as it is.
+	download()
+	assert.EqualValues(t, 0, atomic.LoadInt32(&begun))
+	name, ok := cache.Get(Download, url)
+	assert.True(t, ok)
+	assert.Equal(t, "", name)
+
+	// On a hit, the cached adapter is begun before the batch request is
+	// made, and replaced by the one the server chooses.
+	require.Nil(t, cache.Set(Download, url, "counting"))
+	download()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&begun))
+	name, ok = cache.Get(Download, url)
+	assert.True(t, ok)
+	assert.Equal(t, "", name)
+}
+
+func TestTransferQueueRestartedDownloadReportsProgressOnce(t *testing.T) {
+	contents := "contents"
+	oid := "d1b2a59fbea7e20077af9f91b27e95e865061b270be03ff539ab3b73587882e8"
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/objects/batch":
+			bReq := &batchRequest{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(bReq))
+			for _, o := range bReq.Objects {
+				o.Actions = ActionSet{"download": &Action{Href: srv.URL + "/objects/" + o.Oid}}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&BatchResponse{Objects: bReq.Objects})
+		case "/objects/" + oid:
+			http.ServeContent(w, r, "", time.Time{}, strings.NewReader(contents))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := lfsapi.NewClient(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url": srv.URL + "/api",
+	}))
+	require.Nil(t, err)
+
+	// Leave a corrupt partial download which the server allows to be
+	// resumed, so that the download must be restarted.
+	f := &fs.Filesystem{LFSStorageDir: t.TempDir()}
+	require.Nil(t, os.MkdirAll(filepath.Join(f.LFSStorageDir, "incomplete"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(f.LFSStorageDir, "incomplete", oid+".part"), []byte("xxx"), 0644))
+
+	var progress int64
+	cb := func(total, read int64, current int) error {
+		atomic.AddInt64(&progress, int64(current))
+		return nil
+	}
+
+	path := filepath.Join(t.TempDir(), oid)
+	q := NewTransferQueue(Download, NewManifest(f, cli, "", ""), "origin", WithProgressCallback(cb))
+	q.Add("a.dat", path, oid, int64(len(contents)), false, nil)
+	q.Wait()
+
+	assert.Empty(t, q.Errors())
+	assert.EqualValues(t, len(contents), atomic.LoadInt64(&progress))
+
+	by, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, contents, string(by))
+}